@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/auth"
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/migrations"
+	"github.com/dieg0code/products-microservice/src/tenant"
+)
+
+// errRequiresDB is returned by the operations the HTTP API doesn't expose
+var errRequiresDB = errors.New("only available with --db")
+
+// Catalog is what the commands need from the service, reached through its HTTP API or
+// straight through its database
+type Catalog interface {
+	ListProducts(ctx context.Context, page int, pageSize int) ([]response.ProductResponse, error)
+	GetProduct(ctx context.Context, productID uint) (*response.ProductResponse, error)
+	CreateProduct(ctx context.Context, product *request.CreateProductRequest) (uint, error)
+	UpdateProduct(ctx context.Context, productID uint, product *request.UpdateProductRequest) (*response.ProductResponse, error)
+	DeleteProduct(ctx context.Context, productID uint) error
+	ExecuteBatch(ctx context.Context, batch *request.BatchProductRequest) (*response.BatchProductResponse, error)
+	AdjustStock(ctx context.Context, productID uint, adjustment *request.AdjustStockRequest) (*response.ProductResponse, error)
+	PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error)
+	Migrator() (*migrations.Migrator, error)
+	Close() error
+}
+
+// apiError is a response of the service outside the 2xx range
+type apiError struct {
+	StatusCode int
+	Msg        string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Msg)
+}
+
+type httpCatalog struct {
+	client   *http.Client
+	server   string
+	apiKey   string
+	token    string
+	tenantID string
+}
+
+// ListProducts implements Catalog.
+func (h *httpCatalog) ListProducts(ctx context.Context, page int, pageSize int) ([]response.ProductResponse, error) {
+	query := url.Values{"page": {strconv.Itoa(page)}, "pageSize": {strconv.Itoa(pageSize)}}
+
+	var products []response.ProductResponse
+	err := h.do(ctx, http.MethodGet, "/api/v1/products?"+query.Encode(), nil, &products)
+	return products, err
+}
+
+// GetProduct implements Catalog.
+func (h *httpCatalog) GetProduct(ctx context.Context, productID uint) (*response.ProductResponse, error) {
+	var product response.ProductResponse
+	err := h.do(ctx, http.MethodGet, productPath(productID), nil, &product)
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// CreateProduct implements Catalog.
+func (h *httpCatalog) CreateProduct(ctx context.Context, product *request.CreateProductRequest) (uint, error) {
+	var productID uint
+	err := h.do(ctx, http.MethodPost, "/api/v1/products", product, &productID)
+	return productID, err
+}
+
+// UpdateProduct implements Catalog.
+func (h *httpCatalog) UpdateProduct(ctx context.Context, productID uint, product *request.UpdateProductRequest) (*response.ProductResponse, error) {
+	var updated response.ProductResponse
+	err := h.do(ctx, http.MethodPut, productPath(productID), product, &updated)
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteProduct implements Catalog.
+func (h *httpCatalog) DeleteProduct(ctx context.Context, productID uint) error {
+	return h.do(ctx, http.MethodDelete, productPath(productID), nil, nil)
+}
+
+// ExecuteBatch implements Catalog.
+func (h *httpCatalog) ExecuteBatch(ctx context.Context, batch *request.BatchProductRequest) (*response.BatchProductResponse, error) {
+	var result response.BatchProductResponse
+	err := h.do(ctx, http.MethodPost, "/api/v1/products/batch", batch, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// AdjustStock implements Catalog.
+func (h *httpCatalog) AdjustStock(ctx context.Context, productID uint, adjustment *request.AdjustStockRequest) (*response.ProductResponse, error) {
+	var product response.ProductResponse
+	err := h.do(ctx, http.MethodPost, productPath(productID)+"/stock", adjustment, &product)
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// PurgeDeleted implements Catalog.
+func (h *httpCatalog) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	return 0, errRequiresDB
+}
+
+// Migrator implements Catalog.
+func (h *httpCatalog) Migrator() (*migrations.Migrator, error) {
+	return nil, errRequiresDB
+}
+
+// Close implements Catalog.
+func (h *httpCatalog) Close() error {
+	h.client.CloseIdleConnections()
+	return nil
+}
+
+// do sends body as JSON and decodes the data of the service's response into data
+func (h *httpCatalog) do(ctx context.Context, method string, path string, body interface{}, data interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, h.server+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if h.apiKey != "" {
+		req.Header.Set(auth.ApiKeyHeader, h.apiKey)
+	}
+	if h.token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.token)
+	}
+	if h.tenantID != "" {
+		req.Header.Set(tenant.HeaderName, h.tenantID)
+	}
+
+	res, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	payload, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	// Data holds a pointer, so the payload is decoded straight into the caller's value
+	base := response.BaseResponse{Data: data}
+	err = json.Unmarshal(payload, &base)
+	if res.StatusCode >= 300 {
+		if err != nil || base.Msg == "" {
+			base.Msg = strings.TrimSpace(string(payload))
+		}
+		return &apiError{StatusCode: res.StatusCode, Msg: base.Msg}
+	}
+	if err != nil {
+		return fmt.Errorf("decoding the response of %s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+func productPath(productID uint) string {
+	return "/api/v1/products/" + strconv.FormatUint(uint64(productID), 10)
+}
+
+func newHTTPCatalog(server string, apiKey string, token string, tenantID string, timeout time.Duration) Catalog {
+	return &httpCatalog{
+		client:   &http.Client{Timeout: timeout},
+		server:   strings.TrimSuffix(server, "/"),
+		apiKey:   apiKey,
+		token:    token,
+		tenantID: tenantID,
+	}
+}
@@ -0,0 +1,451 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/migrations"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// exportPageSize is the page size export walks the catalog with
+	exportPageSize = 100
+	// importBatchSize is the most operations the batch endpoint accepts at once
+	importBatchSize = 100
+	// DefaultPurgeAge keeps deleted products around for a month, so mistakes can be undone
+	DefaultPurgeAge = 30 * 24 * time.Hour
+)
+
+type command func(ctx context.Context, c *cli, args []string) error
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"list":    listProducts,
+		"get":     getProduct,
+		"create":  createProduct,
+		"update":  updateProduct,
+		"delete":  deleteProduct,
+		"import":  importProducts,
+		"export":  exportProducts,
+		"stock":   adjustStock,
+		"purge":   purgeDeleted,
+		"migrate": migrate,
+	}
+}
+
+func listProducts(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	page := fs.Int("page", 1, "page to show")
+	pageSize := fs.Int("page-size", 10, "products per page")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err = expectArgs(args); err != nil {
+		return err
+	}
+	if *page < 1 || *pageSize < 1 {
+		return usageError("--page and --page-size must be positive")
+	}
+
+	products, err := c.catalog.ListProducts(ctx, *page, *pageSize)
+	if err != nil {
+		return err
+	}
+	return c.out.products(products)
+}
+
+func getProduct(ctx context.Context, c *cli, args []string) error {
+	if err := expectArgs(args, "ID"); err != nil {
+		return err
+	}
+	productID, err := parseID(args[0])
+	if err != nil {
+		return err
+	}
+
+	product, err := c.catalog.GetProduct(ctx, productID)
+	if err != nil {
+		return err
+	}
+	return c.out.product(product)
+}
+
+func createProduct(ctx context.Context, c *cli, args []string) error {
+	product := &request.CreateProductRequest{}
+	fs := flag.NewFlagSet("create", flag.ContinueOnError)
+	fs.StringVar(&product.Name, "name", "", "product name")
+	fs.StringVar(&product.Category, "category", "", "product category")
+	fs.IntVar(&product.Price, "price", 0, "price")
+	fs.IntVar(&product.Stock, "stock", 0, "units in stock")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err = expectArgs(args); err != nil {
+		return err
+	}
+	if err = c.validate.Struct(product); err != nil {
+		return usageError(err.Error())
+	}
+
+	productID, err := c.catalog.CreateProduct(ctx, product)
+	if err != nil {
+		return err
+	}
+	return c.out.message(fmt.Sprintf("Created product %d", productID), map[string]interface{}{"product_id": productID})
+}
+
+func updateProduct(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("update", flag.ContinueOnError)
+	name := fs.String("name", "", "new name")
+	category := fs.String("category", "", "new category")
+	price := fs.Int("price", 0, "new price")
+	stock := fs.Int("stock", 0, "new units in stock, prefer the stock command for relative changes")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err = expectArgs(args, "ID"); err != nil {
+		return err
+	}
+	productID, err := parseID(args[0])
+	if err != nil {
+		return err
+	}
+
+	// The API replaces the whole product, so the fields that weren't given keep their values
+	current, err := c.catalog.GetProduct(ctx, productID)
+	if err != nil {
+		return err
+	}
+	product := &request.UpdateProductRequest{Name: current.Name, Category: current.Category, Price: current.Price, Stock: current.Stock}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "name":
+			product.Name = *name
+		case "category":
+			product.Category = *category
+		case "price":
+			product.Price = *price
+		case "stock":
+			product.Stock = *stock
+		}
+	})
+	if err = c.validate.Struct(product); err != nil {
+		return usageError(err.Error())
+	}
+
+	updated, err := c.catalog.UpdateProduct(ctx, productID, product)
+	if err != nil {
+		return err
+	}
+	return c.out.product(updated)
+}
+
+func deleteProduct(ctx context.Context, c *cli, args []string) error {
+	if err := expectArgs(args, "ID"); err != nil {
+		return err
+	}
+	productID, err := parseID(args[0])
+	if err != nil {
+		return err
+	}
+
+	err = c.catalog.DeleteProduct(ctx, productID)
+	if err != nil {
+		return err
+	}
+	return c.out.message(fmt.Sprintf("Deleted product %d", productID), map[string]interface{}{"product_id": productID})
+}
+
+// importProducts reads the products written by export, in batches of importBatchSize.
+// all_or_nothing therefore only holds within each batch. Products with a product_id
+// update it, --create ignores the IDs and --upsert creates the products whose ID isn't
+// in the catalog. IDs are assigned by the database, so created products get new ones.
+func importProducts(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	mode := fs.String("mode", request.BatchModeBestEffort, "all_or_nothing stops at the first batch with a failure")
+	create := fs.Bool("create", false, "ignore product_id and create every product, e.g. to copy a catalog into another tenant")
+	upsert := fs.Bool("upsert", false, "create the products whose product_id doesn't exist instead of failing them")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err = expectArgs(args, "FILE"); err != nil {
+		return err
+	}
+	if *mode != request.BatchModeAllOrNothing && *mode != request.BatchModeBestEffort {
+		return usageError(fmt.Sprintf("--mode must be %s or %s", request.BatchModeAllOrNothing, request.BatchModeBestEffort))
+	}
+	if *create && *upsert {
+		return usageError("--create and --upsert can't be combined")
+	}
+
+	products, err := readProducts(c.stdin, args[0])
+	if err != nil {
+		return err
+	}
+
+	var existing map[uint]bool
+	if *upsert {
+		catalog, err := allProducts(ctx, c.catalog)
+		if err != nil {
+			return err
+		}
+		existing = make(map[uint]bool, len(catalog))
+		for _, product := range catalog {
+			existing[product.ProductID] = true
+		}
+	}
+
+	operations := make([]request.BatchProductOperation, 0, len(products))
+	for i, product := range products {
+		productID := product.ProductID
+		if *create || (*upsert && !existing[productID]) {
+			productID = 0
+		}
+
+		operation := request.BatchProductOperation{
+			Op:        request.BatchOpCreate,
+			ProductID: productID,
+			Product:   &request.CreateProductRequest{Name: product.Name, Category: product.Category, Price: product.Price, Stock: product.Stock},
+		}
+		if productID != 0 {
+			operation.Op = request.BatchOpUpdate
+		}
+		if err = c.validate.Struct(operation); err != nil {
+			return fmt.Errorf("product %d of %s: %w", i, args[0], err)
+		}
+		operations = append(operations, operation)
+	}
+
+	var results []response.BatchOperationResult
+	failed := 0
+	for start := 0; start < len(operations); start += importBatchSize {
+		end := min(start+importBatchSize, len(operations))
+		result, err := c.catalog.ExecuteBatch(ctx, &request.BatchProductRequest{Mode: *mode, Operations: operations[start:end]})
+		if err != nil {
+			return fmt.Errorf("importing products %d to %d: %w", start, end-1, err)
+		}
+
+		for _, operation := range result.Results {
+			operation.Index += start
+			results = append(results, operation)
+		}
+		failed += result.Failed
+		if failed > 0 && *mode == request.BatchModeAllOrNothing {
+			break
+		}
+	}
+
+	err = c.out.batch(results)
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d products failed", failed, len(operations))
+	}
+	return nil
+}
+
+func exportProducts(ctx context.Context, c *cli, args []string) error {
+	if len(args) > 1 {
+		return usageError("expected at most one FILE")
+	}
+
+	products, err := allProducts(ctx, c.catalog)
+	if err != nil {
+		return err
+	}
+
+	out := &printer{w: c.out.w, format: OutputJSON}
+	if c.out.format == OutputYAML {
+		out.format = OutputYAML
+	}
+	if len(args) == 0 || args[0] == "-" {
+		return out.products(products)
+	}
+
+	file, err := os.Create(args[0])
+	if err != nil {
+		return err
+	}
+	out.w = file
+	err = out.products(products)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stderr, "Exported %d products to %s\n", len(products), args[0])
+	return nil
+}
+
+// allProducts walks the catalog page by page, the pages are ordered by ID so none
+// overlap or skip a product
+func allProducts(ctx context.Context, catalog Catalog) ([]response.ProductResponse, error) {
+	products := []response.ProductResponse{}
+	for page := 1; ; page++ {
+		batch, err := catalog.ListProducts(ctx, page, exportPageSize)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, batch...)
+		if len(batch) < exportPageSize {
+			return products, nil
+		}
+	}
+}
+
+func adjustStock(ctx context.Context, c *cli, args []string) error {
+	// No flags, so a negative DELTA isn't taken for one
+	if err := expectArgs(args, "ID", "DELTA"); err != nil {
+		return err
+	}
+	productID, err := parseID(args[0])
+	if err != nil {
+		return err
+	}
+	delta, err := strconv.Atoi(args[1])
+	if err != nil || delta == 0 {
+		return usageError(fmt.Sprintf("DELTA must be a non zero integer, got %q", args[1]))
+	}
+
+	product, err := c.catalog.AdjustStock(ctx, productID, &request.AdjustStockRequest{Delta: delta})
+	if err != nil {
+		return err
+	}
+	return c.out.product(product)
+}
+
+func purgeDeleted(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("purge", flag.ContinueOnError)
+	olderThan := fs.Duration("older-than", DefaultPurgeAge, "only purge products deleted longer ago than this")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err = expectArgs(args); err != nil {
+		return err
+	}
+	if *olderThan < 0 {
+		return usageError("--older-than must not be negative")
+	}
+
+	purged, err := c.catalog.PurgeDeleted(ctx, time.Now().Add(-*olderThan))
+	if err != nil {
+		return err
+	}
+	return c.out.message(fmt.Sprintf("Purged %d deleted products", purged), map[string]interface{}{"purged": purged})
+}
+
+func migrate(ctx context.Context, c *cli, args []string) error {
+	if len(args) == 0 {
+		return usageError("expected up, down [N] or status")
+	}
+
+	migrator, err := c.catalog.Migrator()
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		if err = expectArgs(args[1:]); err != nil {
+			return err
+		}
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		return c.out.message(fmt.Sprintf("Applied %d migrations", len(applied)), map[string]interface{}{"applied": migrationVersions(applied)})
+	case "down":
+		steps := 1
+		if len(args) > 2 {
+			return usageError("expected down [N]")
+		}
+		if len(args) == 2 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return usageError(fmt.Sprintf("N must be a positive integer, got %q", args[1]))
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		return c.out.message(fmt.Sprintf("Reverted %d migrations", len(reverted)), map[string]interface{}{"reverted": migrationVersions(reverted)})
+	case "status":
+		if err = expectArgs(args[1:]); err != nil {
+			return err
+		}
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return c.out.migrations(statuses)
+	default:
+		return usageError(fmt.Sprintf("unknown migrate command %q, expected up, down [N] or status", args[0]))
+	}
+}
+
+func migrationVersions(applied []migrations.Migration) []uint64 {
+	versions := make([]uint64, 0, len(applied))
+	for _, migration := range applied {
+		versions = append(versions, migration.Version)
+	}
+	return versions
+}
+
+func parseID(value string) (uint, error) {
+	productID, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || productID == 0 {
+		return 0, usageError(fmt.Sprintf("ID must be a positive integer, got %q", value))
+	}
+	return uint(productID), nil
+}
+
+// readProducts reads a JSON or YAML list of products from path, - being stdin. JSON
+// is valid YAML, so both go through the YAML decoder and then the DTOs' json names.
+func readProducts(stdin io.Reader, path string) ([]response.ProductResponse, error) {
+	var content []byte
+	var err error
+	if path == "-" {
+		content, err = io.ReadAll(stdin)
+	} else {
+		content, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var generic interface{}
+	err = yaml.Unmarshal(content, &generic)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	encoded, err := json.Marshal(generic)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var products []response.ProductResponse
+	err = json.Unmarshal(encoded, &products)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: expected a list of products: %w", path, err)
+	}
+	return products, nil
+}
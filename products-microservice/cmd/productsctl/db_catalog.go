@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/db"
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/migrations"
+	"github.com/dieg0code/products-microservice/src/repository"
+	"github.com/dieg0code/products-microservice/src/services"
+	"github.com/dieg0code/products-microservice/src/tenant"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// dbCatalog runs the service layer in process. It skips the running service, so its
+// product cache may serve the previous values until they expire and stream
+// subscribers aren't notified.
+type dbCatalog struct {
+	db          *gorm.DB
+	productRepo repository.ProductRepository
+	products    services.ProductService
+	stock       services.StockService
+	tenantID    string
+}
+
+// ListProducts implements Catalog.
+func (d *dbCatalog) ListProducts(ctx context.Context, page int, pageSize int) ([]response.ProductResponse, error) {
+	return d.products.GetAllProducts(d.scope(ctx), page, pageSize)
+}
+
+// GetProduct implements Catalog.
+func (d *dbCatalog) GetProduct(ctx context.Context, productID uint) (*response.ProductResponse, error) {
+	return d.products.GetProductById(d.scope(ctx), productID)
+}
+
+// CreateProduct implements Catalog.
+func (d *dbCatalog) CreateProduct(ctx context.Context, product *request.CreateProductRequest) (uint, error) {
+	productID, err := d.products.CreateProduct(d.scope(ctx), product)
+	if err != nil {
+		return 0, err
+	}
+	return *productID, nil
+}
+
+// UpdateProduct implements Catalog.
+func (d *dbCatalog) UpdateProduct(ctx context.Context, productID uint, product *request.UpdateProductRequest) (*response.ProductResponse, error) {
+	return d.products.UpdateProduct(d.scope(ctx), productID, product)
+}
+
+// DeleteProduct implements Catalog.
+func (d *dbCatalog) DeleteProduct(ctx context.Context, productID uint) error {
+	return d.products.DeleteProduct(d.scope(ctx), productID)
+}
+
+// ExecuteBatch implements Catalog.
+func (d *dbCatalog) ExecuteBatch(ctx context.Context, batch *request.BatchProductRequest) (*response.BatchProductResponse, error) {
+	return d.products.ExecuteBatch(d.scope(ctx), batch)
+}
+
+// AdjustStock implements Catalog.
+func (d *dbCatalog) AdjustStock(ctx context.Context, productID uint, adjustment *request.AdjustStockRequest) (*response.ProductResponse, error) {
+	return d.stock.AdjustStock(d.scope(ctx), productID, adjustment)
+}
+
+// PurgeDeleted implements Catalog.
+func (d *dbCatalog) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	return d.productRepo.PurgeDeleted(d.scope(ctx), deletedBefore)
+}
+
+// Migrator implements Catalog.
+func (d *dbCatalog) Migrator() (*migrations.Migrator, error) {
+	return migrations.NewMigrator(d.db)
+}
+
+// Close implements Catalog.
+func (d *dbCatalog) Close() error {
+	return db.CloseDatabaseConnection(d.db)
+}
+
+func (d *dbCatalog) scope(ctx context.Context) context.Context {
+	return tenant.WithTenant(ctx, d.tenantID)
+}
+
+func newDBCatalog(conn *gorm.DB, tenantID string) Catalog {
+	// The commands print their own output, gorm would mix its logs into it
+	conn = conn.Session(&gorm.Session{Logger: logger.Discard})
+	productRepo := repository.NewPorductRespositoryImpl(conn)
+
+	return &dbCatalog{
+		db:          conn,
+		productRepo: productRepo,
+		products:    services.NewProductServiceImpl(productRepo),
+		stock:       services.NewStockServiceImpl(repository.NewStockRepositoryImpl(conn)),
+		tenantID:    tenantID,
+	}
+}
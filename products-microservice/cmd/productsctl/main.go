@@ -0,0 +1,231 @@
+// productsctl manages the product catalog through the service's HTTP API, or straight
+// through its database with --db.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/auth"
+	"github.com/dieg0code/products-microservice/src/config"
+	"github.com/dieg0code/products-microservice/src/db"
+	"github.com/dieg0code/products-microservice/src/tenant"
+	"github.com/dieg0code/products-microservice/src/validation"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+)
+
+const usage = `productsctl manages the product catalog through the service's HTTP API, or
+straight through its database with --db.
+
+Usage:
+  productsctl [flags] <command> [arguments]
+
+Commands:
+  list [--page N] [--page-size N]                   list products
+  get ID                                            show a product
+  create --name N --category C --price P --stock S  create a product
+  update ID [--name N] [--category C] [--price P] [--stock S]
+                                                    change the given fields of a product
+  delete ID                                         delete a product
+  import [--mode all_or_nothing|best_effort] [--create|--upsert] FILE
+                                                    create the products of a JSON or YAML file and
+                                                    update the ones with a product_id, - reads stdin.
+                                                    --create ignores the IDs, --upsert creates the
+                                                    products whose ID doesn't exist
+  export [FILE]                                     write every product as JSON, or YAML with -o yaml
+  stock ID DELTA                                    add DELTA, which may be negative, to the stock
+  purge [--older-than 720h]                         remove soft deleted products for good (--db only)
+  migrate up|down [N]|status                        run the database migrations (--db only)
+
+Flags:
+`
+
+// env holds the environment variables read as flag defaults
+var env = struct {
+	Server, ApiKey, Token, Tenant string
+}{"PRODUCTSCTL_SERVER", "PRODUCTSCTL_API_KEY", "PRODUCTSCTL_TOKEN", "PRODUCTSCTL_TENANT"}
+
+type options struct {
+	server     string
+	apiKey     string
+	token      string
+	tenantID   string
+	useDB      bool
+	configFile string
+	output     string
+	timeout    time.Duration
+}
+
+// cli carries what every command needs
+type cli struct {
+	catalog  Catalog
+	out      *printer
+	stdin    io.Reader
+	stderr   io.Writer
+	validate *validator.Validate
+}
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdin, os.Stdout, os.Stderr, os.LookupEnv))
+}
+
+// run executes the command in args and returns the exit code
+func run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer, lookupEnv func(string) (string, bool)) int {
+	getenv := func(name string, fallback string) string {
+		if value, ok := lookupEnv(name); ok {
+			return value
+		}
+		return fallback
+	}
+
+	opts := options{}
+	fs := flag.NewFlagSet("productsctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&opts.server, "server", getenv(env.Server, "http://localhost:8080"), "base URL of the service, defaults to $"+env.Server)
+	fs.StringVar(&opts.apiKey, "api-key", getenv(env.ApiKey, ""), "API key sent in the "+auth.ApiKeyHeader+" header, defaults to $"+env.ApiKey)
+	fs.StringVar(&opts.token, "token", getenv(env.Token, ""), "bearer token, defaults to $"+env.Token)
+	fs.StringVar(&opts.tenantID, "tenant", getenv(env.Tenant, ""), "tenant to manage, defaults to $"+env.Tenant+" or the service's default tenant")
+	fs.BoolVar(&opts.useDB, "db", false, "connect to the database with the service's configuration instead of calling the API")
+	fs.StringVar(&opts.configFile, "config", "", "service configuration file read with --db, defaults to $"+config.FileEnv)
+	fs.StringVar(&opts.output, "o", OutputTable, "output format: table, json or yaml")
+	fs.DurationVar(&opts.timeout, "timeout", time.Minute, "give up on the command after this long")
+
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+	if opts.output != OutputTable && opts.output != OutputJSON && opts.output != OutputYAML {
+		fmt.Fprintf(stderr, "productsctl: -o must be table, json or yaml, got %q\n", opts.output)
+		return 2
+	}
+	if opts.tenantID != "" && !tenant.Valid(opts.tenantID) {
+		fmt.Fprintf(stderr, "productsctl: invalid tenant %q\n", opts.tenantID)
+		return 2
+	}
+
+	command, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(stderr, "productsctl: unknown command %q\n", fs.Arg(0))
+		fs.Usage()
+		return 2
+	}
+
+	catalog, err := openCatalog(opts, lookupEnv)
+	if err != nil {
+		fmt.Fprintf(stderr, "productsctl: %v\n", err)
+		return 2
+	}
+	defer catalog.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, opts.timeout)
+	defer cancel()
+
+	c := &cli{
+		catalog:  catalog,
+		out:      &printer{w: stdout, format: opts.output},
+		stdin:    stdin,
+		stderr:   stderr,
+		validate: validation.Validator(),
+	}
+	err = command(ctx, c, fs.Args()[1:])
+	var usageErr usageError
+	if errors.As(err, &usageErr) {
+		fmt.Fprintf(stderr, "productsctl %s: %v\n", fs.Arg(0), err)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "productsctl %s: %v\n", fs.Arg(0), err)
+		return 1
+	}
+	return 0
+}
+
+func openCatalog(opts options, lookupEnv func(string) (string, bool)) (catalog Catalog, err error) {
+	if !opts.useDB {
+		return newHTTPCatalog(opts.server, opts.apiKey, opts.token, opts.tenantID, opts.timeout), nil
+	}
+
+	// The service's own flags aren't accepted here, only its file and environment
+	var args []string
+	if opts.configFile != "" {
+		args = []string{"--config", opts.configFile}
+	}
+	flags, err := config.ParseFlags("productsctl", args, io.Discard)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.Load(flags, lookupEnv)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	// The service layer logs every operation, only problems matter here
+	logrus.SetLevel(logrus.WarnLevel)
+
+	tenantID := opts.tenantID
+	if tenantID == "" {
+		tenantID = cfg.Tenants.Default
+	}
+	if tenantID == "" {
+		return nil, errors.New("--tenant is required, the service has no default tenant")
+	}
+
+	// DatabaseConnection panics once it runs out of attempts
+	defer func() {
+		if r := recover(); r != nil {
+			catalog, err = nil, fmt.Errorf("%v", r)
+		}
+	}()
+	return newDBCatalog(db.DatabaseConnection(cfg.Database), tenantID), nil
+}
+
+// usageError reports arguments the command can't make sense of
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+// parseArgs parses fs from args, flags may come before or after the positional
+// arguments, which are returned
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	fs.SetOutput(io.Discard)
+
+	var positional []string
+	for {
+		err := fs.Parse(args)
+		if err != nil {
+			return nil, usageError(err.Error())
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+func expectArgs(args []string, names ...string) error {
+	if len(args) != len(names) {
+		return usageError(fmt.Sprintf("expected %s, got %d arguments", strings.Join(names, " "), len(args)))
+	}
+	return nil
+}
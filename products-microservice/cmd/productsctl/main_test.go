@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/controllers"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/middleware"
+	"github.com/dieg0code/products-microservice/src/models"
+	"github.com/dieg0code/products-microservice/src/repository"
+	"github.com/dieg0code/products-microservice/src/router"
+	"github.com/dieg0code/products-microservice/src/services"
+	"github.com/dieg0code/products-microservice/src/tenant"
+	"github.com/dieg0code/products-microservice/src/testutils"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestProductsctl(t *testing.T) {
+	db := testutils.SetupTestDB(&models.Product{})
+
+	productRepo := repository.NewPorductRespositoryImpl(db)
+	r := router.NewRouter(controllers.NewProductControllerImpl(services.NewProductServiceImpl(productRepo), validator.New()))
+	r.StockController = controllers.NewStockControllerImpl(services.NewStockServiceImpl(repository.NewStockRepositoryImpl(db)), validator.New())
+	r.Contract = middleware.ContractConfig{Requests: true, Responses: true}
+	r.AuthDisabled = true
+	server := httptest.NewServer(r.InitRoutes())
+	defer server.Close()
+
+	noEnv := func(string) (string, bool) { return "", false }
+
+	productsctl := func(stdin string, args ...string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		args = append([]string{"--server", server.URL}, args...)
+		code := run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr, noEnv)
+		return code, stdout.String(), stderr.String()
+	}
+
+	create := func(t *testing.T, name string, stock string) uint {
+		code, stdout, stderr := productsctl("", "-o", "json", "create", "--name", name, "--category", "Tools", "--price", "100", "--stock", stock)
+		assert.Equal(t, 0, code, "Expected create to succeed, got %s", stderr)
+
+		var created struct {
+			ProductID uint `json:"product_id"`
+		}
+		assert.Nil(t, json.Unmarshal([]byte(stdout), &created), "Expected create to print JSON")
+		assert.NotZero(t, created.ProductID, "Expected the ID of the created product")
+		return created.ProductID
+	}
+
+	t.Run("Run_Usage_Errors", func(t *testing.T) {
+		code, _, stderr := productsctl("")
+		assert.Equal(t, 2, code, "Expected exit code 2 without a command")
+		assert.Contains(t, stderr, "Usage:", "Expected the usage to be printed")
+
+		code, _, stderr = productsctl("", "unknown")
+		assert.Equal(t, 2, code, "Expected exit code 2 for an unknown command")
+		assert.Contains(t, stderr, `unknown command "unknown"`, "Expected the unknown command to be reported")
+
+		code, _, _ = productsctl("", "-o", "xml", "list")
+		assert.Equal(t, 2, code, "Expected exit code 2 for an unknown output format")
+
+		code, _, stderr = productsctl("", "get", "abc")
+		assert.Equal(t, 2, code, "Expected exit code 2 for an invalid ID")
+		assert.Contains(t, stderr, "ID must be a positive integer", "Expected the invalid ID to be reported")
+
+		code, _, _ = productsctl("", "create", "--name", "No Price")
+		assert.Equal(t, 2, code, "Expected exit code 2 for an invalid product")
+	})
+
+	t.Run("Create_Get_List", func(t *testing.T) {
+		productID := create(t, "Hammer", "10")
+
+		code, stdout, _ := productsctl("", "-o", "json", "get", formatID(productID))
+		assert.Equal(t, 0, code, "Expected get to succeed")
+		var product response.ProductResponse
+		assert.Nil(t, json.Unmarshal([]byte(stdout), &product), "Expected get to print JSON")
+		assert.Equal(t, "Hammer", product.Name, "Expected the created product")
+		assert.Equal(t, 10, product.Stock, "Expected the created stock")
+
+		code, stdout, _ = productsctl("", "list", "--page-size", "100")
+		assert.Equal(t, 0, code, "Expected list to succeed")
+		assert.True(t, strings.HasPrefix(stdout, "ID "), "Expected a table header, got %s", stdout)
+		assert.Contains(t, stdout, "Hammer", "Expected the product in the table")
+	})
+
+	t.Run("Get_Not_Found", func(t *testing.T) {
+		code, _, stderr := productsctl("", "get", "999999")
+		assert.Equal(t, 1, code, "Expected exit code 1 for a missing product")
+		assert.Contains(t, stderr, "Error getting product by ID", "Expected the message of the service in the error")
+	})
+
+	t.Run("Update_Keeps_Unset_Fields", func(t *testing.T) {
+		productID := create(t, "Saw", "4")
+
+		code, stdout, stderr := productsctl("", "-o", "yaml", "update", formatID(productID), "--price", "250")
+		assert.Equal(t, 0, code, "Expected update to succeed, got %s", stderr)
+
+		var product response.ProductResponse
+		assert.Nil(t, yaml.Unmarshal([]byte(stdout), &product), "Expected update to print YAML")
+		assert.Equal(t, 250, product.Price, "Expected the new price")
+		assert.Equal(t, "Saw", product.Name, "Expected the name to be kept")
+		assert.Equal(t, 4, product.Stock, "Expected the stock to be kept")
+	})
+
+	t.Run("Stock_Adjustment", func(t *testing.T) {
+		productID := create(t, "Drill", "5")
+
+		code, stdout, stderr := productsctl("", "-o", "json", "stock", formatID(productID), "-3")
+		assert.Equal(t, 0, code, "Expected the adjustment to succeed, got %s", stderr)
+		var product response.ProductResponse
+		assert.Nil(t, json.Unmarshal([]byte(stdout), &product), "Expected stock to print JSON")
+		assert.Equal(t, 2, product.Stock, "Expected the stock to be decremented")
+
+		code, _, stderr = productsctl("", "stock", formatID(productID), "-3")
+		assert.Equal(t, 1, code, "Expected exit code 1 for insufficient stock")
+		assert.Contains(t, stderr, "409", "Expected a conflict")
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		productID := create(t, "Chisel", "1")
+
+		code, stdout, _ := productsctl("", "delete", formatID(productID))
+		assert.Equal(t, 0, code, "Expected delete to succeed")
+		assert.Contains(t, stdout, "Deleted product", "Expected a confirmation")
+
+		code, _, _ = productsctl("", "get", formatID(productID))
+		assert.Equal(t, 1, code, "Expected the product to be gone")
+	})
+
+	t.Run("Export_Import", func(t *testing.T) {
+		productID := create(t, "Wrench", "7")
+		file := filepath.Join(t.TempDir(), "products.yaml")
+
+		code, _, stderr := productsctl("", "-o", "yaml", "export", file)
+		assert.Equal(t, 0, code, "Expected export to succeed, got %s", stderr)
+		assert.Contains(t, stderr, "Exported", "Expected a summary on stderr")
+
+		content, err := os.ReadFile(file)
+		assert.Nil(t, err, "Expected the export file to be written")
+		var exported []response.ProductResponse
+		assert.Nil(t, yaml.Unmarshal(content, &exported), "Expected the export to be YAML")
+		assert.NotEmpty(t, exported, "Expected products to be exported")
+
+		input := `[{"product_id": ` + formatID(productID) + `, "name": "Wrench", "category": "Tools", "price": 90, "stock": 7},
+			{"name": "Pliers", "category": "Tools", "price": 40, "stock": 3}]`
+		code, stdout, stderr := productsctl(input, "-o", "json", "import", "-")
+		assert.Equal(t, 0, code, "Expected import to succeed, got %s", stderr)
+
+		var results []response.BatchOperationResult
+		assert.Nil(t, json.Unmarshal([]byte(stdout), &results), "Expected import to print JSON")
+		assert.Len(t, results, 2, "Expected one result per product")
+		assert.Equal(t, "update", results[0].Op, "Expected products with an ID to be updated")
+		assert.Equal(t, "create", results[1].Op, "Expected products without an ID to be created")
+
+		code, stdout, _ = productsctl("", "-o", "json", "get", formatID(productID))
+		assert.Equal(t, 0, code, "Expected get to succeed")
+		var product response.ProductResponse
+		assert.Nil(t, json.Unmarshal([]byte(stdout), &product), "Expected get to print JSON")
+		assert.Equal(t, 90, product.Price, "Expected the imported price")
+	})
+
+	t.Run("Export_Import_Into_Empty_Tenant", func(t *testing.T) {
+		create(t, "Mallet", "6")
+		file := filepath.Join(t.TempDir(), "products.json")
+
+		code, _, stderr := productsctl("", "-o", "json", "export", file)
+		assert.Equal(t, 0, code, "Expected export to succeed, got %s", stderr)
+		content, err := os.ReadFile(file)
+		assert.Nil(t, err, "Expected the export file to be written")
+		var exported []response.ProductResponse
+		assert.Nil(t, json.Unmarshal(content, &exported), "Expected the export to be JSON")
+
+		code, _, _ = productsctl("", "--tenant", "empty", "import", file)
+		assert.Equal(t, 1, code, "Expected the IDs of another tenant to fail as updates")
+
+		code, _, stderr = productsctl("", "--tenant", "copy", "-o", "json", "import", "--create", file)
+		assert.Equal(t, 0, code, "Expected import --create to succeed, got %s", stderr)
+
+		code, stdout, _ := productsctl("", "--tenant", "copy", "-o", "json", "export")
+		assert.Equal(t, 0, code, "Expected export to succeed")
+		var copied []response.ProductResponse
+		assert.Nil(t, json.Unmarshal([]byte(stdout), &copied), "Expected the export to be JSON")
+		assert.Len(t, copied, len(exported), "Expected every product to be copied")
+		for i := range exported {
+			assert.Equal(t, exported[i].Name, copied[i].Name, "Expected the products in the same order")
+			assert.Equal(t, exported[i].Stock, copied[i].Stock, "Expected the stock to be copied")
+		}
+
+		code, _, _ = productsctl("", "import", "--create", "--upsert", file)
+		assert.Equal(t, 2, code, "Expected --create and --upsert to be exclusive")
+	})
+
+	t.Run("Import_Upsert", func(t *testing.T) {
+		productID := create(t, "Clamp", "2")
+
+		input := `[{"product_id": ` + formatID(productID) + `, "name": "Clamp", "category": "Tools", "price": 60, "stock": 2},
+			{"product_id": 999999, "name": "Vise", "category": "Tools", "price": 80, "stock": 1}]`
+		code, stdout, stderr := productsctl(input, "-o", "json", "import", "--upsert", "-")
+		assert.Equal(t, 0, code, "Expected import --upsert to succeed, got %s", stderr)
+
+		var results []response.BatchOperationResult
+		assert.Nil(t, json.Unmarshal([]byte(stdout), &results), "Expected import to print JSON")
+		assert.Len(t, results, 2, "Expected one result per product")
+		assert.Equal(t, "update", results[0].Op, "Expected the existing product to be updated")
+		assert.Equal(t, "create", results[1].Op, "Expected the missing product to be created")
+	})
+
+	t.Run("Import_Invalid_File", func(t *testing.T) {
+		code, _, stderr := productsctl(`{"name": "not a list"}`, "import", "-")
+		assert.Equal(t, 1, code, "Expected exit code 1 for an invalid file")
+		assert.Contains(t, stderr, "expected a list of products", "Expected the file to be rejected")
+	})
+
+	t.Run("Purge_Requires_DB", func(t *testing.T) {
+		code, _, stderr := productsctl("", "purge")
+		assert.Equal(t, 1, code, "Expected exit code 1 without --db")
+		assert.Contains(t, stderr, errRequiresDB.Error(), "Expected the command to require --db")
+	})
+
+	t.Run("DB_Purge", func(t *testing.T) {
+		var stdout bytes.Buffer
+		c := &cli{
+			catalog:  newDBCatalog(db, tenant.DefaultTenantID),
+			out:      &printer{w: &stdout, format: OutputJSON},
+			validate: validator.New(),
+		}
+
+		productID := create(t, "Level", "2")
+		assert.Nil(t, commands["delete"](context.Background(), c, []string{formatID(productID)}), "Expected delete to succeed")
+		stdout.Reset()
+
+		err := commands["purge"](context.Background(), c, []string{"--older-than", "1h"})
+		assert.Nil(t, err, "Expected purge to succeed")
+		assert.JSONEq(t, `{"purged": 0}`, stdout.String(), "Expected recently deleted products to be kept")
+		stdout.Reset()
+
+		err = commands["purge"](context.Background(), c, []string{"--older-than", "0s"})
+		assert.Nil(t, err, "Expected purge to succeed")
+		var purged struct {
+			Purged int64 `json:"purged"`
+		}
+		assert.Nil(t, json.Unmarshal(stdout.Bytes(), &purged), "Expected purge to print JSON")
+		assert.GreaterOrEqual(t, purged.Purged, int64(1), "Expected the deleted product to be purged")
+	})
+
+	t.Run("Printer_Formats", func(t *testing.T) {
+		products := []response.ProductResponse{{ProductID: 1, Name: "Hammer", Category: "Tools", Price: 100, Stock: 10, LastUpdate: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC).Format(time.RFC3339)}}
+
+		var out bytes.Buffer
+		assert.Nil(t, (&printer{w: &out, format: OutputYAML}).products(products), "Expected YAML output")
+		assert.Contains(t, out.String(), "product_id: 1", "Expected the DTO's json names as YAML keys")
+
+		out.Reset()
+		assert.Nil(t, (&printer{w: &out, format: OutputTable}).message("Done", map[string]interface{}{"done": true}), "Expected table output")
+		assert.Equal(t, "Done\n", out.String(), "Expected the message as text")
+	})
+
+	t.Run("ParseArgs_Interleaved", func(t *testing.T) {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		price := fs.Int("price", 0, "")
+		args, err := parseArgs(fs, []string{"7", "--price", "5", "extra"})
+		assert.Nil(t, err, "Expected no error")
+		assert.Equal(t, []string{"7", "extra"}, args, "Expected the positional arguments")
+		assert.Equal(t, 5, *price, "Expected the flag after a positional argument")
+
+		_, err = parseArgs(fs, []string{"--unknown"})
+		assert.IsType(t, usageError(""), err, "Expected a usage error for an unknown flag")
+	})
+}
+
+func formatID(productID uint) string {
+	return strconv.FormatUint(uint64(productID), 10)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/migrations"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+)
+
+// printer writes command results as a table for people, or as JSON or YAML for scripts
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+func (p *printer) products(products []response.ProductResponse) error {
+	if p.format != OutputTable {
+		return p.encode(products)
+	}
+
+	return p.table(func(tw io.Writer) {
+		fmt.Fprintln(tw, "ID\tNAME\tCATEGORY\tPRICE\tSTOCK\tLAST UPDATE")
+		for _, product := range products {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%d\t%s\n", product.ProductID, product.Name, product.Category, product.Price, product.Stock, product.LastUpdate)
+		}
+	})
+}
+
+func (p *printer) product(product *response.ProductResponse) error {
+	if p.format != OutputTable {
+		return p.encode(product)
+	}
+	return p.products([]response.ProductResponse{*product})
+}
+
+func (p *printer) batch(results []response.BatchOperationResult) error {
+	if p.format != OutputTable {
+		return p.encode(results)
+	}
+
+	return p.table(func(tw io.Writer) {
+		fmt.Fprintln(tw, "INDEX\tOP\tID\tSTATUS\tERROR")
+		for _, result := range results {
+			productID := result.ProductID
+			if productID == 0 && result.Product != nil {
+				productID = result.Product.ProductID
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%d\t%s\t%s\n", result.Index, result.Op, productID, result.Status, result.Error)
+		}
+	})
+}
+
+func (p *printer) migrations(statuses []migrations.Status) error {
+	if p.format != OutputTable {
+		return p.encode(statuses)
+	}
+
+	return p.table(func(tw io.Writer) {
+		fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(tw, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+	})
+}
+
+// message reports the outcome of commands without a result, values holds the same
+// facts for JSON and YAML
+func (p *printer) message(text string, values map[string]interface{}) error {
+	if p.format != OutputTable {
+		return p.encode(values)
+	}
+	_, err := fmt.Fprintln(p.w, text)
+	return err
+}
+
+func (p *printer) table(rows func(tw io.Writer)) error {
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	rows(tw)
+	return tw.Flush()
+}
+
+func (p *printer) encode(value interface{}) error {
+	if p.format == OutputYAML {
+		return writeYAML(p.w, value)
+	}
+
+	encoder := json.NewEncoder(p.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// writeYAML goes through JSON first, so the keys are the DTOs' json names
+func writeYAML(w io.Writer, value interface{}) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	var generic interface{}
+	err = yaml.Unmarshal(encoded, &generic)
+	if err != nil {
+		return err
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	err = encoder.Encode(generic)
+	if err != nil {
+		return err
+	}
+	return encoder.Close()
+}
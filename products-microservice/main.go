@@ -1,48 +1,471 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
+	"github.com/dieg0code/products-microservice/src/auth"
+	"github.com/dieg0code/products-microservice/src/cache"
+	"github.com/dieg0code/products-microservice/src/config"
 	"github.com/dieg0code/products-microservice/src/controllers"
 	"github.com/dieg0code/products-microservice/src/db"
-	"github.com/dieg0code/products-microservice/src/models"
+	"github.com/dieg0code/products-microservice/src/events"
+	"github.com/dieg0code/products-microservice/src/graphqlapi"
+	"github.com/dieg0code/products-microservice/src/grpcapi"
+	"github.com/dieg0code/products-microservice/src/health"
+	"github.com/dieg0code/products-microservice/src/lifecycle"
+	"github.com/dieg0code/products-microservice/src/logging"
+	"github.com/dieg0code/products-microservice/src/metrics"
+	"github.com/dieg0code/products-microservice/src/middleware"
+	"github.com/dieg0code/products-microservice/src/migrations"
+	"github.com/dieg0code/products-microservice/src/ratelimit"
 	"github.com/dieg0code/products-microservice/src/repository"
 	"github.com/dieg0code/products-microservice/src/router"
 	"github.com/dieg0code/products-microservice/src/services"
-	"github.com/go-playground/validator/v10"
+	"github.com/dieg0code/products-microservice/src/stream"
+	"github.com/dieg0code/products-microservice/src/tracing"
+	"github.com/dieg0code/products-microservice/src/validation"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"gorm.io/gorm"
 )
 
 func main() {
-	db := db.DatabaseConnection()
-	err := db.AutoMigrate(&models.Product{})
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[0], os.Args[2:]))
+	}
+
+	flags, err := config.ParseFlags(os.Args[0], os.Args[1:], os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
 	if err != nil {
-		logrus.Fatalf("Failed to migrate database: %v", err)
-		panic("Failed to migrate database")
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load(flags, os.LookupEnv)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration:\n%v\n", err)
+		os.Exit(2)
+	}
+
+	if flags.PrintConfig {
+		err = cfg.WriteYAML(os.Stdout)
+		if err != nil {
+			logrus.Fatalf("Failed to print configuration: %v", err)
+		}
+		return
+	}
+
+	err = logging.Configure(cfg.Log.Level, cfg.Log.Format)
+	if err != nil {
+		logrus.Fatalf("Failed to configure logging: %v", err)
+	}
+
+	manager := lifecycle.NewManager(lifecycle.Config{
+		DrainPeriod:     cfg.Shutdown.DrainPeriod,
+		ShutdownTimeout: cfg.Shutdown.Timeout,
+	})
+
+	healthChecks := health.NewHealth(health.Config{
+		CheckTimeout: cfg.Health.CheckTimeout,
+		CacheTTL:     cfg.Health.CacheTTL,
+	})
+
+	tracerProvider, err := tracing.NewProvider(context.Background(), tracing.Config{
+		Exporter:    cfg.Tracing.Exporter,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		logrus.Fatalf("Failed to configure tracing: %v", err)
+	}
+	manager.OnClose("tracing", tracerProvider.Shutdown)
+	logrus.AddHook(tracing.LogrusHook{})
+
+	db := db.DatabaseConnection(cfg.Database)
+	err = tracing.RegisterCallbacks(db, tracerProvider)
+	if err != nil {
+		logrus.Fatalf("Failed to instrument database: %v", err)
+	}
+	manager.OnClose("database", databaseCloser(db))
+	healthChecks.Register("database", databaseChecker(db))
+	// A replica that is down only fails the lists it is picked for and every pod shares
+	// it, so it is reported without taking the pods out of rotation
+	if len(cfg.Database.Replicas) > 0 {
+		healthChecks.RegisterOptional("database-replicas", replicaChecker(db))
+	}
+
+	var metricsRegistry *prometheus.Registry
+	if cfg.Features.Metrics {
+		metricsRegistry = metrics.NewRegistry()
+		err = metrics.InstrumentDB(db, metricsRegistry, cfg.Database.Name)
+		if err != nil {
+			logrus.Fatalf("Failed to instrument database: %v", err)
+		}
+		metricsRegistry.MustRegister(metrics.NewCatalogCollector(db, metrics.DefaultCatalogQueryTimeout))
 	}
 
 	repo := repository.NewPorductRespositoryImpl(db)
+	stockRepo := repository.NewStockRepositoryImpl(db)
+
+	var productCacheStats func() cache.Stats
+	if productCache := newProductCache(cfg); productCache != nil {
+		cachedRepo := repository.NewCachedProductRepositoryImpl(repo, productCache, cfg.Cache.TTL)
+		stockRepo = repository.NewCachedStockRepositoryImpl(stockRepo, cachedRepo)
+		repo = cachedRepo
+		productCacheStats = cachedRepo.Stats
+		if metricsRegistry != nil {
+			metricsRegistry.MustRegister(metrics.NewCacheCollector("products", cachedRepo.Stats))
+		}
+	}
+
+	hub := stream.NewHub(stream.DefaultHistorySize, stream.DefaultSubscriberBuffer)
 
-	service := services.NewProductServiceImpl(repo)
+	service := stream.NewProductServiceNotifier(tracing.NewProductServiceTracer(services.NewProductServiceImpl(repo), tracerProvider), hub)
 
-	validator := validator.New()
+	validator := validation.Validator()
+
+	broker, closeBroker := newBroker(cfg)
+	healthChecks.RegisterOptional("broker", broker)
+	stockService := stream.NewStockServiceNotifier(services.NewStockServiceImpl(stockRepo), service, hub)
+	stockConsumer := events.NewStockConsumer(broker, broker, stockService, validator)
+	err = stockConsumer.Start()
+	if err != nil {
+		logrus.Fatalf("Failed to start stock consumer: %v", err)
+	}
 
 	controller := controllers.NewProductControllerImpl(service, validator)
 
+	idempotencyRepo := repository.NewIdempotencyRepositoryImpl(db)
+	manager.Go("idempotency-purge", func(ctx context.Context) {
+		purgeExpiredIdempotencyKeys(ctx, idempotencyRepo, time.Hour)
+	})
+
 	r := router.NewRouter(controller)
+	r.Tenants = middleware.TenantConfig{DefaultTenantID: cfg.Tenants.Default, BaseDomain: cfg.Tenants.BaseDomain}
+	r.RateLimitStore, r.RateLimits = newRateLimits(cfg)
+	r.StockController = controllers.NewStockControllerImpl(stockService, validator)
+	r.ProductCacheStats = productCacheStats
+	r.CachePolicies = newCachePolicies(cfg.HTTPCache)
+	r.MetricsRegistry = metricsRegistry
+	r.TracerProvider = tracerProvider
+	r.Timeouts.Default = cfg.HTTP.RequestTimeout
+	r.Contract = middleware.ContractConfig{Requests: cfg.HTTP.ValidateRequests, Responses: cfg.HTTP.ValidateResponses}
+	r.Readiness = manager.Ready
+	r.Health = healthChecks
+	r.TrustedProxies = cfg.HTTP.TrustedProxies
+	if cfg.Features.Stream {
+		r.ProductStreamController = controllers.NewProductStreamControllerImpl(hub, controllers.DefaultHeartbeatInterval)
+	}
+	r.ProductMiddlewares = append(r.ProductMiddlewares, middleware.Idempotency(idempotencyRepo, cfg.Idempotency.TTL, cfg.Idempotency.Lease))
+
+	tokenVerifier, err := newTokenVerifier(cfg.Auth)
+	if err != nil {
+		logrus.Fatalf("Failed to configure authentication: %v", err)
+	}
+
+	var authenticator *auth.Authenticator
+	if tokenVerifier == nil {
+		logrus.Warn("auth.disabled is set, every route is served without authentication")
+		r.AuthDisabled = true
+	} else {
+		// API keys are managed by admins, so they are only enabled together with JWTs
+		apiKeyService := services.NewApiKeyServiceImpl(repository.NewApiKeyRepositoryImpl(db))
+		authenticator = auth.NewAuthenticator(tokenVerifier, apiKeyService)
+		r.Authenticator = authenticator
+		r.ApiKeyController = controllers.NewApiKeyControllerImpl(apiKeyService, validator)
+	}
+
+	if cfg.Features.GraphQL {
+		schema, err := graphqlapi.NewSchema(service, validator)
+		if err != nil {
+			logrus.Fatalf("Failed to build GraphQL schema: %v", err)
+		}
+		r.GraphQLHandler = graphqlapi.NewHandler(service, schema, graphqlapi.DefaultLimits).ServeGraphQL
+	}
+
+	// Without Redis, rate limits fail open, the product cache falls back to the database
+	// and the broker stops receiving sales
+	if redisClient != nil {
+		healthChecks.RegisterOptional("redis", health.CheckerFunc(func(ctx context.Context) error {
+			return redisClient.Ping(ctx).Err()
+		}))
+		manager.OnClose("redis", func(ctx context.Context) error {
+			return redisClient.Close()
+		})
+	}
+
+	// Closers run in reverse, so sales being applied finish before the broker stops
+	// reading, and both before Redis and the database close
+	if closeBroker != nil {
+		manager.OnClose("broker", closeBroker)
+	}
+	manager.OnClose("stock-consumer", stockConsumer.Stop)
 
 	ginRouter := r.InitRoutes()
 
 	server := &http.Server{
-		Addr:    ":8080",
+		Addr:    cfg.HTTP.Addr,
 		Handler: ginRouter,
 	}
+	// Event streams never go idle, closing the hub ends them so Shutdown can return
+	server.RegisterOnShutdown(hub.Close)
 
-	err = server.ListenAndServe()
+	httpListener, err := net.Listen("tcp", server.Addr)
 	if err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+		logrus.Fatalf("Server failed to start: %v", err)
+	}
+	manager.Serve("http", func() error {
+		if cfg.TLS.Enabled() {
+			return server.ServeTLS(httpListener, cfg.TLS.CertFile, cfg.TLS.KeyFile)
+		}
+		return server.Serve(httpListener)
+	}, server.Shutdown)
+
+	if cfg.Features.GRPC {
+		grpcListener, err := net.Listen("tcp", ":"+strconv.Itoa(cfg.GRPC.Port))
+		if err != nil {
+			logrus.Fatalf("Failed to listen on gRPC port: %v", err)
+		}
+
+		var grpcOptions []grpc.ServerOption
+		if cfg.TLS.Enabled() {
+			creds, err := credentials.NewServerTLSFromFile(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+			if err != nil {
+				logrus.Fatalf("Failed to load TLS certificate: %v", err)
+			}
+			grpcOptions = append(grpcOptions, grpc.Creds(creds))
+		}
+		if authenticator != nil {
+			grpcOptions = append(grpcOptions, grpc.ChainUnaryInterceptor(grpcapi.UnaryAuthInterceptor(authenticator)))
+		}
+		grpcOptions = append(grpcOptions,
+			grpc.ChainUnaryInterceptor(grpcapi.UnaryTenantInterceptor(r.Tenants.DefaultTenantID)),
+			grpc.ChainStreamInterceptor(grpcapi.StreamTenantInterceptor(r.Tenants.DefaultTenantID)),
+		)
+
+		grpcServer := grpcapi.NewGRPCServer(grpcapi.NewProductServer(service, stockService, validator), grpcapi.DefaultRequestTimeout, grpcOptions...)
+		manager.Serve("grpc", func() error { return grpcServer.Serve(grpcListener) }, func(ctx context.Context) error {
+			return stopGRPCServer(ctx, grpcServer)
+		})
 	}
 
+	// The listeners answer the probes while migrations run, /startup and /ready pass
+	// once they are done. Pods starting together wait on the migration lock.
+	manager.Go("startup", func(ctx context.Context) {
+		if cfg.Database.AutoMigrate {
+			migrator, err := migrations.NewMigrator(db)
+			if err == nil {
+				_, err = migrator.Up(ctx)
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				logrus.Fatalf("Failed to migrate database: %v", err)
+			}
+		}
+		healthChecks.MarkStarted()
+		logrus.Info("Startup completed")
+	})
+
 	logrus.Info("Server started successfully")
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	err = manager.Run(ctx)
+	if err != nil {
+		logrus.Fatalf("Server stopped with errors: %v", err)
+	}
+}
+
+func databaseChecker(conn *gorm.DB) health.Checker {
+	return health.CheckerFunc(func(ctx context.Context) error {
+		return db.CheckDatabaseConnection(ctx, conn)
+	})
+}
+
+func replicaChecker(conn *gorm.DB) health.Checker {
+	return health.CheckerFunc(func(ctx context.Context) error {
+		return db.CheckReplicaConnections(ctx, conn)
+	})
+}
+
+func databaseCloser(conn *gorm.DB) func(context.Context) error {
+	return func(context.Context) error {
+		return db.CloseDatabaseConnection(conn)
+	}
+}
+
+// stopGRPCServer waits for in-flight RPCs like http.Server.Shutdown does, and
+// cancels the ones still running when ctx is done
+func stopGRPCServer(ctx context.Context, server *grpc.Server) error {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		server.Stop()
+		return ctx.Err()
+	}
+}
+
+func purgeExpiredIdempotencyKeys(ctx context.Context, repo repository.IdempotencyRepository, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		deleted, err := repo.DeleteExpired(ctx, time.Now())
+		if err != nil {
+			continue
+		}
+		logrus.WithField("deleted", deleted).Debug("Expired idempotency keys purged")
+	}
+}
+
+// newTokenVerifier returns nil when authentication is disabled, Validate makes sure
+// a shared secret or a JWKS URL is configured otherwise
+func newTokenVerifier(authConfig config.AuthConfig) (auth.TokenVerifier, error) {
+	if !authConfig.Enabled() {
+		return nil, nil
+	}
+
+	jwtConfig := auth.JWTConfig{
+		HMACSecret:    []byte(authConfig.JWTSecret),
+		Issuer:        authConfig.Issuer,
+		Audience:      authConfig.Audience,
+		RolesClaim:    authConfig.RolesClaim,
+		TenantClaim:   authConfig.TenantClaim,
+		RequireTenant: authConfig.RequireTenant,
+	}
+	if authConfig.JWKSURL != "" {
+		jwtConfig.KeySet = auth.NewJWKSKeySetImpl(authConfig.JWKSURL, nil, auth.DefaultJWKSRefreshInterval)
+	}
+
+	return auth.NewJWTVerifierImpl(jwtConfig)
+}
+
+// messageBroker is what the stock consumer and the health checks need from a broker
+type messageBroker interface {
+	events.Subscriber
+	events.Publisher
+	health.Checker
+}
+
+// newBroker returns the broker picked by broker.backend and, when it reads in the
+// background, the closer that stops it
+func newBroker(cfg *config.Config) (messageBroker, func(ctx context.Context) error) {
+	if cfg.Broker.Backend == config.BackendRedis {
+		// Pod names are unique, so the hostname tells the replicas of the group apart
+		consumer, err := os.Hostname()
+		if err != nil {
+			logrus.Fatalf("Failed to name the broker consumer: %v", err)
+		}
+		broker := events.NewRedisBroker(sharedRedisClient(cfg.Redis.URL), cfg.Broker.Group, consumer)
+		return broker, broker.Close
+	}
+
+	logrus.Warn("Stock consumer is subscribed to the in-memory broker, sale events from other services are not received")
+	return events.NewInMemoryBroker(), nil
+}
+
+// newRateLimits returns the store picked by rate_limit.store and the limits of every
+// route group, nil when rate limiting is off
+func newRateLimits(cfg *config.Config) (ratelimit.Store, map[string]ratelimit.Policy) {
+	var store ratelimit.Store
+	switch cfg.RateLimit.Store {
+	case config.BackendMemory:
+		store = ratelimit.NewMemoryStoreImpl(ratelimit.DefaultSweepInterval)
+	case config.BackendRedis:
+		store = ratelimit.NewRedisStoreImpl(sharedRedisClient(cfg.Redis.URL), ratelimit.DefaultRedisKeyPrefix)
+	case config.BackendNone:
+		logrus.Warn("rate_limit.store is none, rate limiting is disabled")
+		return nil, nil
+	}
+
+	return store, map[string]ratelimit.Policy{
+		router.RouteGroupProducts: ratePolicy(cfg.RateLimit.Products),
+		router.RouteGroupGraphQL:  ratePolicy(cfg.RateLimit.GraphQL),
+		router.RouteGroupApiKeys:  ratePolicy(cfg.RateLimit.ApiKeys),
+	}
+}
+
+// ratePolicy parses limits Validate already checked, an empty limit disables that bucket
+func ratePolicy(group config.RateLimitGroup) ratelimit.Policy {
+	var policy ratelimit.Policy
+	if group.Read != "" {
+		policy.Read, _ = ratelimit.ParseLimit(group.Read)
+	}
+	if group.Write != "" {
+		policy.Write, _ = ratelimit.ParseLimit(group.Write)
+	}
+	return policy
+}
+
+// newProductCache returns the cache picked by product_cache.backend, nil when it is off
+func newProductCache(cfg *config.Config) cache.Cache {
+	switch cfg.Cache.Backend {
+	case config.BackendMemory:
+		return cache.NewLRUCacheImpl(cfg.Cache.Size)
+	case config.BackendRedis:
+		return cache.NewRedisCacheImpl(sharedRedisClient(cfg.Redis.URL), cache.DefaultRedisKeyPrefix)
+	}
+	return nil
+}
+
+var redisClient *redis.Client
+
+// sharedRedisClient connects to Redis once for every feature that uses it
+func sharedRedisClient(url string) *redis.Client {
+	if redisClient == nil {
+		options, err := redis.ParseURL(url)
+		if err != nil {
+			logrus.Fatalf("Invalid redis.url: %v", err)
+		}
+		redisClient = redis.NewClient(options)
+	}
+	return redisClient
+}
+
+// newCachePolicies overrides the Cache-Control of every cacheable route, routes with
+// an empty one are left out so they are never cached
+func newCachePolicies(httpCache config.HTTPCacheConfig) map[string]middleware.CachePolicy {
+	cacheControls := map[string]string{
+		router.RouteProductsList:       httpCache.ProductsList,
+		router.RouteProductsByCategory: httpCache.ProductsByCategory,
+	}
+
+	policies := make(map[string]middleware.CachePolicy, len(router.DefaultCachePolicies))
+	for route, policy := range router.DefaultCachePolicies {
+		if cacheControls[route] == "" {
+			continue
+		}
+		policy.CacheControl = cacheControls[route]
+		policies[route] = policy
+	}
+	return policies
 }
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/config"
+	"github.com/dieg0code/products-microservice/src/db"
+	"github.com/dieg0code/products-microservice/src/logging"
+	"github.com/dieg0code/products-microservice/src/migrations"
+	"github.com/sirupsen/logrus"
+)
+
+const migrateUsage = "usage: %s migrate up|down [N]|status [flags]\n"
+
+// runMigrate runs `migrate up`, `migrate down [N]` or `migrate status`, which take the
+// same flags as the service, and returns the exit code
+func runMigrate(name string, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, migrateUsage, name)
+		return 2
+	}
+	action, args := args[0], args[1:]
+	if action != "up" && action != "down" && action != "status" {
+		fmt.Fprintf(os.Stderr, migrateUsage, name)
+		return 2
+	}
+
+	// down reverts one migration unless told how many
+	steps := 1
+	if action == "down" && len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			fmt.Fprintf(os.Stderr, "migrate down: invalid number of migrations %q\n", args[0])
+			return 2
+		}
+		steps, args = n, args[1:]
+	}
+
+	flags, err := config.ParseFlags(name+" migrate "+action, args, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		return 2
+	}
+
+	cfg, err := config.Load(flags, os.LookupEnv)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration:\n%v\n", err)
+		return 2
+	}
+
+	err = logging.Configure(cfg.Log.Level, cfg.Log.Format)
+	if err != nil {
+		logrus.Errorf("Failed to configure logging: %v", err)
+		return 1
+	}
+
+	conn := db.DatabaseConnection(cfg.Database)
+	defer db.CloseDatabaseConnection(conn)
+
+	migrator, err := migrations.NewMigrator(conn)
+	if err != nil {
+		logrus.Errorf("Failed to load migrations: %v", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	switch action {
+	case "up":
+		var applied []migrations.Migration
+		applied, err = migrator.Up(ctx)
+		if err == nil {
+			logrus.Infof("Applied %d migrations", len(applied))
+		}
+	case "down":
+		var reverted []migrations.Migration
+		reverted, err = migrator.Down(ctx, steps)
+		if err == nil {
+			logrus.Infof("Reverted %d migrations", len(reverted))
+		}
+	case "status":
+		var statuses []migrations.Status
+		statuses, err = migrator.Status(ctx)
+		if err == nil {
+			err = writeMigrationStatus(os.Stdout, statuses)
+		}
+	}
+	if err != nil {
+		logrus.Errorf("Failed to migrate database: %v", err)
+		return 1
+	}
+	return 0
+}
+
+func writeMigrationStatus(w io.Writer, statuses []migrations.Status) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+const (
+	ApiKeyPrefix = "pmk"
+	ApiKeyHeader = "X-API-Key"
+)
+
+// GenerateApiKey returns a key shaped like pmk_<prefix>_<secret>. Only the prefix and
+// the hash are meant to be stored, the key itself is shown to the client once.
+func GenerateApiKey() (key string, prefix string, hash string, err error) {
+	prefixBytes := make([]byte, 6)
+	_, err = rand.Read(prefixBytes)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	secretBytes := make([]byte, 32)
+	_, err = rand.Read(secretBytes)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	prefix = hex.EncodeToString(prefixBytes)
+	key = ApiKeyPrefix + "_" + prefix + "_" + base64.RawURLEncoding.EncodeToString(secretBytes)
+	return key, prefix, HashApiKey(key), nil
+}
+
+// HashApiKey uses a plain SHA-256 since keys carry 256 bits of entropy, a slow
+// password hash would only add latency to every request
+func HashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// ApiKeyPrefixOf extracts the lookup prefix of a key
+func ApiKeyPrefixOf(key string) (string, bool) {
+	parts := strings.SplitN(key, "_", 3)
+	if len(parts) != 3 || parts[0] != ApiKeyPrefix || parts[1] == "" || parts[2] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+func ApiKeyMatches(key string, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashApiKey(key)), []byte(hash)) == 1
+}
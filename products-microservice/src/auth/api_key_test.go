@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApiKey(t *testing.T) {
+
+	t.Run("GenerateApiKey_Round_Trip", func(t *testing.T) {
+		key, prefix, hash, err := GenerateApiKey()
+		assert.Nil(t, err, "Expected no error generating key")
+		assert.NotContains(t, hash, key, "Expected the hash not to contain the key")
+
+		parsedPrefix, ok := ApiKeyPrefixOf(key)
+		assert.True(t, ok, "Expected key to be parsed")
+		assert.Equal(t, prefix, parsedPrefix, "Expected the generated prefix")
+		assert.True(t, ApiKeyMatches(key, hash), "Expected key to match its hash")
+		assert.False(t, ApiKeyMatches(key+"x", hash), "Expected other keys not to match")
+
+		other, otherPrefix, _, _ := GenerateApiKey()
+		assert.NotEqual(t, key, other, "Expected unique keys")
+		assert.NotEqual(t, prefix, otherPrefix, "Expected unique prefixes")
+	})
+
+	t.Run("ApiKeyPrefixOf_Invalid", func(t *testing.T) {
+		for _, key := range []string{"", "pmk", "pmk__secret", "abc_123_secret", "pmk_123_"} {
+			_, ok := ApiKeyPrefixOf(key)
+			assert.False(t, ok, "Expected %q to be rejected", key)
+		}
+	})
+
+	t.Run("ParseScopes_Drops_Unknown", func(t *testing.T) {
+		scopes := ParseScopes("products:read  admin:all stock:reserve")
+
+		assert.Equal(t, []Scope{ScopeProductsRead, ScopeStockReserve}, scopes, "Expected only known scopes")
+		assert.Equal(t, "products:read stock:reserve", FormatScopes(scopes), "Expected space separated scopes")
+	})
+}
@@ -0,0 +1,40 @@
+package auth
+
+import "context"
+
+type ApiKeyAuthenticator interface {
+	AuthenticateApiKey(ctx context.Context, key string) (*Principal, error)
+}
+
+// Authenticator resolves the caller from either a bearer token or an API key. Both
+// sources are optional, credentials for a source that is not configured are rejected.
+type Authenticator struct {
+	tokens  TokenVerifier
+	apiKeys ApiKeyAuthenticator
+}
+
+// Authenticate returns a nil principal for anonymous callers
+func (a *Authenticator) Authenticate(ctx context.Context, bearerToken string, apiKey string) (*Principal, error) {
+	switch {
+	case bearerToken != "" && apiKey != "":
+		return nil, ErrAmbiguousCredentials
+	case bearerToken != "":
+		if a.tokens == nil {
+			return nil, ErrInvalidToken
+		}
+		return a.tokens.Verify(bearerToken)
+	case apiKey != "":
+		if a.apiKeys == nil {
+			return nil, ErrInvalidApiKey
+		}
+		return a.apiKeys.AuthenticateApiKey(ctx, apiKey)
+	}
+	return nil, nil
+}
+
+func NewAuthenticator(tokens TokenVerifier, apiKeys ApiKeyAuthenticator) *Authenticator {
+	return &Authenticator{
+		tokens:  tokens,
+		apiKeys: apiKeys,
+	}
+}
@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+type staticApiKeys map[string]*Principal
+
+func (s staticApiKeys) AuthenticateApiKey(ctx context.Context, key string) (*Principal, error) {
+	principal, ok := s[key]
+	if !ok {
+		return nil, ErrInvalidApiKey
+	}
+	return principal, nil
+}
+
+func TestAuthenticator(t *testing.T) {
+	verifier, err := NewJWTVerifierImpl(JWTConfig{HMACSecret: testSecret})
+	assert.Nil(t, err, "Expected no error creating verifier")
+
+	apiKeys := staticApiKeys{"pmk_abc_secret": {Subject: "api-key:abc", Scopes: []Scope{ScopeStockReserve}}}
+
+	t.Run("Authenticate_Sources", func(t *testing.T) {
+		authenticator := NewAuthenticator(verifier, apiKeys)
+
+		principal, err := authenticator.Authenticate(context.Background(), "", "")
+		assert.Nil(t, err, "Expected no error for anonymous callers")
+		assert.Nil(t, principal, "Expected no principal for anonymous callers")
+
+		principal, err = authenticator.Authenticate(context.Background(), signToken(t, jwt.SigningMethodHS256, testSecret, "", validClaims("editor")), "")
+		assert.Nil(t, err, "Expected token to authenticate")
+		assert.Equal(t, "user-1", principal.Subject, "Expected token subject")
+
+		principal, err = authenticator.Authenticate(context.Background(), "", "pmk_abc_secret")
+		assert.Nil(t, err, "Expected API key to authenticate")
+		assert.Equal(t, "api-key:abc", principal.Subject, "Expected API key subject")
+
+		_, err = authenticator.Authenticate(context.Background(), "token", "pmk_abc_secret")
+		assert.ErrorIs(t, err, ErrAmbiguousCredentials, "Expected both credentials to be rejected")
+	})
+
+	t.Run("Authenticate_Unconfigured_Sources", func(t *testing.T) {
+		_, err := NewAuthenticator(nil, apiKeys).Authenticate(context.Background(), "token", "")
+		assert.ErrorIs(t, err, ErrInvalidToken, "Expected tokens to be rejected without a verifier")
+
+		_, err = NewAuthenticator(verifier, nil).Authenticate(context.Background(), "", "pmk_abc_secret")
+		assert.ErrorIs(t, err, ErrInvalidApiKey, "Expected API keys to be rejected without a store")
+	})
+}
@@ -0,0 +1,12 @@
+package auth
+
+import "errors"
+
+var (
+	ErrUnauthenticated      = errors.New("authentication required")
+	ErrForbidden            = errors.New("insufficient permissions")
+	ErrInvalidToken         = errors.New("invalid token")
+	ErrInvalidApiKey        = errors.New("invalid API key")
+	ErrAmbiguousCredentials = errors.New("both a bearer token and an API key were sent")
+	ErrUnknownKey           = errors.New("unknown signing key")
+)
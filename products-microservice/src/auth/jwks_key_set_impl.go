@@ -0,0 +1,225 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	DefaultJWKSRefreshInterval    = 10 * time.Minute
+	DefaultJWKSMinRefreshInterval = 30 * time.Second
+)
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// JWKSKeySetImpl caches the keys published at a JWKS URL. The keys are fetched again
+// after refreshInterval, or earlier when a token names a kid that is not cached yet,
+// which is how identity providers roll out a new signing key. The keys are fetched
+// without holding the lock, callers that need a refresh meanwhile wait for the same
+// fetch.
+type JWKSKeySetImpl struct {
+	url                string
+	client             *http.Client
+	refreshInterval    time.Duration
+	minRefreshInterval time.Duration
+	group              singleflight.Group
+
+	mu          sync.Mutex
+	keys        map[string]interface{}
+	fetchedAt   time.Time
+	lastAttempt time.Time
+}
+
+// Key implements KeySet.
+func (j *JWKSKeySetImpl) Key(kid string) (interface{}, error) {
+	j.mu.Lock()
+	stale := time.Since(j.fetchedAt) >= j.refreshInterval
+	key, found := j.lookup(kid)
+	j.mu.Unlock()
+
+	// Unknown kids trigger a refresh, rate limited so that forged tokens cannot hammer the provider
+	if stale || !found {
+		_, _, _ = j.group.Do("refresh", func() (interface{}, error) {
+			j.refresh()
+			return nil, nil
+		})
+
+		j.mu.Lock()
+		key, found = j.lookup(kid)
+		j.mu.Unlock()
+	}
+
+	if !found {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+	return key, nil
+}
+
+// refresh fetches the keys when the last attempt is old enough and swaps them in
+func (j *JWKSKeySetImpl) refresh() {
+	now := time.Now()
+
+	j.mu.Lock()
+	if now.Sub(j.lastAttempt) < j.minRefreshInterval {
+		j.mu.Unlock()
+		return
+	}
+	j.lastAttempt = now
+	j.mu.Unlock()
+
+	keys, err := j.fetch()
+	if err != nil {
+		logrus.WithError(err).WithField("jwks_url", j.url).Warn("Error refreshing JWKS, using cached keys")
+		return
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.fetchedAt = now
+	j.mu.Unlock()
+}
+
+// lookup must be called with mu held
+func (j *JWKSKeySetImpl) lookup(kid string) (interface{}, bool) {
+	if kid == "" && len(j.keys) == 1 {
+		for _, key := range j.keys {
+			return key, true
+		}
+	}
+	key, ok := j.keys[kid]
+	return key, ok
+}
+
+func (j *JWKSKeySetImpl) fetch() (map[string]interface{}, error) {
+	res, err := j.client.Get(j.url)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected JWKS status %d", res.StatusCode)
+	}
+
+	set := jsonWebKeySet{}
+	err = json.NewDecoder(res.Body).Decode(&set)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := parseJSONWebKey(jwk)
+		if err != nil {
+			logrus.WithError(err).WithField("kid", jwk.Kid).Warn("Skipping invalid JWK")
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS has no usable signing keys")
+	}
+
+	return keys, nil
+}
+
+func parseJSONWebKey(jwk jsonWebKey) (interface{}, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("RSA exponent out of range")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("EC point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes) == 0 {
+		return nil, errors.New("empty JWK parameter")
+	}
+	return new(big.Int).SetBytes(bytes), nil
+}
+
+func NewJWKSKeySetImpl(url string, client *http.Client, refreshInterval time.Duration) KeySet {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if refreshInterval <= 0 {
+		refreshInterval = DefaultJWKSRefreshInterval
+	}
+
+	return &JWKSKeySetImpl{
+		url:                url,
+		client:             client,
+		refreshInterval:    refreshInterval,
+		minRefreshInterval: DefaultJWKSMinRefreshInterval,
+		keys:               make(map[string]interface{}),
+	}
+}
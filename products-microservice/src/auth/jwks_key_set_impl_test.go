@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJWKSKeySetImpl(t *testing.T) {
+
+	t.Run("Key_Refreshes_On_Rotation", func(t *testing.T) {
+		oldKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		newKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+		keys := &atomic.Value{}
+		keys.Store([]jsonWebKey{ecJWK("old", &oldKey.PublicKey)})
+		fetches := &atomic.Int32{}
+		server := serveJWKS(keys, fetches)
+		defer server.Close()
+
+		keySet := NewJWKSKeySetImpl(server.URL, nil, time.Hour).(*JWKSKeySetImpl)
+		keySet.minRefreshInterval = 0
+		verifier, _ := NewJWTVerifierImpl(JWTConfig{KeySet: keySet})
+
+		_, err := verifier.Verify(signToken(t, jwt.SigningMethodES256, oldKey, "old", validClaims("viewer")))
+		assert.Nil(t, err, "Expected old key to verify")
+
+		keys.Store([]jsonWebKey{ecJWK("old", &oldKey.PublicKey), ecJWK("new", &newKey.PublicKey)})
+
+		_, err = verifier.Verify(signToken(t, jwt.SigningMethodES256, oldKey, "old", validClaims("viewer")))
+		assert.Nil(t, err, "Expected cached key to verify")
+		assert.Equal(t, int32(1), fetches.Load(), "Expected cached keys to be reused")
+
+		_, err = verifier.Verify(signToken(t, jwt.SigningMethodES256, newKey, "new", validClaims("viewer")))
+		assert.Nil(t, err, "Expected rotated key to verify")
+		assert.Equal(t, int32(2), fetches.Load(), "Expected unknown kid to trigger a refresh")
+	})
+
+	t.Run("Key_Rate_Limits_Unknown_Kids", func(t *testing.T) {
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+		keys := &atomic.Value{}
+		keys.Store([]jsonWebKey{ecJWK("current", &key.PublicKey)})
+		fetches := &atomic.Int32{}
+		server := serveJWKS(keys, fetches)
+		defer server.Close()
+
+		keySet := NewJWKSKeySetImpl(server.URL, nil, time.Hour)
+		for i := 0; i < 5; i++ {
+			_, err := keySet.Key("forged")
+			assert.ErrorIs(t, err, ErrUnknownKey, "Expected unknown key error")
+		}
+
+		assert.Equal(t, int32(1), fetches.Load(), "Expected a single fetch within the refresh interval")
+	})
+
+	t.Run("Key_Keeps_Cached_Keys_When_Refresh_Fails", func(t *testing.T) {
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+		keys := &atomic.Value{}
+		keys.Store([]jsonWebKey{ecJWK("current", &key.PublicKey)})
+		fetches := &atomic.Int32{}
+		server := serveJWKS(keys, fetches)
+
+		keySet := NewJWKSKeySetImpl(server.URL, nil, time.Millisecond).(*JWKSKeySetImpl)
+		keySet.minRefreshInterval = 0
+
+		_, err := keySet.Key("current")
+		assert.Nil(t, err, "Expected key to be fetched")
+
+		server.Close()
+		time.Sleep(5 * time.Millisecond)
+
+		cached, err := keySet.Key("current")
+		assert.Nil(t, err, "Expected stale key to be used while the provider is down")
+		assert.Equal(t, &key.PublicKey, cached, "Expected the cached key")
+	})
+
+	t.Run("Key_Does_Not_Wait_For_Slow_Refresh", func(t *testing.T) {
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+		release := make(chan struct{})
+		fetches := &atomic.Int32{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if fetches.Add(1) > 1 {
+				<-release
+			}
+			_ = json.NewEncoder(w).Encode(jsonWebKeySet{Keys: []jsonWebKey{ecJWK("current", &key.PublicKey)}})
+		}))
+		defer server.Close()
+		defer close(release)
+
+		keySet := NewJWKSKeySetImpl(server.URL, nil, time.Hour).(*JWKSKeySetImpl)
+		keySet.minRefreshInterval = 0
+
+		_, err := keySet.Key("current")
+		assert.Nil(t, err, "Expected key to be fetched")
+
+		go func() {
+			_, _ = keySet.Key("unknown")
+		}()
+		assert.Eventually(t, func() bool { return fetches.Load() == 2 }, time.Second, time.Millisecond, "Expected the unknown kid to start a refresh")
+
+		done := make(chan error, 1)
+		go func() {
+			_, err := keySet.Key("current")
+			done <- err
+		}()
+
+		select {
+		case err := <-done:
+			assert.Nil(t, err, "Expected the cached key")
+		case <-time.After(time.Second):
+			t.Error("Expected cached keys to be served while the refresh is in flight")
+		}
+	})
+
+	t.Run("Key_Without_Kid_Uses_Single_Key", func(t *testing.T) {
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+		keys := &atomic.Value{}
+		keys.Store([]jsonWebKey{ecJWK("only", &key.PublicKey), {Kty: "EC", Kid: "bad", Crv: "P-256", X: "AQ", Y: "AQ"}})
+		fetches := &atomic.Int32{}
+		server := serveJWKS(keys, fetches)
+		defer server.Close()
+
+		keySet := NewJWKSKeySetImpl(server.URL, nil, time.Hour)
+		_, err := keySet.Key("")
+		assert.Nil(t, err, "Expected the only valid key when kid is empty")
+	})
+}
@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	DefaultRolesClaim  = "roles"
+	DefaultTenantClaim = "tenant_id"
+	DefaultLeeway      = 30 * time.Second
+)
+
+type JWTConfig struct {
+	// HMACSecret enables HS256 tokens signed with a shared secret
+	HMACSecret []byte
+	// KeySet enables RS256 and ES256 tokens signed by an identity provider
+	KeySet   KeySet
+	Issuer   string
+	Audience string
+	// RolesClaim holds either a single role or a list of roles
+	RolesClaim string
+	// TenantClaim binds the token to a single tenant when present
+	TenantClaim string
+	// RequireTenant rejects tokens without the tenant claim, otherwise they are
+	// bound to the default tenant
+	RequireTenant bool
+	Leeway        time.Duration
+}
+
+type JWTVerifierImpl struct {
+	config JWTConfig
+	parser *jwt.Parser
+}
+
+// Verify implements TokenVerifier.
+func (j *JWTVerifierImpl) Verify(token string) (*Principal, error) {
+	claims := jwt.MapClaims{}
+	_, err := j.parser.ParseWithClaims(token, claims, j.key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidToken)
+	}
+
+	tenantID, _ := claims[j.config.TenantClaim].(string)
+	if tenantID == "" && j.config.RequireTenant {
+		return nil, fmt.Errorf("%w: missing %s claim", ErrInvalidToken, j.config.TenantClaim)
+	}
+
+	return &Principal{
+		Subject:  subject,
+		Roles:    rolesFromClaim(claims[j.config.RolesClaim]),
+		TenantID: tenantID,
+	}, nil
+}
+
+func (j *JWTVerifierImpl) key(token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if len(j.config.HMACSecret) == 0 {
+			return nil, errors.New("HMAC tokens are not accepted")
+		}
+		return j.config.HMACSecret, nil
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+		if j.config.KeySet == nil {
+			return nil, errors.New("asymmetric tokens are not accepted")
+		}
+		kid, _ := token.Header["kid"].(string)
+		return j.config.KeySet.Key(kid)
+	}
+	return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+}
+
+func rolesFromClaim(claim interface{}) []Role {
+	var values []string
+	switch claim := claim.(type) {
+	case string:
+		values = strings.Fields(claim)
+	case []interface{}:
+		for _, value := range claim {
+			if value, ok := value.(string); ok {
+				values = append(values, value)
+			}
+		}
+	}
+
+	var roles []Role
+	for _, value := range values {
+		if role, ok := ParseRole(value); ok {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+func NewJWTVerifierImpl(config JWTConfig) (TokenVerifier, error) {
+	var methods []string
+	if len(config.HMACSecret) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if config.KeySet != nil {
+		methods = append(methods, jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg())
+	}
+	if len(methods) == 0 {
+		return nil, errors.New("either an HMAC secret or a key set is required")
+	}
+
+	if config.RolesClaim == "" {
+		config.RolesClaim = DefaultRolesClaim
+	}
+	if config.TenantClaim == "" {
+		config.TenantClaim = DefaultTenantClaim
+	}
+	if config.Leeway == 0 {
+		config.Leeway = DefaultLeeway
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(config.Leeway),
+	}
+	if config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(config.Issuer))
+	}
+	if config.Audience != "" {
+		options = append(options, jwt.WithAudience(config.Audience))
+	}
+
+	return &JWTVerifierImpl{
+		config: config,
+		parser: jwt.NewParser(options...),
+	}, nil
+}
@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+var testSecret = []byte("test-secret")
+
+func signToken(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key)
+	assert.Nil(t, err, "Expected no error signing token")
+	return signed
+}
+
+func validClaims(roles interface{}) jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":   "user-1",
+		"roles": roles,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func encodeBigInt(value *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(value.Bytes())
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) jsonWebKey {
+	return jsonWebKey{Kty: "RSA", Kid: kid, Use: "sig", N: encodeBigInt(key.N), E: encodeBigInt(big.NewInt(int64(key.E)))}
+}
+
+func ecJWK(kid string, key *ecdsa.PublicKey) jsonWebKey {
+	return jsonWebKey{Kty: "EC", Kid: kid, Crv: "P-256", X: encodeBigInt(key.X), Y: encodeBigInt(key.Y)}
+}
+
+// serveJWKS serves whatever keys currently holds and counts the requests
+func serveJWKS(keys *atomic.Value, fetches *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		_ = json.NewEncoder(w).Encode(jsonWebKeySet{Keys: keys.Load().([]jsonWebKey)})
+	}))
+}
+
+func TestJWTVerifierImpl(t *testing.T) {
+
+	t.Run("Verify_HS256_Success", func(t *testing.T) {
+		verifier, err := NewJWTVerifierImpl(JWTConfig{HMACSecret: testSecret})
+		assert.Nil(t, err, "Expected no error creating verifier")
+
+		token := signToken(t, jwt.SigningMethodHS256, testSecret, "", validClaims([]string{"editor", "unknown"}))
+
+		principal, err := verifier.Verify(token)
+		assert.Nil(t, err, "Expected no error verifying token")
+		assert.Equal(t, "user-1", principal.Subject, "Expected subject from token")
+		assert.Equal(t, []Role{RoleEditor}, principal.Roles, "Expected unknown roles to be dropped")
+	})
+
+	t.Run("Verify_Role_As_String", func(t *testing.T) {
+		verifier, _ := NewJWTVerifierImpl(JWTConfig{HMACSecret: testSecret, RolesClaim: "role"})
+
+		claims := validClaims(nil)
+		claims["role"] = "admin"
+		principal, err := verifier.Verify(signToken(t, jwt.SigningMethodHS256, testSecret, "", claims))
+		assert.Nil(t, err, "Expected no error verifying token")
+		assert.Equal(t, []Role{RoleAdmin}, principal.Roles, "Expected role from custom claim")
+	})
+
+	t.Run("Verify_Tenant_Claim", func(t *testing.T) {
+		verifier, _ := NewJWTVerifierImpl(JWTConfig{HMACSecret: testSecret})
+
+		claims := validClaims("viewer")
+		claims["tenant_id"] = "acme"
+		principal, err := verifier.Verify(signToken(t, jwt.SigningMethodHS256, testSecret, "", claims))
+		assert.Nil(t, err, "Expected no error verifying token")
+		assert.Equal(t, "acme", principal.TenantID, "Expected tenant from the default claim")
+	})
+
+	t.Run("Verify_Requires_Tenant_Claim", func(t *testing.T) {
+		verifier, _ := NewJWTVerifierImpl(JWTConfig{HMACSecret: testSecret, RequireTenant: true})
+
+		_, err := verifier.Verify(signToken(t, jwt.SigningMethodHS256, testSecret, "", validClaims("viewer")))
+		assert.ErrorIs(t, err, ErrInvalidToken, "Expected tokens without a tenant to be rejected")
+
+		claims := validClaims("viewer")
+		claims["tenant_id"] = "acme"
+		principal, err := verifier.Verify(signToken(t, jwt.SigningMethodHS256, testSecret, "", claims))
+		assert.Nil(t, err, "Expected no error verifying token")
+		assert.Equal(t, "acme", principal.TenantID, "Expected tenant from the claim")
+	})
+
+	t.Run("Verify_Rejects_Wrong_Secret", func(t *testing.T) {
+		verifier, _ := NewJWTVerifierImpl(JWTConfig{HMACSecret: testSecret})
+
+		_, err := verifier.Verify(signToken(t, jwt.SigningMethodHS256, []byte("other"), "", validClaims("admin")))
+		assert.ErrorIs(t, err, ErrInvalidToken, "Expected invalid token error")
+	})
+
+	t.Run("Verify_Rejects_Expired_And_Missing_Expiry", func(t *testing.T) {
+		verifier, _ := NewJWTVerifierImpl(JWTConfig{HMACSecret: testSecret, Leeway: time.Second})
+
+		claims := validClaims("admin")
+		claims["exp"] = time.Now().Add(-time.Minute).Unix()
+		_, err := verifier.Verify(signToken(t, jwt.SigningMethodHS256, testSecret, "", claims))
+		assert.ErrorIs(t, err, ErrInvalidToken, "Expected expired token to be rejected")
+
+		delete(claims, "exp")
+		_, err = verifier.Verify(signToken(t, jwt.SigningMethodHS256, testSecret, "", claims))
+		assert.ErrorIs(t, err, ErrInvalidToken, "Expected token without exp to be rejected")
+	})
+
+	t.Run("Verify_Rejects_Issuer_Audience_And_Subject", func(t *testing.T) {
+		verifier, _ := NewJWTVerifierImpl(JWTConfig{HMACSecret: testSecret, Issuer: "https://idp", Audience: "products"})
+
+		claims := validClaims("admin")
+		claims["iss"] = "https://idp"
+		claims["aud"] = "products"
+		_, err := verifier.Verify(signToken(t, jwt.SigningMethodHS256, testSecret, "", claims))
+		assert.Nil(t, err, "Expected matching issuer and audience to pass")
+
+		claims["aud"] = "sales"
+		_, err = verifier.Verify(signToken(t, jwt.SigningMethodHS256, testSecret, "", claims))
+		assert.ErrorIs(t, err, ErrInvalidToken, "Expected wrong audience to be rejected")
+
+		claims["aud"] = "products"
+		claims["iss"] = "https://evil"
+		_, err = verifier.Verify(signToken(t, jwt.SigningMethodHS256, testSecret, "", claims))
+		assert.ErrorIs(t, err, ErrInvalidToken, "Expected wrong issuer to be rejected")
+
+		claims["iss"] = "https://idp"
+		delete(claims, "sub")
+		_, err = verifier.Verify(signToken(t, jwt.SigningMethodHS256, testSecret, "", claims))
+		assert.ErrorIs(t, err, ErrInvalidToken, "Expected missing subject to be rejected")
+	})
+
+	t.Run("Verify_Rejects_None_Algorithm", func(t *testing.T) {
+		verifier, _ := NewJWTVerifierImpl(JWTConfig{HMACSecret: testSecret})
+
+		token := signToken(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "", validClaims("admin"))
+		_, err := verifier.Verify(token)
+		assert.ErrorIs(t, err, ErrInvalidToken, "Expected unsigned token to be rejected")
+	})
+
+	t.Run("Verify_RS256_And_ES256_From_JWKS", func(t *testing.T) {
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		assert.Nil(t, err, "Expected no error generating RSA key")
+		ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		assert.Nil(t, err, "Expected no error generating EC key")
+
+		keys := &atomic.Value{}
+		keys.Store([]jsonWebKey{rsaJWK("rsa-1", &rsaKey.PublicKey), ecJWK("ec-1", &ecKey.PublicKey)})
+		fetches := &atomic.Int32{}
+		server := serveJWKS(keys, fetches)
+		defer server.Close()
+
+		verifier, _ := NewJWTVerifierImpl(JWTConfig{KeySet: NewJWKSKeySetImpl(server.URL, nil, time.Hour)})
+
+		principal, err := verifier.Verify(signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa-1", validClaims("viewer")))
+		assert.Nil(t, err, "Expected no error verifying RS256 token")
+		assert.Equal(t, []Role{RoleViewer}, principal.Roles, "Expected viewer role")
+
+		_, err = verifier.Verify(signToken(t, jwt.SigningMethodES256, ecKey, "ec-1", validClaims("editor")))
+		assert.Nil(t, err, "Expected no error verifying ES256 token")
+
+		_, err = verifier.Verify(signToken(t, jwt.SigningMethodRS256, rsaKey, "ec-1", validClaims("editor")))
+		assert.ErrorIs(t, err, ErrInvalidToken, "Expected key type mismatch to be rejected")
+
+		_, err = verifier.Verify(signToken(t, jwt.SigningMethodHS256, testSecret, "", validClaims("admin")))
+		assert.ErrorIs(t, err, ErrInvalidToken, "Expected HS256 to be rejected without a secret")
+
+		assert.Equal(t, int32(1), fetches.Load(), "Expected the key set to be fetched once")
+	})
+
+	t.Run("NewJWTVerifierImpl_Requires_Key_Material", func(t *testing.T) {
+		_, err := NewJWTVerifierImpl(JWTConfig{})
+		assert.NotNil(t, err, "Expected error without secret or key set")
+	})
+}
@@ -0,0 +1,6 @@
+package auth
+
+type KeySet interface {
+	// Key returns the public key for kid, an empty kid is accepted when the set holds a single key
+	Key(kid string) (interface{}, error)
+}
@@ -0,0 +1,19 @@
+package auth
+
+// Permission is granted to end users through a role and to API keys through a scope.
+// An empty scope means no API key can be granted the permission.
+type Permission struct {
+	Role  Role
+	Scope Scope
+}
+
+var (
+	PermissionReadProducts  = Permission{Role: RoleViewer, Scope: ScopeProductsRead}
+	PermissionWriteProducts = Permission{Role: RoleEditor, Scope: ScopeProductsWrite}
+	// Deletes stay with administrators, machine clients only create and update
+	PermissionDeleteProducts = Permission{Role: RoleAdmin}
+	PermissionReserveStock   = Permission{Role: RoleEditor, Scope: ScopeStockReserve}
+	PermissionManageApiKeys  = Permission{Role: RoleAdmin}
+	// Debug endpoints expose internals of every tenant
+	PermissionReadDebug = Permission{Role: RoleAdmin}
+)
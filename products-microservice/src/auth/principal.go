@@ -0,0 +1,84 @@
+package auth
+
+import "context"
+
+// Principal is the authenticated caller of a request, end users carry roles and
+// API keys carry scopes. TenantID is set when the credentials are bound to a tenant.
+type Principal struct {
+	Subject  string
+	Roles    []Role
+	Scopes   []Scope
+	ApiKeyID uint
+	TenantID string
+}
+
+func (p *Principal) HasRole(required Role) bool {
+	for _, role := range p.Roles {
+		if role.Satisfies(required) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Principal) HasScope(required Scope) bool {
+	for _, scope := range p.Scopes {
+		if scope == required {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Principal) Can(permission Permission) bool {
+	if p.HasRole(permission.Role) {
+		return true
+	}
+	return permission.Scope != "" && p.HasScope(permission.Scope)
+}
+
+type authStateKey struct{}
+
+type authState struct {
+	principal *Principal
+	disabled  bool
+}
+
+// WithPrincipal marks ctx as authenticated, a nil principal marks an anonymous caller
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, authStateKey{}, &authState{principal: principal})
+}
+
+// WithoutAuthentication marks ctx as served with authentication turned off, every
+// permission is granted
+func WithoutAuthentication(ctx context.Context) context.Context {
+	return context.WithValue(ctx, authStateKey{}, &authState{disabled: true})
+}
+
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	state, ok := ctx.Value(authStateKey{}).(*authState)
+	if !ok || state.principal == nil {
+		return nil, false
+	}
+	return state.principal, true
+}
+
+// Authorize checks that the caller stored in ctx has the permission. Contexts that
+// never went through authentication are rejected, only WithoutAuthentication lets
+// them through.
+func Authorize(ctx context.Context, permission Permission) error {
+	state, ok := ctx.Value(authStateKey{}).(*authState)
+	if !ok {
+		return ErrUnauthenticated
+	}
+	if state.disabled {
+		return nil
+	}
+	if state.principal == nil {
+		return ErrUnauthenticated
+	}
+	if !state.principal.Can(permission) {
+		return ErrForbidden
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrincipal(t *testing.T) {
+
+	t.Run("HasRole_Is_Hierarchical", func(t *testing.T) {
+		principal := &Principal{Subject: "user-1", Roles: []Role{RoleEditor}}
+
+		assert.True(t, principal.HasRole(RoleViewer), "Expected editor to satisfy viewer")
+		assert.True(t, principal.HasRole(RoleEditor), "Expected editor to satisfy editor")
+		assert.False(t, principal.HasRole(RoleAdmin), "Expected editor not to satisfy admin")
+		assert.False(t, (&Principal{}).HasRole(RoleViewer), "Expected no roles to satisfy nothing")
+	})
+
+	t.Run("Can_Uses_Roles_Or_Scopes", func(t *testing.T) {
+		apiKey := &Principal{Subject: "api-key:abc", Scopes: []Scope{ScopeProductsWrite}, ApiKeyID: 1}
+
+		assert.True(t, apiKey.Can(PermissionWriteProducts), "Expected write scope to grant writes")
+		assert.False(t, apiKey.Can(PermissionReserveStock), "Expected missing scope to be denied")
+		assert.False(t, apiKey.Can(PermissionDeleteProducts), "Expected no scope to grant deletes")
+
+		admin := &Principal{Subject: "user-1", Roles: []Role{RoleAdmin}}
+		assert.True(t, admin.Can(PermissionDeleteProducts), "Expected admin to delete")
+		assert.True(t, admin.Can(PermissionReserveStock), "Expected admin to reserve stock")
+	})
+
+	t.Run("Authorize_Context_States", func(t *testing.T) {
+		ctx := context.Background()
+		assert.ErrorIs(t, Authorize(ctx, PermissionReadProducts), ErrUnauthenticated, "Expected contexts without authentication to be rejected")
+		assert.Nil(t, Authorize(WithoutAuthentication(ctx), PermissionDeleteProducts), "Expected every permission when auth is disabled")
+
+		anonymous := WithPrincipal(ctx, nil)
+		assert.ErrorIs(t, Authorize(anonymous, PermissionReadProducts), ErrUnauthenticated, "Expected anonymous caller to be rejected")
+
+		editor := WithPrincipal(ctx, &Principal{Subject: "user-1", Roles: []Role{RoleEditor}})
+		assert.Nil(t, Authorize(editor, PermissionWriteProducts), "Expected editor to be allowed")
+		assert.ErrorIs(t, Authorize(editor, PermissionDeleteProducts), ErrForbidden, "Expected editor to be forbidden from deletes")
+
+		principal, ok := PrincipalFromContext(editor)
+		assert.True(t, ok, "Expected principal in context")
+		assert.Equal(t, "user-1", principal.Subject, "Expected stored principal")
+	})
+}
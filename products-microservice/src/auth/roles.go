@@ -0,0 +1,30 @@
+package auth
+
+import "strings"
+
+type Role string
+
+const (
+	RoleViewer Role = "viewer"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+)
+
+// Roles are hierarchical, an admin can do everything an editor can and so on
+var roleLevels = map[Role]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+}
+
+// ParseRole returns false for roles this service does not know about
+func ParseRole(value string) (Role, bool) {
+	role := Role(strings.ToLower(strings.TrimSpace(value)))
+	_, ok := roleLevels[role]
+	return role, ok
+}
+
+// Satisfies reports whether r grants at least the permissions of required
+func (r Role) Satisfies(required Role) bool {
+	return roleLevels[r] >= roleLevels[required] && roleLevels[r] > 0
+}
@@ -0,0 +1,41 @@
+package auth
+
+import "strings"
+
+type Scope string
+
+const (
+	ScopeProductsRead  Scope = "products:read"
+	ScopeProductsWrite Scope = "products:write"
+	ScopeStockReserve  Scope = "stock:reserve"
+)
+
+var knownScopes = map[Scope]bool{
+	ScopeProductsRead:  true,
+	ScopeProductsWrite: true,
+	ScopeStockReserve:  true,
+}
+
+func ParseScope(value string) (Scope, bool) {
+	scope := Scope(strings.TrimSpace(value))
+	return scope, knownScopes[scope]
+}
+
+// ParseScopes reads a space separated list and drops unknown scopes
+func ParseScopes(value string) []Scope {
+	var scopes []Scope
+	for _, field := range strings.Fields(value) {
+		if scope, ok := ParseScope(field); ok {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+func FormatScopes(scopes []Scope) string {
+	values := make([]string, len(scopes))
+	for i, scope := range scopes {
+		values[i] = string(scope)
+	}
+	return strings.Join(values, " ")
+}
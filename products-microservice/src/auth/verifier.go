@@ -0,0 +1,5 @@
+package auth
+
+type TokenVerifier interface {
+	Verify(token string) (*Principal, error)
+}
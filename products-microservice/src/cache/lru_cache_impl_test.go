@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRUCacheImpl(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Get_Set_Delete", func(t *testing.T) {
+		lru := NewLRUCacheImpl(2)
+
+		_, found, err := lru.Get(ctx, "a")
+		assert.Nil(t, err, "Expected no error on miss")
+		assert.False(t, found, "Expected miss")
+
+		assert.Nil(t, lru.Set(ctx, "a", []byte("1"), time.Minute), "Expected no error setting value")
+		value, found, _ := lru.Get(ctx, "a")
+		assert.True(t, found, "Expected hit")
+		assert.Equal(t, []byte("1"), value, "Expected stored value")
+
+		assert.Nil(t, lru.Delete(ctx, "a", "missing"), "Expected no error deleting")
+		_, found, _ = lru.Get(ctx, "a")
+		assert.False(t, found, "Expected deleted key to miss")
+	})
+
+	t.Run("Evicts_Least_Recently_Used", func(t *testing.T) {
+		lru := NewLRUCacheImpl(2)
+
+		_ = lru.Set(ctx, "a", []byte("1"), time.Minute)
+		_ = lru.Set(ctx, "b", []byte("2"), time.Minute)
+		_, _, _ = lru.Get(ctx, "a")
+		_ = lru.Set(ctx, "c", []byte("3"), time.Minute)
+
+		_, found, _ := lru.Get(ctx, "b")
+		assert.False(t, found, "Expected least recently used key to be evicted")
+		_, found, _ = lru.Get(ctx, "a")
+		assert.True(t, found, "Expected recently used key to stay")
+		_, found, _ = lru.Get(ctx, "c")
+		assert.True(t, found, "Expected new key to stay")
+	})
+
+	t.Run("Expires_After_TTL", func(t *testing.T) {
+		now := time.Now()
+		lru := NewLRUCacheImpl(2).(*LRUCacheImpl)
+		lru.now = func() time.Time { return now }
+
+		_ = lru.Set(ctx, "a", []byte("1"), time.Second)
+		now = now.Add(time.Second)
+
+		_, found, _ := lru.Get(ctx, "a")
+		assert.False(t, found, "Expected expired key to miss")
+		assert.Equal(t, 0, lru.order.Len(), "Expected expired entry to be removed")
+	})
+}
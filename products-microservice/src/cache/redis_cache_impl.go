@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const DefaultRedisKeyPrefix = "cache:"
+
+type RedisCacheImpl struct {
+	client redis.Cmdable
+	prefix string
+}
+
+// Get implements Cache.
+func (r *RedisCacheImpl) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := r.client.Get(ctx, r.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Set implements Cache.
+func (r *RedisCacheImpl) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.client.Set(ctx, r.prefix+key, value, ttl).Err()
+}
+
+// Delete implements Cache.
+func (r *RedisCacheImpl) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	prefixed := make([]string, 0, len(keys))
+	for _, key := range keys {
+		prefixed = append(prefixed, r.prefix+key)
+	}
+	return r.client.Del(ctx, prefixed...).Err()
+}
+
+// NewRedisCacheImpl shares the cache between replicas through any Redis compatible server
+func NewRedisCacheImpl(client redis.Cmdable, prefix string) Cache {
+	return &RedisCacheImpl{client: client, prefix: prefix}
+}
@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisCacheImpl(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (Cache, *miniredis.Miniredis) {
+		server := miniredis.RunT(t)
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { _ = client.Close() })
+		return NewRedisCacheImpl(client, DefaultRedisKeyPrefix), server
+	}
+
+	t.Run("Get_Set_Delete", func(t *testing.T) {
+		redisCache, server := setup(t)
+
+		_, found, err := redisCache.Get(ctx, "a")
+		assert.Nil(t, err, "Expected no error on miss")
+		assert.False(t, found, "Expected miss")
+
+		assert.Nil(t, redisCache.Set(ctx, "a", []byte("1"), time.Minute), "Expected no error setting value")
+		assert.True(t, server.Exists(DefaultRedisKeyPrefix+"a"), "Expected prefixed key")
+
+		value, found, _ := redisCache.Get(ctx, "a")
+		assert.True(t, found, "Expected hit")
+		assert.Equal(t, []byte("1"), value, "Expected stored value")
+
+		assert.Nil(t, redisCache.Delete(ctx, "a"), "Expected no error deleting")
+		_, found, _ = redisCache.Get(ctx, "a")
+		assert.False(t, found, "Expected deleted key to miss")
+	})
+
+	t.Run("Expires_After_TTL", func(t *testing.T) {
+		redisCache, server := setup(t)
+
+		_ = redisCache.Set(ctx, "a", []byte("1"), time.Second)
+		server.FastForward(time.Second)
+
+		_, found, _ := redisCache.Get(ctx, "a")
+		assert.False(t, found, "Expected expired key to miss")
+	})
+
+	t.Run("Server_Down", func(t *testing.T) {
+		redisCache, server := setup(t)
+		server.Close()
+
+		_, found, err := redisCache.Get(ctx, "a")
+		assert.NotNil(t, err, "Expected error when the server is down")
+		assert.False(t, found, "Expected miss when the server is down")
+	})
+}
@@ -0,0 +1,262 @@
+package config
+
+import (
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/cache"
+	"github.com/dieg0code/products-microservice/src/events"
+	"github.com/dieg0code/products-microservice/src/health"
+	"github.com/dieg0code/products-microservice/src/lifecycle"
+	"github.com/dieg0code/products-microservice/src/middleware"
+	"github.com/dieg0code/products-microservice/src/repository"
+	"github.com/dieg0code/products-microservice/src/router"
+	"github.com/dieg0code/products-microservice/src/tenant"
+	"github.com/dieg0code/products-microservice/src/tracing"
+)
+
+// Every setting has a key, the dotted path of its yaml tags, which is also its flag
+// name. The env tag keeps the variable names the service has always read, and
+// secret settings are redacted when the configuration is printed.
+type Config struct {
+	HTTP        HTTPConfig        `yaml:"http"`
+	GRPC        GRPCConfig        `yaml:"grpc"`
+	TLS         TLSConfig         `yaml:"tls"`
+	Database    DatabaseConfig    `yaml:"database"`
+	Log         LogConfig         `yaml:"log"`
+	Tracing     TracingConfig     `yaml:"tracing"`
+	Shutdown    ShutdownConfig    `yaml:"shutdown"`
+	Health      HealthConfig      `yaml:"health"`
+	Tenants     TenantsConfig     `yaml:"tenants"`
+	Auth        AuthConfig        `yaml:"auth"`
+	Redis       RedisConfig       `yaml:"redis"`
+	RateLimit   RateLimitConfig   `yaml:"rate_limit"`
+	Cache       CacheConfig       `yaml:"product_cache"`
+	HTTPCache   HTTPCacheConfig   `yaml:"http_cache"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+	Broker      BrokerConfig      `yaml:"broker"`
+	Features    FeaturesConfig    `yaml:"features"`
+}
+
+type HTTPConfig struct {
+	Addr           string        `yaml:"addr" env:"HTTP_ADDR"`
+	RequestTimeout time.Duration `yaml:"request_timeout" env:"HTTP_REQUEST_TIMEOUT"`
+	// ValidateRequests rejects API requests that don't match the OpenAPI document
+	ValidateRequests bool `yaml:"validate_requests" env:"HTTP_VALIDATE_REQUESTS"`
+	// ValidateResponses fails API responses that don't match it, for development and tests
+	ValidateResponses bool `yaml:"validate_responses" env:"HTTP_VALIDATE_RESPONSES"`
+	// TrustedProxies lists the proxy addresses or CIDRs whose X-Forwarded-For is believed,
+	// with none set the client IP is always the peer address
+	TrustedProxies []string `yaml:"trusted_proxies" env:"HTTP_TRUSTED_PROXIES"`
+}
+
+type GRPCConfig struct {
+	Port int `yaml:"port" env:"GRPC_PORT"`
+}
+
+// TLSConfig serves both HTTP and gRPC over TLS when a certificate is set
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file" env:"TLS_CERT_FILE"`
+	KeyFile  string `yaml:"key_file" env:"TLS_KEY_FILE"`
+}
+
+func (t TLSConfig) Enabled() bool {
+	return t.CertFile != ""
+}
+
+type DatabaseConfig struct {
+	Host     string `yaml:"host" env:"DB_HOST"`
+	Port     int    `yaml:"port" env:"DB_PORT"`
+	User     string `yaml:"user" env:"DB_USER"`
+	Password string `yaml:"password" env:"DB_PASSWORD" secret:"true"`
+	Name     string `yaml:"name" env:"DB_NAME"`
+	// SSLMode is a libpq sslmode, verify-ca and verify-full check the server against RootCert
+	SSLMode  string `yaml:"sslmode" env:"DB_SSLMODE"`
+	RootCert string `yaml:"root_cert" env:"DB_ROOT_CERT"`
+	// Replicas are host or host:port read replicas sharing the primary's credentials,
+	// they serve product lists and searches
+	Replicas []string `yaml:"replicas" env:"DB_REPLICAS"`
+	// MaxOpenConns caps every pool, 0 leaves it unbounded
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME"`
+	Retry           RetryConfig   `yaml:"retry" env:"DB_CONNECT"`
+	// AutoMigrate applies pending migrations at startup, turn it off to run them
+	// with the migrate command instead
+	AutoMigrate bool `yaml:"auto_migrate" env:"DB_AUTO_MIGRATE"`
+}
+
+// RetryConfig backs off exponentially between connection attempts, with jitter so
+// replicas of the service don't retry in lockstep
+type RetryConfig struct {
+	Attempts       int           `yaml:"attempts" env:"ATTEMPTS"`
+	InitialBackoff time.Duration `yaml:"initial_backoff" env:"INITIAL_BACKOFF"`
+	MaxBackoff     time.Duration `yaml:"max_backoff" env:"MAX_BACKOFF"`
+}
+
+type LogConfig struct {
+	Level  string `yaml:"level" env:"LOG_LEVEL"`
+	Format string `yaml:"format" env:"LOG_FORMAT"`
+}
+
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+}
+
+type ShutdownConfig struct {
+	DrainPeriod time.Duration `yaml:"drain_period" env:"SHUTDOWN_DRAIN_PERIOD"`
+	Timeout     time.Duration `yaml:"timeout" env:"SHUTDOWN_TIMEOUT"`
+}
+
+type HealthConfig struct {
+	CheckTimeout time.Duration `yaml:"check_timeout" env:"HEALTH_CHECK_TIMEOUT"`
+	CacheTTL     time.Duration `yaml:"cache_ttl" env:"HEALTH_CACHE_TTL"`
+}
+
+type TenantsConfig struct {
+	// Default serves requests without a tenant, empty requires one
+	Default    string `yaml:"default" env:"TENANT_DEFAULT"`
+	BaseDomain string `yaml:"base_domain" env:"TENANT_BASE_DOMAIN"`
+}
+
+// AuthConfig enables authentication when a JWT secret or a JWKS URL is set. Without
+// them the service refuses to start unless Disabled is set.
+type AuthConfig struct {
+	Disabled    bool   `yaml:"disabled" env:"AUTH_DISABLED"`
+	JWTSecret   string `yaml:"jwt_secret" env:"JWT_SECRET" secret:"true"`
+	JWKSURL     string `yaml:"jwks_url" env:"JWT_JWKS_URL"`
+	Issuer      string `yaml:"issuer" env:"JWT_ISSUER"`
+	Audience    string `yaml:"audience" env:"JWT_AUDIENCE"`
+	RolesClaim  string `yaml:"roles_claim" env:"JWT_ROLES_CLAIM"`
+	TenantClaim string `yaml:"tenant_claim" env:"JWT_TENANT_CLAIM"`
+	// RequireTenant rejects tokens without the tenant claim instead of binding them
+	// to the default tenant
+	RequireTenant bool `yaml:"require_tenant" env:"JWT_REQUIRE_TENANT"`
+}
+
+func (a AuthConfig) Enabled() bool {
+	return a.JWTSecret != "" || a.JWKSURL != ""
+}
+
+type RedisConfig struct {
+	// URL may carry the password, so it is treated as a secret
+	URL string `yaml:"url" env:"REDIS_URL" secret:"true"`
+}
+
+// Backends of the rate limit store, the product cache and the broker
+const (
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+	BackendNone   = "none"
+)
+
+// RateLimitConfig holds limits such as 600/1m per route group, an empty limit disables that bucket
+type RateLimitConfig struct {
+	Store    string         `yaml:"store" env:"RATE_LIMIT_STORE"`
+	Products RateLimitGroup `yaml:"products" env:"RATE_LIMIT_PRODUCTS"`
+	GraphQL  RateLimitGroup `yaml:"graphql" env:"RATE_LIMIT_GRAPHQL"`
+	ApiKeys  RateLimitGroup `yaml:"api_keys" env:"RATE_LIMIT_API_KEYS"`
+}
+
+type RateLimitGroup struct {
+	Read  string `yaml:"read" env:"READ"`
+	Write string `yaml:"write" env:"WRITE"`
+}
+
+type CacheConfig struct {
+	Backend string        `yaml:"backend" env:"PRODUCT_CACHE"`
+	Size    int           `yaml:"size" env:"PRODUCT_CACHE_SIZE"`
+	TTL     time.Duration `yaml:"ttl" env:"PRODUCT_CACHE_TTL"`
+}
+
+// HTTPCacheConfig holds the Cache-Control of every cacheable route, empty turns HTTP caching off for it
+type HTTPCacheConfig struct {
+	ProductsList       string `yaml:"products_list" env:"CACHE_CONTROL_PRODUCTS_LIST"`
+	ProductsByCategory string `yaml:"products_by_category" env:"CACHE_CONTROL_PRODUCTS_BY_CATEGORY"`
+}
+
+type IdempotencyConfig struct {
+	TTL time.Duration `yaml:"ttl" env:"IDEMPOTENCY_TTL"`
+	// Lease is how long a request holds its key before a retry may take it over
+	Lease time.Duration `yaml:"lease" env:"IDEMPOTENCY_LEASE"`
+}
+
+// BrokerConfig picks the broker sales are received from. The memory broker only
+// delivers messages published in-process, so it never sees the sales service.
+type BrokerConfig struct {
+	Backend string `yaml:"backend" env:"BROKER"`
+	// Group is the Redis consumer group, replicas in the same group share the sales
+	Group string `yaml:"group" env:"BROKER_GROUP"`
+}
+
+// FeaturesConfig turns optional APIs on and off
+type FeaturesConfig struct {
+	GRPC    bool `yaml:"grpc" env:"FEATURE_GRPC"`
+	GraphQL bool `yaml:"graphql" env:"FEATURE_GRAPHQL"`
+	Stream  bool `yaml:"stream" env:"FEATURE_STREAM"`
+	Metrics bool `yaml:"metrics" env:"FEATURE_METRICS"`
+}
+
+// Default returns the configuration used for every setting no source overrides
+func Default() *Config {
+	return &Config{
+		HTTP: HTTPConfig{
+			Addr:           ":8080",
+			RequestTimeout: router.DefaultRequestTimeout,
+		},
+		GRPC: GRPCConfig{Port: 9090},
+		Database: DatabaseConfig{
+			Port:            5432,
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+			Retry: RetryConfig{
+				Attempts:       5,
+				InitialBackoff: time.Second,
+				MaxBackoff:     30 * time.Second,
+			},
+			AutoMigrate: true,
+		},
+		Log: LogConfig{Level: "info", Format: "text"},
+		Tracing: TracingConfig{
+			Exporter:    tracing.ExporterNone,
+			SampleRatio: 1,
+		},
+		Shutdown: ShutdownConfig{
+			DrainPeriod: lifecycle.DefaultDrainPeriod,
+			Timeout:     lifecycle.DefaultShutdownTimeout,
+		},
+		Health: HealthConfig{
+			CheckTimeout: health.DefaultCheckTimeout,
+			CacheTTL:     health.DefaultCacheTTL,
+		},
+		Tenants: TenantsConfig{Default: tenant.DefaultTenantID},
+		RateLimit: RateLimitConfig{
+			Store:    BackendMemory,
+			Products: RateLimitGroup{Read: "600/1m", Write: "60/1m"},
+			// GraphQL queries are sent with POST so both buckets get the read limit
+			GraphQL: RateLimitGroup{Read: "300/1m", Write: "300/1m"},
+			ApiKeys: RateLimitGroup{Read: "60/1m", Write: "10/1m"},
+		},
+		Cache: CacheConfig{
+			Backend: BackendMemory,
+			Size:    cache.DefaultLRUCapacity,
+			TTL:     repository.DefaultProductCacheTTL,
+		},
+		HTTPCache: HTTPCacheConfig{
+			ProductsList:       router.DefaultCachePolicies[router.RouteProductsList].CacheControl,
+			ProductsByCategory: router.DefaultCachePolicies[router.RouteProductsByCategory].CacheControl,
+		},
+		Idempotency: IdempotencyConfig{TTL: middleware.DefaultIdempotencyKeysTTL, Lease: middleware.DefaultIdempotencyLease},
+		Broker:      BrokerConfig{Backend: BackendMemory, Group: events.DefaultRedisGroup},
+		Features: FeaturesConfig{
+			GRPC:    true,
+			GraphQL: true,
+			Stream:  true,
+			Metrics: true,
+		},
+	}
+}
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestConfig(t *testing.T) {
+	required := map[string]string{
+		"DB_HOST":       "localhost",
+		"DB_USER":       "postgres",
+		"DB_NAME":       "products",
+		"AUTH_DISABLED": "true",
+	}
+
+	env := func(values map[string]string) func(string) (string, bool) {
+		return func(name string) (string, bool) {
+			if value, ok := values[name]; ok {
+				return value, true
+			}
+			value, ok := required[name]
+			return value, ok
+		}
+	}
+
+	writeFile := func(t *testing.T, name string, content string) string {
+		path := filepath.Join(t.TempDir(), name)
+		assert.Nil(t, os.WriteFile(path, []byte(content), 0o600), "Expected no error writing the file")
+		return path
+	}
+
+	parse := func(t *testing.T, args ...string) *Flags {
+		flags, err := ParseFlags("products-microservice", args, io.Discard)
+		assert.Nil(t, err, "Expected no error parsing flags")
+		return flags
+	}
+
+	t.Run("Load_Defaults", func(t *testing.T) {
+		config, err := Load(parse(t), env(nil))
+		assert.Nil(t, err, "Expected no error")
+		assert.Equal(t, ":8080", config.HTTP.Addr, "Expected the default address")
+		assert.Equal(t, 5432, config.Database.Port, "Expected the default port")
+		assert.True(t, config.Features.GraphQL, "Expected GraphQL to be enabled")
+	})
+
+	t.Run("Load_Precedence", func(t *testing.T) {
+		path := writeFile(t, "config.yaml", `
+database:
+  port: 6000
+  max_open_conns: 50
+http:
+  request_timeout: 15s
+`)
+
+		config, err := Load(parse(t, "--config", path, "--database.max_open_conns=40"), env(map[string]string{
+			"DB_PORT":           "7000",
+			"DB_MAX_OPEN_CONNS": "30",
+		}))
+		assert.Nil(t, err, "Expected no error")
+		assert.Equal(t, 15*time.Second, config.HTTP.RequestTimeout, "Expected the file to override the default")
+		assert.Equal(t, 7000, config.Database.Port, "Expected the environment to override the file")
+		assert.Equal(t, 40, config.Database.MaxOpenConns, "Expected the flag to override the environment")
+	})
+
+	t.Run("Load_TOML_From_Env", func(t *testing.T) {
+		path := writeFile(t, "config.toml", `
+[tracing]
+exporter = "stdout"
+sample_ratio = 0.5
+
+[rate_limit.products]
+read = "100/1s"
+`)
+
+		config, err := Load(parse(t), env(map[string]string{FileEnv: path}))
+		assert.Nil(t, err, "Expected no error")
+		assert.Equal(t, "stdout", config.Tracing.Exporter, "Expected the exporter from the file")
+		assert.Equal(t, 0.5, config.Tracing.SampleRatio, "Expected the ratio from the file")
+		assert.Equal(t, "100/1s", config.RateLimit.Products.Read, "Expected the nested limit from the file")
+	})
+
+	t.Run("Load_Nested_Env_Names", func(t *testing.T) {
+		config, err := Load(parse(t), env(map[string]string{
+			"RATE_LIMIT_API_KEYS_WRITE":   "",
+			"CACHE_CONTROL_PRODUCTS_LIST": "no-store",
+		}))
+		assert.Nil(t, err, "Expected no error")
+		assert.Empty(t, config.RateLimit.ApiKeys.Write, "Expected an empty variable to clear the limit")
+		assert.Equal(t, "no-store", config.HTTPCache.ProductsList, "Expected the Cache-Control from the environment")
+	})
+
+	t.Run("Load_Lists", func(t *testing.T) {
+		path := writeFile(t, "config.yaml", `
+database:
+  replicas:
+    - replica-1
+    - replica-2:5433
+`)
+
+		config, err := Load(parse(t, "--config", path), env(nil))
+		assert.Nil(t, err, "Expected no error")
+		assert.Equal(t, []string{"replica-1", "replica-2:5433"}, config.Database.Replicas, "Expected the replicas from the file")
+
+		config, err = Load(parse(t, "--config", path), env(map[string]string{"DB_REPLICAS": "replica-3, replica-4"}))
+		assert.Nil(t, err, "Expected no error")
+		assert.Equal(t, []string{"replica-3", "replica-4"}, config.Database.Replicas, "Expected the environment to replace the list")
+
+		_, err = Load(parse(t, "--database.replicas=replica-5:http"), env(nil))
+		assert.ErrorContains(t, err, "database.replicas must hold host or host:port entries", "Expected the invalid replica")
+	})
+
+	t.Run("Load_Bool_Flag", func(t *testing.T) {
+		config, err := Load(parse(t, "--features.graphql=false", "--features.stream"), env(nil))
+		assert.Nil(t, err, "Expected no error")
+		assert.False(t, config.Features.GraphQL, "Expected GraphQL to be disabled")
+		assert.True(t, config.Features.Stream, "Expected a bare flag to enable the feature")
+	})
+
+	t.Run("Load_Unknown_Key", func(t *testing.T) {
+		path := writeFile(t, "config.yaml", "database:\n  hots: localhost\n")
+
+		_, err := Load(parse(t, "--config", path), env(nil))
+		assert.True(t, errors.Is(err, ErrUnknownKey), "Expected ErrUnknownKey")
+		assert.ErrorContains(t, err, `"database.hots"`, "Expected the key in the error")
+	})
+
+	t.Run("Load_Unsupported_Format", func(t *testing.T) {
+		path := writeFile(t, "config.json", "{}")
+
+		_, err := Load(parse(t, "--config", path), env(nil))
+		assert.ErrorContains(t, err, "unsupported format", "Expected a format error")
+	})
+
+	t.Run("Load_Reports_Every_Error", func(t *testing.T) {
+		_, err := Load(parse(t, "--grpc.port=abc"), func(name string) (string, bool) {
+			if name == "TRACING_SAMPLE_RATIO" {
+				return "2", true
+			}
+			return "", false
+		})
+		assert.NotNil(t, err, "Expected an error")
+		assert.ErrorContains(t, err, `--grpc.port: invalid integer "abc"`, "Expected the flag error")
+		assert.ErrorContains(t, err, "database.host is required", "Expected the missing host")
+		assert.ErrorContains(t, err, "tracing.sample_ratio must be between 0 and 1", "Expected the ratio error")
+	})
+
+	t.Run("ParseFlags_Unknown_Flag", func(t *testing.T) {
+		_, err := ParseFlags("products-microservice", []string{"--database.hots=localhost"}, io.Discard)
+		assert.NotNil(t, err, "Expected an error for an unknown flag")
+
+		_, err = ParseFlags("products-microservice", []string{"-h"}, io.Discard)
+		assert.True(t, errors.Is(err, flag.ErrHelp), "Expected flag.ErrHelp")
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		config := Default()
+		config.Database = DatabaseConfig{Host: "localhost", Port: 5432, User: "postgres", Name: "products", MaxOpenConns: 5, MaxIdleConns: 10}
+		config.TLS.CertFile = "/missing/cert.pem"
+		config.RateLimit.Store = BackendRedis
+		config.RateLimit.Products.Read = "lots"
+		config.Tenants.Default = "Not A Tenant"
+		config.Auth.JWTSecret = "secret"
+		config.Auth.Disabled = true
+		config.HTTP.TrustedProxies = []string{"10.0.0.0/8", "192.168.1.1", "proxy.internal"}
+		config.Broker = BrokerConfig{Backend: BackendRedis}
+
+		err := config.Validate()
+		assert.ErrorContains(t, err, "database.max_idle_conns must not exceed database.max_open_conns (5)", "Expected the pool error")
+		assert.ErrorContains(t, err, "tls.key_file is required when tls.cert_file is set", "Expected the TLS pair error")
+		assert.ErrorContains(t, err, "tls.cert_file can't be read", "Expected the missing certificate")
+		assert.ErrorContains(t, err, "redis.url is required", "Expected the Redis URL error")
+		assert.ErrorContains(t, err, "rate_limit.products.read must look like 600/1m", "Expected the limit error")
+		assert.ErrorContains(t, err, "tenants.default is not a valid tenant ID", "Expected the tenant error")
+		assert.ErrorContains(t, err, "auth.disabled can't be combined", "Expected the auth error")
+		assert.ErrorContains(t, err, `http.trusted_proxies must hold IP addresses or CIDRs, got "proxy.internal"`, "Expected the proxy error")
+		assert.NotContains(t, err.Error(), `"10.0.0.0/8"`, "Expected CIDRs to be accepted")
+		assert.ErrorContains(t, err, "broker.group is required when broker.backend is redis", "Expected the broker group error")
+
+		config.Auth = AuthConfig{}
+		assert.ErrorContains(t, config.Validate(), "auth.jwt_secret or auth.jwks_url is required", "Expected authentication to be required")
+	})
+
+	t.Run("WriteYAML_Redacts_Secrets", func(t *testing.T) {
+		config, err := Load(parse(t), env(map[string]string{
+			"DB_PASSWORD":   "hunter2",
+			"JWT_SECRET":    "jwt-secret",
+			"AUTH_DISABLED": "false",
+		}))
+		assert.Nil(t, err, "Expected no error")
+
+		var buf bytes.Buffer
+		assert.Nil(t, config.WriteYAML(&buf), "Expected no error printing")
+		assert.NotContains(t, buf.String(), "hunter2", "Expected the password to be redacted")
+		assert.NotContains(t, buf.String(), "jwt-secret", "Expected the JWT secret to be redacted")
+		assert.Equal(t, "hunter2", config.Database.Password, "Expected the configuration to be left untouched")
+
+		var printed map[string]map[string]interface{}
+		assert.Nil(t, yaml.Unmarshal(buf.Bytes(), &printed), "Expected valid YAML")
+		assert.Equal(t, redacted, printed["database"]["password"], "Expected the password placeholder")
+		assert.Equal(t, "", printed["redis"]["url"], "Expected empty secrets to stay empty")
+		assert.Equal(t, "10s", printed["http"]["request_timeout"], "Expected durations as strings")
+	})
+}
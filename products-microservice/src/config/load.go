@@ -0,0 +1,313 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv names the configuration file when --config is not given
+const FileEnv = "CONFIG_FILE"
+
+const redacted = "REDACTED"
+
+var ErrUnknownKey = errors.New("unknown configuration key")
+
+// Flags are the parsed command line, settings given as --<key>=<value> override
+// every other source
+type Flags struct {
+	// File is the YAML or TOML file to read
+	File string
+	// PrintConfig asks to print the configuration with secrets redacted and exit
+	PrintConfig bool
+
+	values map[string]string
+}
+
+// ParseFlags parses args, the program arguments without the program name
+func ParseFlags(name string, args []string, output io.Writer) (*Flags, error) {
+	flags := &Flags{values: make(map[string]string)}
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(&flags.File, "config", "", "YAML or TOML configuration file, defaults to $"+FileEnv)
+	fs.BoolVar(&flags.PrintConfig, "print-config", false, "print the configuration with secrets redacted and exit")
+	for _, s := range settings(Default()) {
+		fs.Var(&flagValue{values: flags.values, key: s.key, defaultValue: s.String(), isBool: s.value.Kind() == reflect.Bool}, s.key, "overrides $"+s.env)
+	}
+
+	err := fs.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	return flags, nil
+}
+
+// Load reads the defaults, then the configuration file, then the environment and
+// finally the flags, each source overriding the previous ones. Every invalid setting
+// is reported in the returned error.
+func Load(flags *Flags, lookupEnv func(string) (string, bool)) (*Config, error) {
+	config := Default()
+	index := make(map[string]setting)
+	all := settings(config)
+	for _, s := range all {
+		index[s.key] = s
+	}
+
+	var errs []error
+
+	file := flags.File
+	if file == "" {
+		file, _ = lookupEnv(FileEnv)
+	}
+	if file != "" {
+		values, err := readFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range sortedKeys(values) {
+			value := values[key]
+			s, ok := index[key]
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s: %w %q", file, ErrUnknownKey, key))
+				continue
+			}
+			if err := s.set(value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %s: %w", file, key, err))
+			}
+		}
+	}
+
+	for _, s := range all {
+		if value, ok := lookupEnv(s.env); ok {
+			if err := s.set(value); err != nil {
+				errs = append(errs, fmt.Errorf("$%s: %w", s.env, err))
+			}
+		}
+	}
+
+	for _, key := range sortedKeys(flags.values) {
+		if err := index[key].set(flags.values[key]); err != nil {
+			errs = append(errs, fmt.Errorf("--%s: %w", key, err))
+		}
+	}
+
+	err := errors.Join(append(errs, config.Validate())...)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// WriteYAML prints the configuration as a file Load could read, with secrets redacted
+func (c *Config) WriteYAML(w io.Writer) error {
+	copied := *c
+	for _, s := range settings(&copied) {
+		if s.secret && s.value.String() != "" {
+			s.value.SetString(redacted)
+		}
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	err := encoder.Encode(&copied)
+	if err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// setting is a single leaf of Config, lists are comma separated in the environment and in flags
+type setting struct {
+	key    string
+	env    string
+	secret bool
+	value  reflect.Value
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// settings lists the leaves of config, their values point into it
+func settings(config *Config) []setting {
+	return walk(reflect.ValueOf(config).Elem(), "", "")
+}
+
+func walk(v reflect.Value, keyPrefix string, envPrefix string) []setting {
+	var result []setting
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		key := keyPrefix + field.Tag.Get("yaml")
+		env := field.Tag.Get("env")
+		if envPrefix != "" {
+			env = envPrefix + "_" + env
+		}
+
+		if field.Type.Kind() == reflect.Struct {
+			result = append(result, walk(v.Field(i), key+".", env)...)
+			continue
+		}
+
+		result = append(result, setting{
+			key:    key,
+			env:    env,
+			secret: field.Tag.Get("secret") == "true",
+			value:  v.Field(i),
+		})
+	}
+	return result
+}
+
+func (s setting) String() string {
+	if s.value.Type() == durationType {
+		return time.Duration(s.value.Int()).String()
+	}
+	if s.value.Kind() == reflect.Slice {
+		return strings.Join(s.value.Interface().([]string), ",")
+	}
+	return fmt.Sprint(s.value.Interface())
+}
+
+func (s setting) set(raw string) error {
+	if s.value.Type() == durationType {
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		s.value.SetInt(int64(duration))
+		return nil
+	}
+
+	switch s.value.Kind() {
+	case reflect.String:
+		s.value.SetString(raw)
+	case reflect.Slice:
+		var values []string
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+		s.value.Set(reflect.ValueOf(values))
+	case reflect.Int:
+		number, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		s.value.SetInt(int64(number))
+	case reflect.Float64:
+		number, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		s.value.SetFloat(number)
+	case reflect.Bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		s.value.SetBool(value)
+	default:
+		return fmt.Errorf("unsupported setting type %s", s.value.Type())
+	}
+	return nil
+}
+
+// readFile flattens a YAML or TOML file into dotted keys
+func readFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading configuration file: %w", err)
+	}
+
+	var tree map[string]interface{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &tree)
+	case ".toml":
+		err = toml.Unmarshal(content, &tree)
+	default:
+		return nil, fmt.Errorf("configuration file %s: unsupported format %q, use .yaml, .yml or .toml", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing configuration file %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	err = flatten(tree, "", values)
+	if err != nil {
+		return nil, fmt.Errorf("configuration file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+func flatten(tree map[string]interface{}, prefix string, values map[string]string) error {
+	for name, value := range tree {
+		key := prefix + name
+		switch value := value.(type) {
+		case map[string]interface{}:
+			err := flatten(value, key+".", values)
+			if err != nil {
+				return err
+			}
+		case []interface{}:
+			items := make([]string, 0, len(value))
+			for _, item := range value {
+				if _, ok := item.(map[string]interface{}); ok {
+					return fmt.Errorf("%s: lists may only hold plain values", key)
+				}
+				items = append(items, fmt.Sprint(item))
+			}
+			values[key] = strings.Join(items, ",")
+		case nil:
+			values[key] = ""
+		default:
+			values[key] = fmt.Sprint(value)
+		}
+	}
+	return nil
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// flagValue records the settings given on the command line, they are parsed by Load
+type flagValue struct {
+	values       map[string]string
+	key          string
+	defaultValue string
+	isBool       bool
+}
+
+func (f *flagValue) String() string {
+	return f.defaultValue
+}
+
+func (f *flagValue) Set(value string) error {
+	f.values[f.key] = value
+	return nil
+}
+
+func (f *flagValue) IsBoolFlag() bool {
+	return f.isBool
+}
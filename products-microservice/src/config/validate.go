@@ -0,0 +1,154 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/logging"
+	"github.com/dieg0code/products-microservice/src/ratelimit"
+	"github.com/dieg0code/products-microservice/src/tenant"
+	"github.com/dieg0code/products-microservice/src/tracing"
+	"github.com/sirupsen/logrus"
+)
+
+// Validate reports every invalid setting, each error names the setting's key
+func (c *Config) Validate() error {
+	v := &validation{}
+
+	v.check(c.HTTP.Addr != "", "http.addr", "is required")
+	v.positive("http.request_timeout", c.HTTP.RequestTimeout)
+	for _, proxy := range c.HTTP.TrustedProxies {
+		_, _, cidrErr := net.ParseCIDR(proxy)
+		v.check(cidrErr == nil || net.ParseIP(proxy) != nil, "http.trusted_proxies", "must hold IP addresses or CIDRs, got %q", proxy)
+	}
+	v.check(c.GRPC.Port > 0 && c.GRPC.Port <= 65535, "grpc.port", "must be between 1 and 65535, got %d", c.GRPC.Port)
+
+	v.check(c.TLS.CertFile == "" || c.TLS.KeyFile != "", "tls.key_file", "is required when tls.cert_file is set")
+	v.check(c.TLS.KeyFile == "" || c.TLS.CertFile != "", "tls.cert_file", "is required when tls.key_file is set")
+	v.readable("tls.cert_file", c.TLS.CertFile)
+	v.readable("tls.key_file", c.TLS.KeyFile)
+
+	v.check(c.Database.Host != "", "database.host", "is required")
+	v.check(c.Database.Port > 0 && c.Database.Port <= 65535, "database.port", "must be between 1 and 65535, got %d", c.Database.Port)
+	v.check(c.Database.User != "", "database.user", "is required")
+	v.check(c.Database.Name != "", "database.name", "is required")
+	v.oneOf("database.sslmode", c.Database.SSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full")
+	v.readable("database.root_cert", c.Database.RootCert)
+	for _, replica := range c.Database.Replicas {
+		v.hostPort("database.replicas", replica)
+	}
+	v.check(c.Database.ConnMaxLifetime >= 0, "database.conn_max_lifetime", "must not be negative")
+	v.check(c.Database.ConnMaxIdleTime >= 0, "database.conn_max_idle_time", "must not be negative")
+	v.check(c.Database.Retry.Attempts > 0, "database.retry.attempts", "must be at least 1, got %d", c.Database.Retry.Attempts)
+	v.positive("database.retry.initial_backoff", c.Database.Retry.InitialBackoff)
+	v.check(c.Database.Retry.MaxBackoff >= c.Database.Retry.InitialBackoff, "database.retry.max_backoff", "must not be shorter than database.retry.initial_backoff (%s)", c.Database.Retry.InitialBackoff)
+	v.check(c.Database.MaxOpenConns >= 0, "database.max_open_conns", "must not be negative")
+	v.check(c.Database.MaxIdleConns >= 0, "database.max_idle_conns", "must not be negative")
+	v.check(c.Database.MaxOpenConns == 0 || c.Database.MaxIdleConns <= c.Database.MaxOpenConns, "database.max_idle_conns", "must not exceed database.max_open_conns (%d)", c.Database.MaxOpenConns)
+
+	if c.Log.Level != "" {
+		_, err := logrus.ParseLevel(c.Log.Level)
+		v.check(err == nil, "log.level", "must be one of panic, fatal, error, warn, info, debug, trace, got %q", c.Log.Level)
+	}
+	v.oneOf("log.format", c.Log.Format, "", logging.FormatText, logging.FormatJSON)
+
+	v.oneOf("tracing.exporter", c.Tracing.Exporter, "", tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterStdout)
+	v.check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio", "must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+
+	v.check(c.Shutdown.DrainPeriod >= 0, "shutdown.drain_period", "must not be negative")
+	v.positive("shutdown.timeout", c.Shutdown.Timeout)
+	v.positive("health.check_timeout", c.Health.CheckTimeout)
+	v.check(c.Health.CacheTTL >= 0, "health.cache_ttl", "must not be negative")
+
+	v.check(c.Tenants.Default == "" || tenant.Valid(c.Tenants.Default), "tenants.default", "is not a valid tenant ID: %q", c.Tenants.Default)
+
+	v.check(c.Auth.Enabled() || c.Auth.Disabled, "auth.jwt_secret", "or auth.jwks_url is required, set auth.disabled to run without authentication")
+	v.check(!c.Auth.Enabled() || !c.Auth.Disabled, "auth.disabled", "can't be combined with auth.jwt_secret or auth.jwks_url")
+	if c.Auth.JWKSURL != "" {
+		parsed, err := url.Parse(c.Auth.JWKSURL)
+		v.check(err == nil && (parsed.Scheme == "https" || parsed.Scheme == "http") && parsed.Host != "", "auth.jwks_url", "must be an http or https URL")
+	}
+
+	v.oneOf("rate_limit.store", c.RateLimit.Store, BackendMemory, BackendRedis, BackendNone)
+	v.limit("rate_limit.products.read", c.RateLimit.Products.Read)
+	v.limit("rate_limit.products.write", c.RateLimit.Products.Write)
+	v.limit("rate_limit.graphql.read", c.RateLimit.GraphQL.Read)
+	v.limit("rate_limit.graphql.write", c.RateLimit.GraphQL.Write)
+	v.limit("rate_limit.api_keys.read", c.RateLimit.ApiKeys.Read)
+	v.limit("rate_limit.api_keys.write", c.RateLimit.ApiKeys.Write)
+
+	v.oneOf("product_cache.backend", c.Cache.Backend, BackendMemory, BackendRedis, BackendNone)
+	v.check(c.Cache.Size > 0, "product_cache.size", "must be positive, got %d", c.Cache.Size)
+	v.positive("product_cache.ttl", c.Cache.TTL)
+
+	v.oneOf("broker.backend", c.Broker.Backend, BackendMemory, BackendRedis)
+	v.check(c.Broker.Backend != BackendRedis || c.Broker.Group != "", "broker.group", "is required when broker.backend is redis")
+
+	if c.RateLimit.Store == BackendRedis || c.Cache.Backend == BackendRedis || c.Broker.Backend == BackendRedis {
+		v.check(c.Redis.URL != "", "redis.url", "is required when rate_limit.store, product_cache.backend or broker.backend is redis")
+	}
+
+	v.positive("idempotency.ttl", c.Idempotency.TTL)
+	v.positive("idempotency.lease", c.Idempotency.Lease)
+	v.check(c.Idempotency.Lease > c.HTTP.RequestTimeout, "idempotency.lease", "must be longer than http.request_timeout, got %s", c.Idempotency.Lease)
+
+	return errors.Join(v.errs...)
+}
+
+type validation struct {
+	errs []error
+}
+
+func (v *validation) check(ok bool, key string, format string, args ...interface{}) {
+	if !ok {
+		v.errs = append(v.errs, fmt.Errorf("%s %s", key, fmt.Sprintf(format, args...)))
+	}
+}
+
+func (v *validation) positive(key string, duration time.Duration) {
+	v.check(duration > 0, key, "must be positive, got %s", duration)
+}
+
+func (v *validation) oneOf(key string, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.check(false, key, "must be one of %q, got %q", allowed, value)
+}
+
+func (v *validation) limit(key string, value string) {
+	if value == "" {
+		return
+	}
+	_, err := ratelimit.ParseLimit(value)
+	v.check(err == nil, key, "must look like 600/1m, got %q", value)
+}
+
+// hostPort accepts host or host:port
+func (v *validation) hostPort(key string, value string) {
+	host, port, err := net.SplitHostPort(value)
+	if err != nil {
+		host, port = value, ""
+	}
+	number, err := strconv.Atoi(port)
+	v.check(host != "" && (port == "" || err == nil && number > 0 && number <= 65535), key, "must hold host or host:port entries, got %q", value)
+}
+
+func (v *validation) readable(key string, path string) {
+	if path == "" {
+		return
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		v.check(false, key, "can't be read: %v", err)
+		return
+	}
+	file.Close()
+}
@@ -0,0 +1,9 @@
+package controllers
+
+import "github.com/gin-gonic/gin"
+
+type ApiKeyController interface {
+	CreateApiKey(c *gin.Context)
+	GetAllApiKeys(c *gin.Context)
+	RevokeApiKey(c *gin.Context)
+}
@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/logging"
+	"github.com/dieg0code/products-microservice/src/repository"
+	"github.com/dieg0code/products-microservice/src/services"
+	"github.com/dieg0code/products-microservice/src/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type ApiKeyControllerImpl struct {
+	ApiKeyService services.ApiKeyService
+	validate      *validator.Validate
+}
+
+// CreateApiKey implements ApiKeyController.
+func (a *ApiKeyControllerImpl) CreateApiKey(c *gin.Context) {
+
+	createApiKeyRequest := &request.CreateApiKeyRequest{}
+
+	err := c.ShouldBindJSON(createApiKeyRequest)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error binding request")
+		errRes := response.BaseResponse{
+			Code:   400,
+			Status: "Bad Request",
+			Msg:    "Invalid request body",
+			Data:   nil,
+		}
+
+		c.JSON(400, errRes)
+		return
+	}
+
+	err = a.validate.Struct(createApiKeyRequest)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error validating request")
+		errRes := response.BaseResponse{
+			Code:   400,
+			Status: "Bad Request",
+			Msg:    "Invalid request body",
+			Data:   nil,
+			Errors: validation.FieldErrors(err, c.GetHeader("Accept-Language")),
+		}
+
+		c.JSON(400, errRes)
+		return
+	}
+
+	apiKey, err := a.ApiKeyService.CreateApiKey(c.Request.Context(), createApiKeyRequest)
+	if err != nil {
+		if errors.Is(err, services.ErrApiKeyExpiryInPast) {
+			errRes := response.BaseResponse{
+				Code:   400,
+				Status: "Bad Request",
+				Msg:    "expires_at must be in the future",
+				Data:   nil,
+			}
+
+			c.JSON(400, errRes)
+			return
+		}
+
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error creating api key")
+		errRes := response.BaseResponse{
+			Code:   500,
+			Status: "Internal Server Error",
+			Msg:    "Error creating api key",
+			Data:   nil,
+		}
+
+		c.JSON(500, errRes)
+		return
+	}
+
+	// The key is only returned here, make sure no cache keeps a copy
+	c.Header("Cache-Control", "no-store")
+
+	res := response.BaseResponse{
+		Code:   201,
+		Status: "Created",
+		Msg:    "Api key created successfully, store the key now as it cannot be retrieved again",
+		Data:   apiKey,
+	}
+
+	c.JSON(201, res)
+}
+
+// GetAllApiKeys implements ApiKeyController.
+func (a *ApiKeyControllerImpl) GetAllApiKeys(c *gin.Context) {
+	apiKeys, err := a.ApiKeyService.GetAllApiKeys(c.Request.Context())
+	if err != nil {
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error getting api keys")
+		errRes := response.BaseResponse{
+			Code:   500,
+			Status: "Internal Server Error",
+			Msg:    "Error getting api keys",
+			Data:   nil,
+		}
+
+		c.JSON(500, errRes)
+		return
+	}
+
+	res := response.BaseResponse{
+		Code:   200,
+		Status: "OK",
+		Msg:    "Api keys found",
+		Data:   apiKeys,
+	}
+
+	c.JSON(200, res)
+}
+
+// RevokeApiKey implements ApiKeyController.
+func (a *ApiKeyControllerImpl) RevokeApiKey(c *gin.Context) {
+	apiKeyID := c.Param("apiKeyID")
+
+	apiKeyIDUint, err := strconv.ParseUint(apiKeyID, 10, 32)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error parsing apiKeyID")
+		errRes := response.BaseResponse{
+			Code:   400,
+			Status: "Bad Request",
+			Msg:    "Invalid apiKeyID",
+			Data:   nil,
+		}
+
+		c.JSON(400, errRes)
+		return
+	}
+
+	err = a.ApiKeyService.RevokeApiKey(c.Request.Context(), uint(apiKeyIDUint))
+	if err != nil {
+		if errors.Is(err, repository.ErrApiKeyNotFound) {
+			errRes := response.BaseResponse{
+				Code:   404,
+				Status: "Not Found",
+				Msg:    "Api key not found",
+				Data:   nil,
+			}
+
+			c.JSON(404, errRes)
+			return
+		}
+
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error revoking api key")
+		errRes := response.BaseResponse{
+			Code:   500,
+			Status: "Internal Server Error",
+			Msg:    "Error revoking api key",
+			Data:   nil,
+		}
+
+		c.JSON(500, errRes)
+		return
+	}
+
+	res := response.BaseResponse{
+		Code:   200,
+		Status: "OK",
+		Msg:    "Api key revoked successfully",
+		Data:   nil,
+	}
+
+	c.JSON(200, res)
+}
+
+func NewApiKeyControllerImpl(apiKeyService services.ApiKeyService, validate *validator.Validate) ApiKeyController {
+	return &ApiKeyControllerImpl{
+		ApiKeyService: apiKeyService,
+		validate:      validate,
+	}
+}
@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/repository"
+	"github.com/dieg0code/products-microservice/src/testutils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestApiKeyControllerImpl(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("CreateApiKey_Success", func(t *testing.T) {
+		mockService := new(testutils.MockApiKeyService)
+		controller := NewApiKeyControllerImpl(mockService, validator.New())
+
+		router := gin.Default()
+		router.POST("/api-keys", controller.CreateApiKey)
+
+		mockService.On("CreateApiKey", mock.Anything, &request.CreateApiKeyRequest{Name: "sales", Scopes: []string{"stock:reserve"}}).Return(&response.CreatedApiKeyResponse{
+			ApiKeyResponse: response.ApiKeyResponse{ApiKeyID: 1, Name: "sales", Prefix: "abc123", Scopes: []string{"stock:reserve"}},
+			Key:            "pmk_abc123_secret",
+		}, nil)
+
+		req, err := http.NewRequest(http.MethodPost, "/api-keys", bytes.NewBufferString(`{"name":"sales","scopes":["stock:reserve"]}`))
+		assert.Nil(t, err, "Expected no error creating request")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code, "Expected status code 201")
+		assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"), "Expected the key not to be cached")
+
+		var res struct {
+			Data response.CreatedApiKeyResponse `json:"data"`
+		}
+		err = json.Unmarshal(rec.Body.Bytes(), &res)
+		assert.Nil(t, err, "Expected no error unmarshalling response")
+		assert.Equal(t, "pmk_abc123_secret", res.Data.Key, "Expected key in the response")
+		assert.Equal(t, "abc123", res.Data.Prefix, "Expected prefix in the response")
+	})
+
+	t.Run("CreateApiKey_Invalid_Scope", func(t *testing.T) {
+		mockService := new(testutils.MockApiKeyService)
+		controller := NewApiKeyControllerImpl(mockService, validator.New())
+
+		router := gin.Default()
+		router.POST("/api-keys", controller.CreateApiKey)
+
+		req, err := http.NewRequest(http.MethodPost, "/api-keys", bytes.NewBufferString(`{"name":"sales","scopes":["products:delete"]}`))
+		assert.Nil(t, err, "Expected no error creating request")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, "Expected status code 400")
+		mockService.AssertNotCalled(t, "CreateApiKey", mock.Anything, mock.Anything)
+	})
+
+	t.Run("GetAllApiKeys_Success", func(t *testing.T) {
+		mockService := new(testutils.MockApiKeyService)
+		controller := NewApiKeyControllerImpl(mockService, validator.New())
+
+		router := gin.Default()
+		router.GET("/api-keys", controller.GetAllApiKeys)
+
+		mockService.On("GetAllApiKeys", mock.Anything).Return([]response.ApiKeyResponse{{ApiKeyID: 1, Prefix: "abc123"}}, nil)
+
+		req, err := http.NewRequest(http.MethodGet, "/api-keys", nil)
+		assert.Nil(t, err, "Expected no error creating request")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code, "Expected status code 200")
+		assert.NotContains(t, rec.Body.String(), `"key"`, "Expected listed keys not to carry the key")
+	})
+
+	t.Run("RevokeApiKey_NotFound", func(t *testing.T) {
+		mockService := new(testutils.MockApiKeyService)
+		controller := NewApiKeyControllerImpl(mockService, validator.New())
+
+		router := gin.Default()
+		router.DELETE("/api-keys/:apiKeyID", controller.RevokeApiKey)
+
+		mockService.On("RevokeApiKey", mock.Anything, uint(7)).Return(repository.ErrApiKeyNotFound)
+
+		req, err := http.NewRequest(http.MethodDelete, "/api-keys/7", nil)
+		assert.Nil(t, err, "Expected no error creating request")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code, "Expected status code 404")
+	})
+
+	t.Run("RevokeApiKey_Success", func(t *testing.T) {
+		mockService := new(testutils.MockApiKeyService)
+		controller := NewApiKeyControllerImpl(mockService, validator.New())
+
+		router := gin.Default()
+		router.DELETE("/api-keys/:apiKeyID", controller.RevokeApiKey)
+
+		mockService.On("RevokeApiKey", mock.Anything, uint(7)).Return(nil)
+
+		req, err := http.NewRequest(http.MethodDelete, "/api-keys/7", nil)
+		assert.Nil(t, err, "Expected no error creating request")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code, "Expected status code 200")
+		mockService.AssertExpectations(t)
+	})
+}
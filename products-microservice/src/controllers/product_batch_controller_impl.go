@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/dieg0code/products-microservice/src/auth"
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/logging"
+	"github.com/dieg0code/products-microservice/src/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// BatchGetProducts implements ProductController.
+func (p *ProductControllerImpl) BatchGetProducts(c *gin.Context) {
+
+	batchGetRequest := &request.BatchGetProductsRequest{}
+
+	err := c.ShouldBindJSON(batchGetRequest)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error binding request")
+		errRes := response.BaseResponse{
+			Code:   400,
+			Status: "Bad Request",
+			Msg:    "Invalid request body",
+			Data:   nil,
+		}
+
+		c.JSON(400, errRes)
+		return
+	}
+
+	err = p.validate.Struct(batchGetRequest)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error validating request")
+		errRes := response.BaseResponse{
+			Code:   400,
+			Status: "Bad Request",
+			Msg:    "Invalid request body",
+			Data:   nil,
+			Errors: validation.FieldErrors(err, c.GetHeader("Accept-Language")),
+		}
+
+		c.JSON(400, errRes)
+		return
+	}
+
+	products, err := p.ProductService.GetProductsByIds(c.Request.Context(), batchGetRequest.ProductIDs)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error getting products by ids")
+		errRes := response.BaseResponse{
+			Code:   500,
+			Status: "Internal Server Error",
+			Msg:    "Error getting products by ids",
+			Data:   nil,
+		}
+
+		c.JSON(500, errRes)
+		return
+	}
+
+	res := response.BaseResponse{
+		Code:   200,
+		Status: "OK",
+		Msg:    "Products retrieved successfully",
+		Data:   products,
+	}
+
+	c.JSON(200, res)
+}
+
+// BatchProducts implements ProductController.
+func (p *ProductControllerImpl) BatchProducts(c *gin.Context) {
+
+	batchRequest := &request.BatchProductRequest{}
+
+	err := c.ShouldBindJSON(batchRequest)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error binding request")
+		errRes := response.BaseResponse{
+			Code:   400,
+			Status: "Bad Request",
+			Msg:    "Invalid request body",
+			Data:   nil,
+		}
+
+		c.JSON(400, errRes)
+		return
+	}
+
+	err = p.validate.Struct(batchRequest)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error validating request")
+		errRes := response.BaseResponse{
+			Code:   400,
+			Status: "Bad Request",
+			Msg:    "Invalid request body",
+			Data:   nil,
+			Errors: validation.FieldErrors(err, c.GetHeader("Accept-Language")),
+		}
+
+		c.JSON(400, errRes)
+		return
+	}
+
+	if hasDeleteOperation(batchRequest.Operations) {
+		err = auth.Authorize(c.Request.Context(), auth.PermissionDeleteProducts)
+		if err != nil {
+			logging.FromContext(c.Request.Context()).WithError(err).Warn("Batch delete rejected")
+			errRes := response.BaseResponse{
+				Code:   403,
+				Status: "Forbidden",
+				Msg:    "Deleting products requires the admin role",
+				Data:   nil,
+			}
+
+			c.JSON(403, errRes)
+			return
+		}
+	}
+
+	result, err := p.ProductService.ExecuteBatch(c.Request.Context(), batchRequest)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error executing product batch")
+		errRes := response.BaseResponse{
+			Code:   500,
+			Status: "Internal Server Error",
+			Msg:    "Error executing product batch",
+			Data:   nil,
+		}
+
+		c.JSON(500, errRes)
+		return
+	}
+
+	if result.Failed > 0 {
+		res := response.BaseResponse{
+			Code:   http.StatusMultiStatus,
+			Status: "Multi-Status",
+			Msg:    "Some batch operations failed",
+			Data:   result,
+		}
+
+		c.JSON(http.StatusMultiStatus, res)
+		return
+	}
+
+	res := response.BaseResponse{
+		Code:   200,
+		Status: "OK",
+		Msg:    "Batch executed successfully",
+		Data:   result,
+	}
+
+	c.JSON(200, res)
+}
+
+func hasDeleteOperation(operations []request.BatchProductOperation) bool {
+	for _, operation := range operations {
+		if operation.Op == request.BatchOpDelete {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dieg0code/products-microservice/src/auth"
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/testutils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestProductBatchControllerImpl(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("BatchGetProducts_Success", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		validator := validator.New()
+		controller := NewProductControllerImpl(mockService, validator)
+
+		router := gin.Default()
+		router.POST("/products/batch-get", controller.BatchGetProducts)
+
+		mockService.On("GetProductsByIds", mock.Anything, []uint{1, 2}).Return(&response.BatchGetProductsResponse{
+			Products:   []response.ProductResponse{{ProductID: 1, Name: "Product 1"}},
+			MissingIDs: []uint{2},
+		}, nil)
+
+		req, err := http.NewRequest(http.MethodPost, "/products/batch-get", bytes.NewBufferString(`{"product_ids":[1,2]}`))
+		assert.Nil(t, err, "Expected no error creating request")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code, "Expected status code 200")
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("BatchGetProducts_BadRequest", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		validator := validator.New()
+		controller := NewProductControllerImpl(mockService, validator)
+
+		router := gin.Default()
+		router.POST("/products/batch-get", controller.BatchGetProducts)
+
+		ids := make([]uint, 101)
+		for i := range ids {
+			ids[i] = uint(i + 1)
+		}
+		body, err := json.Marshal(request.BatchGetProductsRequest{ProductIDs: ids})
+		assert.Nil(t, err, "Expected no error marshalling request body")
+
+		req, err := http.NewRequest(http.MethodPost, "/products/batch-get", bytes.NewBuffer(body))
+		assert.Nil(t, err, "Expected no error creating request")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, "Expected status code 400")
+
+		mockService.AssertNotCalled(t, "GetProductsByIds")
+	})
+
+	t.Run("BatchProducts_Success", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		validator := validator.New()
+		controller := NewProductControllerImpl(mockService, validator)
+
+		router := gin.Default()
+		router.POST("/products/batch", controller.BatchProducts)
+
+		reqBody := &request.BatchProductRequest{
+			Mode: request.BatchModeBestEffort,
+			Operations: []request.BatchProductOperation{
+				{Op: request.BatchOpCreate, Product: &request.CreateProductRequest{Name: "Product 1", Category: "Category 1", Price: 1000, Stock: 10}},
+			},
+		}
+
+		mockService.On("ExecuteBatch", mock.Anything, reqBody).Return(&response.BatchProductResponse{Mode: request.BatchModeBestEffort, Succeeded: 1}, nil)
+
+		body, err := json.Marshal(reqBody)
+		assert.Nil(t, err, "Expected no error marshalling request body")
+
+		req, err := http.NewRequest(http.MethodPost, "/products/batch", bytes.NewBuffer(body))
+		assert.Nil(t, err, "Expected no error creating request")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code, "Expected status code 200")
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("BatchProducts_Partial_Failure", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		validator := validator.New()
+		controller := NewProductControllerImpl(mockService, validator)
+
+		router := gin.Default()
+		router.Use(func(c *gin.Context) {
+			c.Request = c.Request.WithContext(auth.WithoutAuthentication(c.Request.Context()))
+		})
+		router.POST("/products/batch", controller.BatchProducts)
+
+		reqBody := &request.BatchProductRequest{
+			Operations: []request.BatchProductOperation{{Op: request.BatchOpDelete, ProductID: 1}},
+		}
+
+		mockService.On("ExecuteBatch", mock.Anything, reqBody).Return(&response.BatchProductResponse{Failed: 1}, nil)
+
+		body, err := json.Marshal(reqBody)
+		assert.Nil(t, err, "Expected no error marshalling request body")
+
+		req, err := http.NewRequest(http.MethodPost, "/products/batch", bytes.NewBuffer(body))
+		assert.Nil(t, err, "Expected no error creating request")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusMultiStatus, rec.Code, "Expected status code 207")
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("BatchProducts_BadRequest", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		validator := validator.New()
+		controller := NewProductControllerImpl(mockService, validator)
+
+		router := gin.Default()
+		router.POST("/products/batch", controller.BatchProducts)
+
+		req, err := http.NewRequest(http.MethodPost, "/products/batch", bytes.NewBufferString(`{"operations":[{"op":"update","product_id":1}]}`))
+		assert.Nil(t, err, "Expected no error creating request")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, "Expected status code 400")
+
+		mockService.AssertNotCalled(t, "ExecuteBatch")
+	})
+}
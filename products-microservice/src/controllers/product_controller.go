@@ -9,4 +9,6 @@ type ProductController interface {
 	GetByCategory(c *gin.Context)
 	UpdateProduct(c *gin.Context)
 	DeleteProduct(c *gin.Context)
+	BatchGetProducts(c *gin.Context)
+	BatchProducts(c *gin.Context)
 }
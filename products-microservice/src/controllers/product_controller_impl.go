@@ -1,14 +1,17 @@
 package controllers
 
 import (
+	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/dieg0code/products-microservice/src/json/request"
 	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/logging"
 	"github.com/dieg0code/products-microservice/src/services"
+	"github.com/dieg0code/products-microservice/src/validation"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
-	"github.com/sirupsen/logrus"
 )
 
 type ProductControllerImpl struct {
@@ -23,7 +26,7 @@ func (p *ProductControllerImpl) CreateProduct(c *gin.Context) {
 
 	err := c.ShouldBindJSON(createProductRequest)
 	if err != nil {
-		logrus.WithError(err).Error("Error binding request")
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error binding request")
 		errRes := response.BaseResponse{
 			Code:   400,
 			Status: "Bad Request",
@@ -37,21 +40,22 @@ func (p *ProductControllerImpl) CreateProduct(c *gin.Context) {
 
 	err = p.validate.Struct(createProductRequest)
 	if err != nil {
-		logrus.WithError(err).Error("Error validating request")
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error validating request")
 		errRes := response.BaseResponse{
 			Code:   400,
 			Status: "Bad Request",
 			Msg:    "Invalid request body",
 			Data:   nil,
+			Errors: validation.FieldErrors(err, c.GetHeader("Accept-Language")),
 		}
 
 		c.JSON(400, errRes)
 		return
 	}
 
-	productID, err := p.ProductService.CreateProduct(createProductRequest)
+	productID, err := p.ProductService.CreateProduct(c.Request.Context(), createProductRequest)
 	if err != nil {
-		logrus.WithError(err).Error("Error creating product")
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error creating product")
 		errRes := response.BaseResponse{
 			Code:   500,
 			Status: "Internal Server Error",
@@ -79,7 +83,7 @@ func (p *ProductControllerImpl) DeleteProduct(c *gin.Context) {
 
 	productIDUint, err := strconv.ParseUint(productID, 10, 32)
 	if err != nil {
-		logrus.WithError(err).Error("Error parsing productID")
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error parsing productID")
 		errRes := response.BaseResponse{
 			Code:   400,
 			Status: "Bad Request",
@@ -93,9 +97,9 @@ func (p *ProductControllerImpl) DeleteProduct(c *gin.Context) {
 
 	id := uint(productIDUint)
 
-	err = p.ProductService.DeleteProduct(id)
+	err = p.ProductService.DeleteProduct(c.Request.Context(), id)
 	if err != nil {
-		logrus.WithError(err).Error("Error deleting product")
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error deleting product")
 		errRes := response.BaseResponse{
 			Code:   500,
 			Status: "Internal Server Error",
@@ -124,7 +128,7 @@ func (p *ProductControllerImpl) GetAllProducts(c *gin.Context) {
 
 	pageInt, err := strconv.Atoi(page)
 	if err != nil {
-		logrus.WithError(err).Error("Error parsing page")
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error parsing page")
 		errRes := response.BaseResponse{
 			Code:   400,
 			Status: "Bad Request",
@@ -138,7 +142,7 @@ func (p *ProductControllerImpl) GetAllProducts(c *gin.Context) {
 
 	pageSizeInt, err := strconv.Atoi(pageSize)
 	if err != nil {
-		logrus.WithError(err).Error("Error parsing pageSize")
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error parsing pageSize")
 		errRes := response.BaseResponse{
 			Code:   400,
 			Status: "Bad Request",
@@ -150,9 +154,9 @@ func (p *ProductControllerImpl) GetAllProducts(c *gin.Context) {
 		return
 	}
 
-	products, err := p.ProductService.GetAllProducts(pageInt, pageSizeInt)
+	products, err := p.ProductService.GetAllProducts(c.Request.Context(), pageInt, pageSizeInt)
 	if err != nil {
-		logrus.WithError(err).Error("Error getting all products")
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error getting all products")
 		errRes := response.BaseResponse{
 			Code:   500,
 			Status: "Internal Server Error",
@@ -171,6 +175,7 @@ func (p *ProductControllerImpl) GetAllProducts(c *gin.Context) {
 		Data:   products,
 	}
 
+	setLastModified(c, products)
 	c.JSON(200, res)
 }
 
@@ -179,9 +184,9 @@ func (p *ProductControllerImpl) GetByCategory(c *gin.Context) {
 
 	category := c.Param("category")
 
-	products, err := p.ProductService.GetByCategory(category)
+	products, err := p.ProductService.GetByCategory(c.Request.Context(), category)
 	if err != nil {
-		logrus.WithError(err).Error("Error getting products by category")
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error getting products by category")
 		errRes := response.BaseResponse{
 			Code:   500,
 			Status: "Internal Server Error",
@@ -200,6 +205,7 @@ func (p *ProductControllerImpl) GetByCategory(c *gin.Context) {
 		Data:   products,
 	}
 
+	setLastModified(c, products)
 	c.JSON(200, res)
 }
 
@@ -210,7 +216,7 @@ func (p *ProductControllerImpl) GetProductById(c *gin.Context) {
 
 	productIDUint, err := strconv.ParseUint(productID, 10, 32)
 	if err != nil {
-		logrus.WithError(err).Error("Error parsing productID")
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error parsing productID")
 		errRes := response.BaseResponse{
 			Code:   400,
 			Status: "Bad Request",
@@ -224,9 +230,9 @@ func (p *ProductControllerImpl) GetProductById(c *gin.Context) {
 
 	id := uint(productIDUint)
 
-	product, err := p.ProductService.GetProductById(id)
+	product, err := p.ProductService.GetProductById(c.Request.Context(), id)
 	if err != nil {
-		logrus.WithError(err).Error("Error getting product by ID")
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error getting product by ID")
 		errRes := response.BaseResponse{
 			Code:   500,
 			Status: "Internal Server Error",
@@ -255,7 +261,7 @@ func (p *ProductControllerImpl) UpdateProduct(c *gin.Context) {
 
 	productIDUint, err := strconv.ParseUint(productID, 10, 32)
 	if err != nil {
-		logrus.WithError(err).Error("Error parsing productID")
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error parsing productID")
 		errRes := response.BaseResponse{
 			Code:   400,
 			Status: "Bad Request",
@@ -270,7 +276,7 @@ func (p *ProductControllerImpl) UpdateProduct(c *gin.Context) {
 
 	err = c.ShouldBindJSON(updateProductRequest)
 	if err != nil {
-		logrus.WithError(err).Error("Error binding request")
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error binding request")
 		errRes := response.BaseResponse{
 			Code:   400,
 			Status: "Bad Request",
@@ -284,21 +290,22 @@ func (p *ProductControllerImpl) UpdateProduct(c *gin.Context) {
 
 	err = p.validate.Struct(updateProductRequest)
 	if err != nil {
-		logrus.WithError(err).Error("Error validating request")
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error validating request")
 		errRes := response.BaseResponse{
 			Code:   400,
 			Status: "Bad Request",
 			Msg:    "Invalid request body",
 			Data:   nil,
+			Errors: validation.FieldErrors(err, c.GetHeader("Accept-Language")),
 		}
 
 		c.JSON(400, errRes)
 		return
 	}
 
-	product, err := p.ProductService.UpdateProduct(uint(productIDUint), updateProductRequest)
+	product, err := p.ProductService.UpdateProduct(c.Request.Context(), uint(productIDUint), updateProductRequest)
 	if err != nil {
-		logrus.WithError(err).Error("Error updating product")
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error updating product")
 		errRes := response.BaseResponse{
 			Code:   500,
 			Status: "Internal Server Error",
@@ -320,6 +327,21 @@ func (p *ProductControllerImpl) UpdateProduct(c *gin.Context) {
 	c.JSON(200, res)
 }
 
+// setLastModified sends the most recent update of the listed products. Deletions don't
+// move it, the ETag added by the HTTP cache middleware catches those.
+func setLastModified(c *gin.Context, products []response.ProductResponse) {
+	var lastModified time.Time
+	for _, product := range products {
+		if product.UpdatedAt.After(lastModified) {
+			lastModified = product.UpdatedAt
+		}
+	}
+
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+}
+
 func NewProductControllerImpl(productService services.ProductService, validate *validator.Validate) ProductController {
 	return &ProductControllerImpl{
 		ProductService: productService,
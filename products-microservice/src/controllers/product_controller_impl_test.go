@@ -6,13 +6,16 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/dieg0code/products-microservice/src/json/request"
 	"github.com/dieg0code/products-microservice/src/json/response"
 	"github.com/dieg0code/products-microservice/src/testutils"
+	"github.com/dieg0code/products-microservice/src/validation"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestProductControllerImpl(t *testing.T) {
@@ -34,7 +37,7 @@ func TestProductControllerImpl(t *testing.T) {
 		}
 
 		productID := uint(1)
-		mockService.On("CreateProduct", reqBody).Return(&productID, nil)
+		mockService.On("CreateProduct", mock.Anything, reqBody).Return(&productID, nil)
 
 		body, err := json.Marshal(reqBody)
 		assert.Nil(t, err, "Expected no error marshalling request body")
@@ -94,6 +97,39 @@ func TestProductControllerImpl(t *testing.T) {
 		assert.Equal(t, "Invalid request body", response.Msg, "Expected response message Invalid request body")
 	})
 
+	t.Run("CreateProduct_BadRequest_Field_Errors", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		controller := NewProductControllerImpl(mockService, validation.Validator())
+
+		router := gin.Default()
+		router.POST("/products", controller.CreateProduct)
+
+		body := `{"name": "Product 1", "category": "Category 1", "price": -5}`
+		req, err := http.NewRequest(http.MethodPost, "/products", bytes.NewBufferString(body))
+		assert.Nil(t, err, "Expected no error creating request")
+		req.Header.Set("Accept-Language", "es-CL,es;q=0.9,en;q=0.8")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, "Expected status code 400")
+
+		var response response.BaseResponse
+		err = json.Unmarshal(rec.Body.Bytes(), &response)
+
+		assert.Nil(t, err, "Expected no error unmarshalling response body")
+		assert.Equal(t, "Invalid request body", response.Msg, "Expected response message Invalid request body")
+		assert.Len(t, response.Errors, 2, "Expected one error per invalid field")
+		assert.Equal(t, "price", response.Errors[0].Field, "Expected the JSON name of the field")
+		assert.Equal(t, "min", response.Errors[0].Rule, "Expected the failed rule")
+		assert.Equal(t, "1", response.Errors[0].Param, "Expected the parameter of the rule")
+		assert.Equal(t, "price debe ser 1 o más", response.Errors[0].Message, "Expected the message in Spanish")
+		assert.Equal(t, "stock", response.Errors[1].Field, "Expected the JSON name of the field")
+		assert.Equal(t, "required", response.Errors[1].Rule, "Expected the failed rule")
+		assert.Equal(t, "stock es un campo requerido", response.Errors[1].Message, "Expected the message in Spanish")
+		mockService.AssertNotCalled(t, "CreateProduct", mock.Anything, mock.Anything)
+	})
+
 	t.Run("CreateProduct_InternalServerError", func(t *testing.T) {
 		mockService := new(testutils.MockProductService)
 		validator := validator.New()
@@ -110,7 +146,7 @@ func TestProductControllerImpl(t *testing.T) {
 		}
 
 		productID := uint(1)
-		mockService.On("CreateProduct", reqBody).Return(&productID, assert.AnError)
+		mockService.On("CreateProduct", mock.Anything, reqBody).Return(&productID, assert.AnError)
 
 		body, err := json.Marshal(reqBody)
 		assert.Nil(t, err, "Expected no error marshalling request body")
@@ -144,7 +180,7 @@ func TestProductControllerImpl(t *testing.T) {
 		router.DELETE("/products/:productID", controller.DeleteProduct)
 
 		productID := uint(1)
-		mockService.On("DeleteProduct", productID).Return(nil)
+		mockService.On("DeleteProduct", mock.Anything, productID).Return(nil)
 
 		req, err := http.NewRequest(http.MethodDelete, "/products/1", nil)
 		assert.Nil(t, err, "Expected no error creating request")
@@ -200,7 +236,7 @@ func TestProductControllerImpl(t *testing.T) {
 		router.DELETE("/products/:productID", controller.DeleteProduct)
 
 		productID := uint(1)
-		mockService.On("DeleteProduct", productID).Return(assert.AnError)
+		mockService.On("DeleteProduct", mock.Anything, productID).Return(assert.AnError)
 
 		req, err := http.NewRequest(http.MethodDelete, "/products/1", nil)
 		assert.Nil(t, err, "Expected no error creating request")
@@ -232,7 +268,7 @@ func TestProductControllerImpl(t *testing.T) {
 		page := 1
 		pageSize := 10
 
-		mockService.On("GetAllProducts", page, pageSize).Return([]response.ProductResponse{}, nil)
+		mockService.On("GetAllProducts", mock.Anything, page, pageSize).Return([]response.ProductResponse{}, nil)
 
 		req, err := http.NewRequest(http.MethodGet, "/products?page=1&pageSize=10", nil)
 		assert.Nil(t, err, "Expected no error creating request")
@@ -252,6 +288,33 @@ func TestProductControllerImpl(t *testing.T) {
 
 	})
 
+	t.Run("GetAllProducts_LastModified", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		validator := validator.New()
+		controller := NewProductControllerImpl(mockService, validator)
+
+		router := gin.Default()
+		router.GET("/products", controller.GetAllProducts)
+
+		older := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+		newer := time.Date(2024, 5, 2, 12, 30, 15, 0, time.UTC)
+		mockService.On("GetAllProducts", mock.Anything, 1, 10).Return([]response.ProductResponse{
+			{ProductID: 1, UpdatedAt: older},
+			{ProductID: 2, UpdatedAt: newer},
+		}, nil)
+
+		req, err := http.NewRequest(http.MethodGet, "/products?page=1&pageSize=10", nil)
+		assert.Nil(t, err, "Expected no error creating request")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code, "Expected status code 200")
+		assert.Equal(t, newer.Format(http.TimeFormat), rec.Header().Get("Last-Modified"), "Expected Last-Modified to be the newest update")
+
+		mockService.AssertExpectations(t)
+	})
+
 	t.Run("GetAllProducts_BadRequest", func(t *testing.T) {
 		mockService := new(testutils.MockProductService)
 		validator := validator.New()
@@ -290,7 +353,7 @@ func TestProductControllerImpl(t *testing.T) {
 		page := 1
 		pageSize := 10
 
-		mockService.On("GetAllProducts", page, pageSize).Return([]response.ProductResponse{}, assert.AnError)
+		mockService.On("GetAllProducts", mock.Anything, page, pageSize).Return([]response.ProductResponse{}, assert.AnError)
 
 		req, err := http.NewRequest(http.MethodGet, "/products?page=1&pageSize=10", nil)
 		assert.Nil(t, err, "Expected no error creating request")
@@ -321,7 +384,7 @@ func TestProductControllerImpl(t *testing.T) {
 
 		category := "Category 1"
 
-		mockService.On("GetByCategory", category).Return([]response.ProductResponse{}, nil)
+		mockService.On("GetByCategory", mock.Anything, category).Return([]response.ProductResponse{}, nil)
 
 		req, err := http.NewRequest(http.MethodGet, "/products/category/Category%201", nil)
 		assert.Nil(t, err, "Expected no error creating request")
@@ -350,7 +413,7 @@ func TestProductControllerImpl(t *testing.T) {
 
 		category := "Category 1"
 
-		mockService.On("GetByCategory", category).Return([]response.ProductResponse{}, assert.AnError)
+		mockService.On("GetByCategory", mock.Anything, category).Return([]response.ProductResponse{}, assert.AnError)
 
 		req, err := http.NewRequest(http.MethodGet, "/products/category/Category%201", nil)
 		assert.Nil(t, err, "Expected no error creating request")
@@ -379,7 +442,7 @@ func TestProductControllerImpl(t *testing.T) {
 
 		productID := uint(1)
 
-		mockService.On("GetProductById", productID).Return(&response.ProductResponse{}, nil)
+		mockService.On("GetProductById", mock.Anything, productID).Return(&response.ProductResponse{}, nil)
 
 		req, err := http.NewRequest(http.MethodGet, "/products/1", nil)
 		assert.Nil(t, err, "Expected no error creating request")
@@ -436,7 +499,7 @@ func TestProductControllerImpl(t *testing.T) {
 
 		productID := uint(1)
 
-		mockService.On("GetProductById", productID).Return(&response.ProductResponse{}, assert.AnError)
+		mockService.On("GetProductById", mock.Anything, productID).Return(&response.ProductResponse{}, assert.AnError)
 
 		req, err := http.NewRequest(http.MethodGet, "/products/1", nil)
 		assert.Nil(t, err, "Expected no error creating request")
@@ -474,7 +537,7 @@ func TestProductControllerImpl(t *testing.T) {
 			Stock:    10,
 		}
 
-		mockService.On("UpdateProduct", productID, reqBody).Return(&response.ProductResponse{}, nil)
+		mockService.On("UpdateProduct", mock.Anything, productID, reqBody).Return(&response.ProductResponse{}, nil)
 
 		body, err := json.Marshal(reqBody)
 		assert.Nil(t, err, "Expected no error marshalling request body")
@@ -553,7 +616,7 @@ func TestProductControllerImpl(t *testing.T) {
 			Stock:    10,
 		}
 
-		mockService.On("UpdateProduct", productID, reqBody).Return(&response.ProductResponse{}, assert.AnError)
+		mockService.On("UpdateProduct", mock.Anything, productID, reqBody).Return(&response.ProductResponse{}, assert.AnError)
 
 		body, err := json.Marshal(reqBody)
 		assert.Nil(t, err, "Expected no error marshalling request body")
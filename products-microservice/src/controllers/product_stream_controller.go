@@ -0,0 +1,7 @@
+package controllers
+
+import "github.com/gin-gonic/gin"
+
+type ProductStreamController interface {
+	StreamProducts(c *gin.Context)
+}
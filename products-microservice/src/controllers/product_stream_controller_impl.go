@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/logging"
+	"github.com/dieg0code/products-microservice/src/stream"
+	"github.com/dieg0code/products-microservice/src/tenant"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+const DefaultHeartbeatInterval = 15 * time.Second
+
+type ProductStreamControllerImpl struct {
+	hub       *stream.Hub
+	heartbeat time.Duration
+}
+
+// StreamProducts implements ProductStreamController.
+func (p *ProductStreamControllerImpl) StreamProducts(c *gin.Context) {
+
+	tenantID, _ := tenant.FromContext(c.Request.Context())
+	filter := stream.Filter{TenantID: tenantID, Category: c.Query("category")}
+
+	if productIDs := c.Query("product_ids"); productIDs != "" {
+		filter.ProductIDs = make(map[uint]bool)
+		for _, productID := range strings.Split(productIDs, ",") {
+			productIDUint, err := strconv.ParseUint(strings.TrimSpace(productID), 10, 32)
+			if err != nil {
+				logging.FromContext(c.Request.Context()).WithError(err).Error("Error parsing product_ids")
+				errRes := response.BaseResponse{
+					Code:   400,
+					Status: "Bad Request",
+					Msg:    "Invalid product_ids",
+					Data:   nil,
+				}
+
+				c.JSON(400, errRes)
+				return
+			}
+			filter.ProductIDs[uint(productIDUint)] = true
+		}
+	}
+
+	// Browsers resend Last-Event-ID on reconnect, the query parameter covers the first connection
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	lastEventIDUint, err := strconv.ParseUint(lastEventID, 10, 64)
+	if err != nil {
+		lastEventIDUint = 0
+	}
+
+	subscription, replay := p.hub.Subscribe(filter, lastEventIDUint)
+	defer subscription.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(200)
+
+	_, err = c.Writer.WriteString(": connected\n\n")
+	if err != nil {
+		return
+	}
+
+	for i := range replay {
+		if !p.writeEvent(c, &replay[i]) {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(p.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return
+			}
+			if !p.writeEvent(c, &event) {
+				return
+			}
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			_, err := c.Writer.WriteString(": heartbeat\n\n")
+			if err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+func (p *ProductStreamControllerImpl) writeEvent(c *gin.Context, event *stream.ProductEvent) bool {
+	err := sse.Encode(c.Writer, sse.Event{
+		Id:    strconv.FormatUint(event.ID, 10),
+		Event: event.Type,
+		Data:  event,
+	})
+	if err != nil {
+		logging.FromContext(c.Request.Context()).WithError(err).Debug("Error writing product event")
+		return false
+	}
+	return true
+}
+
+// NewProductStreamControllerImpl streams the events of hub, which only sees the writes
+// of this replica. Clients only get every event when the service runs a single replica
+func NewProductStreamControllerImpl(hub *stream.Hub, heartbeat time.Duration) ProductStreamController {
+	if heartbeat <= 0 {
+		heartbeat = DefaultHeartbeatInterval
+	}
+
+	return &ProductStreamControllerImpl{
+		hub:       hub,
+		heartbeat: heartbeat,
+	}
+}
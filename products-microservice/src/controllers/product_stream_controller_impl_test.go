@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/stream"
+	"github.com/dieg0code/products-microservice/src/tenant"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func readSSEEvent(t *testing.T, reader *bufio.Reader) map[string]string {
+	fields := make(map[string]string)
+	for {
+		line, err := reader.ReadString('\n')
+		assert.Nil(t, err, "Expected no error reading stream")
+		if err != nil {
+			return fields
+		}
+
+		line = strings.TrimRight(line, "\n")
+		if line == "" {
+			if len(fields) > 0 {
+				return fields
+			}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		name, value, _ := strings.Cut(line, ":")
+		fields[name] = value
+	}
+}
+
+func TestProductStreamControllerImpl(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setupServer := func(hub *stream.Hub) *httptest.Server {
+		router := gin.New()
+		router.GET("/products/stream", NewProductStreamControllerImpl(hub, time.Minute).StreamProducts)
+		return httptest.NewServer(router)
+	}
+
+	waitForSubscribers := func(hub *stream.Hub, count int) {
+		for i := 0; i < 100 && hub.SubscriberCount() != count; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	t.Run("StreamProducts_Sends_Filtered_Events", func(t *testing.T) {
+		hub := stream.NewHub(8, 8)
+		server := setupServer(hub)
+		defer server.Close()
+
+		res, err := http.Get(server.URL + "/products/stream?category=Books")
+		assert.Nil(t, err, "Expected no error opening stream")
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusOK, res.StatusCode, "Expected status code 200")
+		assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"), "Expected event stream content type")
+
+		waitForSubscribers(hub, 1)
+		hub.Publish(stream.ProductEvent{Type: stream.EventProductCreated, ProductID: 1, Category: "Games"})
+		hub.Publish(stream.ProductEvent{Type: stream.EventProductCreated, ProductID: 2, Category: "Books"})
+
+		fields := readSSEEvent(t, bufio.NewReader(res.Body))
+		assert.Equal(t, "2", fields["id"], "Expected the Books event id")
+		assert.Equal(t, stream.EventProductCreated, fields["event"], "Expected event type")
+
+		var event stream.ProductEvent
+		err = json.Unmarshal([]byte(fields["data"]), &event)
+		assert.Nil(t, err, "Expected valid JSON data")
+		assert.Equal(t, uint(2), event.ProductID, "Expected product ID 2")
+	})
+
+	t.Run("StreamProducts_Replays_From_LastEventID", func(t *testing.T) {
+		hub := stream.NewHub(8, 8)
+		server := setupServer(hub)
+		defer server.Close()
+
+		hub.Publish(stream.ProductEvent{Type: stream.EventStockChanged, ProductID: 1})
+		hub.Publish(stream.ProductEvent{Type: stream.EventStockChanged, ProductID: 1})
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/products/stream?product_ids=1", nil)
+		assert.Nil(t, err, "Expected no error creating request")
+		req.Header.Set("Last-Event-ID", "1")
+
+		res, err := http.DefaultClient.Do(req)
+		assert.Nil(t, err, "Expected no error opening stream")
+		defer res.Body.Close()
+
+		fields := readSSEEvent(t, bufio.NewReader(res.Body))
+		assert.Equal(t, "2", fields["id"], "Expected replay of event 2")
+	})
+
+	t.Run("StreamProducts_Scoped_To_Tenant", func(t *testing.T) {
+		hub := stream.NewHub(8, 8)
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Request = c.Request.WithContext(tenant.WithTenant(c.Request.Context(), "acme"))
+		})
+		router.GET("/products/stream", NewProductStreamControllerImpl(hub, time.Minute).StreamProducts)
+		server := httptest.NewServer(router)
+		defer server.Close()
+
+		res, err := http.Get(server.URL + "/products/stream")
+		assert.Nil(t, err, "Expected no error opening stream")
+		defer res.Body.Close()
+
+		waitForSubscribers(hub, 1)
+		hub.Publish(stream.ProductEvent{TenantID: "other", Type: stream.EventProductCreated, ProductID: 1})
+		hub.Publish(stream.ProductEvent{TenantID: "acme", Type: stream.EventProductCreated, ProductID: 2})
+
+		fields := readSSEEvent(t, bufio.NewReader(res.Body))
+		assert.Equal(t, "2", fields["id"], "Expected only the tenant's event")
+	})
+
+	t.Run("StreamProducts_Invalid_ProductIDs", func(t *testing.T) {
+		hub := stream.NewHub(8, 8)
+		router := gin.New()
+		router.GET("/products/stream", NewProductStreamControllerImpl(hub, time.Minute).StreamProducts)
+
+		req, err := http.NewRequest(http.MethodGet, "/products/stream?product_ids=1,abc", nil)
+		assert.Nil(t, err, "Expected no error creating request")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, "Expected status code 400")
+		assert.Equal(t, 0, hub.SubscriberCount(), "Expected no subscription")
+	})
+}
@@ -0,0 +1,7 @@
+package controllers
+
+import "github.com/gin-gonic/gin"
+
+type StockController interface {
+	AdjustStock(c *gin.Context)
+}
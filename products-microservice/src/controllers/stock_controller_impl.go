@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/logging"
+	"github.com/dieg0code/products-microservice/src/repository"
+	"github.com/dieg0code/products-microservice/src/services"
+	"github.com/dieg0code/products-microservice/src/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type StockControllerImpl struct {
+	StockService services.StockService
+	validate     *validator.Validate
+}
+
+// AdjustStock implements StockController.
+func (s *StockControllerImpl) AdjustStock(c *gin.Context) {
+	productID := c.Param("productID")
+
+	productIDUint, err := strconv.ParseUint(productID, 10, 32)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error parsing productID")
+		errRes := response.BaseResponse{
+			Code:   400,
+			Status: "Bad Request",
+			Msg:    "Invalid productID",
+			Data:   nil,
+		}
+
+		c.JSON(400, errRes)
+		return
+	}
+
+	adjustStockRequest := &request.AdjustStockRequest{}
+
+	err = c.ShouldBindJSON(adjustStockRequest)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error binding request")
+		errRes := response.BaseResponse{
+			Code:   400,
+			Status: "Bad Request",
+			Msg:    "Invalid request body",
+			Data:   nil,
+		}
+
+		c.JSON(400, errRes)
+		return
+	}
+
+	err = s.validate.Struct(adjustStockRequest)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error validating request")
+		errRes := response.BaseResponse{
+			Code:   400,
+			Status: "Bad Request",
+			Msg:    "Invalid request body",
+			Data:   nil,
+			Errors: validation.FieldErrors(err, c.GetHeader("Accept-Language")),
+		}
+
+		c.JSON(400, errRes)
+		return
+	}
+
+	product, err := s.StockService.AdjustStock(c.Request.Context(), uint(productIDUint), adjustStockRequest)
+	if err != nil {
+		if errors.Is(err, repository.ErrProductNotFound) {
+			errRes := response.BaseResponse{
+				Code:   404,
+				Status: "Not Found",
+				Msg:    "Product not found",
+				Data:   nil,
+			}
+
+			c.JSON(404, errRes)
+			return
+		}
+
+		if errors.Is(err, repository.ErrInsufficientStock) {
+			errRes := response.BaseResponse{
+				Code:   409,
+				Status: "Conflict",
+				Msg:    "Insufficient stock",
+				Data:   nil,
+			}
+
+			c.JSON(409, errRes)
+			return
+		}
+
+		logging.FromContext(c.Request.Context()).WithError(err).Error("Error adjusting stock")
+		errRes := response.BaseResponse{
+			Code:   500,
+			Status: "Internal Server Error",
+			Msg:    "Error adjusting stock",
+			Data:   nil,
+		}
+
+		c.JSON(500, errRes)
+		return
+	}
+
+	res := response.BaseResponse{
+		Code:   200,
+		Status: "OK",
+		Msg:    "Stock adjusted successfully",
+		Data:   product,
+	}
+
+	c.JSON(200, res)
+}
+
+func NewStockControllerImpl(stockService services.StockService, validate *validator.Validate) StockController {
+	return &StockControllerImpl{
+		StockService: stockService,
+		validate:     validate,
+	}
+}
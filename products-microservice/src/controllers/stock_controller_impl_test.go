@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/repository"
+	"github.com/dieg0code/products-microservice/src/testutils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStockControllerImpl(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("AdjustStock_Success", func(t *testing.T) {
+		mockService := new(testutils.MockStockService)
+		controller := NewStockControllerImpl(mockService, validator.New())
+
+		router := gin.Default()
+		router.POST("/products/:productID/stock", controller.AdjustStock)
+
+		mockService.On("AdjustStock", mock.Anything, uint(1), &request.AdjustStockRequest{Delta: 5}).Return(&response.ProductResponse{ProductID: 1, Name: "Test Product", Stock: 15}, nil)
+
+		req, err := http.NewRequest(http.MethodPost, "/products/1/stock", bytes.NewBufferString(`{"delta":5}`))
+		assert.Nil(t, err, "Expected no error creating request")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code, "Expected status code 200")
+
+		var res struct {
+			Data response.ProductResponse `json:"data"`
+		}
+		err = json.Unmarshal(rec.Body.Bytes(), &res)
+		assert.Nil(t, err, "Expected no error unmarshalling response")
+		assert.Equal(t, 15, res.Data.Stock, "Expected the adjusted stock in the response")
+	})
+
+	t.Run("AdjustStock_Zero_Delta", func(t *testing.T) {
+		mockService := new(testutils.MockStockService)
+		controller := NewStockControllerImpl(mockService, validator.New())
+
+		router := gin.Default()
+		router.POST("/products/:productID/stock", controller.AdjustStock)
+
+		req, err := http.NewRequest(http.MethodPost, "/products/1/stock", bytes.NewBufferString(`{"delta":0}`))
+		assert.Nil(t, err, "Expected no error creating request")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, "Expected status code 400")
+		mockService.AssertNotCalled(t, "AdjustStock", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("AdjustStock_Insufficient_Stock", func(t *testing.T) {
+		mockService := new(testutils.MockStockService)
+		controller := NewStockControllerImpl(mockService, validator.New())
+
+		router := gin.Default()
+		router.POST("/products/:productID/stock", controller.AdjustStock)
+
+		mockService.On("AdjustStock", mock.Anything, uint(1), &request.AdjustStockRequest{Delta: -20}).Return((*response.ProductResponse)(nil), repository.ErrInsufficientStock)
+
+		req, err := http.NewRequest(http.MethodPost, "/products/1/stock", bytes.NewBufferString(`{"delta":-20}`))
+		assert.Nil(t, err, "Expected no error creating request")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusConflict, rec.Code, "Expected status code 409")
+	})
+
+	t.Run("AdjustStock_Not_Found", func(t *testing.T) {
+		mockService := new(testutils.MockStockService)
+		controller := NewStockControllerImpl(mockService, validator.New())
+
+		router := gin.Default()
+		router.POST("/products/:productID/stock", controller.AdjustStock)
+
+		mockService.On("AdjustStock", mock.Anything, uint(42), &request.AdjustStockRequest{Delta: 1}).Return((*response.ProductResponse)(nil), repository.ErrProductNotFound)
+
+		req, err := http.NewRequest(http.MethodPost, "/products/42/stock", bytes.NewBufferString(`{"delta":1}`))
+		assert.Nil(t, err, "Expected no error creating request")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code, "Expected status code 404")
+	})
+}
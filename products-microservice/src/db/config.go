@@ -1,50 +1,145 @@
 package db
 
 import (
-	"fmt"
-	"os"
+	"context"
+	"math/rand/v2"
+	"net"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/dieg0code/products-microservice/src/config"
+	"github.com/dieg0code/products-microservice/src/replica"
+	"github.com/dieg0code/products-microservice/src/tenant"
 	"github.com/sirupsen/logrus"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-func DatabaseConnection() *gorm.DB {
-	host := os.Getenv("DB_HOST")
-	port, _ := strconv.Atoi(os.Getenv("DB_PORT"))
-	user := os.Getenv("DB_USER")
-	password := os.Getenv("DB_PASSWORD")
-	dbName := os.Getenv("DB_NAME")
+// DatabaseConnection opens the pool described by dbConfig, retrying while the database
+// starts, and routes product lists and searches to its read replicas
+func DatabaseConnection(dbConfig config.DatabaseConfig) *gorm.DB {
+	db, err := openWithRetry(postgres.Open(DSN(dbConfig, dbConfig.Host, dbConfig.Port)), dbConfig.Retry)
+	if err != nil {
+		panic("Failed to connect to database after multiple attempts!")
+	}
 
-	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable", host, port, user, password, dbName)
+	sqlDB, err := db.DB()
+	if err != nil {
+		panic("Failed to get database pool!")
+	}
+	sqlDB.SetMaxOpenConns(dbConfig.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(dbConfig.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(dbConfig.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(dbConfig.ConnMaxIdleTime)
 
-	var db *gorm.DB
-	var err error
+	if len(dbConfig.Replicas) > 0 {
+		replicas := make([]gorm.Dialector, 0, len(dbConfig.Replicas))
+		for _, hostPort := range dbConfig.Replicas {
+			host, port := splitHostPort(hostPort, dbConfig.Port)
+			replicas = append(replicas, postgres.Open(DSN(dbConfig, host, port)))
+		}
 
-	maxAttempts := 5
-	for attempts := 1; attempts <= maxAttempts; attempts++ {
-		db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
-		if err == nil {
-			break
+		resolver, err := replica.Register(db, replicas)
+		if err != nil {
+			panic("Failed to register read replicas!")
 		}
-		logrus.WithError(err).Errorf("Failed to connect to database (attempt %d/%d)", attempts, maxAttempts)
-		time.Sleep(5 * time.Second) // Wait for 5 seconds before retrying
+		// Applies to the primary too, which is already configured the same way
+		resolver.SetMaxOpenConns(dbConfig.MaxOpenConns).
+			SetMaxIdleConns(dbConfig.MaxIdleConns).
+			SetConnMaxLifetime(dbConfig.ConnMaxLifetime).
+			SetConnMaxIdleTime(dbConfig.ConnMaxIdleTime)
+		logrus.WithField("replicas", len(replicas)).Info("Read replicas enabled")
 	}
 
+	err = tenant.RegisterCallbacks(db)
 	if err != nil {
-		panic("Failed to connect to database after multiple attempts!")
+		panic("Failed to register tenant callbacks!")
 	}
 
 	return db
 }
 
-func CheckDatabaseConnection(db *gorm.DB) error {
-	sqlDB, err := db.DB()
+// DSN builds the connection string of host, quoting values so passwords may hold
+// spaces or quotes
+func DSN(dbConfig config.DatabaseConfig, host string, port int) string {
+	params := []string{
+		"host=" + quote(host),
+		"port=" + strconv.Itoa(port),
+		"user=" + quote(dbConfig.User),
+		"password=" + quote(dbConfig.Password),
+		"dbname=" + quote(dbConfig.Name),
+		"sslmode=" + quote(dbConfig.SSLMode),
+	}
+	if dbConfig.RootCert != "" {
+		params = append(params, "sslrootcert="+quote(dbConfig.RootCert))
+	}
+	return strings.Join(params, " ")
+}
+
+func quote(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+func splitHostPort(hostPort string, defaultPort int) (string, int) {
+	host, port, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return hostPort, defaultPort
+	}
+	number, err := strconv.Atoi(port)
 	if err != nil {
-		logrus.WithError(err).Error("Error getting database connection")
-		return err
+		return host, defaultPort
 	}
-	return sqlDB.Ping()
+	return host, number
+}
+
+func openWithRetry(dialector gorm.Dialector, retry config.RetryConfig) (*gorm.DB, error) {
+	for attempt := 1; ; attempt++ {
+		db, err := gorm.Open(dialector, &gorm.Config{TranslateError: true})
+		if err == nil {
+			return db, nil
+		}
+		if attempt >= retry.Attempts {
+			return nil, err
+		}
+
+		wait := Backoff(retry, attempt)
+		logrus.WithError(err).Errorf("Failed to connect to database (attempt %d/%d), retrying in %s", attempt, retry.Attempts, wait)
+		time.Sleep(wait)
+	}
+}
+
+// Backoff returns how long to wait after the given failed attempt, doubling from
+// InitialBackoff up to MaxBackoff. Half of it is random so restarts spread out.
+func Backoff(retry config.RetryConfig, attempt int) time.Duration {
+	backoff := retry.InitialBackoff
+	for i := 1; i < attempt && backoff < retry.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	backoff = min(backoff, retry.MaxBackoff)
+
+	return backoff/2 + rand.N(backoff/2+1)
+}
+
+// CheckDatabaseConnection pings the primary of db, it gives up once ctx is done
+func CheckDatabaseConnection(ctx context.Context, db *gorm.DB) error {
+	err := replica.PingPrimary(ctx, db)
+	if err != nil {
+		logrus.WithError(err).Error("Error pinging database")
+	}
+	return err
+}
+
+// CheckReplicaConnections pings the replicas of db, it gives up once ctx is done
+func CheckReplicaConnections(ctx context.Context, db *gorm.DB) error {
+	err := replica.PingReplicas(ctx, db)
+	if err != nil {
+		logrus.WithError(err).Error("Error pinging database replicas")
+	}
+	return err
+}
+
+// CloseDatabaseConnection closes the pools of the primary and the replicas of db
+func CloseDatabaseConnection(db *gorm.DB) error {
+	return replica.Close(db)
 }
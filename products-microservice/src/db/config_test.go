@@ -0,0 +1,43 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig(t *testing.T) {
+	t.Run("DSN", func(t *testing.T) {
+		dsn := DSN(config.DatabaseConfig{
+			User:     "postgres",
+			Password: `it's a \secret`,
+			Name:     "products",
+			SSLMode:  "verify-full",
+			RootCert: "/etc/ssl/root.crt",
+		}, "replica-1", 5433)
+
+		assert.Equal(t, `host='replica-1' port=5433 user='postgres' password='it\'s a \\secret' dbname='products' sslmode='verify-full' sslrootcert='/etc/ssl/root.crt'`, dsn, "Expected quoted values")
+	})
+
+	t.Run("Backoff", func(t *testing.T) {
+		retry := config.RetryConfig{Attempts: 10, InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+
+		for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 9: 5 * time.Second} {
+			wait := Backoff(retry, attempt)
+			assert.GreaterOrEqual(t, wait, want/2, "Expected at least half the backoff of attempt %d", attempt)
+			assert.LessOrEqual(t, wait, want, "Expected at most the backoff of attempt %d", attempt)
+		}
+	})
+
+	t.Run("SplitHostPort", func(t *testing.T) {
+		host, port := splitHostPort("replica-1", 5432)
+		assert.Equal(t, "replica-1", host, "Expected the host")
+		assert.Equal(t, 5432, port, "Expected the default port")
+
+		host, port = splitHostPort("replica-2:5433", 5432)
+		assert.Equal(t, "replica-2", host, "Expected the host")
+		assert.Equal(t, 5433, port, "Expected the replica's port")
+	})
+}
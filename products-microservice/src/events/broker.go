@@ -0,0 +1,18 @@
+package events
+
+const (
+	TopicSaleCreated       = "sales.sale_created"
+	TopicStockCompensation = "products.stock_compensation"
+)
+
+// Handler processes a single message payload. Returning an error signals the
+// broker that the message was not handled.
+type Handler func(payload []byte) error
+
+type Subscriber interface {
+	Subscribe(topic string, handler Handler) error
+}
+
+type Publisher interface {
+	Publish(topic string, payload []byte) error
+}
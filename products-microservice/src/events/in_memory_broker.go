@@ -0,0 +1,46 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// InMemoryBroker delivers messages synchronously to the handlers registered in
+// the same process. It is meant for tests and local runs without a message broker.
+type InMemoryBroker struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+// Subscribe implements Subscriber.
+func (b *InMemoryBroker) Subscribe(topic string, handler Handler) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers[topic] = append(b.handlers[topic], handler)
+	return nil
+}
+
+// Publish implements Publisher.
+func (b *InMemoryBroker) Publish(topic string, payload []byte) error {
+	b.mu.RLock()
+	handlers := append([]Handler(nil), b.handlers[topic]...)
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		if err := handler(payload); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Check implements health.Checker, handlers run in-process so the broker is always reachable.
+func (b *InMemoryBroker) Check(ctx context.Context) error {
+	return nil
+}
+
+func NewInMemoryBroker() *InMemoryBroker {
+	return &InMemoryBroker{handlers: make(map[string][]Handler)}
+}
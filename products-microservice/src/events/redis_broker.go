@@ -0,0 +1,178 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultRedisGroup = "products-microservice"
+	// DefaultRedisClaimIdle is how long a message may stay unacknowledged before a
+	// consumer of the group retries it
+	DefaultRedisClaimIdle    = time.Minute
+	DefaultRedisStreamMaxLen = 100000
+
+	redisPayloadField = "payload"
+	redisReadBlock    = 5 * time.Second
+	redisReadCount    = 10
+)
+
+// RedisBroker carries messages over Redis streams, one stream per topic. Every
+// subscriber joins the same consumer group, so each message is handled by a single
+// replica. Messages whose handler failed, or whose replica died, stay pending and
+// are claimed again once they have been idle for claimIdle.
+type RedisBroker struct {
+	client    redis.UniversalClient
+	group     string
+	consumer  string
+	claimIdle time.Duration
+	block     time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Subscribe implements Subscriber.
+func (b *RedisBroker) Subscribe(topic string, handler Handler) error {
+	// New groups start at the end of the stream, messages published before the
+	// first replica ever subscribed are not replayed
+	err := b.client.XGroupCreateMkStream(b.ctx, topic, b.group, "$").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		b.consume(topic, handler)
+	}()
+	return nil
+}
+
+// Publish implements Publisher.
+func (b *RedisBroker) Publish(topic string, payload []byte) error {
+	return b.client.XAdd(context.Background(), &redis.XAddArgs{
+		Stream: topic,
+		MaxLen: DefaultRedisStreamMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{redisPayloadField: payload},
+	}).Err()
+}
+
+// Check implements health.Checker.
+func (b *RedisBroker) Check(ctx context.Context) error {
+	return b.client.Ping(ctx).Err()
+}
+
+// Close stops reading new messages and waits for the handlers running, or until ctx
+// is done. The client is left open for its other users.
+func (b *RedisBroker) Close(ctx context.Context) error {
+	b.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *RedisBroker) consume(topic string, handler Handler) {
+	for b.ctx.Err() == nil {
+		messages, err := b.read(topic)
+		if err != nil {
+			if b.ctx.Err() != nil {
+				return
+			}
+			logrus.WithError(err).WithField("topic", topic).Error("Error reading from Redis stream")
+			select {
+			case <-time.After(time.Second):
+			case <-b.ctx.Done():
+			}
+			continue
+		}
+
+		for _, message := range messages {
+			b.handle(topic, handler, message)
+		}
+	}
+}
+
+// read returns the messages left idle in the group first, then waits for new ones
+func (b *RedisBroker) read(topic string) ([]redis.XMessage, error) {
+	claimed, _, err := b.client.XAutoClaim(b.ctx, &redis.XAutoClaimArgs{
+		Stream:   topic,
+		Group:    b.group,
+		Consumer: b.consumer,
+		MinIdle:  b.claimIdle,
+		Start:    "0-0",
+		Count:    redisReadCount,
+	}).Result()
+	if err != nil || len(claimed) > 0 {
+		return claimed, err
+	}
+
+	streams, err := b.client.XReadGroup(b.ctx, &redis.XReadGroupArgs{
+		Group:    b.group,
+		Consumer: b.consumer,
+		Streams:  []string{topic, ">"},
+		Count:    redisReadCount,
+		Block:    b.block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []redis.XMessage
+	for _, stream := range streams {
+		messages = append(messages, stream.Messages...)
+	}
+	return messages, nil
+}
+
+func (b *RedisBroker) handle(topic string, handler Handler, message redis.XMessage) {
+	payload, _ := message.Values[redisPayloadField].(string)
+
+	err := handler([]byte(payload))
+	if err != nil {
+		// Unacknowledged messages stay pending and are claimed again once idle
+		logrus.WithError(err).WithField("topic", topic).WithField("message_id", message.ID).Warn("Error handling message, it will be retried")
+		return
+	}
+
+	err = b.client.XAck(context.Background(), topic, b.group, message.ID).Err()
+	if err != nil {
+		logrus.WithError(err).WithField("topic", topic).WithField("message_id", message.ID).Error("Error acknowledging message")
+	}
+}
+
+// NewRedisBroker shares topics between services through any Redis compatible server
+// with streams. consumer names this replica inside group and must be unique in it.
+func NewRedisBroker(client redis.UniversalClient, group string, consumer string) *RedisBroker {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &RedisBroker{
+		client:    client,
+		group:     group,
+		consumer:  consumer,
+		claimIdle: DefaultRedisClaimIdle,
+		block:     redisReadBlock,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
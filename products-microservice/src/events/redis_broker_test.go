@@ -0,0 +1,116 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisBroker(t *testing.T) {
+	newBroker := func(t *testing.T, server *miniredis.Miniredis, consumer string) *RedisBroker {
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		broker := NewRedisBroker(client, DefaultRedisGroup, consumer)
+		broker.block = 20 * time.Millisecond
+		broker.claimIdle = 50 * time.Millisecond
+		t.Cleanup(func() {
+			_ = broker.Close(context.Background())
+			_ = client.Close()
+		})
+		return broker
+	}
+
+	t.Run("Publish_Delivers_To_Subscriber", func(t *testing.T) {
+		server := miniredis.RunT(t)
+		broker := newBroker(t, server, "replica-1")
+
+		received := make(chan string, 1)
+		err := broker.Subscribe(TopicSaleCreated, func(payload []byte) error {
+			received <- string(payload)
+			return nil
+		})
+		assert.Nil(t, err, "Expected no error subscribing")
+
+		err = broker.Publish(TopicSaleCreated, []byte(`{"sale_id":"sale-1"}`))
+		assert.Nil(t, err, "Expected no error publishing")
+
+		select {
+		case payload := <-received:
+			assert.Equal(t, `{"sale_id":"sale-1"}`, payload, "Expected the published payload")
+		case <-time.After(time.Second):
+			t.Fatal("Expected the message to be delivered")
+		}
+	})
+
+	t.Run("Failed_Message_Is_Retried", func(t *testing.T) {
+		server := miniredis.RunT(t)
+		broker := newBroker(t, server, "replica-1")
+
+		var attempts atomic.Int32
+		done := make(chan struct{})
+		err := broker.Subscribe(TopicSaleCreated, func(payload []byte) error {
+			if attempts.Add(1) == 1 {
+				return errors.New("database unavailable")
+			}
+			close(done)
+			return nil
+		})
+		assert.Nil(t, err, "Expected no error subscribing")
+
+		err = broker.Publish(TopicSaleCreated, []byte(`{}`))
+		assert.Nil(t, err, "Expected no error publishing")
+
+		select {
+		case <-done:
+			assert.Equal(t, int32(2), attempts.Load(), "Expected one retry")
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected the failed message to be retried")
+		}
+	})
+
+	t.Run("Replicas_Share_Messages", func(t *testing.T) {
+		server := miniredis.RunT(t)
+
+		var mu sync.Mutex
+		handled := make(map[string]int)
+		var wg sync.WaitGroup
+		wg.Add(10)
+		for _, consumer := range []string{"replica-1", "replica-2"} {
+			err := newBroker(t, server, consumer).Subscribe(TopicSaleCreated, func(payload []byte) error {
+				mu.Lock()
+				handled[string(payload)]++
+				mu.Unlock()
+				wg.Done()
+				return nil
+			})
+			assert.Nil(t, err, "Expected no error subscribing")
+		}
+
+		publisher := newBroker(t, server, "publisher")
+		for i := 0; i < 10; i++ {
+			err := publisher.Publish(TopicSaleCreated, []byte{byte('a' + i)})
+			assert.Nil(t, err, "Expected no error publishing")
+		}
+
+		wg.Wait()
+		assert.Len(t, handled, 10, "Expected every message to be handled")
+		for payload, count := range handled {
+			assert.Equal(t, 1, count, "Expected message %q to be handled once", payload)
+		}
+	})
+
+	t.Run("Check_Fails_Without_Redis", func(t *testing.T) {
+		server := miniredis.RunT(t)
+		broker := newBroker(t, server, "replica-1")
+
+		assert.Nil(t, broker.Check(context.Background()), "Expected a healthy broker")
+		server.Close()
+		assert.NotNil(t, broker.Check(context.Background()), "Expected an error once Redis is gone")
+	})
+}
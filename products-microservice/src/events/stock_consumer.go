@@ -0,0 +1,136 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/logging"
+	"github.com/dieg0code/products-microservice/src/repository"
+	"github.com/dieg0code/products-microservice/src/services"
+	"github.com/dieg0code/products-microservice/src/tenant"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrConsumerStopped is returned for the sales delivered after Stop, so the broker
+// redelivers them to another instance
+var ErrConsumerStopped = errors.New("stock consumer stopped")
+
+// StockConsumer listens for sales and keeps product stock in sync with them.
+type StockConsumer struct {
+	subscriber   Subscriber
+	publisher    Publisher
+	stockService services.StockService
+	validate     *validator.Validate
+
+	mu       sync.RWMutex
+	stopped  bool
+	inFlight sync.WaitGroup
+}
+
+func (s *StockConsumer) Start() error {
+	return s.subscriber.Subscribe(TopicSaleCreated, s.handle)
+}
+
+// Stop refuses new sales and waits for the ones being applied, or until ctx is done
+func (s *StockConsumer) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *StockConsumer) handle(payload []byte) error {
+	s.mu.RLock()
+	if s.stopped {
+		s.mu.RUnlock()
+		return ErrConsumerStopped
+	}
+	s.inFlight.Add(1)
+	s.mu.RUnlock()
+	defer s.inFlight.Done()
+
+	return s.HandleSaleCreated(payload)
+}
+
+func (s *StockConsumer) HandleSaleCreated(payload []byte) error {
+	sale := &request.SaleCreatedEvent{}
+
+	err := json.Unmarshal(payload, sale)
+	if err != nil {
+		// A malformed message will never succeed, so it is dropped instead of retried
+		logrus.WithError(err).Error("Error decoding sale created event")
+		return nil
+	}
+
+	err = s.validate.Struct(sale)
+	if err != nil {
+		logrus.WithError(err).WithField("sale_id", sale.SaleID).Error("Error validating sale created event")
+		return nil
+	}
+
+	// Sales published before tenants existed carry no tenant and belong to the default one
+	tenantID := sale.TenantID
+	if tenantID == "" {
+		tenantID = tenant.DefaultTenantID
+	}
+	if !tenant.Valid(tenantID) {
+		logrus.WithField("sale_id", sale.SaleID).WithField("tenant_id", tenantID).Error("Error validating sale created event tenant")
+		return nil
+	}
+
+	ctx := logging.WithFields(tenant.WithTenant(context.Background(), tenantID), logrus.Fields{
+		"sale_id":   sale.SaleID,
+		"tenant_id": tenantID,
+	})
+
+	compensation, err := s.stockService.ApplySale(ctx, sale)
+	if err != nil {
+		if errors.Is(err, repository.ErrSaleAlreadyProcessed) {
+			return nil
+		}
+		return err
+	}
+
+	if compensation == nil {
+		return nil
+	}
+
+	body, err := json.Marshal(compensation)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Error encoding stock compensation event")
+		return err
+	}
+
+	err = s.publisher.Publish(TopicStockCompensation, body)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Error publishing stock compensation event")
+		return err
+	}
+
+	return nil
+}
+
+func NewStockConsumer(subscriber Subscriber, publisher Publisher, stockService services.StockService, validate *validator.Validate) *StockConsumer {
+	return &StockConsumer{
+		subscriber:   subscriber,
+		publisher:    publisher,
+		stockService: stockService,
+		validate:     validate,
+	}
+}
@@ -0,0 +1,231 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/models"
+	"github.com/dieg0code/products-microservice/src/repository"
+	"github.com/dieg0code/products-microservice/src/services"
+	"github.com/dieg0code/products-microservice/src/tenant"
+	"github.com/dieg0code/products-microservice/src/testutils"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupConsumer(t *testing.T, db *gorm.DB) (*InMemoryBroker, *[]response.StockCompensationEvent) {
+	broker := NewInMemoryBroker()
+	stockService := services.NewStockServiceImpl(repository.NewStockRepositoryImpl(db))
+
+	consumer := NewStockConsumer(broker, broker, stockService, validator.New())
+	err := consumer.Start()
+	assert.Nil(t, err, "Expected no error starting consumer")
+
+	compensations := &[]response.StockCompensationEvent{}
+	err = broker.Subscribe(TopicStockCompensation, func(payload []byte) error {
+		var event response.StockCompensationEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return err
+		}
+		*compensations = append(*compensations, event)
+		return nil
+	})
+	assert.Nil(t, err, "Expected no error subscribing to compensations")
+
+	return broker, compensations
+}
+
+// publisherFunc adapts a function to Publisher
+type publisherFunc func(topic string, payload []byte) error
+
+func (f publisherFunc) Publish(topic string, payload []byte) error {
+	return f(topic, payload)
+}
+
+func publishSale(t *testing.T, broker *InMemoryBroker, sale request.SaleCreatedEvent) {
+	body, err := json.Marshal(sale)
+	assert.Nil(t, err, "Expected no error marshalling sale")
+
+	err = broker.Publish(TopicSaleCreated, body)
+	assert.Nil(t, err, "Expected no error publishing sale")
+}
+
+func TestStockConsumer(t *testing.T) {
+	ctx := tenant.WithTenant(context.Background(), tenant.DefaultTenantID)
+
+	t.Run("HandleSaleCreated_Decrements_Stock_Once", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.Product{}, &models.ProcessedSale{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		product := &models.Product{Name: "Test Product", Category: "Test Category", Price: 1000, Stock: 10}
+		assert.Nil(t, db.WithContext(ctx).Create(product).Error, "Expected no error creating product")
+
+		broker, compensations := setupConsumer(t, db)
+
+		sale := request.SaleCreatedEvent{
+			SaleID:   "sale-1",
+			UserID:   1,
+			Products: []request.SaleProductEvent{{ProductID: product.ID, Name: product.Name, Price: 1000, Quantity: 4}},
+		}
+
+		publishSale(t, broker, sale)
+		publishSale(t, broker, sale)
+
+		var stored models.Product
+		assert.Nil(t, db.WithContext(ctx).First(&stored, product.ID).Error, "Expected no error getting product")
+		assert.Equal(t, 6, stored.Stock, "Expected stock to be decremented once")
+		assert.Empty(t, *compensations, "Expected no compensation events")
+	})
+
+	t.Run("HandleSaleCreated_Emits_Compensation", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.Product{}, &models.ProcessedSale{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		product := &models.Product{Name: "Test Product", Category: "Test Category", Price: 1000, Stock: 1}
+		assert.Nil(t, db.WithContext(ctx).Create(product).Error, "Expected no error creating product")
+
+		broker, compensations := setupConsumer(t, db)
+
+		publishSale(t, broker, request.SaleCreatedEvent{
+			SaleID:   "sale-2",
+			Products: []request.SaleProductEvent{{ProductID: product.ID, Quantity: 3}},
+		})
+
+		var stored models.Product
+		assert.Nil(t, db.WithContext(ctx).First(&stored, product.ID).Error, "Expected no error getting product")
+		assert.Equal(t, 1, stored.Stock, "Expected stock to be untouched")
+
+		assert.Len(t, *compensations, 1, "Expected one compensation event")
+		assert.Equal(t, "sale-2", (*compensations)[0].SaleID, "Expected compensation for the sale")
+		assert.Equal(t, 3, (*compensations)[0].Shortage[0].Requested, "Expected requested quantity to be reported")
+	})
+
+	t.Run("HandleSaleCreated_Republishes_Lost_Compensation", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.Product{}, &models.ProcessedSale{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		product := &models.Product{Name: "Test Product", Category: "Test Category", Price: 1000, Stock: 1}
+		assert.Nil(t, db.WithContext(ctx).Create(product).Error, "Expected no error creating product")
+
+		failing := true
+		var compensations []response.StockCompensationEvent
+		publisher := publisherFunc(func(topic string, payload []byte) error {
+			if failing {
+				return errors.New("broker unavailable")
+			}
+			var event response.StockCompensationEvent
+			if err := json.Unmarshal(payload, &event); err != nil {
+				return err
+			}
+			compensations = append(compensations, event)
+			return nil
+		})
+
+		stockService := services.NewStockServiceImpl(repository.NewStockRepositoryImpl(db))
+		consumer := NewStockConsumer(NewInMemoryBroker(), publisher, stockService, validator.New())
+
+		payload, err := json.Marshal(request.SaleCreatedEvent{
+			SaleID:   "sale-4",
+			Products: []request.SaleProductEvent{{ProductID: product.ID, Quantity: 3}},
+		})
+		assert.Nil(t, err, "Expected no error marshalling sale")
+
+		assert.NotNil(t, consumer.HandleSaleCreated(payload), "Expected the failed publish to be reported so the sale is redelivered")
+
+		failing = false
+		assert.Nil(t, consumer.HandleSaleCreated(payload), "Expected no error handling the redelivery")
+
+		assert.Len(t, compensations, 1, "Expected the redelivery to publish the compensation")
+		assert.Equal(t, 3, compensations[0].Shortage[0].Requested, "Expected the recorded shortage")
+		assert.Equal(t, 1, compensations[0].Shortage[0].Available, "Expected the recorded shortage")
+
+		var stored models.Product
+		assert.Nil(t, db.WithContext(ctx).First(&stored, product.ID).Error, "Expected no error getting product")
+		assert.Equal(t, 1, stored.Stock, "Expected stock to be untouched")
+	})
+
+	t.Run("HandleSaleCreated_Scoped_To_Tenant", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.Product{}, &models.ProcessedSale{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		product := &models.Product{Name: "Test Product", Category: "Test Category", Price: 1000, Stock: 10}
+		assert.Nil(t, db.WithContext(ctx).Create(product).Error, "Expected no error creating product")
+
+		broker, compensations := setupConsumer(t, db)
+
+		publishSale(t, broker, request.SaleCreatedEvent{
+			SaleID:   "sale-3",
+			TenantID: "acme",
+			Products: []request.SaleProductEvent{{ProductID: product.ID, Quantity: 3}},
+		})
+
+		var stored models.Product
+		assert.Nil(t, db.WithContext(ctx).First(&stored, product.ID).Error, "Expected no error getting product")
+		assert.Equal(t, 10, stored.Stock, "Expected another tenant's sale not to touch the product")
+		assert.Len(t, *compensations, 1, "Expected the product to be missing for the other tenant")
+	})
+
+	t.Run("Stop_Waits_For_Sales_And_Refuses_New_Ones", func(t *testing.T) {
+		release := make(chan struct{})
+		started := make(chan struct{})
+		broker := NewInMemoryBroker()
+		consumer := NewStockConsumer(broker, broker, new(services.StockServiceImpl), validator.New())
+		assert.Nil(t, consumer.Start(), "Expected no error starting consumer")
+
+		// Validation blocks until release, keeping the sale in flight
+		consumer.validate = validator.New()
+		consumer.validate.RegisterStructValidation(func(validator.StructLevel) {
+			close(started)
+			<-release
+		}, request.SaleCreatedEvent{})
+
+		go func() { _ = broker.Publish(TopicSaleCreated, []byte(`{"saleID":""}`)) }()
+		<-started
+
+		timeout, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, consumer.Stop(timeout), context.DeadlineExceeded, "Expected Stop to wait for the sale in flight")
+		assert.ErrorIs(t, broker.Publish(TopicSaleCreated, []byte(`{}`)), ErrConsumerStopped, "Expected new sales to be refused")
+
+		close(release)
+		assert.Nil(t, consumer.Stop(context.Background()), "Expected Stop to return once the sale is done")
+	})
+
+	t.Run("HandleSaleCreated_Drops_Invalid_Payload", func(t *testing.T) {
+		broker := NewInMemoryBroker()
+		consumer := NewStockConsumer(broker, broker, new(services.StockServiceImpl), validator.New())
+
+		assert.Nil(t, consumer.HandleSaleCreated([]byte("not json")), "Expected malformed payload to be dropped")
+		assert.Nil(t, consumer.HandleSaleCreated([]byte(`{"saleID":""}`)), "Expected invalid payload to be dropped")
+	})
+}
@@ -0,0 +1,129 @@
+package graphqlapi
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// listFields return many values, their subtree cost is multiplied by the page size
+var listFields = map[string]bool{
+	"products":   true,
+	"categories": true,
+}
+
+type Limits struct {
+	MaxComplexity int
+	MaxDepth      int
+}
+
+var DefaultLimits = Limits{MaxComplexity: 1000, MaxDepth: 10}
+
+type complexityWalker struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	visiting  map[string]bool
+}
+
+// checkComplexity estimates the cost of the operation before it runs. Every field
+// costs one and list fields multiply their selection by the requested page size.
+func checkComplexity(doc *ast.Document, operationName string, variables map[string]interface{}, limits Limits) error {
+	walker := &complexityWalker{
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: variables,
+		visiting:  make(map[string]bool),
+	}
+
+	var operations []*ast.OperationDefinition
+	for _, definition := range doc.Definitions {
+		switch def := definition.(type) {
+		case *ast.FragmentDefinition:
+			walker.fragments[def.Name.Value] = def
+		case *ast.OperationDefinition:
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				operations = append(operations, def)
+			}
+		}
+	}
+
+	for _, operation := range operations {
+		complexity, depth := walker.selectionSet(operation.SelectionSet)
+		if depth > limits.MaxDepth {
+			return fmt.Errorf("query depth %d exceeds the limit of %d", depth, limits.MaxDepth)
+		}
+		if complexity > limits.MaxComplexity {
+			return fmt.Errorf("query complexity %d exceeds the limit of %d", complexity, limits.MaxComplexity)
+		}
+	}
+
+	return nil
+}
+
+func (w *complexityWalker) selectionSet(set *ast.SelectionSet) (int, int) {
+	if set == nil {
+		return 0, 0
+	}
+
+	complexity, depth := 0, 0
+	for _, selection := range set.Selections {
+		var c, d int
+
+		switch sel := selection.(type) {
+		case *ast.Field:
+			childComplexity, childDepth := w.selectionSet(sel.SelectionSet)
+			c = 1 + childComplexity*w.multiplier(sel)
+			d = 1 + childDepth
+		case *ast.InlineFragment:
+			c, d = w.selectionSet(sel.SelectionSet)
+		case *ast.FragmentSpread:
+			name := sel.Name.Value
+			fragment, ok := w.fragments[name]
+			if !ok || w.visiting[name] {
+				continue
+			}
+			w.visiting[name] = true
+			c, d = w.selectionSet(fragment.SelectionSet)
+			w.visiting[name] = false
+		}
+
+		complexity += c
+		if d > depth {
+			depth = d
+		}
+	}
+
+	return complexity, depth
+}
+
+func (w *complexityWalker) multiplier(field *ast.Field) int {
+	if !listFields[field.Name.Value] {
+		return 1
+	}
+
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != "first" {
+			continue
+		}
+
+		switch value := argument.Value.(type) {
+		case *ast.IntValue:
+			if first, err := strconv.Atoi(value.Value); err == nil && first > 0 {
+				return first
+			}
+		case *ast.Variable:
+			switch first := w.variables[value.Name.Value].(type) {
+			case float64:
+				if first > 0 {
+					return int(first)
+				}
+			case int:
+				if first > 0 {
+					return first
+				}
+			}
+		}
+	}
+
+	return DefaultPageSize
+}
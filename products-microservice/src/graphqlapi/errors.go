@@ -0,0 +1,80 @@
+package graphqlapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/dieg0code/products-microservice/src/auth"
+	"github.com/dieg0code/products-microservice/src/logging"
+	"github.com/dieg0code/products-microservice/src/repository"
+	"github.com/dieg0code/products-microservice/src/validation"
+	"github.com/graphql-go/graphql"
+	"gorm.io/gorm"
+)
+
+// argumentError is a problem with the arguments of a query, its message is meant for the client
+type argumentError string
+
+func (e argumentError) Error() string {
+	return string(e)
+}
+
+// toGraphQLError maps service and repository errors to messages any caller may see.
+// Errors that may carry database details are logged and answered with a generic message.
+func toGraphQLError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var argErr argumentError
+
+	switch {
+	case errors.As(err, &argErr):
+		return argErr
+	case errors.Is(err, auth.ErrUnauthenticated):
+		return auth.ErrUnauthenticated
+	case errors.Is(err, auth.ErrForbidden):
+		return auth.ErrForbidden
+	case errors.Is(err, context.DeadlineExceeded):
+		return errors.New("request timed out")
+	case errors.Is(err, repository.ErrProductNotFound):
+		return repository.ErrProductNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return errors.New("a product with this name already exists")
+	}
+
+	if fieldErrors := validation.FieldErrors(err, ""); fieldErrors != nil {
+		messages := make([]string, 0, len(fieldErrors))
+		for _, fieldError := range fieldErrors {
+			messages = append(messages, fieldError.Message)
+		}
+		return errors.New(strings.Join(messages, "; "))
+	}
+
+	logging.FromContext(ctx).WithError(err).Error("Error resolving GraphQL field")
+	return errors.New("internal error")
+}
+
+// maskErrors runs the errors of resolve, and of the thunk it may return for batched
+// fields, through toGraphQLError
+func maskErrors(resolve graphql.FieldResolveFn) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		value, err := resolve(p)
+		if err != nil {
+			return nil, toGraphQLError(p.Context, err)
+		}
+
+		thunk, ok := value.(func() (interface{}, error))
+		if !ok {
+			return value, nil
+		}
+		return func() (interface{}, error) {
+			value, err := thunk()
+			if err != nil {
+				return nil, toGraphQLError(p.Context, err)
+			}
+			return value, nil
+		}, nil
+	}
+}
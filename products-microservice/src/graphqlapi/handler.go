@@ -0,0 +1,77 @@
+package graphqlapi
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/dieg0code/products-microservice/src/logging"
+	"github.com/dieg0code/products-microservice/src/services"
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+type graphQLRequest struct {
+	Query         string                 `json:"query" form:"query"`
+	OperationName string                 `json:"operationName" form:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+type Handler struct {
+	schema         graphql.Schema
+	productService services.ProductService
+	limits         Limits
+}
+
+// ServeGraphQL answers POST requests with a JSON body and GET requests with query parameters
+func (h *Handler) ServeGraphQL(c *gin.Context) {
+	req := &graphQLRequest{}
+
+	var err error
+	if c.Request.Method == http.MethodGet {
+		err = c.ShouldBindQuery(req)
+	} else {
+		err = c.ShouldBindJSON(req)
+	}
+	if err != nil || req.Query == "" {
+		c.JSON(http.StatusBadRequest, graphql.Result{
+			Errors: []gqlerrors.FormattedError{gqlerrors.NewFormattedError("Invalid GraphQL request")},
+		})
+		return
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(req.Query)})})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+		return
+	}
+
+	err = checkComplexity(doc, req.OperationName, req.Variables, h.limits)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).WithError(err).Warn("GraphQL query rejected")
+		c.JSON(http.StatusBadRequest, graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), loadersKey{}, newLoaders(c.Request.Context(), h.productService))
+
+	result := graphql.Do(graphql.Params{
+		Schema:         h.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        ctx,
+	})
+
+	c.JSON(http.StatusOK, result)
+}
+
+func NewHandler(productService services.ProductService, schema graphql.Schema, limits Limits) *Handler {
+	return &Handler{
+		schema:         schema,
+		productService: productService,
+		limits:         limits,
+	}
+}
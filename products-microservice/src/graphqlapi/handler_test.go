@@ -0,0 +1,281 @@
+package graphqlapi
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dieg0code/products-microservice/src/auth"
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/repository"
+	"github.com/dieg0code/products-microservice/src/testutils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type graphQLResponse struct {
+	Data   map[string]interface{}   `json:"data"`
+	Errors []map[string]interface{} `json:"errors"`
+}
+
+func setupGraphQL(t *testing.T, mockService *testutils.MockProductService) *gin.Engine {
+	validate := validator.New()
+
+	schema, err := NewSchema(mockService, validate)
+	assert.Nil(t, err, "Expected no error building schema")
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(auth.WithoutAuthentication(c.Request.Context()))
+	})
+	router.POST("/graphql", NewHandler(mockService, schema, DefaultLimits).ServeGraphQL)
+	return router
+}
+
+func postQuery(t *testing.T, router *gin.Engine, query string, variables map[string]interface{}) (*httptest.ResponseRecorder, graphQLResponse) {
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	assert.Nil(t, err, "Expected no error marshalling request body")
+
+	req, err := http.NewRequest(http.MethodPost, "/graphql", bytes.NewBuffer(body))
+	assert.Nil(t, err, "Expected no error creating request")
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var res graphQLResponse
+	err = json.Unmarshal(rec.Body.Bytes(), &res)
+	assert.Nil(t, err, "Expected no error unmarshalling response body")
+
+	return rec, res
+}
+
+func TestGraphQLHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Product_Lookups_Are_Batched", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		router := setupGraphQL(t, mockService)
+
+		mockService.On("GetProductsByIds", mock.Anything, mock.MatchedBy(func(ids []uint) bool {
+			return assert.ElementsMatch(t, []uint{1, 2, 3}, ids)
+		})).Return(&response.BatchGetProductsResponse{
+			Products: []response.ProductResponse{
+				{ProductID: 1, Name: "Product 1"},
+				{ProductID: 2, Name: "Product 2"},
+			},
+			MissingIDs: []uint{3},
+		}, nil)
+
+		rec, res := postQuery(t, router, `{
+			a: product(id: 1) { name }
+			b: product(id: 2) { name }
+			c: product(id: 3) { name }
+		}`, nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code, "Expected status code 200")
+		assert.Empty(t, res.Errors, "Expected no errors")
+		assert.Equal(t, "Product 1", res.Data["a"].(map[string]interface{})["name"], "Expected first product")
+		assert.Equal(t, "Product 2", res.Data["b"].(map[string]interface{})["name"], "Expected second product")
+		assert.Nil(t, res.Data["c"], "Expected missing product to be null")
+
+		mockService.AssertNumberOfCalls(t, "GetProductsByIds", 1)
+	})
+
+	t.Run("Category_Products_Are_Batched", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		router := setupGraphQL(t, mockService)
+
+		mockService.On("GetCategories", mock.Anything).Return([]string{"Books", "Games"}, nil)
+		mockService.On("GetByCategories", mock.Anything, mock.MatchedBy(func(categories []string) bool {
+			return assert.ElementsMatch(t, []string{"Books", "Games"}, categories)
+		}), 0, 1).Return(map[string][]response.ProductResponse{
+			"Books": {{ProductID: 1, Name: "Book 1", Category: "Books"}},
+			"Games": {{ProductID: 3, Name: "Game 1", Category: "Games"}},
+		}, nil)
+		mockService.On("CountByCategories", mock.Anything, mock.MatchedBy(func(categories []string) bool {
+			return assert.ElementsMatch(t, []string{"Books", "Games"}, categories)
+		})).Return(map[string]int64{"Books": 2, "Games": 1}, nil)
+
+		rec, res := postQuery(t, router, `{
+			categories {
+				name
+				productCount
+				products(first: 1) {
+					totalCount
+					pageInfo { hasNextPage }
+					edges { node { name } }
+				}
+			}
+		}`, nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code, "Expected status code 200")
+		assert.Empty(t, res.Errors, "Expected no errors")
+
+		categories := res.Data["categories"].([]interface{})
+		books := categories[0].(map[string]interface{})
+		assert.Equal(t, float64(2), books["productCount"], "Expected product count")
+		connection := books["products"].(map[string]interface{})
+		assert.Equal(t, true, connection["pageInfo"].(map[string]interface{})["hasNextPage"], "Expected another page")
+		assert.Len(t, connection["edges"], 1, "Expected one edge")
+
+		mockService.AssertNumberOfCalls(t, "GetByCategories", 1)
+		mockService.AssertNumberOfCalls(t, "CountByCategories", 1)
+	})
+
+	t.Run("Products_Filter_And_Cursor", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		router := setupGraphQL(t, mockService)
+
+		inStock := true
+		minPrice := 100
+		mockService.On("SearchProducts", mock.Anything, &request.ProductFilter{Category: "Books", MinPrice: &minPrice, InStock: &inStock}, 3, 2).
+			Return([]response.ProductResponse{{ProductID: 4}, {ProductID: 5}}, int64(10), nil)
+
+		rec, res := postQuery(t, router, `query($after: String) {
+			products(first: 2, after: $after, filter: {category: "Books", minPrice: 100, inStock: true}) {
+				totalCount
+				pageInfo { hasNextPage hasPreviousPage endCursor }
+				edges { cursor node { productId } }
+			}
+		}`, map[string]interface{}{"after": encodeCursor(2)})
+
+		assert.Equal(t, http.StatusOK, rec.Code, "Expected status code 200")
+		assert.Empty(t, res.Errors, "Expected no errors")
+
+		connection := res.Data["products"].(map[string]interface{})
+		assert.Equal(t, float64(10), connection["totalCount"], "Expected total count")
+		pageInfo := connection["pageInfo"].(map[string]interface{})
+		assert.Equal(t, true, pageInfo["hasNextPage"], "Expected another page")
+		assert.Equal(t, true, pageInfo["hasPreviousPage"], "Expected a previous page")
+		assert.Equal(t, encodeCursor(4), pageInfo["endCursor"], "Expected end cursor at the last edge")
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Products_Rejects_Out_Of_Range_Cursor", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		router := setupGraphQL(t, mockService)
+
+		for _, offset := range []string{"9223372036854775807", "-1", "1000001"} {
+			after := base64.StdEncoding.EncodeToString([]byte(cursorPrefix + offset))
+
+			_, res := postQuery(t, router, `query($after: String) {
+				products(after: $after) { totalCount }
+			}`, map[string]interface{}{"after": after})
+
+			assert.NotEmpty(t, res.Errors, "Expected offset %s to be rejected", offset)
+		}
+
+		mockService.AssertNotCalled(t, "SearchProducts")
+	})
+
+	t.Run("Complexity_Limit_Rejects_Query", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		router := setupGraphQL(t, mockService)
+
+		rec, res := postQuery(t, router, `{
+			products(first: 100) {
+				edges { node { category { products(first: 100) { edges { node { name } } } } } }
+			}
+		}`, nil)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, "Expected status code 400")
+		assert.NotEmpty(t, res.Errors, "Expected complexity error")
+
+		mockService.AssertNotCalled(t, "SearchProducts")
+	})
+
+	t.Run("CreateProduct_Mutation", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		router := setupGraphQL(t, mockService)
+
+		productID := uint(9)
+		mockService.On("CreateProduct", mock.Anything, &request.CreateProductRequest{Name: "Product 1", Category: "Books", Price: 1000, Stock: 5}).Return(&productID, nil)
+		mockService.On("GetProductById", mock.Anything, uint(9)).Return(&response.ProductResponse{ProductID: 9, Name: "Product 1"}, nil)
+
+		rec, res := postQuery(t, router, `mutation {
+			createProduct(input: {name: "Product 1", category: "Books", price: 1000, stock: 5}) { id name }
+		}`, nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code, "Expected status code 200")
+		assert.Empty(t, res.Errors, "Expected no errors")
+		assert.Equal(t, "9", res.Data["createProduct"].(map[string]interface{})["id"], "Expected created product id")
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("CreateProduct_Mutation_Invalid", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		router := setupGraphQL(t, mockService)
+
+		_, res := postQuery(t, router, `mutation {
+			createProduct(input: {name: "Product 1", category: "Books", price: 0, stock: 5}) { id }
+		}`, nil)
+
+		assert.NotEmpty(t, res.Errors, "Expected validation error")
+		mockService.AssertNotCalled(t, "CreateProduct")
+	})
+
+	t.Run("Errors_Hide_Database_Details", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		router := setupGraphQL(t, mockService)
+
+		dbErr := errors.New(`pq: relation "products" does not exist (SQLSTATE 42P01)`)
+		mockService.On("SearchProducts", mock.Anything, mock.Anything, 0, DefaultPageSize).Return([]response.ProductResponse(nil), int64(0), dbErr)
+		mockService.On("GetProductsByIds", mock.Anything, mock.Anything).Return((*response.BatchGetProductsResponse)(nil), dbErr)
+		mockService.On("UpdateProduct", mock.Anything, uint(1), mock.Anything).Return((*response.ProductResponse)(nil), fmt.Errorf("updating: %w", repository.ErrProductNotFound))
+		mockService.On("CreateProduct", mock.Anything, mock.Anything).Return((*uint)(nil), fmt.Errorf(`duplicate key value violates unique constraint "idx_products_name": %w`, gorm.ErrDuplicatedKey))
+
+		queries := map[string]string{
+			`{ products { totalCount } }`: "internal error",
+			`{ product(id: 1) { name } }`: "internal error",
+			`mutation { updateProduct(id: 1, input: {name: "Product 1", category: "Books", price: 1000, stock: 5}) { id } }`: "product not found",
+			`mutation { createProduct(input: {name: "Product 1", category: "Books", price: 1000, stock: 5}) { id } }`:        "a product with this name already exists",
+			`mutation { createProduct(input: {name: "Product 1", category: "Books", price: 0, stock: 5}) { id } }`:           "Price failed on the required rule",
+		}
+		for query, message := range queries {
+			_, res := postQuery(t, router, query, nil)
+
+			if assert.Len(t, res.Errors, 1, "Expected one error for %s", query) {
+				assert.Equal(t, message, res.Errors[0]["message"], "Expected a safe message for %s", query)
+				assert.NotContains(t, res.Errors[0]["message"], "pq:", "Expected no database details for %s", query)
+				assert.NotContains(t, res.Errors[0]["message"], "constraint", "Expected no database details for %s", query)
+			}
+		}
+	})
+
+	t.Run("DeleteProduct_Mutation_Needs_Admin", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		mockService.On("DeleteProduct", mock.Anything, uint(1)).Return(nil)
+
+		var principal *auth.Principal
+		schema, err := NewSchema(mockService, validator.New())
+		assert.Nil(t, err, "Expected no error building schema")
+
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		})
+		router.POST("/graphql", NewHandler(mockService, schema, DefaultLimits).ServeGraphQL)
+
+		principal = &auth.Principal{Subject: "user-1", Roles: []auth.Role{auth.RoleEditor}}
+		_, res := postQuery(t, router, `mutation { deleteProduct(id: 1) }`, nil)
+		assert.NotEmpty(t, res.Errors, "Expected editor delete to be rejected")
+		mockService.AssertNotCalled(t, "DeleteProduct", mock.Anything, uint(1))
+
+		principal = &auth.Principal{Subject: "user-1", Roles: []auth.Role{auth.RoleAdmin}}
+		_, res = postQuery(t, router, `mutation { deleteProduct(id: 1) }`, nil)
+		assert.Empty(t, res.Errors, "Expected admin delete to succeed")
+		mockService.AssertNumberOfCalls(t, "DeleteProduct", 1)
+	})
+}
@@ -0,0 +1,57 @@
+package graphqlapi
+
+import "sync"
+
+// batchLoader collects the keys requested while a GraphQL level is being resolved
+// and fetches all of them with a single call once the first value is needed.
+type batchLoader[K comparable, V any] struct {
+	mu      sync.Mutex
+	fetch   func(keys []K) (map[K]V, error)
+	pending []K
+	queued  map[K]bool
+	cache   map[K]V
+	errs    map[K]error
+}
+
+// Load returns a thunk so graphql-go can defer it until every sibling field queued its key
+func (l *batchLoader[K, V]) Load(key K) func() (V, error) {
+	l.mu.Lock()
+	_, cached := l.cache[key]
+	_, failed := l.errs[key]
+	if !cached && !failed && !l.queued[key] {
+		l.queued[key] = true
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if len(l.pending) > 0 {
+			keys := l.pending
+			l.pending = nil
+			l.queued = make(map[K]bool)
+
+			values, err := l.fetch(keys)
+			for _, k := range keys {
+				if err != nil {
+					l.errs[k] = err
+					continue
+				}
+				l.cache[k] = values[k]
+			}
+		}
+
+		return l.cache[key], l.errs[key]
+	}
+}
+
+func newBatchLoader[K comparable, V any](fetch func(keys []K) (map[K]V, error)) *batchLoader[K, V] {
+	return &batchLoader[K, V]{
+		fetch:  fetch,
+		queued: make(map[K]bool),
+		cache:  make(map[K]V),
+		errs:   make(map[K]error),
+	}
+}
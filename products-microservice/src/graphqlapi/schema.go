@@ -0,0 +1,541 @@
+package graphqlapi
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dieg0code/products-microservice/src/auth"
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/services"
+	"github.com/go-playground/validator/v10"
+	"github.com/graphql-go/graphql"
+)
+
+const (
+	DefaultPageSize = 10
+	MaxPageSize     = 100
+	cursorPrefix    = "offset:"
+	// maxCursorOffset bounds the offsets cursors may point at, deeper pages are
+	// better reached with a filter
+	maxCursorOffset = 1_000_000
+)
+
+type loadersKey struct{}
+
+// loaders are created per request so cached values never outlive it and every
+// batch runs with the request's tenant
+type loaders struct {
+	products         *batchLoader[uint, *response.ProductResponse]
+	categoryProducts *batchLoader[categoryPage, []response.ProductResponse]
+	categoryCounts   *batchLoader[string, int64]
+}
+
+// categoryPage identifies the page of a category a products field asks for
+type categoryPage struct {
+	category string
+	offset   int
+	first    int
+}
+
+func newLoaders(ctx context.Context, productService services.ProductService) *loaders {
+	return &loaders{
+		products: newBatchLoader(func(productIDs []uint) (map[uint]*response.ProductResponse, error) {
+			batch, err := productService.GetProductsByIds(ctx, productIDs)
+			if err != nil {
+				return nil, err
+			}
+			products := make(map[uint]*response.ProductResponse, len(batch.Products))
+			for i := range batch.Products {
+				products[batch.Products[i].ProductID] = &batch.Products[i]
+			}
+			return products, nil
+		}),
+		categoryProducts: newBatchLoader(func(pages []categoryPage) (map[categoryPage][]response.ProductResponse, error) {
+			// Categories asking for the same page share a query
+			windows := make(map[[2]int][]string)
+			for _, page := range pages {
+				window := [2]int{page.offset, page.first}
+				windows[window] = append(windows[window], page.category)
+			}
+
+			products := make(map[categoryPage][]response.ProductResponse, len(pages))
+			for window, categories := range windows {
+				byCategory, err := productService.GetByCategories(ctx, categories, window[0], window[1])
+				if err != nil {
+					return nil, err
+				}
+				for _, category := range categories {
+					products[categoryPage{category: category, offset: window[0], first: window[1]}] = byCategory[category]
+				}
+			}
+			return products, nil
+		}),
+		categoryCounts: newBatchLoader(func(categories []string) (map[string]int64, error) {
+			return productService.CountByCategories(ctx, categories)
+		}),
+	}
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+type category struct {
+	Name string
+}
+
+type resolver struct {
+	productService services.ProductService
+	validate       *validator.Validate
+}
+
+// NewSchema builds the catalog schema, every resolver delegates to productService
+func NewSchema(productService services.ProductService, validate *validator.Validate) (graphql.Schema, error) {
+	r := &resolver{productService: productService, validate: validate}
+
+	pageInfoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"hasNextPage":     &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"hasPreviousPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"startCursor":     &graphql.Field{Type: graphql.String},
+			"endCursor":       &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	var categoryType *graphql.Object
+
+	productType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Product",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.ID),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return strconv.FormatUint(uint64(p.Source.(*response.ProductResponse).ProductID), 10), nil
+					},
+				},
+				"productId": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.Int),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return int(p.Source.(*response.ProductResponse).ProductID), nil
+					},
+				},
+				"name": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.String),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source.(*response.ProductResponse).Name, nil
+					},
+				},
+				"category": &graphql.Field{
+					Type: graphql.NewNonNull(categoryType),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return &category{Name: p.Source.(*response.ProductResponse).Category}, nil
+					},
+				},
+				"price": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.Int),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source.(*response.ProductResponse).Price, nil
+					},
+				},
+				"stock": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.Int),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source.(*response.ProductResponse).Stock, nil
+					},
+				},
+				"lastUpdate": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source.(*response.ProductResponse).LastUpdate, nil
+					},
+				},
+			}
+		}),
+	})
+
+	productEdgeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ProductEdge",
+		Fields: graphql.Fields{
+			"cursor": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"node":   &graphql.Field{Type: graphql.NewNonNull(productType)},
+		},
+	})
+
+	productConnectionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ProductConnection",
+		Fields: graphql.Fields{
+			"edges":      &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(productEdgeType)))},
+			"pageInfo":   &graphql.Field{Type: graphql.NewNonNull(pageInfoType)},
+			"totalCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	connectionArgs := graphql.FieldConfigArgument{
+		"first": &graphql.ArgumentConfig{Type: graphql.Int},
+		"after": &graphql.ArgumentConfig{Type: graphql.String},
+	}
+
+	categoryType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Category",
+		Fields: graphql.Fields{
+			"name": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*category).Name, nil
+				},
+			},
+			"productCount": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.Int),
+				Resolve: maskErrors(r.resolveCategoryProductCount),
+			},
+			"products": &graphql.Field{
+				Type:    graphql.NewNonNull(productConnectionType),
+				Args:    connectionArgs,
+				Resolve: maskErrors(r.resolveCategoryProducts),
+			},
+		},
+	})
+
+	productFilterType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "ProductFilter",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"category":     &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"nameContains": &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"minPrice":     &graphql.InputObjectFieldConfig{Type: graphql.Int},
+			"maxPrice":     &graphql.InputObjectFieldConfig{Type: graphql.Int},
+			"inStock":      &graphql.InputObjectFieldConfig{Type: graphql.Boolean},
+		},
+	})
+
+	productInputType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "ProductInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"name":     &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"category": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"price":    &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Int)},
+			"stock":    &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"product": &graphql.Field{
+				Type: productType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: maskErrors(r.resolveProduct),
+			},
+			"products": &graphql.Field{
+				Type: graphql.NewNonNull(productConnectionType),
+				Args: graphql.FieldConfigArgument{
+					"first":  &graphql.ArgumentConfig{Type: graphql.Int},
+					"after":  &graphql.ArgumentConfig{Type: graphql.String},
+					"filter": &graphql.ArgumentConfig{Type: productFilterType},
+				},
+				Resolve: maskErrors(r.resolveProducts),
+			},
+			"categories": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(categoryType))),
+				Resolve: maskErrors(r.resolveCategories),
+			},
+			"category": &graphql.Field{
+				Type: categoryType,
+				Args: graphql.FieldConfigArgument{
+					"name": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return &category{Name: p.Args["name"].(string)}, nil
+				},
+			},
+		},
+	})
+
+	mutationType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createProduct": &graphql.Field{
+				Type: graphql.NewNonNull(productType),
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(productInputType)},
+				},
+				Resolve: maskErrors(r.resolveCreateProduct),
+			},
+			"updateProduct": &graphql.Field{
+				Type: graphql.NewNonNull(productType),
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(productInputType)},
+				},
+				Resolve: maskErrors(r.resolveUpdateProduct),
+			},
+			"deleteProduct": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: maskErrors(r.resolveDeleteProduct),
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    queryType,
+		Mutation: mutationType,
+	})
+}
+
+func (r *resolver) resolveProduct(p graphql.ResolveParams) (interface{}, error) {
+	id, err := productIDArg(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	load := loadersFrom(p.Context).products.Load(id)
+
+	return func() (interface{}, error) {
+		product, err := load()
+		if err != nil || product == nil {
+			return nil, err
+		}
+		return product, nil
+	}, nil
+}
+
+func (r *resolver) resolveProducts(p graphql.ResolveParams) (interface{}, error) {
+	first, offset, err := pageArgs(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &request.ProductFilter{}
+	if raw, ok := p.Args["filter"].(map[string]interface{}); ok {
+		filter.Category, _ = raw["category"].(string)
+		filter.NameContains, _ = raw["nameContains"].(string)
+		if value, ok := raw["minPrice"].(int); ok {
+			filter.MinPrice = &value
+		}
+		if value, ok := raw["maxPrice"].(int); ok {
+			filter.MaxPrice = &value
+		}
+		if value, ok := raw["inStock"].(bool); ok {
+			filter.InStock = &value
+		}
+	}
+
+	err = r.validate.Struct(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	products, total, err := r.productService.SearchProducts(p.Context, filter, offset, first)
+	if err != nil {
+		return nil, err
+	}
+
+	return newConnection(products, offset, int(total)), nil
+}
+
+func (r *resolver) resolveCategories(p graphql.ResolveParams) (interface{}, error) {
+	names, err := r.productService.GetCategories(p.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make([]*category, 0, len(names))
+	for _, name := range names {
+		categories = append(categories, &category{Name: name})
+	}
+
+	return categories, nil
+}
+
+func (r *resolver) resolveCategoryProducts(p graphql.ResolveParams) (interface{}, error) {
+	first, offset, err := pageArgs(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	name := p.Source.(*category).Name
+	loadProducts := loadersFrom(p.Context).categoryProducts.Load(categoryPage{category: name, offset: offset, first: first})
+	loadTotal := loadersFrom(p.Context).categoryCounts.Load(name)
+
+	return func() (interface{}, error) {
+		products, err := loadProducts()
+		if err != nil {
+			return nil, err
+		}
+
+		total, err := loadTotal()
+		if err != nil {
+			return nil, err
+		}
+
+		return newConnection(products, offset, int(total)), nil
+	}, nil
+}
+
+func (r *resolver) resolveCategoryProductCount(p graphql.ResolveParams) (interface{}, error) {
+	load := loadersFrom(p.Context).categoryCounts.Load(p.Source.(*category).Name)
+
+	return func() (interface{}, error) {
+		total, err := load()
+		return int(total), err
+	}, nil
+}
+
+func (r *resolver) resolveCreateProduct(p graphql.ResolveParams) (interface{}, error) {
+	err := auth.Authorize(p.Context, auth.PermissionWriteProducts)
+	if err != nil {
+		return nil, err
+	}
+
+	input := p.Args["input"].(map[string]interface{})
+
+	createProductRequest := &request.CreateProductRequest{
+		Name:     input["name"].(string),
+		Category: input["category"].(string),
+		Price:    input["price"].(int),
+		Stock:    input["stock"].(int),
+	}
+
+	err = r.validate.Struct(createProductRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	productID, err := r.productService.CreateProduct(p.Context, createProductRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.productService.GetProductById(p.Context, *productID)
+}
+
+func (r *resolver) resolveUpdateProduct(p graphql.ResolveParams) (interface{}, error) {
+	err := auth.Authorize(p.Context, auth.PermissionWriteProducts)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := productIDArg(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	input := p.Args["input"].(map[string]interface{})
+
+	updateProductRequest := &request.UpdateProductRequest{
+		Name:     input["name"].(string),
+		Category: input["category"].(string),
+		Price:    input["price"].(int),
+		Stock:    input["stock"].(int),
+	}
+
+	err = r.validate.Struct(updateProductRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.productService.UpdateProduct(p.Context, id, updateProductRequest)
+}
+
+func (r *resolver) resolveDeleteProduct(p graphql.ResolveParams) (interface{}, error) {
+	err := auth.Authorize(p.Context, auth.PermissionDeleteProducts)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := productIDArg(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.productService.DeleteProduct(p.Context, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return true, nil
+}
+
+func productIDArg(args map[string]interface{}) (uint, error) {
+	id, _ := args["id"].(int)
+	if id <= 0 {
+		return 0, argumentError("id must be a positive integer")
+	}
+	return uint(id), nil
+}
+
+func pageArgs(args map[string]interface{}) (int, int, error) {
+	first := DefaultPageSize
+	if value, ok := args["first"].(int); ok {
+		if value < 0 || value > MaxPageSize {
+			return 0, 0, argumentError(fmt.Sprintf("first must be between 0 and %d", MaxPageSize))
+		}
+		first = value
+	}
+
+	offset := 0
+	if after, ok := args["after"].(string); ok && after != "" {
+		position, err := decodeCursor(after)
+		if err != nil {
+			return 0, 0, err
+		}
+		offset = position + 1
+	}
+
+	return first, offset, nil
+}
+
+func newConnection(products []response.ProductResponse, offset int, total int) map[string]interface{} {
+	edges := make([]map[string]interface{}, 0, len(products))
+	for i := range products {
+		edges = append(edges, map[string]interface{}{
+			"cursor": encodeCursor(offset + i),
+			"node":   &products[i],
+		})
+	}
+
+	pageInfo := map[string]interface{}{
+		"hasNextPage":     offset+len(products) < total,
+		"hasPreviousPage": offset > 0,
+		"startCursor":     nil,
+		"endCursor":       nil,
+	}
+	if len(edges) > 0 {
+		pageInfo["startCursor"] = edges[0]["cursor"]
+		pageInfo["endCursor"] = edges[len(edges)-1]["cursor"]
+	}
+
+	return map[string]interface{}{
+		"edges":      edges,
+		"pageInfo":   pageInfo,
+		"totalCount": total,
+	}
+}
+
+func encodeCursor(offset int) string {
+	return base64.StdEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), cursorPrefix) {
+		return 0, argumentError("invalid cursor")
+	}
+
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(raw), cursorPrefix))
+	if err != nil || offset < 0 || offset > maxCursorOffset {
+		return 0, argumentError("invalid cursor")
+	}
+
+	return offset, nil
+}
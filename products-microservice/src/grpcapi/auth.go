@@ -0,0 +1,60 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/dieg0code/products-microservice/src/auth"
+	"github.com/dieg0code/products-microservice/src/pb/productsv1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// methodPermissions mirrors the REST routes, methods not listed here are public
+var methodPermissions = map[string]auth.Permission{
+	productsv1.ProductService_CreateProduct_FullMethodName: auth.PermissionWriteProducts,
+	productsv1.ProductService_UpdateProduct_FullMethodName: auth.PermissionWriteProducts,
+	productsv1.ProductService_DeleteProduct_FullMethodName: auth.PermissionDeleteProducts,
+	productsv1.ProductService_ReserveStock_FullMethodName:  auth.PermissionReserveStock,
+}
+
+// UnaryAuthInterceptor authenticates calls from the authorization or x-api-key
+// metadata and checks the permission of the method
+func UnaryAuthInterceptor(authenticator *auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		var bearerToken, apiKey string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get("authorization"); len(values) > 0 {
+				scheme, token, found := strings.Cut(values[0], " ")
+				if !found || !strings.EqualFold(scheme, "Bearer") {
+					return nil, status.Error(codes.Unauthenticated, "invalid authorization metadata")
+				}
+				bearerToken = strings.TrimSpace(token)
+			}
+			if values := md.Get(strings.ToLower(auth.ApiKeyHeader)); len(values) > 0 {
+				apiKey = values[0]
+			}
+		}
+
+		principal, err := authenticator.Authenticate(ctx, bearerToken, apiKey)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+		}
+		ctx = auth.WithPrincipal(ctx, principal)
+
+		if permission, ok := methodPermissions[info.FullMethod]; ok {
+			err = auth.Authorize(ctx, permission)
+			if errors.Is(err, auth.ErrUnauthenticated) {
+				return nil, status.Error(codes.Unauthenticated, err.Error())
+			}
+			if err != nil {
+				return nil, status.Error(codes.PermissionDenied, err.Error())
+			}
+		}
+
+		return handler(ctx, req)
+	}
+}
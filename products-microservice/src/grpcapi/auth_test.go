@@ -0,0 +1,93 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/auth"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/pb/productsv1"
+	"github.com/dieg0code/products-microservice/src/testutils"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func setupAuthenticatedGRPC(t *testing.T, productService *testutils.MockProductService, stockService *testutils.MockStockService) productsv1.ProductServiceClient {
+	apiKeyService := new(testutils.MockApiKeyService)
+	apiKeyService.On("AuthenticateApiKey", mock.Anything, "pmk_abc_reserve").Return(&auth.Principal{Subject: "api-key:abc", Scopes: []auth.Scope{auth.ScopeStockReserve}}, nil)
+	apiKeyService.On("AuthenticateApiKey", mock.Anything, mock.Anything).Return((*auth.Principal)(nil), auth.ErrInvalidApiKey)
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := NewGRPCServer(NewProductServer(productService, stockService, validator.New()), time.Second,
+		grpc.ChainUnaryInterceptor(UnaryAuthInterceptor(auth.NewAuthenticator(nil, apiKeyService))))
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.Nil(t, err, "Expected no error dialing bufconn")
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return productsv1.NewProductServiceClient(conn)
+}
+
+func TestUnaryAuthInterceptor(t *testing.T) {
+
+	reserveRequest := &productsv1.ReserveStockRequest{
+		ReservationId: "sale-1",
+		Items:         []*productsv1.StockItem{{ProductId: 1, Quantity: 1}},
+	}
+
+	t.Run("ReserveStock_Needs_Scope", func(t *testing.T) {
+		stockService := new(testutils.MockStockService)
+		stockService.On("ApplySale", mock.Anything, mock.Anything).Return((*response.StockCompensationEvent)(nil), nil)
+		client := setupAuthenticatedGRPC(t, new(testutils.MockProductService), stockService)
+
+		_, err := client.ReserveStock(context.Background(), reserveRequest)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err), "Expected anonymous reservation to be rejected")
+
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "pmk_abc_unknown")
+		_, err = client.ReserveStock(ctx, reserveRequest)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err), "Expected unknown key to be rejected")
+
+		ctx = metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "pmk_abc_reserve")
+		res, err := client.ReserveStock(ctx, reserveRequest)
+		assert.Nil(t, err, "Expected reservation with stock:reserve to succeed")
+		assert.True(t, res.GetReserved(), "Expected stock to be reserved")
+	})
+
+	t.Run("DeleteProduct_Forbidden_For_ApiKey", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		client := setupAuthenticatedGRPC(t, mockService, new(testutils.MockStockService))
+
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "pmk_abc_reserve")
+		_, err := client.DeleteProduct(ctx, &productsv1.DeleteProductRequest{ProductId: 1})
+
+		assert.Equal(t, codes.PermissionDenied, status.Code(err), "Expected permission denied status")
+		mockService.AssertNotCalled(t, "DeleteProduct", mock.Anything, mock.Anything)
+	})
+
+	t.Run("GetProduct_Is_Public", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		mockService.On("GetProductById", mock.Anything, uint(1)).Return(&response.ProductResponse{ProductID: 1}, nil)
+		client := setupAuthenticatedGRPC(t, mockService, new(testutils.MockStockService))
+
+		_, err := client.GetProduct(context.Background(), &productsv1.GetProductRequest{ProductId: 1})
+		assert.Nil(t, err, "Expected anonymous read to succeed")
+	})
+}
@@ -0,0 +1,41 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dieg0code/products-microservice/src/logging"
+	"github.com/dieg0code/products-microservice/src/repository"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// toStatusError maps service and repository errors to gRPC status codes. Errors
+// that may carry database details are logged and answered with a generic message.
+func toStatusError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, repository.ErrProductNotFound):
+		return status.Error(codes.NotFound, repository.ErrProductNotFound.Error())
+	case errors.Is(err, repository.ErrSaleAlreadyProcessed):
+		return status.Error(codes.AlreadyExists, repository.ErrSaleAlreadyProcessed.Error())
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return status.Error(codes.AlreadyExists, "a product with this name already exists")
+	case errors.As(err, &validationErrors):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	logging.FromContext(ctx).WithError(err).Error("Error handling gRPC request")
+	return status.Error(codes.Internal, "internal error")
+}
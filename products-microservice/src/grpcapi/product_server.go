@@ -0,0 +1,235 @@
+package grpcapi
+
+import (
+	"context"
+
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/logging"
+	"github.com/dieg0code/products-microservice/src/pb/productsv1"
+	"github.com/dieg0code/products-microservice/src/services"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const defaultListPageSize = 100
+
+// ProductServer serves productsv1.ProductService on top of the same services as the REST controllers.
+type ProductServer struct {
+	productsv1.UnimplementedProductServiceServer
+	productService services.ProductService
+	stockService   services.StockService
+	validate       *validator.Validate
+}
+
+// GetProduct implements productsv1.ProductServiceServer.
+func (p *ProductServer) GetProduct(ctx context.Context, req *productsv1.GetProductRequest) (*productsv1.Product, error) {
+	if req.GetProductId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "product_id is required")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, toStatusError(ctx, err)
+	}
+
+	product, err := p.productService.GetProductById(ctx, uint(req.GetProductId()))
+	if err != nil {
+		return nil, toStatusError(ctx, err)
+	}
+
+	return toProductMessage(product), nil
+}
+
+// ListProducts implements productsv1.ProductServiceServer.
+func (p *ProductServer) ListProducts(req *productsv1.ListProductsRequest, stream productsv1.ProductService_ListProductsServer) error {
+	ctx := stream.Context()
+
+	if req.GetCategory() != "" {
+		products, err := p.productService.GetByCategory(ctx, req.GetCategory())
+		if err != nil {
+			return toStatusError(ctx, err)
+		}
+		return sendProducts(ctx, stream, products)
+	}
+
+	pageSize := int(req.GetPageSize())
+	if pageSize <= 0 {
+		pageSize = defaultListPageSize
+	}
+
+	for page := 1; ; page++ {
+		if err := ctx.Err(); err != nil {
+			return toStatusError(ctx, err)
+		}
+
+		products, err := p.productService.GetAllProducts(ctx, page, pageSize)
+		if err != nil {
+			return toStatusError(ctx, err)
+		}
+
+		err = sendProducts(ctx, stream, products)
+		if err != nil {
+			return err
+		}
+
+		if len(products) < pageSize {
+			return nil
+		}
+	}
+}
+
+// CreateProduct implements productsv1.ProductServiceServer.
+func (p *ProductServer) CreateProduct(ctx context.Context, req *productsv1.CreateProductRequest) (*productsv1.Product, error) {
+	createProductRequest := &request.CreateProductRequest{
+		Name:     req.GetName(),
+		Category: req.GetCategory(),
+		Price:    int(req.GetPrice()),
+		Stock:    int(req.GetStock()),
+	}
+
+	err := p.validate.Struct(createProductRequest)
+	if err != nil {
+		return nil, toStatusError(ctx, err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, toStatusError(ctx, err)
+	}
+
+	productID, err := p.productService.CreateProduct(ctx, createProductRequest)
+	if err != nil {
+		return nil, toStatusError(ctx, err)
+	}
+
+	product, err := p.productService.GetProductById(ctx, *productID)
+	if err != nil {
+		return nil, toStatusError(ctx, err)
+	}
+
+	return toProductMessage(product), nil
+}
+
+// UpdateProduct implements productsv1.ProductServiceServer.
+func (p *ProductServer) UpdateProduct(ctx context.Context, req *productsv1.UpdateProductRequest) (*productsv1.Product, error) {
+	if req.GetProductId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "product_id is required")
+	}
+
+	updateProductRequest := &request.UpdateProductRequest{
+		Name:     req.GetName(),
+		Category: req.GetCategory(),
+		Price:    int(req.GetPrice()),
+		Stock:    int(req.GetStock()),
+	}
+
+	err := p.validate.Struct(updateProductRequest)
+	if err != nil {
+		return nil, toStatusError(ctx, err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, toStatusError(ctx, err)
+	}
+
+	product, err := p.productService.UpdateProduct(ctx, uint(req.GetProductId()), updateProductRequest)
+	if err != nil {
+		return nil, toStatusError(ctx, err)
+	}
+
+	return toProductMessage(product), nil
+}
+
+// DeleteProduct implements productsv1.ProductServiceServer.
+func (p *ProductServer) DeleteProduct(ctx context.Context, req *productsv1.DeleteProductRequest) (*productsv1.DeleteProductResponse, error) {
+	if req.GetProductId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "product_id is required")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, toStatusError(ctx, err)
+	}
+
+	err := p.productService.DeleteProduct(ctx, uint(req.GetProductId()))
+	if err != nil {
+		return nil, toStatusError(ctx, err)
+	}
+
+	return &productsv1.DeleteProductResponse{}, nil
+}
+
+// ReserveStock implements productsv1.ProductServiceServer. The reservation ID is
+// recorded as a processed sale, so a rejected reservation can't be retried with it.
+func (p *ProductServer) ReserveStock(ctx context.Context, req *productsv1.ReserveStockRequest) (*productsv1.ReserveStockResponse, error) {
+	sale := &request.SaleCreatedEvent{SaleID: req.GetReservationId()}
+	for _, item := range req.GetItems() {
+		sale.Products = append(sale.Products, request.SaleProductEvent{
+			ProductID: uint(item.GetProductId()),
+			Quantity:  int(item.GetQuantity()),
+		})
+	}
+
+	err := p.validate.Struct(sale)
+	if err != nil {
+		return nil, toStatusError(ctx, err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, toStatusError(ctx, err)
+	}
+
+	compensation, err := p.stockService.ApplySale(ctx, sale)
+	if err != nil {
+		return nil, toStatusError(ctx, err)
+	}
+
+	if compensation == nil {
+		return &productsv1.ReserveStockResponse{Reserved: true}, nil
+	}
+
+	res := &productsv1.ReserveStockResponse{Reserved: false}
+	for _, shortage := range compensation.Shortage {
+		res.Shortages = append(res.Shortages, &productsv1.StockShortage{
+			ProductId: uint32(shortage.ProductID),
+			Requested: int64(shortage.Requested),
+			Available: int64(shortage.Available),
+		})
+	}
+
+	return res, nil
+}
+
+func sendProducts(ctx context.Context, stream productsv1.ProductService_ListProductsServer, products []response.ProductResponse) error {
+	for i := range products {
+		if err := ctx.Err(); err != nil {
+			return toStatusError(ctx, err)
+		}
+
+		err := stream.Send(toProductMessage(&products[i]))
+		if err != nil {
+			logging.FromContext(ctx).WithError(err).Error("Error streaming product")
+			return err
+		}
+	}
+
+	return nil
+}
+
+func toProductMessage(product *response.ProductResponse) *productsv1.Product {
+	return &productsv1.Product{
+		ProductId:  uint32(product.ProductID),
+		Name:       product.Name,
+		Category:   product.Category,
+		Price:      int64(product.Price),
+		Stock:      int64(product.Stock),
+		LastUpdate: product.LastUpdate,
+	}
+}
+
+func NewProductServer(productService services.ProductService, stockService services.StockService, validate *validator.Validate) *ProductServer {
+	return &ProductServer{
+		productService: productService,
+		stockService:   stockService,
+		validate:       validate,
+	}
+}
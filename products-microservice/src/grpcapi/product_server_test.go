@@ -0,0 +1,254 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/pb/productsv1"
+	"github.com/dieg0code/products-microservice/src/repository"
+	"github.com/dieg0code/products-microservice/src/testutils"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"gorm.io/gorm"
+)
+
+func setupGRPC(t *testing.T, productService *testutils.MockProductService, stockService *testutils.MockStockService) *grpc.ClientConn {
+	listener := bufconn.Listen(1024 * 1024)
+
+	server := NewGRPCServer(NewProductServer(productService, stockService, validator.New()), time.Second)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.Nil(t, err, "Expected no error dialing bufconn")
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func TestProductServer(t *testing.T) {
+
+	t.Run("GetProduct_Success", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		client := productsv1.NewProductServiceClient(setupGRPC(t, mockService, new(testutils.MockStockService)))
+
+		mockService.On("GetProductById", mock.Anything, uint(1)).Return(&response.ProductResponse{ProductID: 1, Name: "Product 1", Price: 1000}, nil)
+
+		product, err := client.GetProduct(context.Background(), &productsv1.GetProductRequest{ProductId: 1})
+
+		assert.Nil(t, err, "Expected no error getting product")
+		assert.Equal(t, "Product 1", product.GetName(), "Expected product name to be the same")
+		assert.Equal(t, int64(1000), product.GetPrice(), "Expected product price to be the same")
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("GetProduct_NotFound", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		client := productsv1.NewProductServiceClient(setupGRPC(t, mockService, new(testutils.MockStockService)))
+
+		mockService.On("GetProductById", mock.Anything, uint(1)).Return((*response.ProductResponse)(nil), repository.ErrProductNotFound)
+
+		_, err := client.GetProduct(context.Background(), &productsv1.GetProductRequest{ProductId: 1})
+
+		assert.Equal(t, codes.NotFound, status.Code(err), "Expected not found status")
+	})
+
+	t.Run("GetProduct_Internal_Hides_Cause", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		client := productsv1.NewProductServiceClient(setupGRPC(t, mockService, new(testutils.MockStockService)))
+
+		mockService.On("GetProductById", mock.Anything, uint(1)).Return((*response.ProductResponse)(nil), errors.New("pq: relation \"products\" does not exist"))
+
+		_, err := client.GetProduct(context.Background(), &productsv1.GetProductRequest{ProductId: 1})
+
+		assert.Equal(t, codes.Internal, status.Code(err), "Expected internal status")
+		assert.Equal(t, "internal error", status.Convert(err).Message(), "Expected the database error to stay hidden")
+	})
+
+	t.Run("GetProduct_InvalidArgument", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		client := productsv1.NewProductServiceClient(setupGRPC(t, mockService, new(testutils.MockStockService)))
+
+		_, err := client.GetProduct(context.Background(), &productsv1.GetProductRequest{})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), "Expected invalid argument status")
+		mockService.AssertNotCalled(t, "GetProductById")
+	})
+
+	t.Run("ListProducts_Streams_All_Pages", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		client := productsv1.NewProductServiceClient(setupGRPC(t, mockService, new(testutils.MockStockService)))
+
+		mockService.On("GetAllProducts", mock.Anything, 1, 2).Return([]response.ProductResponse{{ProductID: 1}, {ProductID: 2}}, nil)
+		mockService.On("GetAllProducts", mock.Anything, 2, 2).Return([]response.ProductResponse{{ProductID: 3}}, nil)
+
+		stream, err := client.ListProducts(context.Background(), &productsv1.ListProductsRequest{PageSize: 2})
+		assert.Nil(t, err, "Expected no error listing products")
+
+		var ids []uint32
+		for {
+			product, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			assert.Nil(t, err, "Expected no error receiving product")
+			ids = append(ids, product.GetProductId())
+		}
+
+		assert.Equal(t, []uint32{1, 2, 3}, ids, "Expected every page to be streamed")
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("ListProducts_Deadline_Exceeded", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		client := productsv1.NewProductServiceClient(setupGRPC(t, mockService, new(testutils.MockStockService)))
+
+		mockService.On("GetByCategory", mock.Anything, "slow").Run(func(mock.Arguments) {
+			time.Sleep(100 * time.Millisecond)
+		}).Return([]response.ProductResponse{{ProductID: 1}}, nil)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		stream, err := client.ListProducts(ctx, &productsv1.ListProductsRequest{Category: "slow"})
+		assert.Nil(t, err, "Expected no error opening stream")
+
+		_, err = stream.Recv()
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err), "Expected deadline exceeded status")
+	})
+
+	t.Run("ListProducts_No_Default_Deadline", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		client := productsv1.NewProductServiceClient(setupGRPC(t, mockService, new(testutils.MockStockService)))
+
+		noDeadline := mock.MatchedBy(func(ctx context.Context) bool {
+			_, ok := ctx.Deadline()
+			return !ok
+		})
+		mockService.On("GetByCategory", noDeadline, "books").Return([]response.ProductResponse{{ProductID: 1}}, nil)
+
+		stream, err := client.ListProducts(context.Background(), &productsv1.ListProductsRequest{Category: "books"})
+		assert.Nil(t, err, "Expected no error opening stream")
+
+		product, err := stream.Recv()
+		assert.Nil(t, err, "Expected the stream to run without the unary deadline")
+		assert.Equal(t, uint32(1), product.GetProductId(), "Expected the product")
+	})
+
+	t.Run("CreateProduct_InvalidArgument", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		client := productsv1.NewProductServiceClient(setupGRPC(t, mockService, new(testutils.MockStockService)))
+
+		_, err := client.CreateProduct(context.Background(), &productsv1.CreateProductRequest{Name: "Product 1"})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), "Expected invalid argument status")
+		mockService.AssertNotCalled(t, "CreateProduct")
+	})
+
+	t.Run("CreateProduct_Duplicate_Name", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		client := productsv1.NewProductServiceClient(setupGRPC(t, mockService, new(testutils.MockStockService)))
+
+		mockService.On("CreateProduct", mock.Anything, mock.Anything).Return((*uint)(nil), fmt.Errorf("ERROR: duplicate key value violates unique constraint \"idx_products_tenant_name\": %w", gorm.ErrDuplicatedKey))
+
+		_, err := client.CreateProduct(context.Background(), &productsv1.CreateProductRequest{Name: "Product 1", Category: "Category 1", Price: 1000, Stock: 10})
+
+		assert.Equal(t, codes.AlreadyExists, status.Code(err), "Expected already exists status")
+		assert.NotContains(t, status.Convert(err).Message(), "idx_products_tenant_name", "Expected the constraint name to stay hidden")
+	})
+
+	t.Run("CreateProduct_Success", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		client := productsv1.NewProductServiceClient(setupGRPC(t, mockService, new(testutils.MockStockService)))
+
+		productID := uint(7)
+		mockService.On("CreateProduct", mock.Anything, &request.CreateProductRequest{Name: "Product 1", Category: "Category 1", Price: 1000, Stock: 10}).Return(&productID, nil)
+		mockService.On("GetProductById", mock.Anything, uint(7)).Return(&response.ProductResponse{ProductID: 7, Name: "Product 1"}, nil)
+
+		product, err := client.CreateProduct(context.Background(), &productsv1.CreateProductRequest{Name: "Product 1", Category: "Category 1", Price: 1000, Stock: 10})
+
+		assert.Nil(t, err, "Expected no error creating product")
+		assert.Equal(t, uint32(7), product.GetProductId(), "Expected created product to be returned")
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("ReserveStock_Shortage", func(t *testing.T) {
+		stockService := new(testutils.MockStockService)
+		client := productsv1.NewProductServiceClient(setupGRPC(t, new(testutils.MockProductService), stockService))
+
+		stockService.On("ApplySale", mock.Anything, &request.SaleCreatedEvent{
+			SaleID:   "reservation-1",
+			Products: []request.SaleProductEvent{{ProductID: 1, Quantity: 5}},
+		}).Return(&response.StockCompensationEvent{
+			SaleID:   "reservation-1",
+			Shortage: []response.StockShortageResponse{{ProductID: 1, Requested: 5, Available: 2}},
+		}, nil)
+
+		res, err := client.ReserveStock(context.Background(), &productsv1.ReserveStockRequest{
+			ReservationId: "reservation-1",
+			Items:         []*productsv1.StockItem{{ProductId: 1, Quantity: 5}},
+		})
+
+		assert.Nil(t, err, "Expected no error reserving stock")
+		assert.False(t, res.GetReserved(), "Expected reservation to be rejected")
+		assert.Equal(t, int64(2), res.GetShortages()[0].GetAvailable(), "Expected shortage to be reported")
+		stockService.AssertExpectations(t)
+	})
+
+	t.Run("ReserveStock_Already_Processed", func(t *testing.T) {
+		stockService := new(testutils.MockStockService)
+		client := productsv1.NewProductServiceClient(setupGRPC(t, new(testutils.MockProductService), stockService))
+
+		stockService.On("ApplySale", mock.Anything, mock.Anything).Return((*response.StockCompensationEvent)(nil), repository.ErrSaleAlreadyProcessed)
+
+		_, err := client.ReserveStock(context.Background(), &productsv1.ReserveStockRequest{
+			ReservationId: "reservation-1",
+			Items:         []*productsv1.StockItem{{ProductId: 1, Quantity: 1}},
+		})
+
+		assert.Equal(t, codes.AlreadyExists, status.Code(err), "Expected already exists status")
+	})
+
+	t.Run("Reflection_Lists_Service", func(t *testing.T) {
+		conn := setupGRPC(t, new(testutils.MockProductService), new(testutils.MockStockService))
+
+		stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+		assert.Nil(t, err, "Expected no error opening reflection stream")
+
+		err = stream.Send(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+		})
+		assert.Nil(t, err, "Expected no error sending reflection request")
+
+		res, err := stream.Recv()
+		assert.Nil(t, err, "Expected no error receiving reflection response")
+
+		var services []string
+		for _, service := range res.GetListServicesResponse().GetService() {
+			services = append(services, service.GetName())
+		}
+		assert.Contains(t, services, "products.v1.ProductService", "Expected product service to be listed")
+	})
+}
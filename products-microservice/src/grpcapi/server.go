@@ -0,0 +1,50 @@
+package grpcapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/pb/productsv1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+const DefaultRequestTimeout = 10 * time.Second
+
+// NewGRPCServer registers the product server with reflection enabled. Unary calls
+// without a client deadline get timeout applied to them. Streams have no default
+// deadline, like the HTTP event stream, they last as long as the client wants.
+func NewGRPCServer(productServer productsv1.ProductServiceServer, timeout time.Duration, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts, grpc.ChainUnaryInterceptor(unaryTimeoutInterceptor(timeout)))
+
+	server := grpc.NewServer(opts...)
+	productsv1.RegisterProductServiceServer(server, productServer)
+	reflection.Register(server)
+
+	return server
+}
+
+func withDefaultDeadline(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+func unaryTimeoutInterceptor(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, cancel := withDefaultDeadline(ctx, timeout)
+		defer cancel()
+
+		return handler(ctx, req)
+	}
+}
+
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
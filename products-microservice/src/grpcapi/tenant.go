@@ -0,0 +1,61 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/dieg0code/products-microservice/src/auth"
+	"github.com/dieg0code/products-microservice/src/tenant"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryTenantInterceptor resolves the tenant from the principal's tenant claim or the
+// x-tenant-id metadata like the REST API does, it must run after UnaryAuthInterceptor
+func UnaryTenantInterceptor(defaultTenantID string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := resolveTenant(ctx, defaultTenantID)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamTenantInterceptor is the streaming counterpart of UnaryTenantInterceptor
+func StreamTenantInterceptor(defaultTenantID string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := resolveTenant(stream.Context(), defaultTenantID)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &contextServerStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+func resolveTenant(ctx context.Context, defaultTenantID string) (context.Context, error) {
+	requested := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(strings.ToLower(tenant.HeaderName)); len(values) > 0 {
+			requested = values[0]
+		}
+	}
+
+	tenantID, err := tenant.Resolve("", requested, defaultTenantID)
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		tenantID, err = tenant.ResolveAuthenticated(principal.TenantID, requested, defaultTenantID)
+	}
+	if errors.Is(err, tenant.ErrTenantMismatch) {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return tenant.WithTenant(ctx, tenantID), nil
+}
@@ -0,0 +1,54 @@
+package grpcapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dieg0code/products-microservice/src/auth"
+	"github.com/dieg0code/products-microservice/src/tenant"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestTenantInterceptor(t *testing.T) {
+	interceptor := UnaryTenantInterceptor(tenant.DefaultTenantID)
+	info := &grpc.UnaryServerInfo{FullMethod: "/products.v1.ProductService/GetProduct"}
+
+	call := func(ctx context.Context) (string, error) {
+		res, err := interceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			tenantID, _ := tenant.FromContext(ctx)
+			return tenantID, nil
+		})
+		if err != nil {
+			return "", err
+		}
+		return res.(string), nil
+	}
+
+	t.Run("Resolves_From_Metadata", func(t *testing.T) {
+		tenantID, err := call(metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-tenant-id", "acme")))
+		assert.Nil(t, err, "Expected no error")
+		assert.Equal(t, "acme", tenantID, "Expected tenant from metadata")
+
+		tenantID, err = call(context.Background())
+		assert.Nil(t, err, "Expected no error")
+		assert.Equal(t, tenant.DefaultTenantID, tenantID, "Expected default tenant")
+	})
+
+	t.Run("Rejects_Other_Tenant", func(t *testing.T) {
+		ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "user-1", TenantID: "acme"})
+
+		_, err := call(metadata.NewIncomingContext(ctx, metadata.Pairs("x-tenant-id", "globex")))
+		assert.Equal(t, codes.PermissionDenied, status.Code(err), "Expected permission denied")
+
+		unbound := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "user-1"})
+		_, err = call(metadata.NewIncomingContext(unbound, metadata.Pairs("x-tenant-id", "globex")))
+		assert.Equal(t, codes.PermissionDenied, status.Code(err), "Expected callers without a tenant to stay in the default tenant")
+
+		_, err = call(metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-tenant-id", "Not_Valid")))
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), "Expected invalid argument")
+	})
+}
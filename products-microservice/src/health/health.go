@@ -0,0 +1,186 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	DefaultCheckTimeout = 2 * time.Second
+	DefaultCacheTTL     = 2 * time.Second
+)
+
+type Status string
+
+const (
+	StatusUp Status = "up"
+	// StatusDegraded means an optional dependency failed, the process still serves requests
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+)
+
+// Checker reports whether a dependency works, it must give up once ctx is done
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc lets a plain function be used as a Checker
+type CheckerFunc func(ctx context.Context) error
+
+// Check implements Checker.
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+type ComponentReport struct {
+	Status     Status `json:"status"`
+	Required   bool   `json:"required"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+type Report struct {
+	Status     Status                     `json:"status"`
+	Components map[string]ComponentReport `json:"components"`
+	CheckedAt  time.Time                  `json:"checked_at"`
+}
+
+type Config struct {
+	// CheckTimeout bounds every check, a check that takes longer fails
+	CheckTimeout time.Duration
+	// CacheTTL reuses the last report for this long, so frequent probes don't load the dependencies
+	CacheTTL time.Duration
+}
+
+type component struct {
+	name     string
+	checker  Checker
+	required bool
+}
+
+// Health checks the registered dependencies for the readiness probe and tracks
+// whether startup work such as migrations is done for the startup probe.
+type Health struct {
+	config  Config
+	started atomic.Bool
+
+	mu         sync.Mutex
+	components []component
+	last       *Report
+
+	group singleflight.Group
+}
+
+// Register adds a dependency the process can't serve without, its failure fails readiness
+func (h *Health) Register(name string, checker Checker) {
+	h.register(component{name: name, checker: checker, required: true})
+}
+
+// RegisterOptional adds a dependency the process can do without, its failure only
+// degrades the report
+func (h *Health) RegisterOptional(name string, checker Checker) {
+	h.register(component{name: name, checker: checker})
+}
+
+func (h *Health) register(c component) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.components = append(h.components, c)
+	h.last = nil
+}
+
+// MarkStarted makes the startup probe pass
+func (h *Health) MarkStarted() {
+	h.started.Store(true)
+}
+
+// Started reports whether MarkStarted was called
+func (h *Health) Started() bool {
+	return h.started.Load()
+}
+
+// Check runs every check concurrently, or returns the last report while it is fresh.
+// Concurrent callers share a single run.
+func (h *Health) Check(ctx context.Context) Report {
+	h.mu.Lock()
+	last := h.last
+	h.mu.Unlock()
+	if last != nil && time.Since(last.CheckedAt) < h.config.CacheTTL {
+		return *last
+	}
+
+	// The run is shared, so it must not be cancelled because the first caller went away
+	result, _, _ := h.group.Do("check", func() (interface{}, error) {
+		report := h.run(context.WithoutCancel(ctx))
+
+		h.mu.Lock()
+		h.last = &report
+		h.mu.Unlock()
+		return report, nil
+	})
+	return result.(Report)
+}
+
+func (h *Health) run(ctx context.Context) Report {
+	h.mu.Lock()
+	components := append([]component(nil), h.components...)
+	h.mu.Unlock()
+
+	reports := make([]ComponentReport, len(components))
+	var wg sync.WaitGroup
+	for i, c := range components {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reports[i] = h.check(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, Components: make(map[string]ComponentReport, len(components)), CheckedAt: time.Now()}
+	for i, c := range components {
+		report.Components[c.name] = reports[i]
+		if reports[i].Status == StatusUp {
+			continue
+		}
+		if c.required {
+			report.Status = StatusDown
+		} else if report.Status == StatusUp {
+			report.Status = StatusDegraded
+		}
+	}
+
+	return report
+}
+
+func (h *Health) check(ctx context.Context, c component) ComponentReport {
+	ctx, cancel := context.WithTimeout(ctx, h.config.CheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.checker.Check(ctx)
+	report := ComponentReport{Status: StatusUp, Required: c.required, DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		logrus.WithError(err).WithField("component", c.name).Warn("Health check failed")
+		report.Status = StatusDown
+		report.Error = err.Error()
+	}
+	return report
+}
+
+func NewHealth(config Config) *Health {
+	if config.CheckTimeout <= 0 {
+		config.CheckTimeout = DefaultCheckTimeout
+	}
+	if config.CacheTTL < 0 {
+		config.CacheTTL = 0
+	}
+
+	return &Health{config: config}
+}
@@ -0,0 +1,88 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth(t *testing.T) {
+	up := CheckerFunc(func(ctx context.Context) error { return nil })
+	down := CheckerFunc(func(ctx context.Context) error { return errors.New("connection refused") })
+
+	t.Run("Check_Up", func(t *testing.T) {
+		h := NewHealth(Config{})
+		h.Register("database", up)
+		h.RegisterOptional("redis", up)
+
+		report := h.Check(context.Background())
+		assert.Equal(t, StatusUp, report.Status, "Expected status up")
+		assert.Equal(t, StatusUp, report.Components["database"].Status, "Expected the database to be up")
+		assert.True(t, report.Components["database"].Required, "Expected the database to be required")
+		assert.False(t, report.Components["redis"].Required, "Expected redis to be optional")
+	})
+
+	t.Run("Check_Required_Down", func(t *testing.T) {
+		h := NewHealth(Config{})
+		h.Register("database", down)
+		h.RegisterOptional("redis", up)
+
+		report := h.Check(context.Background())
+		assert.Equal(t, StatusDown, report.Status, "Expected status down")
+		assert.Equal(t, "connection refused", report.Components["database"].Error, "Expected the check error")
+	})
+
+	t.Run("Check_Optional_Down", func(t *testing.T) {
+		h := NewHealth(Config{})
+		h.Register("database", up)
+		h.RegisterOptional("redis", down)
+
+		report := h.Check(context.Background())
+		assert.Equal(t, StatusDegraded, report.Status, "Expected status degraded")
+		assert.Equal(t, StatusDown, report.Components["redis"].Status, "Expected redis to be down")
+	})
+
+	t.Run("Check_Timeout", func(t *testing.T) {
+		h := NewHealth(Config{CheckTimeout: 20 * time.Millisecond})
+		h.Register("database", CheckerFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}))
+
+		start := time.Now()
+		report := h.Check(context.Background())
+		assert.Less(t, time.Since(start), time.Second, "Expected the check to be bounded")
+		assert.Equal(t, StatusDown, report.Status, "Expected status down")
+		assert.Equal(t, context.DeadlineExceeded.Error(), report.Components["database"].Error, "Expected a deadline error")
+	})
+
+	t.Run("Check_Caches_Report", func(t *testing.T) {
+		var calls atomic.Int32
+		h := NewHealth(Config{CacheTTL: time.Hour})
+		h.Register("database", CheckerFunc(func(ctx context.Context) error {
+			calls.Add(1)
+			return nil
+		}))
+
+		h.Check(context.Background())
+		h.Check(context.Background())
+		assert.Equal(t, int32(1), calls.Load(), "Expected the second probe to reuse the report")
+
+		h.RegisterOptional("redis", up)
+		report := h.Check(context.Background())
+		assert.Equal(t, int32(2), calls.Load(), "Expected registering a component to invalidate the report")
+		assert.Contains(t, report.Components, "redis", "Expected the new component")
+	})
+
+	t.Run("Started", func(t *testing.T) {
+		h := NewHealth(Config{})
+		assert.False(t, h.Started(), "Expected not started")
+
+		h.MarkStarted()
+		assert.True(t, h.Started(), "Expected started")
+	})
+}
@@ -0,0 +1,6 @@
+package request
+
+// AdjustStockRequest struct, a negative Delta removes stock
+type AdjustStockRequest struct {
+	Delta int `json:"delta" validate:"required"`
+}
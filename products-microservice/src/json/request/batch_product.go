@@ -0,0 +1,28 @@
+package request
+
+const (
+	BatchModeAllOrNothing = "all_or_nothing"
+	BatchModeBestEffort   = "best_effort"
+
+	BatchOpCreate = "create"
+	BatchOpUpdate = "update"
+	BatchOpDelete = "delete"
+)
+
+// BatchGetProductsRequest struct
+type BatchGetProductsRequest struct {
+	ProductIDs []uint `json:"product_ids" validate:"required,min=1,max=100,dive,min=1"`
+}
+
+// BatchProductRequest struct
+type BatchProductRequest struct {
+	Mode       string                  `json:"mode" validate:"omitempty,oneof=all_or_nothing best_effort"`
+	Operations []BatchProductOperation `json:"operations" validate:"required,min=1,max=100,dive"`
+}
+
+// BatchProductOperation struct, Product is ignored for deletes
+type BatchProductOperation struct {
+	Op        string                `json:"op" validate:"required,oneof=create update delete"`
+	ProductID uint                  `json:"product_id" validate:"required_unless=Op create"`
+	Product   *CreateProductRequest `json:"product" validate:"required_unless=Op delete"`
+}
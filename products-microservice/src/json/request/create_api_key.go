@@ -0,0 +1,10 @@
+package request
+
+import "time"
+
+// CreateApiKeyRequest struct
+type CreateApiKeyRequest struct {
+	Name      string     `json:"name" validate:"required,min=1,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,oneof=products:read products:write stock:reserve"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
@@ -0,0 +1,10 @@
+package request
+
+// ProductFilter struct
+type ProductFilter struct {
+	Category     string `json:"category"`
+	NameContains string `json:"name_contains"`
+	MinPrice     *int   `json:"min_price" validate:"omitempty,min=0"`
+	MaxPrice     *int   `json:"max_price" validate:"omitempty,min=0"`
+	InStock      *bool  `json:"in_stock"`
+}
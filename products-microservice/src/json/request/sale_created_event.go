@@ -0,0 +1,19 @@
+package request
+
+// SaleCreatedEvent is published by the sales microservice after a sale is stored
+type SaleCreatedEvent struct {
+	SaleID      string             `json:"saleID" validate:"required"`
+	TenantID    string             `json:"tenantID,omitempty"`
+	UserID      uint               `json:"userID"`
+	Products    []SaleProductEvent `json:"products" validate:"required,min=1,dive"`
+	TotalAmount int                `json:"totalAmount"`
+	CreatedAt   string             `json:"createdAt"`
+}
+
+// SaleProductEvent struct
+type SaleProductEvent struct {
+	ProductID uint   `json:"productID" validate:"required"`
+	Name      string `json:"name"`
+	Price     int    `json:"price"`
+	Quantity  int    `json:"quantity" validate:"required,min=1"`
+}
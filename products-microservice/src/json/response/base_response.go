@@ -5,4 +5,30 @@ type BaseResponse struct {
 	Status string      `json:"status"`
 	Msg    string      `json:"msg"`
 	Data   interface{} `json:"data"`
+	// Violations lists where a request breaks the OpenAPI document
+	Violations []ContractViolation `json:"violations,omitempty"`
+	// Errors lists the fields of a request body that failed validation
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError is a field that failed a validation rule, with a message in the
+// language the client asked for
+type FieldError struct {
+	// Field is the path to the field by its JSON names, e.g. operations[0].op
+	Field string `json:"field"`
+	// Rule is the validate tag that failed, e.g. required or min
+	Rule string `json:"rule"`
+	// Param is the parameter of the rule, e.g. 1 for min=1, empty when it has none
+	Param   string `json:"param"`
+	Message string `json:"message"`
+}
+
+// ContractViolation is a value that doesn't match the OpenAPI document
+type ContractViolation struct {
+	// In is path, query, header, body or response
+	In string `json:"in"`
+	// Pointer is the JSON pointer to the value, or the name of a parameter
+	Pointer string `json:"pointer"`
+	Keyword string `json:"keyword"`
+	Message string `json:"message"`
 }
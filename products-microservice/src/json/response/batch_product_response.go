@@ -0,0 +1,29 @@
+package response
+
+const (
+	BatchStatusSucceeded  = "succeeded"
+	BatchStatusFailed     = "failed"
+	BatchStatusRolledBack = "rolled_back"
+	BatchStatusSkipped    = "skipped"
+)
+
+type BatchGetProductsResponse struct {
+	Products   []ProductResponse `json:"products"`
+	MissingIDs []uint            `json:"missing_ids"`
+}
+
+type BatchProductResponse struct {
+	Mode      string                 `json:"mode"`
+	Succeeded int                    `json:"succeeded"`
+	Failed    int                    `json:"failed"`
+	Results   []BatchOperationResult `json:"results"`
+}
+
+type BatchOperationResult struct {
+	Index     int              `json:"index"`
+	Op        string           `json:"op"`
+	ProductID uint             `json:"product_id,omitempty"`
+	Status    string           `json:"status"`
+	Error     string           `json:"error,omitempty"`
+	Product   *ProductResponse `json:"product,omitempty"`
+}
@@ -1,5 +1,7 @@
 package response
 
+import "time"
+
 type ProductResponse struct {
 	ProductID  uint   `json:"product_id"`
 	Name       string `json:"name"`
@@ -7,4 +9,6 @@ type ProductResponse struct {
 	Price      int    `json:"price"`
 	Stock      int    `json:"stock"`
 	LastUpdate string `json:"last_update"`
+	// UpdatedAt backs the Last-Modified header, LastUpdate only has day precision
+	UpdatedAt time.Time `json:"-"`
 }
@@ -0,0 +1,14 @@
+package response
+
+// StockCompensationEvent is emitted when a sale could not be applied to stock
+type StockCompensationEvent struct {
+	SaleID   string                  `json:"saleID"`
+	Reason   string                  `json:"reason"`
+	Shortage []StockShortageResponse `json:"shortage"`
+}
+
+type StockShortageResponse struct {
+	ProductID uint `json:"productID"`
+	Requested int  `json:"requested"`
+	Available int  `json:"available"`
+}
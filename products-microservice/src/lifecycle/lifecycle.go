@@ -0,0 +1,183 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultDrainPeriod     = 5 * time.Second
+	DefaultShutdownTimeout = 30 * time.Second
+)
+
+type Config struct {
+	// DrainPeriod keeps serving after readiness starts failing, so load balancers stop
+	// sending new requests before the listeners close
+	DrainPeriod time.Duration
+	// ShutdownTimeout bounds how long in-flight requests, workers and closers may take
+	ShutdownTimeout time.Duration
+}
+
+type hook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Manager runs the servers and background workers of the process and stops them in
+// order: servers first so in-flight requests finish, then workers, then closers such
+// as database connections.
+type Manager struct {
+	config Config
+	ready  atomic.Bool
+
+	mu        sync.Mutex
+	shutdowns []hook
+	closers   []hook
+
+	workers       sync.WaitGroup
+	workersCtx    context.Context
+	cancelWorkers context.CancelFunc
+
+	// failures receives the first server that stopped on its own
+	failures chan error
+}
+
+// Ready reports whether the process accepts new requests, it turns false as soon as
+// shutdown starts
+func (m *Manager) Ready() bool {
+	return m.ready.Load()
+}
+
+// Serve runs serve in the background and calls shutdown when the process stops. A
+// server that stops on its own shuts the whole process down.
+func (m *Manager) Serve(name string, serve func() error, shutdown func(ctx context.Context) error) {
+	m.mu.Lock()
+	m.shutdowns = append(m.shutdowns, hook{name: name, fn: shutdown})
+	m.mu.Unlock()
+
+	go func() {
+		err := serve()
+		if errors.Is(err, http.ErrServerClosed) {
+			return
+		}
+		if err == nil {
+			err = errors.New("stopped unexpectedly")
+		}
+
+		select {
+		case m.failures <- fmt.Errorf("%s: %w", name, err):
+		default:
+		}
+	}()
+}
+
+// Go runs a background worker until shutdown cancels its context, shutdown waits
+// for it to return
+func (m *Manager) Go(name string, worker func(ctx context.Context)) {
+	m.workers.Add(1)
+	go func() {
+		defer m.workers.Done()
+		worker(m.workersCtx)
+		logrus.WithField("worker", name).Debug("Worker stopped")
+	}()
+}
+
+// OnClose registers a resource to release once servers and workers are stopped,
+// closers run in reverse order like deferred calls
+func (m *Manager) OnClose(name string, close func(ctx context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.closers = append(m.closers, hook{name: name, fn: close})
+}
+
+// Run marks the process ready and blocks until ctx is done or a server fails, then
+// shuts everything down. The returned error joins the failure and every shutdown error.
+func (m *Manager) Run(ctx context.Context) error {
+	m.ready.Store(true)
+
+	var failure error
+	select {
+	case <-ctx.Done():
+		logrus.Info("Shutdown signal received, draining connections")
+	case failure = <-m.failures:
+		logrus.WithError(failure).Error("Server failed, shutting down")
+	}
+
+	m.ready.Store(false)
+
+	// A failed server can't be drained, and the others would only delay the restart
+	if failure == nil && m.config.DrainPeriod > 0 {
+		time.Sleep(m.config.DrainPeriod)
+	}
+
+	return errors.Join(failure, m.shutdown())
+}
+
+func (m *Manager) shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), m.config.ShutdownTimeout)
+	defer cancel()
+
+	m.mu.Lock()
+	shutdowns := append([]hook(nil), m.shutdowns...)
+	closers := append([]hook(nil), m.closers...)
+	m.mu.Unlock()
+
+	var errs []error
+	for _, server := range shutdowns {
+		errs = append(errs, run(ctx, server))
+	}
+
+	m.cancelWorkers()
+	stopped := make(chan struct{})
+	go func() {
+		m.workers.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("workers: %w", ctx.Err()))
+	}
+
+	for i := len(closers) - 1; i >= 0; i-- {
+		errs = append(errs, run(ctx, closers[i]))
+	}
+
+	logrus.Info("Shutdown complete")
+	return errors.Join(errs...)
+}
+
+func run(ctx context.Context, h hook) error {
+	err := h.fn(ctx)
+	if err != nil {
+		logrus.WithError(err).WithField("component", h.name).Error("Error during shutdown")
+		return fmt.Errorf("%s: %w", h.name, err)
+	}
+	logrus.WithField("component", h.name).Debug("Stopped")
+	return nil
+}
+
+func NewManager(config Config) *Manager {
+	if config.DrainPeriod < 0 {
+		config.DrainPeriod = 0
+	}
+	if config.ShutdownTimeout <= 0 {
+		config.ShutdownTimeout = DefaultShutdownTimeout
+	}
+
+	workersCtx, cancelWorkers := context.WithCancel(context.Background())
+	return &Manager{
+		config:        config,
+		workersCtx:    workersCtx,
+		cancelWorkers: cancelWorkers,
+		failures:      make(chan error, 1),
+	}
+}
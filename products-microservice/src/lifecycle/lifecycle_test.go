@@ -0,0 +1,158 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManager(t *testing.T) {
+	t.Run("Run_Drains_On_Signal", func(t *testing.T) {
+		manager := NewManager(Config{DrainPeriod: 50 * time.Millisecond, ShutdownTimeout: 5 * time.Second})
+
+		var mu sync.Mutex
+		var stopped []string
+		record := func(name string) {
+			mu.Lock()
+			defer mu.Unlock()
+			stopped = append(stopped, name)
+		}
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		mux := http.NewServeMux()
+		mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+			_, _ = w.Write([]byte("done"))
+		})
+		mux.HandleFunc("/ready", func(w http.ResponseWriter, r *http.Request) {
+			if !manager.Ready() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		})
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.Nil(t, err, "Expected no error listening")
+		baseURL := "http://" + listener.Addr().String()
+
+		server := &http.Server{Handler: mux}
+		manager.Serve("http", func() error { return server.Serve(listener) }, func(ctx context.Context) error {
+			err := server.Shutdown(ctx)
+			record("http")
+			return err
+		})
+		manager.Go("worker", func(ctx context.Context) {
+			<-ctx.Done()
+			record("worker")
+		})
+		manager.OnClose("tracer", func(ctx context.Context) error {
+			record("tracer")
+			return nil
+		})
+		manager.OnClose("db", func(ctx context.Context) error {
+			record("db")
+			return nil
+		})
+
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+		defer stop()
+
+		runErr := make(chan error, 1)
+		go func() {
+			runErr <- manager.Run(ctx)
+		}()
+
+		type result struct {
+			status int
+			body   string
+			err    error
+		}
+		slow := make(chan result, 1)
+		go func() {
+			res, err := http.Get(baseURL + "/slow")
+			if err != nil {
+				slow <- result{err: err}
+				return
+			}
+			defer res.Body.Close()
+			body, err := io.ReadAll(res.Body)
+			slow <- result{status: res.StatusCode, body: string(body), err: err}
+		}()
+		<-started
+
+		assert.Eventually(t, manager.Ready, time.Second, 5*time.Millisecond, "Expected the manager to be ready")
+		assert.Nil(t, syscall.Kill(os.Getpid(), syscall.SIGTERM), "Expected no error sending the signal")
+
+		assert.Eventually(t, func() bool {
+			res, err := http.Get(baseURL + "/ready")
+			if err != nil {
+				return false
+			}
+			res.Body.Close()
+			return res.StatusCode == http.StatusServiceUnavailable
+		}, time.Second, 5*time.Millisecond, "Expected readiness to fail while draining")
+
+		close(release)
+
+		res := <-slow
+		assert.Nil(t, res.err, "Expected the in-flight request to finish")
+		assert.Equal(t, http.StatusOK, res.status, "Expected status code 200")
+		assert.Equal(t, "done", res.body, "Expected the full response body")
+
+		select {
+		case err := <-runErr:
+			assert.Nil(t, err, "Expected a clean shutdown")
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected Run to return after shutdown")
+		}
+
+		assert.Equal(t, []string{"http", "worker", "db", "tracer"}, stopped, "Expected servers, then workers, then closers in reverse order")
+
+		_, err = http.Get(baseURL + "/ready")
+		assert.NotNil(t, err, "Expected the listener to be closed")
+	})
+
+	t.Run("Run_Server_Failure", func(t *testing.T) {
+		manager := NewManager(Config{DrainPeriod: time.Hour})
+
+		closed := false
+		manager.Serve("grpc", func() error { return errors.New("listener closed") }, func(ctx context.Context) error {
+			return nil
+		})
+		manager.OnClose("db", func(ctx context.Context) error {
+			closed = true
+			return nil
+		})
+
+		err := manager.Run(context.Background())
+		assert.ErrorContains(t, err, "grpc: listener closed", "Expected the server failure")
+		assert.True(t, closed, "Expected closers to run")
+		assert.False(t, manager.Ready(), "Expected the manager not to be ready")
+	})
+
+	t.Run("Run_Shutdown_Errors", func(t *testing.T) {
+		manager := NewManager(Config{})
+
+		closeErr := errors.New("close failed")
+		manager.OnClose("db", func(ctx context.Context) error {
+			return closeErr
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := manager.Run(ctx)
+		assert.ErrorIs(t, err, closeErr, "Expected the closer error")
+	})
+}
@@ -0,0 +1,91 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+var ErrInvalidFormat = errors.New("invalid log format")
+
+// Caller supplied request IDs are kept when they are short and safe to log as is
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// ValidRequestID reports whether a request ID from a caller can be propagated
+func ValidRequestID(requestID string) bool {
+	return requestIDPattern.MatchString(requestID)
+}
+
+// NewRequestID returns 16 random bytes in hex
+func NewRequestID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+type loggerKey struct{}
+type requestIDKey struct{}
+
+// WithLogger attaches a logger to the context, callers add their fields to the logger
+// from FromContext and attach the result again
+func WithLogger(ctx context.Context, logger *logrus.Entry) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger attached to ctx, or the standard logger when there is
+// none. The entry is bound to ctx so hooks can read the context, e.g. the trace IDs.
+func FromContext(ctx context.Context) *logrus.Entry {
+	logger, ok := ctx.Value(loggerKey{}).(*logrus.Entry)
+	if !ok {
+		return logrus.WithContext(ctx)
+	}
+	return logger.WithContext(ctx)
+}
+
+// WithFields attaches a logger with the fields added to the one already in ctx
+func WithFields(ctx context.Context, fields logrus.Fields) context.Context {
+	return WithLogger(ctx, FromContext(ctx).WithFields(fields))
+}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(requestIDKey{}).(string)
+	return requestID, ok && requestID != ""
+}
+
+// Configure sets the level (panic to trace) and the format (json or text) of the
+// standard logger
+func Configure(level string, format string) error {
+	if level != "" {
+		parsed, err := logrus.ParseLevel(level)
+		if err != nil {
+			return err
+		}
+		logrus.SetLevel(parsed)
+	}
+
+	switch format {
+	case "", FormatText:
+		logrus.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	case FormatJSON:
+		logrus.SetFormatter(&logrus.JSONFormatter{})
+	default:
+		return fmt.Errorf("%w %q", ErrInvalidFormat, format)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogging(t *testing.T) {
+	t.Run("FromContext_Without_Logger", func(t *testing.T) {
+		ctx := context.Background()
+		entry := FromContext(ctx)
+
+		assert.Equal(t, logrus.StandardLogger(), entry.Logger, "Expected the standard logger")
+		assert.Equal(t, ctx, entry.Context, "Expected the entry to be bound to the context")
+	})
+
+	t.Run("WithFields_Accumulates", func(t *testing.T) {
+		logger, hook := test.NewNullLogger()
+
+		ctx := WithLogger(context.Background(), logrus.NewEntry(logger))
+		ctx = WithFields(ctx, logrus.Fields{"request_id": "abc"})
+		ctx = WithFields(ctx, logrus.Fields{"tenant_id": "acme"})
+		FromContext(ctx).Info("handled")
+
+		entry := hook.LastEntry()
+		assert.Equal(t, "abc", entry.Data["request_id"], "Expected the request id")
+		assert.Equal(t, "acme", entry.Data["tenant_id"], "Expected the tenant id")
+		assert.Equal(t, ctx, entry.Context, "Expected the entry to be bound to the latest context")
+	})
+
+	t.Run("RequestID_Context", func(t *testing.T) {
+		_, ok := RequestIDFromContext(context.Background())
+		assert.False(t, ok, "Expected no request id")
+
+		requestID, ok := RequestIDFromContext(WithRequestID(context.Background(), "abc"))
+		assert.True(t, ok, "Expected a request id")
+		assert.Equal(t, "abc", requestID, "Expected the request id")
+	})
+
+	t.Run("ValidRequestID", func(t *testing.T) {
+		assert.True(t, ValidRequestID("4bf92f35-77b3-4da6"), "Expected a uuid like id to be valid")
+		assert.True(t, ValidRequestID(NewRequestID()), "Expected generated ids to be valid")
+		assert.False(t, ValidRequestID(""), "Expected an empty id to be invalid")
+		assert.False(t, ValidRequestID("abc\ninjected=1"), "Expected new lines to be invalid")
+		assert.False(t, ValidRequestID(string(make([]byte, 129))), "Expected long ids to be invalid")
+	})
+
+	t.Run("Configure", func(t *testing.T) {
+		level, formatter := logrus.GetLevel(), logrus.StandardLogger().Formatter
+		defer func() {
+			logrus.SetLevel(level)
+			logrus.SetFormatter(formatter)
+		}()
+
+		err := Configure("debug", FormatJSON)
+		assert.Nil(t, err, "Expected no error")
+		assert.Equal(t, logrus.DebugLevel, logrus.GetLevel(), "Expected the debug level")
+		assert.IsType(t, &logrus.JSONFormatter{}, logrus.StandardLogger().Formatter, "Expected the JSON formatter")
+
+		err = Configure("loud", FormatText)
+		assert.NotNil(t, err, "Expected an error for an unknown level")
+
+		err = Configure("info", "xml")
+		assert.True(t, errors.Is(err, ErrInvalidFormat), "Expected ErrInvalidFormat")
+	})
+}
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"github.com/dieg0code/products-microservice/src/cache"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CacheCollector exports the lookup counters of a cached repository
+type CacheCollector struct {
+	stats  func() cache.Stats
+	hits   *prometheus.Desc
+	misses *prometheus.Desc
+	errors *prometheus.Desc
+}
+
+// Describe implements prometheus.Collector.
+func (c *CacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.errors
+}
+
+// Collect implements prometheus.Collector.
+func (c *CacheCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.stats()
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(c.errors, prometheus.CounterValue, float64(stats.Errors))
+}
+
+// NewCacheCollector exports products_cache_*_total with the cache name as label
+func NewCacheCollector(name string, stats func() cache.Stats) prometheus.Collector {
+	labels := prometheus.Labels{"cache": name}
+	return &CacheCollector{
+		stats:  stats,
+		hits:   prometheus.NewDesc(prometheus.BuildFQName(Namespace, "cache", "hits_total"), "Cache lookups that were hits.", nil, labels),
+		misses: prometheus.NewDesc(prometheus.BuildFQName(Namespace, "cache", "misses_total"), "Cache lookups that were misses.", nil, labels),
+		errors: prometheus.NewDesc(prometheus.BuildFQName(Namespace, "cache", "errors_total"), "Cache lookups that failed and fell back to the database.", nil, labels),
+	}
+}
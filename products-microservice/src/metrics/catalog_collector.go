@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// DefaultCatalogQueryTimeout bounds the query run on every scrape
+const DefaultCatalogQueryTimeout = 5 * time.Second
+
+var (
+	productsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "catalog", "products"),
+		"Products in the catalog, by tenant.",
+		[]string{"tenant_id"}, nil,
+	)
+	outOfStockDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "catalog", "products_out_of_stock"),
+		"Products without stock, by tenant.",
+		[]string{"tenant_id"}, nil,
+	)
+)
+
+type catalogCount struct {
+	TenantID   string
+	Total      int64
+	OutOfStock int64
+}
+
+// CatalogCollector counts the catalog of every tenant when scraped. The query is raw
+// SQL so it is not scoped by the tenant callbacks.
+type CatalogCollector struct {
+	db      *gorm.DB
+	timeout time.Duration
+}
+
+// Describe implements prometheus.Collector.
+func (c *CatalogCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- productsDesc
+	ch <- outOfStockDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *CatalogCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	var counts []catalogCount
+	err := c.db.WithContext(ctx).Raw(
+		"SELECT tenant_id, COUNT(*) AS total, " +
+			"COALESCE(SUM(CASE WHEN stock <= 0 THEN 1 ELSE 0 END), 0) AS out_of_stock " +
+			"FROM products WHERE deleted_at IS NULL GROUP BY tenant_id",
+	).Scan(&counts).Error
+	if err != nil {
+		logrus.WithError(err).Error("Error counting products for metrics")
+		ch <- prometheus.NewInvalidMetric(productsDesc, err)
+		return
+	}
+
+	for _, count := range counts {
+		ch <- prometheus.MustNewConstMetric(productsDesc, prometheus.GaugeValue, float64(count.Total), count.TenantID)
+		ch <- prometheus.MustNewConstMetric(outOfStockDesc, prometheus.GaugeValue, float64(count.OutOfStock), count.TenantID)
+	}
+}
+
+func NewCatalogCollector(db *gorm.DB, timeout time.Duration) prometheus.Collector {
+	return &CatalogCollector{db: db, timeout: timeout}
+}
@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/cache"
+	"github.com/dieg0code/products-microservice/src/models"
+	"github.com/dieg0code/products-microservice/src/tenant"
+	"github.com/dieg0code/products-microservice/src/testutils"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectors(t *testing.T) {
+	t.Run("CatalogCollector_Counts_Per_Tenant", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.Product{})
+		sqlDB, _ := db.DB()
+		defer sqlDB.Close()
+
+		acme := tenant.WithTenant(context.Background(), "acme")
+		globex := tenant.WithTenant(context.Background(), "globex")
+		assert.Nil(t, db.WithContext(acme).Create(&[]models.Product{
+			{Name: "Apple", Category: "Fruit", Price: 100, Stock: 10},
+			{Name: "Pear", Category: "Fruit", Price: 100, Stock: 0},
+		}).Error, "Expected no error creating products")
+		assert.Nil(t, db.WithContext(globex).Create(&models.Product{Name: "Apple", Category: "Fruit", Price: 100, Stock: 3}).Error, "Expected no error creating product")
+
+		expected := `
+# HELP products_catalog_products Products in the catalog, by tenant.
+# TYPE products_catalog_products gauge
+products_catalog_products{tenant_id="acme"} 2
+products_catalog_products{tenant_id="globex"} 1
+# HELP products_catalog_products_out_of_stock Products without stock, by tenant.
+# TYPE products_catalog_products_out_of_stock gauge
+products_catalog_products_out_of_stock{tenant_id="acme"} 1
+products_catalog_products_out_of_stock{tenant_id="globex"} 0
+`
+		err := testutil.CollectAndCompare(NewCatalogCollector(db, time.Second), strings.NewReader(expected))
+		assert.Nil(t, err, "Expected the catalog to be counted per tenant")
+	})
+
+	t.Run("CacheCollector_Exports_Stats", func(t *testing.T) {
+		collector := NewCacheCollector("products", func() cache.Stats {
+			return cache.Stats{Hits: 3, Misses: 2, Errors: 1}
+		})
+
+		expected := `
+# HELP products_cache_hits_total Cache lookups that were hits.
+# TYPE products_cache_hits_total counter
+products_cache_hits_total{cache="products"} 3
+# HELP products_cache_misses_total Cache lookups that were misses.
+# TYPE products_cache_misses_total counter
+products_cache_misses_total{cache="products"} 2
+`
+		err := testutil.CollectAndCompare(collector, strings.NewReader(expected), "products_cache_hits_total", "products_cache_misses_total")
+		assert.Nil(t, err, "Expected the cache stats as counters")
+	})
+}
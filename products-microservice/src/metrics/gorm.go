@@ -0,0 +1,105 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"gorm.io/gorm"
+)
+
+const startedAtKey = "metrics:started_at"
+
+// InstrumentDB times every GORM statement by operation and table and exports the
+// connection pool stats of the underlying sql.DB
+func InstrumentDB(db *gorm.DB, registerer prometheus.Registerer, dbName string) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "GORM statement latency, by operation and table.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	err = registerer.Register(duration)
+	if err != nil {
+		return err
+	}
+	err = registerer.Register(collectors.NewDBStatsCollector(sqlDB, dbName))
+	if err != nil {
+		return err
+	}
+
+	return registerTimingCallbacks(db, duration)
+}
+
+func registerTimingCallbacks(db *gorm.DB, duration *prometheus.HistogramVec) error {
+	callbacks := db.Callback()
+	start := func(db *gorm.DB) {
+		db.InstanceSet(startedAtKey, time.Now())
+	}
+	observe := func(operation string) func(*gorm.DB) {
+		return func(db *gorm.DB) {
+			value, ok := db.InstanceGet(startedAtKey)
+			if !ok {
+				return
+			}
+			startedAt, ok := value.(time.Time)
+			if !ok {
+				return
+			}
+			duration.WithLabelValues(operation, db.Statement.Table).Observe(time.Since(startedAt).Seconds())
+		}
+	}
+
+	err := callbacks.Create().Before("gorm:create").Register("metrics:create_start", start)
+	if err != nil {
+		return err
+	}
+	err = callbacks.Create().After("gorm:create").Register("metrics:create_end", observe("create"))
+	if err != nil {
+		return err
+	}
+	err = callbacks.Query().Before("gorm:query").Register("metrics:query_start", start)
+	if err != nil {
+		return err
+	}
+	err = callbacks.Query().After("gorm:query").Register("metrics:query_end", observe("query"))
+	if err != nil {
+		return err
+	}
+	err = callbacks.Update().Before("gorm:update").Register("metrics:update_start", start)
+	if err != nil {
+		return err
+	}
+	err = callbacks.Update().After("gorm:update").Register("metrics:update_end", observe("update"))
+	if err != nil {
+		return err
+	}
+	err = callbacks.Delete().Before("gorm:delete").Register("metrics:delete_start", start)
+	if err != nil {
+		return err
+	}
+	err = callbacks.Delete().After("gorm:delete").Register("metrics:delete_end", observe("delete"))
+	if err != nil {
+		return err
+	}
+	err = callbacks.Row().Before("gorm:row").Register("metrics:row_start", start)
+	if err != nil {
+		return err
+	}
+	err = callbacks.Row().After("gorm:row").Register("metrics:row_end", observe("row"))
+	if err != nil {
+		return err
+	}
+	err = callbacks.Raw().Before("gorm:raw").Register("metrics:raw_start", start)
+	if err != nil {
+		return err
+	}
+	return callbacks.Raw().After("gorm:raw").Register("metrics:raw_end", observe("raw"))
+}
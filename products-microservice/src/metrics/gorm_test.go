@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dieg0code/products-microservice/src/models"
+	"github.com/dieg0code/products-microservice/src/tenant"
+	"github.com/dieg0code/products-microservice/src/testutils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstrumentDB(t *testing.T) {
+	t.Run("InstrumentDB_Times_Statements", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.Product{})
+		sqlDB, _ := db.DB()
+		defer sqlDB.Close()
+
+		registry := prometheus.NewRegistry()
+		err := InstrumentDB(db, registry, "products")
+		assert.Nil(t, err, "Expected no error instrumenting the database")
+
+		ctx := tenant.WithTenant(context.Background(), tenant.DefaultTenantID)
+		product := &models.Product{Name: "Apple", Category: "Fruit", Price: 100, Stock: 10}
+		assert.Nil(t, db.WithContext(ctx).Create(product).Error, "Expected no error creating product")
+		assert.Nil(t, db.WithContext(ctx).First(&models.Product{}, product.ID).Error, "Expected no error getting product")
+
+		families, err := registry.Gather()
+		assert.Nil(t, err, "Expected no error gathering metrics")
+
+		observed := map[string]uint64{}
+		for _, family := range families {
+			if family.GetName() != "products_db_query_duration_seconds" {
+				continue
+			}
+			for _, metric := range family.GetMetric() {
+				for _, label := range metric.GetLabel() {
+					if label.GetName() == "operation" {
+						observed[label.GetValue()] = metric.GetHistogram().GetSampleCount()
+					}
+				}
+			}
+		}
+		assert.Equal(t, uint64(1), observed["create"], "Expected the create to be timed")
+		assert.Equal(t, uint64(1), observed["query"], "Expected the query to be timed")
+	})
+
+	t.Run("InstrumentDB_Exports_Pool_Stats", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.Product{})
+		sqlDB, _ := db.DB()
+		defer sqlDB.Close()
+
+		registry := prometheus.NewRegistry()
+		err := InstrumentDB(db, registry, "products")
+		assert.Nil(t, err, "Expected no error instrumenting the database")
+
+		assert.Equal(t, 1, testutil.CollectAndCount(registry, "go_sql_open_connections"), "Expected the pool stats")
+	})
+}
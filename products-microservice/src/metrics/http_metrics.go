@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// HTTPMetrics records the rate, errors and duration of the HTTP API
+type HTTPMetrics interface {
+	// ObserveRequest records a finished request, route is the route pattern and not the path
+	ObserveRequest(method string, route string, status int, duration time.Duration)
+}
+
+type HTTPMetricsImpl struct {
+	requests *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// ObserveRequest implements HTTPMetrics.
+func (h *HTTPMetricsImpl) ObserveRequest(method string, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	h.requests.WithLabelValues(method, route, code).Inc()
+	if status >= 500 {
+		h.errors.WithLabelValues(method, route, code).Inc()
+	}
+	h.duration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+// NewHTTPMetricsImpl registers the HTTP collectors, it panics if they already are
+func NewHTTPMetricsImpl(registerer prometheus.Registerer) HTTPMetrics {
+	factory := promauto.With(registerer)
+	labels := []string{"method", "route", "status"}
+
+	return &HTTPMetricsImpl{
+		requests: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests handled, by method, route and status.",
+		}, labels),
+		errors: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "http",
+			Name:      "request_errors_total",
+			Help:      "HTTP requests that ended with a 5xx status, by method, route and status.",
+		}, labels),
+		duration: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency, by method, route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, labels),
+	}
+}
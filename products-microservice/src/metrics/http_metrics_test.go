@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPMetrics(t *testing.T) {
+	t.Run("ObserveRequest_Counts_Requests", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		httpMetrics := NewHTTPMetricsImpl(registry)
+
+		httpMetrics.ObserveRequest("GET", "/api/v1/products/:productID", 200, 10*time.Millisecond)
+		httpMetrics.ObserveRequest("GET", "/api/v1/products/:productID", 200, 20*time.Millisecond)
+		httpMetrics.ObserveRequest("GET", "/api/v1/products/:productID", 404, time.Millisecond)
+
+		expected := `
+# HELP products_http_requests_total HTTP requests handled, by method, route and status.
+# TYPE products_http_requests_total counter
+products_http_requests_total{method="GET",route="/api/v1/products/:productID",status="200"} 2
+products_http_requests_total{method="GET",route="/api/v1/products/:productID",status="404"} 1
+`
+		err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "products_http_requests_total")
+		assert.Nil(t, err, "Expected requests to be counted by route and status")
+		assert.Equal(t, 0, testutil.CollectAndCount(registry, "products_http_request_errors_total"), "Expected client errors not to count as errors")
+		assert.Equal(t, 2, testutil.CollectAndCount(registry, "products_http_request_duration_seconds"), "Expected a histogram per route and status")
+	})
+
+	t.Run("ObserveRequest_Counts_Server_Errors", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		httpMetrics := NewHTTPMetricsImpl(registry)
+
+		httpMetrics.ObserveRequest("POST", "/api/v1/products", 500, time.Millisecond)
+		httpMetrics.ObserveRequest("POST", "/api/v1/products", 201, time.Millisecond)
+
+		expected := `
+# HELP products_http_request_errors_total HTTP requests that ended with a 5xx status, by method, route and status.
+# TYPE products_http_request_errors_total counter
+products_http_request_errors_total{method="POST",route="/api/v1/products",status="500"} 1
+`
+		err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "products_http_request_errors_total")
+		assert.Nil(t, err, "Expected only the 500 to count as an error")
+	})
+}
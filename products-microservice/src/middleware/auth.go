@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/dieg0code/products-microservice/src/auth"
+	"github.com/dieg0code/products-microservice/src/logging"
+	"github.com/gin-gonic/gin"
+)
+
+const PrincipalContextKey = "principal"
+
+// Authenticate resolves the caller from a bearer token or an X-API-Key header. Requests
+// without credentials continue as anonymous so public routes keep working, Require
+// decides whether a route needs a caller.
+func Authenticate(authenticator *auth.Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var bearerToken string
+		if header := c.GetHeader("Authorization"); header != "" {
+			scheme, token, found := strings.Cut(header, " ")
+			if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+				abortUnauthorized(c, "Invalid Authorization header")
+				return
+			}
+			bearerToken = strings.TrimSpace(token)
+		}
+
+		principal, err := authenticator.Authenticate(c.Request.Context(), bearerToken, c.GetHeader(auth.ApiKeyHeader))
+		if err != nil {
+			logging.FromContext(c.Request.Context()).WithError(err).Warn("Rejected credentials")
+			abortUnauthorized(c, "Invalid credentials")
+			return
+		}
+
+		if principal != nil {
+			c.Set(PrincipalContextKey, principal)
+		}
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+// DisableAuthentication lets every request through Require, for deployments that
+// explicitly turned authentication off
+func DisableAuthentication() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(auth.WithoutAuthentication(c.Request.Context()))
+		c.Next()
+	}
+}
+
+// Require rejects callers without the permission, 401 for anonymous callers and 403 for the rest
+func Require(permission auth.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := auth.Authorize(c.Request.Context(), permission)
+		if errors.Is(err, auth.ErrUnauthenticated) {
+			abortUnauthorized(c, "Authentication required")
+			return
+		}
+		if err != nil {
+			abortWithError(c, http.StatusForbidden, "Insufficient permissions")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func abortUnauthorized(c *gin.Context, msg string) {
+	c.Header("WWW-Authenticate", `Bearer realm="products"`)
+	abortWithError(c, http.StatusUnauthorized, msg)
+}
@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/auth"
+	"github.com/dieg0code/products-microservice/src/testutils"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var authTestSecret = []byte("test-secret")
+
+func signTestToken(t *testing.T, roles ...string) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":   "user-1",
+		"roles": roles,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	})
+
+	signed, err := token.SignedString(authTestSecret)
+	assert.Nil(t, err, "Expected no error signing token")
+	return signed
+}
+
+func TestAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	verifier, err := auth.NewJWTVerifierImpl(auth.JWTConfig{HMACSecret: authTestSecret})
+	assert.Nil(t, err, "Expected no error creating verifier")
+
+	apiKeyService := new(testutils.MockApiKeyService)
+	apiKeyService.On("AuthenticateApiKey", mock.Anything, "pmk_abc_write").Return(&auth.Principal{Subject: "api-key:abc", Scopes: []auth.Scope{auth.ScopeProductsWrite}}, nil)
+	apiKeyService.On("AuthenticateApiKey", mock.Anything, mock.Anything).Return((*auth.Principal)(nil), auth.ErrInvalidApiKey)
+	authenticator := auth.NewAuthenticator(verifier, apiKeyService)
+
+	setupRouter := func() *gin.Engine {
+		router := gin.New()
+		router.Use(Authenticate(authenticator))
+		router.GET("/public", func(c *gin.Context) {
+			_, ok := auth.PrincipalFromContext(c.Request.Context())
+			c.JSON(http.StatusOK, gin.H{"authenticated": ok})
+		})
+		router.POST("/write", Require(auth.PermissionWriteProducts), func(c *gin.Context) {
+			c.Status(http.StatusCreated)
+		})
+		router.DELETE("/admin", Require(auth.PermissionDeleteProducts), func(c *gin.Context) {
+			c.Status(http.StatusNoContent)
+		})
+		return router
+	}
+
+	perform := func(router *gin.Engine, method string, path string, authorization string, apiKey ...string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, path, nil)
+		assert.Nil(t, err, "Expected no error creating request")
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		if len(apiKey) > 0 {
+			req.Header.Set(auth.ApiKeyHeader, apiKey[0])
+		}
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Authenticate_Allows_Anonymous_Public_Route", func(t *testing.T) {
+		rec := perform(setupRouter(), http.MethodGet, "/public", "")
+
+		assert.Equal(t, http.StatusOK, rec.Code, "Expected status code 200")
+		assert.JSONEq(t, `{"authenticated":false}`, rec.Body.String(), "Expected anonymous caller")
+	})
+
+	t.Run("Authenticate_Stores_Principal", func(t *testing.T) {
+		rec := perform(setupRouter(), http.MethodGet, "/public", "Bearer "+signTestToken(t, "viewer"))
+
+		assert.Equal(t, http.StatusOK, rec.Code, "Expected status code 200")
+		assert.JSONEq(t, `{"authenticated":true}`, rec.Body.String(), "Expected authenticated caller")
+	})
+
+	t.Run("Authenticate_Rejects_Invalid_Token", func(t *testing.T) {
+		router := setupRouter()
+
+		rec := perform(router, http.MethodGet, "/public", "Bearer not-a-jwt")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, "Expected status code 401")
+		assert.NotEmpty(t, rec.Header().Get("WWW-Authenticate"), "Expected WWW-Authenticate header")
+
+		rec = perform(router, http.MethodGet, "/public", "Basic dXNlcjpwYXNz")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, "Expected status code 401 for other schemes")
+	})
+
+	t.Run("Require_Anonymous_Unauthorized", func(t *testing.T) {
+		rec := perform(setupRouter(), http.MethodDelete, "/admin", "")
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, "Expected status code 401")
+	})
+
+	t.Run("Require_Insufficient_Role_Forbidden", func(t *testing.T) {
+		rec := perform(setupRouter(), http.MethodDelete, "/admin", "Bearer "+signTestToken(t, "editor"))
+
+		assert.Equal(t, http.StatusForbidden, rec.Code, "Expected status code 403")
+	})
+
+	t.Run("Require_Success", func(t *testing.T) {
+		rec := perform(setupRouter(), http.MethodDelete, "/admin", "Bearer "+signTestToken(t, "admin"))
+
+		assert.Equal(t, http.StatusNoContent, rec.Code, "Expected status code 204")
+	})
+	t.Run("Authenticate_ApiKey_Scopes", func(t *testing.T) {
+		router := setupRouter()
+
+		assert.Equal(t, http.StatusCreated, perform(router, http.MethodPost, "/write", "", "pmk_abc_write").Code, "Expected write scope to be allowed")
+		assert.Equal(t, http.StatusForbidden, perform(router, http.MethodDelete, "/admin", "", "pmk_abc_write").Code, "Expected API key delete to be forbidden")
+		assert.Equal(t, http.StatusUnauthorized, perform(router, http.MethodPost, "/write", "", "pmk_abc_revoked").Code, "Expected invalid key to be rejected")
+		assert.Equal(t, http.StatusUnauthorized, perform(router, http.MethodPost, "/write", "Bearer "+signTestToken(t, "editor"), "pmk_abc_write").Code, "Expected both credentials to be rejected")
+	})
+}
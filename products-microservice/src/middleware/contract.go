@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/logging"
+	"github.com/dieg0code/products-microservice/src/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+// ContractConfig picks what ValidateContract checks against the OpenAPI document
+type ContractConfig struct {
+	// Requests rejects requests that break the document with 400 Bad Request
+	Requests bool
+	// Responses replaces JSON responses that break the document with 500 Internal
+	// Server Error. It buffers every response, so it is meant for development and tests.
+	Responses bool
+}
+
+func (c ContractConfig) Enabled() bool {
+	return c.Requests || c.Responses
+}
+
+// ValidateContract checks requests, and responses when configured, against the
+// operation of the matched route. Undocumented routes pass through.
+func ValidateContract(document *openapi.Document, config ContractConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		operation := document.Operation(c.Request.Method, c.FullPath())
+		if operation == nil {
+			c.Next()
+			return
+		}
+
+		if config.Requests {
+			var body []byte
+			if c.Request.Body != nil {
+				var err error
+				body, err = io.ReadAll(c.Request.Body)
+				if err != nil {
+					abortWithError(c, http.StatusBadRequest, "Invalid request body")
+					return
+				}
+				c.Request.Body = io.NopCloser(bytes.NewReader(body))
+			}
+
+			pathParams := make(map[string]string, len(c.Params))
+			for _, param := range c.Params {
+				pathParams[param.Key] = param.Value
+			}
+
+			violations := document.ValidateRequest(operation, c.Request, pathParams, body)
+			if len(violations) > 0 {
+				logging.FromContext(c.Request.Context()).WithField("violations", violations).Warn("Request does not match the API contract")
+				abortWithViolations(c, http.StatusBadRequest, "Request does not match the API contract", violations)
+				return
+			}
+		}
+
+		if !config.Responses || !hasJSONResponses(operation) {
+			c.Next()
+			return
+		}
+
+		writer := c.Writer
+		buffer := &bufferedResponseWriter{ResponseWriter: writer, body: &bytes.Buffer{}}
+		c.Writer = buffer
+
+		c.Next()
+
+		c.Writer = writer
+		violations := document.ValidateResponse(operation, writer.Status(), buffer.body.Bytes())
+		if len(violations) > 0 {
+			logging.FromContext(c.Request.Context()).WithField("violations", violations).Error("Response does not match the API contract")
+			for _, name := range []string{"Content-Length", "Cache-Control", "ETag", "Last-Modified"} {
+				writer.Header().Del(name)
+			}
+			abortWithViolations(c, http.StatusInternalServerError, "Response does not match the API contract", violations)
+			return
+		}
+
+		if buffer.body.Len() == 0 {
+			writer.WriteHeaderNow()
+			return
+		}
+		_, _ = writer.Write(buffer.body.Bytes())
+	}
+}
+
+// hasJSONResponses leaves streams alone, they can't be buffered
+func hasJSONResponses(operation *openapi.Operation) bool {
+	for status, res := range operation.Responses {
+		if !strings.HasPrefix(status, "2") {
+			continue
+		}
+		if _, ok := res.Content[openapi.ContentTypeJSON]; !ok && len(res.Content) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func abortWithViolations(c *gin.Context, code int, msg string, violations []openapi.Violation) {
+	res := response.BaseResponse{
+		Code:       code,
+		Status:     http.StatusText(code),
+		Msg:        msg,
+		Data:       nil,
+		Violations: make([]response.ContractViolation, 0, len(violations)),
+	}
+	for _, violation := range violations {
+		res.Violations = append(res.Violations, response.ContractViolation{
+			In:      violation.In,
+			Pointer: violation.Pointer,
+			Keyword: violation.Keyword,
+			Message: violation.Message,
+		})
+	}
+	c.AbortWithStatusJSON(code, res)
+}
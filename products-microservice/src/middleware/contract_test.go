@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/openapi"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateContract(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	b := openapi.NewBuilder(openapi.Info{Title: "Test", Version: "1.0.0"})
+	b.Add(http.MethodPost, "/products/:productID", &openapi.Operation{
+		OperationID: "updateProduct",
+		Parameters: []*openapi.Parameter{
+			{Name: "productID", In: openapi.InPath, Required: true, Schema: &openapi.Schema{Type: openapi.Types{openapi.TypeInteger}, Minimum: new(float64)}},
+			{Name: "dry_run", In: openapi.InQuery, Schema: &openapi.Schema{Type: openapi.Types{openapi.TypeBoolean}}},
+		},
+		RequestBody: b.JSONBody(request.UpdateProductRequest{}),
+		Responses: map[string]*openapi.Response{
+			"200": openapi.JSONResponse("The product", b.ResponseSchema(response.ProductResponse{})),
+		},
+	})
+	document, err := b.Document()
+	assert.Nil(t, err, "Expected the document to build")
+
+	setupRouter := func(config ContractConfig, handler gin.HandlerFunc) *gin.Engine {
+		router := gin.New()
+		router.Use(ValidateContract(document, config))
+		router.POST("/products/:productID", handler)
+		router.GET("/undocumented", handler)
+		return router
+	}
+
+	validProduct := func(c *gin.Context) {
+		c.JSON(http.StatusOK, response.ProductResponse{ProductID: 1, Name: "Test Product", Category: "Tools", Price: 100, Stock: 10, LastUpdate: "2024-01-02"})
+	}
+
+	perform := func(router *gin.Engine, method string, path string, body string) (*httptest.ResponseRecorder, response.BaseResponse) {
+		req, err := http.NewRequest(method, path, bytes.NewBufferString(body))
+		assert.Nil(t, err, "Expected no error creating request")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		var res response.BaseResponse
+		_ = json.Unmarshal(rec.Body.Bytes(), &res)
+		return rec, res
+	}
+
+	t.Run("ValidateContract_Valid_Request", func(t *testing.T) {
+		var body string
+		router := setupRouter(ContractConfig{Requests: true}, func(c *gin.Context) {
+			raw, _ := c.GetRawData()
+			body = string(raw)
+			validProduct(c)
+		})
+
+		payload := `{"name": "Test Product", "category": "Tools", "price": 100, "stock": 10}`
+		rec, _ := perform(router, http.MethodPost, "/products/1?dry_run=true", payload)
+
+		assert.Equal(t, http.StatusOK, rec.Code, "Expected status code 200")
+		assert.Equal(t, payload, body, "Expected the handler to still read the body")
+	})
+
+	t.Run("ValidateContract_Invalid_Request", func(t *testing.T) {
+		called := false
+		router := setupRouter(ContractConfig{Requests: true}, func(c *gin.Context) {
+			called = true
+			validProduct(c)
+		})
+
+		rec, res := perform(router, http.MethodPost, "/products/abc?dry_run=maybe", `{"name": "", "category": "Tools", "price": "100"}`)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, "Expected status code 400")
+		assert.False(t, called, "Expected the handler to not run")
+		assert.Equal(t, "Request does not match the API contract", res.Msg, "Expected the contract message")
+		assert.Equal(t, []response.ContractViolation{
+			{In: openapi.InPath, Pointer: "/productID", Keyword: "type", Message: "must be integer"},
+			{In: openapi.InQuery, Pointer: "/dry_run", Keyword: "type", Message: "must be boolean"},
+			{In: openapi.InBody, Pointer: "/stock", Keyword: "required", Message: "is required"},
+			{In: openapi.InBody, Pointer: "/name", Keyword: "minLength", Message: "must be at least 1 characters long"},
+			{In: openapi.InBody, Pointer: "/price", Keyword: "type", Message: "must be integer"},
+		}, res.Violations, "Expected every violation with its JSON pointer")
+	})
+
+	t.Run("ValidateContract_Missing_Body", func(t *testing.T) {
+		router := setupRouter(ContractConfig{Requests: true}, validProduct)
+
+		rec, res := perform(router, http.MethodPost, "/products/1", "")
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, "Expected status code 400")
+		assert.Equal(t, []response.ContractViolation{{In: openapi.InBody, Keyword: "required", Message: "request body is required"}}, res.Violations, "Expected the body to be required")
+	})
+
+	t.Run("ValidateContract_Invalid_Response", func(t *testing.T) {
+		router := setupRouter(ContractConfig{Responses: true}, func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"product_id": -1, "name": "Test Product"})
+		})
+
+		rec, res := perform(router, http.MethodPost, "/products/1", `{}`)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code, "Expected status code 500")
+		assert.Equal(t, "Response does not match the API contract", res.Msg, "Expected the contract message")
+		assert.Contains(t, res.Violations, response.ContractViolation{In: openapi.InResponse, Pointer: "/product_id", Keyword: "minimum", Message: "must be at least 0"}, "Expected the invalid field")
+		assert.Contains(t, res.Violations, response.ContractViolation{In: openapi.InResponse, Pointer: "/category", Keyword: "required", Message: "is required"}, "Expected the missing field")
+	})
+
+	t.Run("ValidateContract_Undocumented_Status", func(t *testing.T) {
+		router := setupRouter(ContractConfig{Responses: true}, func(c *gin.Context) {
+			c.JSON(http.StatusTeapot, gin.H{})
+		})
+
+		rec, res := perform(router, http.MethodPost, "/products/1", `{}`)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code, "Expected status code 500")
+		assert.Equal(t, "status 418 is not documented", res.Violations[0].Message, "Expected the status to be reported")
+	})
+
+	t.Run("ValidateContract_Valid_Response", func(t *testing.T) {
+		router := setupRouter(ContractConfig{Requests: true, Responses: true}, validProduct)
+
+		rec, _ := perform(router, http.MethodPost, "/products/1", `{"name": "Test Product", "category": "Tools", "price": 100, "stock": 10}`)
+
+		assert.Equal(t, http.StatusOK, rec.Code, "Expected status code 200")
+		assert.Contains(t, rec.Body.String(), `"product_id":1`, "Expected the handler's response")
+	})
+
+	t.Run("ValidateContract_Undocumented_Route", func(t *testing.T) {
+		router := setupRouter(ContractConfig{Requests: true, Responses: true}, func(c *gin.Context) {
+			c.JSON(http.StatusTeapot, gin.H{"anything": true})
+		})
+
+		rec, _ := perform(router, http.MethodGet, "/undocumented", "")
+
+		assert.Equal(t, http.StatusTeapot, rec.Code, "Expected undocumented routes to pass through")
+	})
+}
@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/dieg0code/products-microservice/src/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// CachePolicy is how clients and CDNs may cache the responses of a route
+type CachePolicy struct {
+	// CacheControl is sent as is, e.g. "public, max-age=30"
+	CacheControl string
+	// Vary lists the request headers that change the response
+	Vary []string
+}
+
+// HTTPCache adds the policy and a weak ETag over the body to successful GET and HEAD
+// responses and answers conditional requests with 304 Not Modified. Handlers can set
+// Last-Modified for If-Modified-Since, If-None-Match wins when both are sent.
+// Responses to requests carrying credentials are only cacheable by the client.
+func HTTPCache(policy CachePolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		writer := c.Writer
+		buffer := &bufferedResponseWriter{ResponseWriter: writer, body: &bytes.Buffer{}}
+		c.Writer = buffer
+
+		c.Next()
+
+		c.Writer = writer
+		if writer.Status() != http.StatusOK {
+			_, _ = writer.Write(buffer.body.Bytes())
+			return
+		}
+
+		header := writer.Header()
+		if policy.CacheControl != "" {
+			cacheControl := policy.CacheControl
+			if hasCredentials(c.Request) {
+				cacheControl = privateCacheControl(cacheControl)
+			}
+			header.Set("Cache-Control", cacheControl)
+		}
+		for _, name := range policy.Vary {
+			header.Add("Vary", name)
+		}
+
+		etag := weakETag(buffer.body.Bytes())
+		header.Set("ETag", etag)
+
+		if notModified(c.Request, etag, header.Get("Last-Modified")) {
+			header.Del("Content-Type")
+			header.Del("Content-Length")
+			writer.WriteHeader(http.StatusNotModified)
+			writer.WriteHeaderNow()
+			return
+		}
+
+		_, _ = writer.Write(buffer.body.Bytes())
+	}
+}
+
+func hasCredentials(req *http.Request) bool {
+	return req.Header.Get("Authorization") != "" || req.Header.Get(auth.ApiKeyHeader) != ""
+}
+
+// privateCacheControl turns a shared cache policy into one only the client may apply,
+// dropping the directives meant for shared caches
+func privateCacheControl(cacheControl string) string {
+	directives := []string{"private"}
+	for _, directive := range strings.Split(cacheControl, ",") {
+		directive = strings.TrimSpace(directive)
+		name := strings.ToLower(strings.SplitN(directive, "=", 2)[0])
+		switch name {
+		case "", "public", "private", "s-maxage", "proxy-revalidate":
+			continue
+		}
+		directives = append(directives, directive)
+	}
+	return strings.Join(directives, ", ")
+}
+
+func weakETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// notModified follows RFC 9110, If-None-Match uses the weak comparison and makes
+// If-Modified-Since be ignored
+func notModified(req *http.Request, etag string, lastModified string) bool {
+	if ifNoneMatch := req.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	ifModifiedSince := req.Header.Get("If-Modified-Since")
+	if ifModifiedSince == "" || lastModified == "" {
+		return false
+	}
+
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+	return !modified.After(since)
+}
+
+// bufferedResponseWriter holds the body back so headers can still change once the
+// handler is done
+type bufferedResponseWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (b *bufferedResponseWriter) Write(data []byte) (int, error) {
+	return b.body.Write(data)
+}
+
+func (b *bufferedResponseWriter) WriteString(s string) (int, error) {
+	return b.body.WriteString(s)
+}
+
+func (b *bufferedResponseWriter) WriteHeaderNow() {}
@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPCache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	lastModified := time.Date(2024, 5, 2, 12, 30, 15, 0, time.UTC)
+	policy := CachePolicy{CacheControl: "public, max-age=30", Vary: []string{"X-Tenant-ID"}}
+
+	setupRouter := func() *gin.Engine {
+		router := gin.New()
+		router.Use(HTTPCache(policy))
+		router.GET("/products", func(c *gin.Context) {
+			c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+			c.JSON(http.StatusOK, gin.H{"products": []string{"apple"}})
+		})
+		router.GET("/missing", func(c *gin.Context) {
+			c.JSON(http.StatusNotFound, gin.H{"msg": "not found"})
+		})
+		router.POST("/products", func(c *gin.Context) {
+			c.JSON(http.StatusCreated, gin.H{"msg": "created"})
+		})
+		return router
+	}
+
+	perform := func(router *gin.Engine, method string, path string, headers map[string]string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, path, nil)
+		assert.Nil(t, err, "Expected no error creating request")
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("HTTPCache_Headers", func(t *testing.T) {
+		rec := perform(setupRouter(), http.MethodGet, "/products", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code, "Expected status code 200")
+		assert.Equal(t, "public, max-age=30", rec.Header().Get("Cache-Control"), "Expected Cache-Control from the policy")
+		assert.Equal(t, "X-Tenant-ID", rec.Header().Get("Vary"), "Expected Vary from the policy")
+		assert.Regexp(t, `^W/"[0-9a-f]{32}"$`, rec.Header().Get("ETag"), "Expected a weak ETag")
+		assert.JSONEq(t, `{"products":["apple"]}`, rec.Body.String(), "Expected the body to be written")
+	})
+
+	t.Run("HTTPCache_Private_With_Credentials", func(t *testing.T) {
+		router := setupRouter()
+		for _, headers := range []map[string]string{{"Authorization": "Bearer token"}, {"X-API-Key": "pmk_abc_secret"}} {
+			rec := perform(router, http.MethodGet, "/products", headers)
+
+			assert.Equal(t, http.StatusOK, rec.Code, "Expected status code 200")
+			assert.Equal(t, "private, max-age=30", rec.Header().Get("Cache-Control"), "Expected shared caches not to store the response")
+		}
+
+		assert.Equal(t, "private, max-age=30, stale-while-revalidate=60", privateCacheControl("public, s-maxage=60, max-age=30, stale-while-revalidate=60"), "Expected the shared cache directives to be dropped")
+	})
+
+	t.Run("HTTPCache_ETag_Stable", func(t *testing.T) {
+		router := setupRouter()
+		first := perform(router, http.MethodGet, "/products", nil)
+		second := perform(router, http.MethodGet, "/products", nil)
+
+		assert.Equal(t, first.Header().Get("ETag"), second.Header().Get("ETag"), "Expected the same ETag for the same payload")
+	})
+
+	t.Run("HTTPCache_If_None_Match", func(t *testing.T) {
+		router := setupRouter()
+		etag := perform(router, http.MethodGet, "/products", nil).Header().Get("ETag")
+
+		rec := perform(router, http.MethodGet, "/products", map[string]string{"If-None-Match": `"other", ` + etag})
+		assert.Equal(t, http.StatusNotModified, rec.Code, "Expected status code 304")
+		assert.Empty(t, rec.Body.String(), "Expected no body")
+		assert.Equal(t, etag, rec.Header().Get("ETag"), "Expected the ETag on the 304")
+
+		rec = perform(router, http.MethodGet, "/products", map[string]string{"If-None-Match": `W/"other"`})
+		assert.Equal(t, http.StatusOK, rec.Code, "Expected status code 200 for a stale ETag")
+	})
+
+	t.Run("HTTPCache_If_Modified_Since", func(t *testing.T) {
+		router := setupRouter()
+
+		rec := perform(router, http.MethodGet, "/products", map[string]string{"If-Modified-Since": lastModified.Format(http.TimeFormat)})
+		assert.Equal(t, http.StatusNotModified, rec.Code, "Expected status code 304")
+
+		rec = perform(router, http.MethodGet, "/products", map[string]string{"If-Modified-Since": lastModified.Add(-time.Hour).Format(http.TimeFormat)})
+		assert.Equal(t, http.StatusOK, rec.Code, "Expected status code 200 when modified since")
+	})
+
+	t.Run("HTTPCache_If_None_Match_Wins", func(t *testing.T) {
+		rec := perform(setupRouter(), http.MethodGet, "/products", map[string]string{
+			"If-None-Match":     `W/"other"`,
+			"If-Modified-Since": lastModified.Format(http.TimeFormat),
+		})
+		assert.Equal(t, http.StatusOK, rec.Code, "Expected If-Modified-Since to be ignored")
+	})
+
+	t.Run("HTTPCache_Errors_Not_Cached", func(t *testing.T) {
+		rec := perform(setupRouter(), http.MethodGet, "/missing", nil)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code, "Expected status code 404")
+		assert.Empty(t, rec.Header().Get("Cache-Control"), "Expected no Cache-Control")
+		assert.Empty(t, rec.Header().Get("ETag"), "Expected no ETag")
+		assert.JSONEq(t, `{"msg":"not found"}`, rec.Body.String(), "Expected the body to be written")
+	})
+
+	t.Run("HTTPCache_Skips_Writes", func(t *testing.T) {
+		rec := perform(setupRouter(), http.MethodPost, "/products", nil)
+
+		assert.Equal(t, http.StatusCreated, rec.Code, "Expected status code 201")
+		assert.Empty(t, rec.Header().Get("ETag"), "Expected no ETag")
+	})
+}
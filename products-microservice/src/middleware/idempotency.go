@@ -0,0 +1,202 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/auth"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/logging"
+	"github.com/dieg0code/products-microservice/src/models"
+	"github.com/dieg0code/products-microservice/src/repository"
+	"github.com/dieg0code/products-microservice/src/tenant"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	MaxIdempotencyKeyLength   = 255
+	DefaultIdempotencyKeysTTL = 24 * time.Hour
+	// DefaultIdempotencyLease outlasts the request timeouts, so only requests whose pod
+	// died lose their key to a retry
+	DefaultIdempotencyLease = time.Minute
+)
+
+// Idempotency replays the stored response of mutating requests that carry an
+// Idempotency-Key header instead of executing them again. Keys are scoped to the
+// tenant and the principal. A key stays locked for lease while its request runs,
+// retries after that take it over.
+func Idempotency(repo repository.IdempotencyRepository, ttl time.Duration, lease time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !isMutatingMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		if len(key) > MaxIdempotencyKeyLength {
+			abortWithError(c, http.StatusBadRequest, "Invalid Idempotency-Key")
+			return
+		}
+		key = idempotencyScope(c.Request.Context()) + ":" + key
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			logging.FromContext(c.Request.Context()).WithError(err).Error("Error reading request body")
+			abortWithError(c, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := requestHash(c.Request.Method, c.Request.URL.Path, body)
+
+		reserved, err := reserveKey(c.Request.Context(), repo, key, hash, ttl, lease)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, "Error processing Idempotency-Key")
+			return
+		}
+
+		if !reserved {
+			replayResponse(c, repo, key, hash)
+			return
+		}
+
+		recorder := newResponseRecorder(c.Writer)
+		c.Writer = recorder
+
+		// The outcome is stored even when the request timed out, otherwise the key would
+		// stay reserved until it expires
+		ctx := context.WithoutCancel(c.Request.Context())
+		finished := false
+		defer func() {
+			if finished {
+				return
+			}
+			// The handler panicked, release the key so the client can retry
+			if err := repo.Release(ctx, key); err != nil {
+				logging.FromContext(ctx).WithError(err).WithField("idempotency_key", key).Error("Error releasing idempotency key")
+			}
+		}()
+
+		c.Next()
+		finished = true
+
+		// Server errors are not stored so that the client can retry them
+		if recorder.Status() >= http.StatusInternalServerError {
+			err = repo.Release(ctx, key)
+		} else {
+			err = repo.Complete(ctx, key, recorder.Status(), recorder.Header().Get("Content-Type"), recorder.body.String())
+		}
+		if err != nil {
+			logging.FromContext(c.Request.Context()).WithError(err).WithField("idempotency_key", key).Error("Error storing idempotent response")
+		}
+	}
+}
+
+func reserveKey(ctx context.Context, repo repository.IdempotencyRepository, key string, hash string, ttl time.Duration, lease time.Duration) (bool, error) {
+	now := time.Now()
+	lockedUntil := now.Add(lease)
+	record := &models.IdempotencyRecord{
+		Key:         key,
+		RequestHash: hash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+		LockedUntil: &lockedUntil,
+	}
+
+	reserved, err := repo.Reserve(ctx, record)
+	if err != nil || reserved {
+		return reserved, err
+	}
+
+	existing, err := repo.GetByKey(ctx, key)
+	if err != nil {
+		return false, nil
+	}
+	if !existing.ExpiresAt.Before(now) {
+		// The request holding the key may have died with its pod, once its lock
+		// expires the first retry runs it instead
+		return repo.TakeOver(ctx, key, hash, now, lockedUntil)
+	}
+
+	err = repo.Release(ctx, key)
+	if err != nil {
+		return false, err
+	}
+
+	record.ID = 0
+	return repo.Reserve(ctx, record)
+}
+
+func replayResponse(c *gin.Context, repo repository.IdempotencyRepository, key string, hash string) {
+	record, err := repo.GetByKey(c.Request.Context(), key)
+	if err != nil {
+		abortWithError(c, http.StatusConflict, "A request with this Idempotency-Key is already in progress")
+		return
+	}
+
+	if record.RequestHash != hash {
+		abortWithError(c, http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request")
+		return
+	}
+
+	if record.StatusCode == 0 {
+		abortWithError(c, http.StatusConflict, "A request with this Idempotency-Key is already in progress")
+		return
+	}
+
+	c.Header(IdempotentReplayedHeader, "true")
+	c.Data(record.StatusCode, record.ContentType, []byte(record.Body))
+	c.Abort()
+}
+
+// idempotencyScope keeps the keys of every tenant and principal apart. It is hashed
+// so that long subjects still fit the key column.
+func idempotencyScope(ctx context.Context) string {
+	tenantID, _ := tenant.FromContext(ctx)
+	var principalID string
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		if principal.ApiKeyID != 0 {
+			principalID = "api-key:" + strconv.FormatUint(uint64(principal.ApiKeyID), 10)
+		} else {
+			principalID = "sub:" + principal.Subject
+		}
+	}
+
+	hash := sha256.Sum256([]byte(tenantID + "\x00" + principalID))
+	return hex.EncodeToString(hash[:])
+}
+
+func requestHash(method string, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(path))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func abortWithError(c *gin.Context, code int, msg string) {
+	c.AbortWithStatusJSON(code, response.BaseResponse{
+		Code:   code,
+		Status: http.StatusText(code),
+		Msg:    msg,
+		Data:   nil,
+	})
+}
@@ -0,0 +1,278 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/auth"
+	"github.com/dieg0code/products-microservice/src/models"
+	"github.com/dieg0code/products-microservice/src/repository"
+	"github.com/dieg0code/products-microservice/src/tenant"
+	"github.com/dieg0code/products-microservice/src/testutils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupIdempotencyRouter(db *gorm.DB, ttl time.Duration, status int, calls *int) *gin.Engine {
+	router := gin.New()
+	router.Use(Idempotency(repository.NewIdempotencyRepositoryImpl(db), ttl, DefaultIdempotencyLease))
+	handler := func(c *gin.Context) {
+		*calls++
+		c.JSON(status, gin.H{"call": *calls})
+	}
+	router.POST("/products", handler)
+	router.GET("/products", handler)
+	return router
+}
+
+func sendWithKey(router *gin.Engine, method string, key string, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, "/products", bytes.NewBufferString(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Replays_Stored_Response", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.IdempotencyRecord{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		calls := 0
+		router := setupIdempotencyRouter(db, time.Hour, http.StatusCreated, &calls)
+
+		first := sendWithKey(router, http.MethodPost, "key-1", `{"name":"Product 1"}`)
+		second := sendWithKey(router, http.MethodPost, "key-1", `{"name":"Product 1"}`)
+
+		assert.Equal(t, 1, calls, "Expected handler to run once")
+		assert.Equal(t, http.StatusCreated, second.Code, "Expected stored status code")
+		assert.Equal(t, first.Body.String(), second.Body.String(), "Expected stored body")
+		assert.Equal(t, "true", second.Header().Get(IdempotentReplayedHeader), "Expected replay header")
+	})
+
+	t.Run("Keys_Scoped_To_Tenant", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.IdempotencyRecord{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		calls := 0
+		router := gin.New()
+		router.Use(ResolveTenant(TenantConfig{DefaultTenantID: tenant.DefaultTenantID}))
+		router.Use(Idempotency(repository.NewIdempotencyRepositoryImpl(db), time.Hour, DefaultIdempotencyLease))
+		router.POST("/products", func(c *gin.Context) {
+			calls++
+			c.JSON(http.StatusCreated, gin.H{"call": calls})
+		})
+
+		for _, tenantID := range []string{"acme", "globex"} {
+			req, _ := http.NewRequest(http.MethodPost, "/products", bytes.NewBufferString(`{"name":"Product 1"}`))
+			req.Header.Set(IdempotencyKeyHeader, "key-1")
+			req.Header.Set(tenant.HeaderName, tenantID)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Empty(t, rec.Header().Get(IdempotentReplayedHeader), "Expected no replay across tenants")
+		}
+
+		assert.Equal(t, 2, calls, "Expected handler to run once per tenant")
+	})
+
+	t.Run("Keys_Scoped_To_Principal", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.IdempotencyRecord{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		calls := 0
+		router := gin.New()
+		router.Use(ResolveTenant(TenantConfig{DefaultTenantID: tenant.DefaultTenantID}))
+		router.Use(func(c *gin.Context) {
+			principal := &auth.Principal{Subject: c.GetHeader("X-Subject")}
+			c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		})
+		router.Use(Idempotency(repository.NewIdempotencyRepositoryImpl(db), time.Hour, DefaultIdempotencyLease))
+		router.POST("/products", func(c *gin.Context) {
+			calls++
+			c.JSON(http.StatusCreated, gin.H{"call": calls})
+		})
+
+		for _, subject := range []string{"alice", "bob", "alice"} {
+			req, _ := http.NewRequest(http.MethodPost, "/products", bytes.NewBufferString(`{"name":"Product 1"}`))
+			req.Header.Set(IdempotencyKeyHeader, "key-1")
+			req.Header.Set("X-Subject", subject)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusCreated, rec.Code, "Expected the stored or executed response")
+		}
+
+		assert.Equal(t, 2, calls, "Expected handler to run once per principal")
+	})
+
+	t.Run("Rejects_Different_Payload", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.IdempotencyRecord{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		calls := 0
+		router := setupIdempotencyRouter(db, time.Hour, http.StatusCreated, &calls)
+
+		sendWithKey(router, http.MethodPost, "key-1", `{"name":"Product 1"}`)
+		rec := sendWithKey(router, http.MethodPost, "key-1", `{"name":"Product 2"}`)
+
+		assert.Equal(t, 1, calls, "Expected handler to run once")
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, "Expected status code 422")
+	})
+
+	t.Run("Does_Not_Store_Server_Errors", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.IdempotencyRecord{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		calls := 0
+		router := setupIdempotencyRouter(db, time.Hour, http.StatusInternalServerError, &calls)
+
+		sendWithKey(router, http.MethodPost, "key-1", `{}`)
+		sendWithKey(router, http.MethodPost, "key-1", `{}`)
+
+		assert.Equal(t, 2, calls, "Expected failed request to be retried")
+	})
+
+	t.Run("Expired_Key_Runs_Again", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.IdempotencyRecord{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		calls := 0
+		router := setupIdempotencyRouter(db, -time.Second, http.StatusCreated, &calls)
+
+		sendWithKey(router, http.MethodPost, "key-1", `{}`)
+		rec := sendWithKey(router, http.MethodPost, "key-1", `{"other":true}`)
+
+		assert.Equal(t, 2, calls, "Expected expired key to be reusable")
+		assert.Equal(t, http.StatusCreated, rec.Code, "Expected status code 201")
+	})
+
+	t.Run("Ignores_Reads_And_Missing_Key", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.IdempotencyRecord{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		calls := 0
+		router := setupIdempotencyRouter(db, time.Hour, http.StatusOK, &calls)
+
+		sendWithKey(router, http.MethodGet, "key-1", "")
+		sendWithKey(router, http.MethodGet, "key-1", "")
+		sendWithKey(router, http.MethodPost, "", `{}`)
+		sendWithKey(router, http.MethodPost, "", `{}`)
+
+		assert.Equal(t, 4, calls, "Expected every request to reach the handler")
+	})
+
+	t.Run("Releases_Key_When_Handler_Panics", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.IdempotencyRecord{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		calls := 0
+		router := gin.New()
+		router.Use(gin.Recovery())
+		router.Use(Idempotency(repository.NewIdempotencyRepositoryImpl(db), time.Hour, DefaultIdempotencyLease))
+		router.POST("/products", func(c *gin.Context) {
+			calls++
+			if calls == 1 {
+				panic("handler failed")
+			}
+			c.JSON(http.StatusCreated, gin.H{"call": calls})
+		})
+
+		first := sendWithKey(router, http.MethodPost, "key-1", `{}`)
+		second := sendWithKey(router, http.MethodPost, "key-1", `{}`)
+
+		assert.Equal(t, http.StatusInternalServerError, first.Code, "Expected the panic to be recovered")
+		assert.Equal(t, http.StatusCreated, second.Code, "Expected the retry to run")
+		assert.Equal(t, 2, calls, "Expected the key to be released")
+	})
+
+	t.Run("Takes_Over_Abandoned_Key", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.IdempotencyRecord{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		repo := repository.NewIdempotencyRepositoryImpl(db)
+		now := time.Now()
+		expired := now.Add(-time.Second)
+		locked := now.Add(time.Minute)
+		hash := requestHash(http.MethodPost, "/products", []byte(`{}`))
+		for key, lockedUntil := range map[string]*time.Time{"abandoned": &expired, "running": &locked} {
+			_, err := repo.Reserve(context.Background(), &models.IdempotencyRecord{Key: idempotencyScope(context.Background()) + ":" + key, RequestHash: hash, CreatedAt: now, ExpiresAt: now.Add(time.Hour), LockedUntil: lockedUntil})
+			assert.Nil(t, err, "Expected no error reserving key")
+		}
+
+		calls := 0
+		router := setupIdempotencyRouter(db, time.Hour, http.StatusCreated, &calls)
+
+		running := sendWithKey(router, http.MethodPost, "running", `{}`)
+		abandoned := sendWithKey(router, http.MethodPost, "abandoned", `{}`)
+		replayed := sendWithKey(router, http.MethodPost, "abandoned", `{}`)
+
+		assert.Equal(t, http.StatusConflict, running.Code, "Expected a locked key to stay in progress")
+		assert.Equal(t, http.StatusCreated, abandoned.Code, "Expected the retry to take over the expired lock")
+		assert.Equal(t, "true", replayed.Header().Get(IdempotentReplayedHeader), "Expected the taken over response to be stored")
+		assert.Equal(t, 1, calls, "Expected the handler to run once")
+	})
+}
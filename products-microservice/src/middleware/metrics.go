@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels requests that matched no route, so scanners probing random
+// paths can't blow up the metrics' cardinality
+const unmatchedRoute = "unmatched"
+
+// Metrics records every request under its route pattern, e.g. /api/v1/products/:productID
+func Metrics(httpMetrics metrics.HTTPMetrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		httpMetrics.ObserveRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordedRequest struct {
+	method string
+	route  string
+	status int
+}
+
+type fakeHTTPMetrics struct {
+	requests []recordedRequest
+}
+
+func (f *fakeHTTPMetrics) ObserveRequest(method string, route string, status int, duration time.Duration) {
+	f.requests = append(f.requests, recordedRequest{method: method, route: route, status: status})
+}
+
+func TestMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Metrics_Records_Route_Pattern", func(t *testing.T) {
+		httpMetrics := &fakeHTTPMetrics{}
+		router := gin.New()
+		router.Use(Metrics(httpMetrics))
+		router.GET("/products/:productID", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		for _, path := range []string{"/products/1", "/products/2", "/unknown/path"} {
+			req, err := http.NewRequest(http.MethodGet, path, nil)
+			assert.Nil(t, err, "Expected no error creating request")
+			router.ServeHTTP(httptest.NewRecorder(), req)
+		}
+
+		assert.Equal(t, []recordedRequest{
+			{method: http.MethodGet, route: "/products/:productID", status: http.StatusOK},
+			{method: http.MethodGet, route: "/products/:productID", status: http.StatusOK},
+			{method: http.MethodGet, route: unmatchedRoute, status: http.StatusNotFound},
+		}, httpMetrics.requests, "Expected requests to be recorded by route pattern")
+	})
+}
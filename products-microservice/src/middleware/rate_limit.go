@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/auth"
+	"github.com/dieg0code/products-microservice/src/logging"
+	"github.com/dieg0code/products-microservice/src/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+	RateLimitPolicyHeader    = "RateLimit-Policy"
+	RetryAfterHeader         = "Retry-After"
+)
+
+// RateLimit gives every client of a route group a token bucket for reads and one for
+// mutating methods. Clients are told apart by API key, token subject or IP, so it
+// must run after Authenticate. When the store fails requests are let through, a
+// broken limiter should not take the API down with it.
+func RateLimit(store ratelimit.Store, group string, policy ratelimit.Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, bucket := policy.Read, "read"
+		if isMutatingMethod(c.Request.Method) {
+			limit, bucket = policy.Write, "write"
+		}
+		if !limit.Enabled() {
+			c.Next()
+			return
+		}
+
+		key := group + ":" + bucket + ":" + rateLimitClient(c)
+		result, err := store.Take(c.Request.Context(), key, limit)
+		if err != nil {
+			logging.FromContext(c.Request.Context()).WithError(err).WithField("rate_limit_key", key).Warn("Error checking rate limit")
+			c.Next()
+			return
+		}
+
+		c.Header(RateLimitLimitHeader, strconv.Itoa(result.Limit))
+		c.Header(RateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+		c.Header(RateLimitResetHeader, formatSeconds(result.Reset))
+		c.Header(RateLimitPolicyHeader, fmt.Sprintf("%d;w=%s", limit.Requests, formatSeconds(limit.Period)))
+
+		if !result.Allowed {
+			c.Header(RetryAfterHeader, formatSeconds(result.RetryAfter))
+			abortWithError(c, http.StatusTooManyRequests, "Rate limit exceeded")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func rateLimitClient(c *gin.Context) string {
+	if principal, ok := auth.PrincipalFromContext(c.Request.Context()); ok {
+		if principal.ApiKeyID != 0 {
+			return "api-key:" + strconv.FormatUint(uint64(principal.ApiKeyID), 10)
+		}
+		return "sub:" + principal.Subject
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// formatSeconds rounds up so clients never retry before the token is there
+func formatSeconds(duration time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(duration.Seconds())), 10)
+}
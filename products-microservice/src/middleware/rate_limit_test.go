@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/auth"
+	"github.com/dieg0code/products-microservice/src/ratelimit"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingStore struct{}
+
+func (failingStore) Take(ctx context.Context, key string, limit ratelimit.Limit) (*ratelimit.Result, error) {
+	return nil, errors.New("store unavailable")
+}
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	policy := ratelimit.Policy{
+		Read:  ratelimit.Limit{Requests: 2, Period: time.Minute},
+		Write: ratelimit.Limit{Requests: 1, Period: time.Minute},
+	}
+
+	setupRouter := func(store ratelimit.Store, principal *auth.Principal) *gin.Engine {
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			if principal != nil {
+				c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+			}
+		})
+		router.Use(RateLimit(store, "products", policy))
+		router.GET("/products", func(c *gin.Context) { c.Status(http.StatusOK) })
+		router.POST("/products", func(c *gin.Context) { c.Status(http.StatusCreated) })
+		return router
+	}
+
+	perform := func(router *gin.Engine, method string, remoteAddr string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, "/products", nil)
+		assert.Nil(t, err, "Expected no error creating request")
+		req.RemoteAddr = remoteAddr
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("RateLimit_Headers_And_429", func(t *testing.T) {
+		router := setupRouter(ratelimit.NewMemoryStoreImpl(time.Minute), nil)
+
+		rec := perform(router, http.MethodGet, "10.0.0.1:1234")
+		assert.Equal(t, http.StatusOK, rec.Code, "Expected status code 200")
+		assert.Equal(t, "2", rec.Header().Get(RateLimitLimitHeader), "Expected limit header")
+		assert.Equal(t, "1", rec.Header().Get(RateLimitRemainingHeader), "Expected remaining header")
+		assert.Equal(t, "30", rec.Header().Get(RateLimitResetHeader), "Expected reset header")
+		assert.Equal(t, "2;w=60", rec.Header().Get(RateLimitPolicyHeader), "Expected policy header")
+
+		perform(router, http.MethodGet, "10.0.0.1:1234")
+		rec = perform(router, http.MethodGet, "10.0.0.1:1234")
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code, "Expected status code 429")
+		assert.Equal(t, "30", rec.Header().Get(RetryAfterHeader), "Expected Retry-After header")
+		assert.Equal(t, "0", rec.Header().Get(RateLimitRemainingHeader), "Expected no remaining requests")
+
+		rec = perform(router, http.MethodGet, "10.0.0.2:1234")
+		assert.Equal(t, http.StatusOK, rec.Code, "Expected other IPs to have their own bucket")
+	})
+
+	t.Run("RateLimit_Separate_Write_Bucket", func(t *testing.T) {
+		router := setupRouter(ratelimit.NewMemoryStoreImpl(time.Minute), nil)
+
+		rec := perform(router, http.MethodPost, "10.0.0.1:1234")
+		assert.Equal(t, http.StatusCreated, rec.Code, "Expected status code 201")
+		rec = perform(router, http.MethodPost, "10.0.0.1:1234")
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code, "Expected write limit to be reached")
+
+		rec = perform(router, http.MethodGet, "10.0.0.1:1234")
+		assert.Equal(t, http.StatusOK, rec.Code, "Expected reads to keep working")
+	})
+
+	t.Run("RateLimit_Keyed_By_Principal", func(t *testing.T) {
+		store := ratelimit.NewMemoryStoreImpl(time.Minute)
+		user := setupRouter(store, &auth.Principal{Subject: "user-1"})
+		apiKey := setupRouter(store, &auth.Principal{Subject: "api-key:abc", ApiKeyID: 1})
+
+		perform(user, http.MethodPost, "10.0.0.1:1234")
+		rec := perform(user, http.MethodPost, "10.0.0.2:1234")
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code, "Expected the subject to be limited from any IP")
+
+		rec = perform(apiKey, http.MethodPost, "10.0.0.1:1234")
+		assert.Equal(t, http.StatusCreated, rec.Code, "Expected the api key to have its own bucket")
+	})
+
+	t.Run("RateLimit_Store_Error_Fails_Open", func(t *testing.T) {
+		router := setupRouter(failingStore{}, nil)
+
+		rec := perform(router, http.MethodGet, "10.0.0.1:1234")
+		assert.Equal(t, http.StatusOK, rec.Code, "Expected request to be let through")
+		assert.Empty(t, rec.Header().Get(RateLimitLimitHeader), "Expected no rate limit headers")
+	})
+}
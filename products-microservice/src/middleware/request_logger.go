@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// RequestLogger gives every request an ID, reusing a valid X-Request-ID from the
+// caller, and echoes it back. Handlers and the layers below log through
+// logging.FromContext to get the request_id field. Once the request is done one
+// access line is logged, at warn level for 4xx and error level for 5xx.
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := c.GetHeader(logging.RequestIDHeader)
+		if !logging.ValidRequestID(requestID) {
+			requestID = logging.NewRequestID()
+		}
+		c.Header(logging.RequestIDHeader, requestID)
+
+		ctx := logging.WithRequestID(c.Request.Context(), requestID)
+		ctx = logging.WithFields(ctx, logrus.Fields{"request_id": requestID})
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		status := c.Writer.Status()
+		logger := logging.FromContext(c.Request.Context()).WithFields(logrus.Fields{
+			"method":     c.Request.Method,
+			"route":      route,
+			"path":       c.Request.URL.Path,
+			"status":     status,
+			"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+			"bytes":      c.Writer.Size(),
+			"client_ip":  c.ClientIP(),
+			"user_agent": c.Request.UserAgent(),
+		})
+
+		switch {
+		case status >= http.StatusInternalServerError:
+			logger.Error("Request handled")
+		case status >= http.StatusBadRequest:
+			logger.Warn("Request handled")
+		default:
+			logger.Info("Request handled")
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dieg0code/products-microservice/src/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setupRouter := func() (*gin.Engine, *string) {
+		var handlerRequestID string
+		router := gin.New()
+		router.Use(RequestLogger())
+		router.GET("/products/:productID", func(c *gin.Context) {
+			handlerRequestID, _ = logging.RequestIDFromContext(c.Request.Context())
+			logging.FromContext(c.Request.Context()).Info("Handling request")
+			c.Status(http.StatusNotFound)
+		})
+		return router, &handlerRequestID
+	}
+
+	perform := func(router *gin.Engine, requestID string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(http.MethodGet, "/products/1", nil)
+		assert.Nil(t, err, "Expected no error creating request")
+		if requestID != "" {
+			req.Header.Set(logging.RequestIDHeader, requestID)
+		}
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("RequestLogger_Propagates_Request_ID", func(t *testing.T) {
+		hook := test.NewGlobal()
+		defer hook.Reset()
+		router, handlerRequestID := setupRouter()
+
+		rec := perform(router, "caller-id-1")
+
+		assert.Equal(t, "caller-id-1", rec.Header().Get(logging.RequestIDHeader), "Expected the caller's request id")
+		assert.Equal(t, "caller-id-1", *handlerRequestID, "Expected the request id in the context")
+
+		entries := hook.AllEntries()
+		assert.Equal(t, 2, len(entries), "Expected the handler line and the access line")
+		assert.Equal(t, "caller-id-1", entries[0].Data["request_id"], "Expected the handler line to carry the request id")
+	})
+
+	t.Run("RequestLogger_Generates_Request_ID", func(t *testing.T) {
+		hook := test.NewGlobal()
+		defer hook.Reset()
+		router, _ := setupRouter()
+
+		rec := perform(router, "bad id with spaces")
+
+		requestID := rec.Header().Get(logging.RequestIDHeader)
+		assert.NotEqual(t, "bad id with spaces", requestID, "Expected an invalid request id to be replaced")
+		assert.True(t, logging.ValidRequestID(requestID), "Expected a generated request id")
+	})
+
+	t.Run("RequestLogger_Access_Line", func(t *testing.T) {
+		hook := test.NewGlobal()
+		defer hook.Reset()
+		router, _ := setupRouter()
+
+		perform(router, "caller-id-2")
+
+		entry := hook.LastEntry()
+		assert.Equal(t, "Request handled", entry.Message, "Expected the access line")
+		assert.Equal(t, logrus.WarnLevel, entry.Level, "Expected 4xx to be logged as warnings")
+		assert.Equal(t, "caller-id-2", entry.Data["request_id"], "Expected the request id")
+		assert.Equal(t, "/products/:productID", entry.Data["route"], "Expected the route pattern")
+		assert.Equal(t, "/products/1", entry.Data["path"], "Expected the path")
+		assert.Equal(t, http.StatusNotFound, entry.Data["status"], "Expected the status")
+		assert.Contains(t, entry.Data, "latency_ms", "Expected the latency")
+	})
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/dieg0code/products-microservice/src/auth"
+	"github.com/dieg0code/products-microservice/src/logging"
+	"github.com/dieg0code/products-microservice/src/tenant"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const TenantContextKey = "tenant"
+
+type TenantConfig struct {
+	// DefaultTenantID serves requests that ask for no tenant, empty rejects them
+	DefaultTenantID string
+	// BaseDomain enables resolving the tenant from subdomains, acme.<BaseDomain>
+	BaseDomain string
+}
+
+// ResolveTenant stores the tenant of the request in its context. The tenant bound to
+// the caller's credentials wins, then the X-Tenant-ID header, then the subdomain and
+// finally the default tenant. Callers authenticated without a tenant only reach the
+// default tenant. It must run after Authenticate.
+func ResolveTenant(config TenantConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requested := c.GetHeader(tenant.HeaderName)
+		if requested == "" {
+			requested = tenant.FromHost(c.Request.Host, config.BaseDomain)
+		}
+
+		tenantID, err := tenant.Resolve("", requested, config.DefaultTenantID)
+		if principal, ok := auth.PrincipalFromContext(c.Request.Context()); ok {
+			tenantID, err = tenant.ResolveAuthenticated(principal.TenantID, requested, config.DefaultTenantID)
+		}
+		if errors.Is(err, tenant.ErrTenantMismatch) {
+			abortWithError(c, http.StatusForbidden, "Tenant not allowed")
+			return
+		}
+		if errors.Is(err, tenant.ErrMissingTenant) {
+			abortWithError(c, http.StatusBadRequest, "Missing tenant")
+			return
+		}
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, "Invalid tenant")
+			return
+		}
+
+		c.Set(TenantContextKey, tenantID)
+		ctx := logging.WithFields(tenant.WithTenant(c.Request.Context(), tenantID), logrus.Fields{"tenant_id": tenantID})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
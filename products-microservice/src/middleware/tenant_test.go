@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dieg0code/products-microservice/src/auth"
+	"github.com/dieg0code/products-microservice/src/tenant"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setupRouter := func(config TenantConfig, principal *auth.Principal) *gin.Engine {
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		})
+		router.Use(ResolveTenant(config))
+		router.GET("/products", func(c *gin.Context) {
+			tenantID, _ := tenant.FromContext(c.Request.Context())
+			c.String(http.StatusOK, tenantID)
+		})
+		return router
+	}
+
+	perform := func(router *gin.Engine, host string, header string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(http.MethodGet, "/products", nil)
+		assert.Nil(t, err, "Expected no error creating request")
+		req.Host = host
+		if header != "" {
+			req.Header.Set(tenant.HeaderName, header)
+		}
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	config := TenantConfig{DefaultTenantID: tenant.DefaultTenantID, BaseDomain: "products.example.com"}
+
+	t.Run("ResolveTenant_Precedence", func(t *testing.T) {
+		router := setupRouter(config, nil)
+
+		rec := perform(router, "acme.products.example.com", "globex")
+		assert.Equal(t, "globex", rec.Body.String(), "Expected header to win over the subdomain")
+
+		rec = perform(router, "acme.products.example.com:8080", "")
+		assert.Equal(t, "acme", rec.Body.String(), "Expected tenant from the subdomain")
+
+		rec = perform(router, "localhost:8080", "")
+		assert.Equal(t, tenant.DefaultTenantID, rec.Body.String(), "Expected default tenant")
+	})
+
+	t.Run("ResolveTenant_Token_Claim", func(t *testing.T) {
+		router := setupRouter(config, &auth.Principal{Subject: "user-1", TenantID: "acme"})
+
+		rec := perform(router, "localhost", "")
+		assert.Equal(t, "acme", rec.Body.String(), "Expected tenant from the token")
+
+		rec = perform(router, "localhost", "acme")
+		assert.Equal(t, http.StatusOK, rec.Code, "Expected matching header to be accepted")
+
+		rec = perform(router, "localhost", "globex")
+		assert.Equal(t, http.StatusForbidden, rec.Code, "Expected status code 403")
+
+		rec = perform(router, "globex.products.example.com", "")
+		assert.Equal(t, http.StatusForbidden, rec.Code, "Expected status code 403")
+	})
+
+	t.Run("ResolveTenant_Unbound_Principal", func(t *testing.T) {
+		router := setupRouter(config, &auth.Principal{Subject: "user-1"})
+
+		rec := perform(router, "localhost", "")
+		assert.Equal(t, tenant.DefaultTenantID, rec.Body.String(), "Expected the default tenant")
+
+		rec = perform(router, "localhost", "globex")
+		assert.Equal(t, http.StatusForbidden, rec.Code, "Expected other tenants to be rejected")
+
+		rec = perform(router, "globex.products.example.com", "")
+		assert.Equal(t, http.StatusForbidden, rec.Code, "Expected other tenants to be rejected")
+
+		rec = perform(setupRouter(TenantConfig{}, &auth.Principal{Subject: "user-1"}), "localhost", "globex")
+		assert.Equal(t, http.StatusForbidden, rec.Code, "Expected rejection without a default tenant")
+	})
+
+	t.Run("ResolveTenant_Rejected", func(t *testing.T) {
+		router := setupRouter(config, nil)
+
+		rec := perform(router, "localhost", "Not_Valid")
+		assert.Equal(t, http.StatusBadRequest, rec.Code, "Expected status code 400")
+
+		router = setupRouter(TenantConfig{}, nil)
+		rec = perform(router, "localhost", "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code, "Expected status code 400")
+	})
+}
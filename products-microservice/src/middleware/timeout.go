@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// TimeoutConfig bounds how long the handlers of a route may run
+type TimeoutConfig struct {
+	// Default applies to routes without an entry in Routes, zero means no timeout
+	Default time.Duration
+	// Routes overrides Default by method and route pattern, e.g.
+	// "POST /api/v1/products/batch", a zero timeout disables it for the route
+	Routes map[string]time.Duration
+}
+
+// Timeout puts a deadline on the request context and answers 504 Gateway Timeout when
+// it expires. Handlers must stop once the context is done, they are not abandoned,
+// so a handler that ignores the context still holds the request until it returns.
+// The response is buffered to replace whatever the handler wrote on the way out,
+// streaming routes should be given a zero timeout.
+func Timeout(config TimeoutConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout, ok := config.Routes[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			timeout = config.Default
+		}
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		writer := c.Writer
+		buffer := &bufferedResponseWriter{ResponseWriter: writer, body: &bytes.Buffer{}}
+		c.Writer = buffer
+
+		c.Next()
+
+		c.Writer = writer
+		if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			_, _ = writer.Write(buffer.body.Bytes())
+			return
+		}
+
+		logging.FromContext(c.Request.Context()).WithField("timeout", timeout.String()).Warn("Request timed out")
+		header := writer.Header()
+		for _, name := range []string{"Cache-Control", "ETag", "Last-Modified", "Content-Length"} {
+			header.Del(name)
+		}
+		abortWithError(c, http.StatusGatewayTimeout, "Request timed out")
+	}
+}
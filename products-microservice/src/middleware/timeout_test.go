@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	config := TimeoutConfig{
+		Default: 20 * time.Millisecond,
+		Routes: map[string]time.Duration{
+			"GET /stream": 0,
+			"GET /slow":   time.Second,
+		},
+	}
+
+	// waitForContext stands in for a query that honors cancellation
+	waitForContext := func(handlerErr *error) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			select {
+			case <-c.Request.Context().Done():
+				*handlerErr = c.Request.Context().Err()
+				c.JSON(http.StatusInternalServerError, gin.H{"msg": "query cancelled"})
+			case <-time.After(100 * time.Millisecond):
+				c.JSON(http.StatusOK, gin.H{"msg": "done"})
+			}
+		}
+	}
+
+	perform := func(router *gin.Engine, path string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(http.MethodGet, path, nil)
+		assert.Nil(t, err, "Expected no error creating request")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Timeout_Cancels_Handler", func(t *testing.T) {
+		var handlerErr error
+		router := gin.New()
+		router.Use(Timeout(config))
+		router.GET("/products", waitForContext(&handlerErr))
+
+		start := time.Now()
+		rec := perform(router, "/products")
+
+		assert.Equal(t, http.StatusGatewayTimeout, rec.Code, "Expected status code 504")
+		assert.ErrorIs(t, handlerErr, context.DeadlineExceeded, "Expected the handler's context to expire")
+		assert.Less(t, time.Since(start), 100*time.Millisecond, "Expected the handler to stop at the deadline")
+
+		var body response.BaseResponse
+		err := json.Unmarshal(rec.Body.Bytes(), &body)
+		assert.Nil(t, err, "Expected a JSON error body")
+		assert.Equal(t, http.StatusGatewayTimeout, body.Code, "Expected response code 504")
+	})
+
+	t.Run("Timeout_Route_Override", func(t *testing.T) {
+		var handlerErr error
+		router := gin.New()
+		router.Use(Timeout(config))
+		router.GET("/slow", waitForContext(&handlerErr))
+
+		rec := perform(router, "/slow")
+
+		assert.Equal(t, http.StatusOK, rec.Code, "Expected the longer route timeout to apply")
+		assert.Nil(t, handlerErr, "Expected the handler not to be cancelled")
+		assert.JSONEq(t, `{"msg":"done"}`, rec.Body.String(), "Expected the handler's body")
+	})
+
+	t.Run("Timeout_Disabled_For_Route", func(t *testing.T) {
+		var deadlineSet bool
+		router := gin.New()
+		router.Use(Timeout(config))
+		router.GET("/stream", func(c *gin.Context) {
+			_, deadlineSet = c.Request.Context().Deadline()
+			c.Status(http.StatusOK)
+		})
+
+		rec := perform(router, "/stream")
+
+		assert.Equal(t, http.StatusOK, rec.Code, "Expected status code 200")
+		assert.False(t, deadlineSet, "Expected no deadline on the route")
+	})
+}
@@ -0,0 +1,312 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Every dialect has its own directory of NNNN_name.up.sql and NNNN_name.down.sql
+// files holding the same versions. sqlite mirrors postgres so the migrations can be
+// tested without a server.
+//
+//go:embed postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+// lockKey identifies the advisory lock held while migrating, so pods starting
+// together wait for the first one instead of migrating twice
+const lockKey = 0x70726f6475637473
+
+const createTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version BIGINT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    applied_at TIMESTAMP NOT NULL
+)`
+
+var ErrUnsupportedDialect = errors.New("migrations: unsupported dialect")
+
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status is a known migration, AppliedAt is nil while it is pending
+type Status struct {
+	Version   uint64     `json:"version" yaml:"version"`
+	Name      string     `json:"name" yaml:"name"`
+	AppliedAt *time.Time `json:"applied_at" yaml:"applied_at"`
+}
+
+type dialect struct {
+	lock        string
+	unlock      string
+	placeholder func(n int) string
+}
+
+var dialects = map[string]dialect{
+	"postgres": {
+		lock:        fmt.Sprintf("SELECT pg_advisory_lock(%d)", lockKey),
+		unlock:      fmt.Sprintf("SELECT pg_advisory_unlock(%d)", lockKey),
+		placeholder: func(n int) string { return "$" + strconv.Itoa(n) },
+	},
+	// A SQLite database is a local file, there are no other pods to wait for
+	"sqlite": {
+		placeholder: func(int) string { return "?" },
+	},
+}
+
+// Migrator applies the migrations on the primary database. It uses database/sql
+// directly, so neither the replica routing nor the tenant callbacks get in the way.
+type Migrator struct {
+	db         *sql.DB
+	dialect    dialect
+	migrations []Migration
+}
+
+// Up applies every pending migration in order, each in its own transaction, and
+// returns the ones it applied
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		versions, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := versions[migration.Version]; ok {
+				continue
+			}
+
+			err = m.run(ctx, conn, migration, migration.Up,
+				"INSERT INTO schema_migrations (version, name, applied_at) VALUES ("+m.dialect.placeholder(1)+", "+m.dialect.placeholder(2)+", "+m.dialect.placeholder(3)+")",
+				migration.Version, migration.Name, time.Now().UTC())
+			if err != nil {
+				return err
+			}
+			logrus.WithField("version", migration.Version).Infof("Applied migration %s", migration.Name)
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the last steps applied migrations, newest first, and returns the ones it reverted
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		versions, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := versions[migration.Version]; !ok {
+				continue
+			}
+
+			err = m.run(ctx, conn, migration, migration.Down,
+				"DELETE FROM schema_migrations WHERE version = "+m.dialect.placeholder(1),
+				migration.Version)
+			if err != nil {
+				return err
+			}
+			logrus.WithField("version", migration.Version).Infof("Reverted migration %s", migration.Name)
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status lists every known migration in order
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, createTable)
+	if err != nil {
+		return nil, fmt.Errorf("creating schema_migrations: %w", err)
+	}
+	versions, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if appliedAt, ok := versions[migration.Version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// locked runs fc on a single connection holding the advisory lock, advisory locks
+// belong to the session that took them
+func (m *Migrator) locked(ctx context.Context, fc func(conn *sql.Conn) error) (err error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if m.dialect.lock != "" {
+		_, err = conn.ExecContext(ctx, m.dialect.lock)
+		if err != nil {
+			return fmt.Errorf("taking the migration lock: %w", err)
+		}
+		defer func() {
+			// The lock must be released even when ctx is done, or the pooled
+			// connection would keep holding it
+			_, unlockErr := conn.ExecContext(context.WithoutCancel(ctx), m.dialect.unlock)
+			if unlockErr != nil {
+				err = errors.Join(err, fmt.Errorf("releasing the migration lock: %w", unlockErr))
+			}
+		}()
+	}
+
+	_, err = conn.ExecContext(ctx, createTable)
+	if err != nil {
+		return fmt.Errorf("creating schema_migrations: %w", err)
+	}
+	return fc(conn)
+}
+
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[uint64]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("reading schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	versions := make(map[uint64]time.Time)
+	for rows.Next() {
+		var version uint64
+		var appliedAt time.Time
+		err = rows.Scan(&version, &appliedAt)
+		if err != nil {
+			return nil, fmt.Errorf("reading schema_migrations: %w", err)
+		}
+		versions[version] = appliedAt
+	}
+	return versions, rows.Err()
+}
+
+// run executes script and the bookkeeping statement in one transaction, so a failed
+// migration leaves neither behind
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, migration Migration, script string, bookkeeping string, args ...interface{}) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, script)
+	if err != nil {
+		return fmt.Errorf("migration %04d_%s: %w", migration.Version, migration.Name, err)
+	}
+	_, err = tx.ExecContext(ctx, bookkeeping, args...)
+	if err != nil {
+		return fmt.Errorf("migration %04d_%s: %w", migration.Version, migration.Name, err)
+	}
+	return tx.Commit()
+}
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// load reads the migrations of fsys sorted by version, every version needs both an
+// up and a down file
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migrations: unexpected file %q, want NNNN_name.up.sql or NNNN_name.down.sql", entry.Name())
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migrations: invalid version in %q: %w", entry.Name(), err)
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migrations: version %d is used by both %q and %q", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migrations: %04d_%s needs both an up and a down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// NewMigrator returns a Migrator for the primary database of db, with the migrations
+// of its dialect
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	name := db.Dialector.Name()
+	dialect, ok := dialects[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedDialect, name)
+	}
+
+	sub, err := fs.Sub(files, name)
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := load(sub)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: sqlDB, dialect: dialect, migrations: migrations}, nil
+}
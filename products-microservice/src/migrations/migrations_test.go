@@ -0,0 +1,178 @@
+package migrations
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dieg0code/products-microservice/src/models"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+	tables := []interface{}{&models.Product{}, &models.ProcessedSale{}, &models.IdempotencyRecord{}, &models.ApiKey{}}
+
+	setup := func(t *testing.T, name string) (*gorm.DB, *Migrator) {
+		db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), name)), &gorm.Config{})
+		assert.Nil(t, err, "Expected no error opening the database")
+		t.Cleanup(func() {
+			sqlDB, _ := db.DB()
+			sqlDB.Close()
+		})
+
+		migrator, err := NewMigrator(db)
+		assert.Nil(t, err, "Expected no error creating the migrator")
+		return db, migrator
+	}
+
+	t.Run("Up_Applies_Pending_Migrations", func(t *testing.T) {
+		db, migrator := setup(t, "products.db")
+
+		applied, err := migrator.Up(ctx)
+		assert.Nil(t, err, "Expected no error")
+		assert.Len(t, applied, len(migrator.migrations), "Expected every migration to be applied")
+		for _, table := range tables {
+			assert.True(t, db.Migrator().HasTable(table), "Expected the table of %T", table)
+		}
+
+		applied, err = migrator.Up(ctx)
+		assert.Nil(t, err, "Expected no error")
+		assert.Empty(t, applied, "Expected nothing left to apply")
+	})
+
+	t.Run("Up_Matches_Models", func(t *testing.T) {
+		db, migrator := setup(t, "migrated.db")
+		_, err := migrator.Up(ctx)
+		assert.Nil(t, err, "Expected no error")
+
+		autoMigrated, _ := setup(t, "auto_migrated.db")
+		assert.Nil(t, autoMigrated.AutoMigrate(tables...), "Expected no error auto migrating")
+
+		for _, table := range tables {
+			assert.Equal(t, columns(t, autoMigrated, table), columns(t, db, table), "Expected the columns of %T to match the model", table)
+			assert.Equal(t, indexes(t, autoMigrated, table), indexes(t, db, table), "Expected the indexes of %T to match the model", table)
+		}
+	})
+
+	t.Run("Down_Reverts_Newest_First", func(t *testing.T) {
+		db, migrator := setup(t, "products.db")
+		_, err := migrator.Up(ctx)
+		assert.Nil(t, err, "Expected no error")
+
+		reverted, err := migrator.Down(ctx, 1)
+		assert.Nil(t, err, "Expected no error")
+		assert.Len(t, reverted, 1, "Expected one migration to be reverted")
+		assert.Equal(t, migrator.migrations[len(migrator.migrations)-1].Version, reverted[0].Version, "Expected the newest migration to be reverted")
+		assert.False(t, db.Migrator().HasColumn(&models.ApiKey{}, "tenant_id"), "Expected the tenant_id column to be dropped")
+		assert.True(t, db.Migrator().HasColumn(&models.IdempotencyRecord{}, "locked_until"), "Expected the locked_until column to be kept")
+
+		reverted, err = migrator.Down(ctx, 100)
+		assert.Nil(t, err, "Expected no error")
+		assert.Len(t, reverted, len(migrator.migrations)-1, "Expected the remaining migrations to be reverted")
+		assert.False(t, db.Migrator().HasTable(&models.Product{}), "Expected the products table to be dropped")
+	})
+
+	t.Run("Status", func(t *testing.T) {
+		_, migrator := setup(t, "products.db")
+
+		statuses, err := migrator.Status(ctx)
+		assert.Nil(t, err, "Expected no error")
+		assert.Len(t, statuses, len(migrator.migrations), "Expected every migration")
+		assert.Nil(t, statuses[0].AppliedAt, "Expected the migrations to be pending")
+
+		_, err = migrator.Up(ctx)
+		assert.Nil(t, err, "Expected no error")
+		_, err = migrator.Down(ctx, 1)
+		assert.Nil(t, err, "Expected no error")
+
+		statuses, err = migrator.Status(ctx)
+		assert.Nil(t, err, "Expected no error")
+		assert.NotNil(t, statuses[0].AppliedAt, "Expected the first migration to be applied")
+		assert.Nil(t, statuses[len(statuses)-1].AppliedAt, "Expected the reverted migration to be pending")
+	})
+
+	t.Run("Up_Failure_Rolls_Back", func(t *testing.T) {
+		db, migrator := setup(t, "products.db")
+		migrator.migrations = append(migrator.migrations, Migration{Version: 9999, Name: "broken", Up: "CREATE TABLE broken (id INTEGER); NOT SQL", Down: "DROP TABLE broken"})
+
+		_, err := migrator.Up(ctx)
+		assert.ErrorContains(t, err, "9999_broken", "Expected the failed migration in the error")
+		assert.False(t, db.Migrator().HasTable("broken"), "Expected the failed migration to be rolled back")
+
+		statuses, err := migrator.Status(ctx)
+		assert.Nil(t, err, "Expected no error")
+		assert.NotNil(t, statuses[0].AppliedAt, "Expected the previous migrations to stay applied")
+		assert.Nil(t, statuses[len(statuses)-1].AppliedAt, "Expected the failed migration to stay pending")
+	})
+
+	t.Run("Dialects_Have_The_Same_Versions", func(t *testing.T) {
+		var want []Migration
+		for name := range dialects {
+			sub, err := fs.Sub(files, name)
+			assert.Nil(t, err, "Expected the %s directory", name)
+			migrations, err := load(sub)
+			assert.Nil(t, err, "Expected no error loading %s", name)
+
+			var versions []Migration
+			for _, migration := range migrations {
+				versions = append(versions, Migration{Version: migration.Version, Name: migration.Name})
+			}
+			if want == nil {
+				want = versions
+			}
+			assert.Equal(t, want, versions, "Expected %s to have the same migrations", name)
+		}
+	})
+
+	t.Run("Load_Errors", func(t *testing.T) {
+		_, err := load(fstest.MapFS{"0001_products.up.sql": {Data: []byte("SELECT 1")}})
+		assert.ErrorContains(t, err, "needs both an up and a down file", "Expected the missing down file")
+
+		_, err = load(fstest.MapFS{"products.sql": {Data: []byte("SELECT 1")}})
+		assert.ErrorContains(t, err, "unexpected file", "Expected the misnamed file")
+
+		_, err = load(fstest.MapFS{
+			"0001_products.up.sql":   {Data: []byte("SELECT 1")},
+			"0001_products.down.sql": {Data: []byte("SELECT 1")},
+			"0001_sales.up.sql":      {Data: []byte("SELECT 1")},
+		})
+		assert.ErrorContains(t, err, "version 1 is used by both", "Expected the duplicated version")
+	})
+}
+
+func columns(t *testing.T, db *gorm.DB, table interface{}) map[string]string {
+	columnTypes, err := db.Migrator().ColumnTypes(table)
+	assert.Nil(t, err, "Expected no error reading the columns")
+
+	columns := make(map[string]string)
+	for _, column := range columnTypes {
+		nullable, _ := column.Nullable()
+		columns[column.Name()] = strings.ToUpper(column.DatabaseTypeName())
+		if !nullable {
+			columns[column.Name()] += " NOT NULL"
+		}
+	}
+	return columns
+}
+
+func indexes(t *testing.T, db *gorm.DB, table interface{}) map[string][]string {
+	found, err := db.Migrator().GetIndexes(table)
+	assert.Nil(t, err, "Expected no error reading the indexes")
+
+	indexes := make(map[string][]string)
+	for _, index := range found {
+		unique, _ := index.Unique()
+		key := index.Name()
+		if unique {
+			key += " UNIQUE"
+		}
+		indexes[key] = index.Columns()
+	}
+	return indexes
+}
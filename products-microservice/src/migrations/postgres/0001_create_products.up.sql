@@ -0,0 +1,20 @@
+-- Databases created by AutoMigrate already hold the tables, so every statement is
+-- safe to run against them
+CREATE TABLE IF NOT EXISTS products (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    tenant_id VARCHAR(63) NOT NULL DEFAULT 'default',
+    name VARCHAR(100) NOT NULL,
+    category VARCHAR(100) NOT NULL,
+    price INT NOT NULL,
+    stock INT NOT NULL
+);
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT 'default';
+-- Names used to be unique across tenants
+ALTER TABLE products DROP CONSTRAINT IF EXISTS uni_products_name;
+
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at ON products (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_tenant_name ON products (tenant_id, name);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS processed_sales (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    tenant_id VARCHAR(63) NOT NULL DEFAULT 'default',
+    sale_id VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL
+);
+
+ALTER TABLE processed_sales ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT 'default';
+-- Sale IDs used to be unique across tenants
+DROP INDEX IF EXISTS idx_processed_sales_sale_id;
+
+CREATE INDEX IF NOT EXISTS idx_processed_sales_deleted_at ON processed_sales (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_processed_sales_tenant_sale ON processed_sales (tenant_id, sale_id);
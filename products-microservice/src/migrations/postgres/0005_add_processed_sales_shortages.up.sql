@@ -0,0 +1 @@
+ALTER TABLE processed_sales ADD COLUMN IF NOT EXISTS shortages TEXT;
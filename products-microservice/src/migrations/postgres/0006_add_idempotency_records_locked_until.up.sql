@@ -0,0 +1 @@
+ALTER TABLE idempotency_records ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ;
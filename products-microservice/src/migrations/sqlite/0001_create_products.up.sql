@@ -0,0 +1,14 @@
+CREATE TABLE products (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    tenant_id VARCHAR(63) NOT NULL DEFAULT 'default',
+    name VARCHAR(100) NOT NULL,
+    category VARCHAR(100) NOT NULL,
+    price INTEGER NOT NULL,
+    stock INTEGER NOT NULL
+);
+
+CREATE INDEX idx_products_deleted_at ON products (deleted_at);
+CREATE UNIQUE INDEX idx_products_tenant_name ON products (tenant_id, name);
@@ -0,0 +1,12 @@
+CREATE TABLE processed_sales (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    tenant_id VARCHAR(63) NOT NULL DEFAULT 'default',
+    sale_id VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL
+);
+
+CREATE INDEX idx_processed_sales_deleted_at ON processed_sales (deleted_at);
+CREATE UNIQUE INDEX idx_processed_sales_tenant_sale ON processed_sales (tenant_id, sale_id);
@@ -0,0 +1,13 @@
+CREATE TABLE idempotency_records (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    "key" VARCHAR(320) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    content_type VARCHAR(100),
+    body TEXT,
+    created_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_idempotency_records_key ON idempotency_records ("key");
+CREATE INDEX idx_idempotency_records_expires_at ON idempotency_records (expires_at);
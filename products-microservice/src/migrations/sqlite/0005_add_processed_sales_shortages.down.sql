@@ -0,0 +1 @@
+ALTER TABLE processed_sales DROP COLUMN shortages;
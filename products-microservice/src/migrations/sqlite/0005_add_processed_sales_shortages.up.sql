@@ -0,0 +1 @@
+ALTER TABLE processed_sales ADD COLUMN shortages TEXT;
@@ -0,0 +1 @@
+ALTER TABLE idempotency_records DROP COLUMN locked_until;
@@ -0,0 +1 @@
+ALTER TABLE idempotency_records ADD COLUMN locked_until DATETIME;
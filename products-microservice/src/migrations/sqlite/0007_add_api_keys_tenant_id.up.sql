@@ -0,0 +1,3 @@
+ALTER TABLE api_keys ADD COLUMN tenant_id VARCHAR(63) NOT NULL DEFAULT 'default';
+
+CREATE INDEX idx_api_keys_tenant_id ON api_keys (tenant_id);
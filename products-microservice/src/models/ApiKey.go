@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ApiKey only stores the hash of the key, Prefix identifies it without revealing it.
+// A key only gives access to the tenant it was created in.
+type ApiKey struct {
+	gorm.Model
+	// TenantID is assigned by the tenant callbacks from the statement context
+	TenantID   string `gorm:"type:varchar(63);not null;default:'default';index"`
+	Name       string `gorm:"type:varchar(100);not null"`
+	Prefix     string `gorm:"type:varchar(32);uniqueIndex;not null"`
+	KeyHash    string `gorm:"type:varchar(64);not null"`
+	Scopes     string `gorm:"type:varchar(255);not null"`
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// IdempotencyRecord stores the first response produced for an Idempotency-Key.
+// A zero StatusCode means the original request is still being processed, until
+// LockedUntil passes and a retry may take it over. Keys are prefixed with the
+// tenant so one tenant can never replay another's response.
+type IdempotencyRecord struct {
+	ID          uint      `gorm:"primarykey"`
+	Key         string    `gorm:"type:varchar(320);uniqueIndex;not null"`
+	RequestHash string    `gorm:"type:varchar(64);not null"`
+	StatusCode  int       `gorm:"type:int;not null;default:0"`
+	ContentType string    `gorm:"type:varchar(100)"`
+	Body        string    `gorm:"type:text"`
+	CreatedAt   time.Time `gorm:"not null"`
+	ExpiresAt   time.Time `gorm:"index;not null"`
+	LockedUntil *time.Time
+}
@@ -0,0 +1,18 @@
+package models
+
+import "gorm.io/gorm"
+
+const (
+	SaleStatusApplied  = "applied"
+	SaleStatusRejected = "rejected"
+)
+
+type ProcessedSale struct {
+	gorm.Model
+	// TenantID is assigned by the tenant callbacks from the statement context
+	TenantID string `gorm:"type:varchar(63);not null;default:'default';uniqueIndex:idx_processed_sales_tenant_sale,priority:1"`
+	SaleID   string `gorm:"type:varchar(100);not null;uniqueIndex:idx_processed_sales_tenant_sale,priority:2"`
+	Status   string `gorm:"type:varchar(20);not null"`
+	// Shortages holds the JSON encoded shortages of a rejected sale
+	Shortages string `gorm:"type:text"`
+}
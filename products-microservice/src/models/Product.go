@@ -4,7 +4,9 @@ import "gorm.io/gorm"
 
 type Product struct {
 	gorm.Model
-	Name     string `gorm:"type:varchar(100);unique;not null"`
+	// TenantID is assigned by the tenant callbacks from the statement context
+	TenantID string `gorm:"type:varchar(63);not null;default:'default';uniqueIndex:idx_products_tenant_name,priority:1"`
+	Name     string `gorm:"type:varchar(100);not null;uniqueIndex:idx_products_tenant_name,priority:2"`
 	Category string `gorm:"type:varchar(100);not null"`
 	Price    int    `gorm:"type:int;not null"`
 	Stock    int    `gorm:"type:int;not null"`
@@ -0,0 +1,10 @@
+package models
+
+// ProductFilter narrows product searches, zero values are ignored
+type ProductFilter struct {
+	Category     string
+	NameContains string
+	MinPrice     *int
+	MaxPrice     *int
+	InStock      *bool
+}
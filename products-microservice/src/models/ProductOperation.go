@@ -0,0 +1,19 @@
+package models
+
+const (
+	ProductOperationCreate = "create"
+	ProductOperationUpdate = "update"
+	ProductOperationDelete = "delete"
+)
+
+// ProductOperation is a single write inside a batch, Product is unused for deletes
+type ProductOperation struct {
+	Op        string
+	ProductID uint
+	Product   *Product
+}
+
+type ProductOperationResult struct {
+	Product *Product
+	Err     error
+}
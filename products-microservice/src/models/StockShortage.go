@@ -0,0 +1,8 @@
+package models
+
+// StockShortage describes a product that cannot cover the requested quantity
+type StockShortage struct {
+	ProductID uint
+	Requested int
+	Available int
+}
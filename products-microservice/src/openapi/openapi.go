@@ -0,0 +1,302 @@
+// Package openapi builds the OpenAPI 3.1 document of the HTTP API from the Go types
+// the handlers bind and return.
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const Version = "3.1.0"
+
+const (
+	InPath   = "path"
+	InQuery  = "query"
+	InHeader = "header"
+)
+
+const ContentTypeJSON = "application/json"
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds the operations of a path by lower case HTTP method
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []SecurityRequirement `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Description string                `json:"description,omitempty"`
+	Required    bool                  `json:"required,omitempty"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Description  string `json:"description,omitempty"`
+	Name         string `json:"name,omitempty"`
+	In           string `json:"in,omitempty"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// SecurityRequirement maps security scheme names to the scopes they need
+type SecurityRequirement map[string][]string
+
+// Builder collects the operations of a Document, checking them as they are added
+type Builder struct {
+	doc       *Document
+	reflector *reflector
+	errs      []error
+}
+
+// RequestSchema describes v as a request body, required fields are the ones its
+// validate tags require
+func (b *Builder) RequestSchema(v interface{}) *Schema {
+	return b.reflector.schema(reflect.TypeOf(v), false)
+}
+
+// ResponseSchema describes v as encoding/json writes it
+func (b *Builder) ResponseSchema(v interface{}) *Schema {
+	return b.reflector.schema(reflect.TypeOf(v), true)
+}
+
+// ResponseObject is ResponseSchema without the reference, for callers adjusting
+// the properties of a struct
+func (b *Builder) ResponseObject(v interface{}) *Schema {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return b.reflector.object(t, true)
+}
+
+// AddSchema registers a component and returns a reference to it
+func (b *Builder) AddSchema(name string, schema *Schema) *Schema {
+	if _, ok := b.reflector.schemas[name]; ok {
+		b.errs = append(b.errs, fmt.Errorf("schema %s is registered twice", name))
+	}
+	b.reflector.schemas[name] = schema
+	b.reflector.owners[name] = component{}
+	return &Schema{Ref: schemaRefPrefix + name}
+}
+
+func (b *Builder) AddSecurityScheme(name string, scheme *SecurityScheme) {
+	b.doc.Components.SecuritySchemes[name] = scheme
+}
+
+// JSONBody is a required JSON request body of type v
+func (b *Builder) JSONBody(v interface{}) *RequestBody {
+	return &RequestBody{Required: true, Content: map[string]*MediaType{ContentTypeJSON: {Schema: b.RequestSchema(v)}}}
+}
+
+// Add documents the operation of a route, path uses the gin syntax the route is
+// registered with
+func (b *Builder) Add(method string, path string, operation *Operation) {
+	path, params := ginPath(path)
+	key := strings.ToUpper(method) + " " + path
+
+	declared := map[string]bool{}
+	for _, param := range operation.Parameters {
+		if param.In == InPath {
+			declared[param.Name] = true
+		}
+	}
+	for _, param := range params {
+		if !declared[param] {
+			b.errs = append(b.errs, fmt.Errorf("%s: path parameter %s is not declared", key, param))
+		}
+		delete(declared, param)
+	}
+	for param := range declared {
+		b.errs = append(b.errs, fmt.Errorf("%s: parameter %s is not in the path", key, param))
+	}
+
+	if operation.OperationID == "" {
+		b.errs = append(b.errs, fmt.Errorf("%s: missing operationId", key))
+	}
+	for otherPath, item := range b.doc.Paths {
+		for otherMethod, other := range *item {
+			if other.OperationID == operation.OperationID {
+				b.errs = append(b.errs, fmt.Errorf("%s: operationId %s is already used by %s %s", key, operation.OperationID, strings.ToUpper(otherMethod), otherPath))
+			}
+		}
+	}
+	if len(operation.Responses) == 0 {
+		b.errs = append(b.errs, fmt.Errorf("%s: no responses", key))
+	}
+
+	item, ok := b.doc.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		b.doc.Paths[path] = item
+	}
+	if _, ok := (*item)[strings.ToLower(method)]; ok {
+		b.errs = append(b.errs, fmt.Errorf("%s is documented twice", key))
+	}
+	(*item)[strings.ToLower(method)] = operation
+}
+
+// Document returns the document, or every problem found while building it
+func (b *Builder) Document() (*Document, error) {
+	errs := append(append([]error{}, b.errs...), b.reflector.errs...)
+
+	// Operations can only name schemes and components that exist
+	var refs []string
+	walk := func(schema *Schema) { collectRefs(schema, &refs) }
+	for path, item := range b.doc.Paths {
+		for method, operation := range *item {
+			for _, param := range operation.Parameters {
+				walk(param.Schema)
+			}
+			if operation.RequestBody != nil {
+				for _, media := range operation.RequestBody.Content {
+					walk(media.Schema)
+				}
+			}
+			for _, res := range operation.Responses {
+				for _, media := range res.Content {
+					walk(media.Schema)
+				}
+				for _, header := range res.Headers {
+					walk(header.Schema)
+				}
+			}
+			for _, requirement := range operation.Security {
+				for name := range requirement {
+					if _, ok := b.doc.Components.SecuritySchemes[name]; !ok {
+						errs = append(errs, fmt.Errorf("%s %s: unknown security scheme %s", strings.ToUpper(method), path, name))
+					}
+				}
+			}
+		}
+	}
+	for _, schema := range b.reflector.schemas {
+		walk(schema)
+	}
+	sort.Strings(refs)
+	for i, ref := range refs {
+		if i > 0 && refs[i-1] == ref {
+			continue
+		}
+		if _, ok := b.reflector.schemas[strings.TrimPrefix(ref, schemaRefPrefix)]; !ok {
+			errs = append(errs, fmt.Errorf("unresolved reference %s", ref))
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	b.doc.Components.Schemas = b.reflector.schemas
+	return b.doc, nil
+}
+
+// Resolve follows a reference to a component of the document
+func (d *Document) Resolve(schema *Schema) *Schema {
+	for schema != nil && schema.Ref != "" {
+		schema = d.Components.Schemas[strings.TrimPrefix(schema.Ref, schemaRefPrefix)]
+	}
+	return schema
+}
+
+func collectRefs(schema *Schema, refs *[]string) {
+	if schema == nil {
+		return
+	}
+	if schema.Ref != "" {
+		*refs = append(*refs, schema.Ref)
+	}
+	collectRefs(schema.Items, refs)
+	collectRefs(schema.AdditionalProperties, refs)
+	for _, property := range schema.Properties {
+		collectRefs(property, refs)
+	}
+	for _, alternative := range schema.AnyOf {
+		collectRefs(alternative, refs)
+	}
+}
+
+// ginPath turns /products/:productID into /products/{productID}
+func ginPath(path string) (string, []string) {
+	var params []string
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			params = append(params, segment[1:])
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// JSONResponse is a response with a JSON body described by schema
+func JSONResponse(description string, schema *Schema) *Response {
+	return &Response{Description: description, Content: map[string]*MediaType{ContentTypeJSON: {Schema: schema}}}
+}
+
+func NewBuilder(info Info) *Builder {
+	return &Builder{
+		doc: &Document{
+			OpenAPI: Version,
+			Info:    info,
+			Paths:   map[string]*PathItem{},
+			Components: Components{
+				SecuritySchemes: map[string]*SecurityScheme{},
+			},
+		},
+		reflector: &reflector{
+			schemas: map[string]*Schema{},
+			owners:  map[string]component{},
+		},
+	}
+}
@@ -0,0 +1,78 @@
+package openapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder(t *testing.T) {
+
+	idParam := &Parameter{Name: "itemID", In: InPath, Required: true, Schema: &Schema{Type: Types{TypeInteger}}}
+	ok := func(b *Builder) map[string]*Response {
+		return map[string]*Response{"200": JSONResponse("The item", b.ResponseSchema(testItemResponse{}))}
+	}
+
+	t.Run("Document_Success", func(t *testing.T) {
+		b := NewBuilder(Info{Title: "Items", Version: "1.0.0"})
+		b.Add(http.MethodGet, "/items/:itemID", &Operation{OperationID: "getItem", Parameters: []*Parameter{idParam}, Responses: ok(b)})
+		b.Add(http.MethodPut, "/items/:itemID", &Operation{OperationID: "updateItem", Parameters: []*Parameter{idParam}, RequestBody: b.JSONBody(testItemRequest{}), Responses: ok(b)})
+
+		document, err := b.Document()
+		assert.Nil(t, err, "Expected no error")
+		assert.Equal(t, Version, document.OpenAPI, "Expected the OpenAPI version")
+
+		item := document.Paths["/items/{itemID}"]
+		assert.NotNil(t, item, "Expected gin parameters as path templates")
+		assert.Contains(t, *item, "get", "Expected operations by lower case method")
+		assert.Contains(t, *item, "put", "Expected operations by lower case method")
+		assert.Contains(t, document.Components.Schemas, "testItemRequest", "Expected the request schema")
+
+		resolved := document.Resolve((*item)["get"].Responses["200"].Content[ContentTypeJSON].Schema)
+		assert.Contains(t, resolved.Properties, "item_id", "Expected references to resolve to their component")
+	})
+
+	t.Run("Document_Undeclared_Path_Parameter", func(t *testing.T) {
+		b := NewBuilder(Info{})
+		b.Add(http.MethodGet, "/items/:itemID", &Operation{OperationID: "getItem", Responses: ok(b)})
+		b.Add(http.MethodGet, "/items", &Operation{OperationID: "listItems", Parameters: []*Parameter{idParam}, Responses: ok(b)})
+
+		_, err := b.Document()
+		assert.ErrorContains(t, err, "path parameter itemID is not declared", "Expected path parameters to be declared")
+		assert.ErrorContains(t, err, "parameter itemID is not in the path", "Expected declared parameters to be in the path")
+	})
+
+	t.Run("Document_Duplicates", func(t *testing.T) {
+		b := NewBuilder(Info{})
+		b.Add(http.MethodGet, "/items", &Operation{OperationID: "listItems", Responses: ok(b)})
+		b.Add(http.MethodGet, "/items", &Operation{OperationID: "listItems", Responses: ok(b)})
+		b.Add(http.MethodPost, "/items", &Operation{Responses: ok(b)})
+
+		_, err := b.Document()
+		assert.ErrorContains(t, err, "operationId listItems is already used", "Expected operation IDs to be unique")
+		assert.ErrorContains(t, err, "GET /items is documented twice", "Expected each route to be documented once")
+		assert.ErrorContains(t, err, "POST /items: missing operationId", "Expected operation IDs to be required")
+	})
+
+	t.Run("Document_Unresolved_References", func(t *testing.T) {
+		b := NewBuilder(Info{})
+		b.Add(http.MethodGet, "/items", &Operation{
+			OperationID: "listItems",
+			Responses:   map[string]*Response{"200": JSONResponse("Items", &Schema{Ref: schemaRefPrefix + "Missing"})},
+			Security:    []SecurityRequirement{{"bearerAuth": {}}},
+		})
+
+		_, err := b.Document()
+		assert.ErrorContains(t, err, "unresolved reference #/components/schemas/Missing", "Expected references to exist")
+		assert.ErrorContains(t, err, "unknown security scheme bearerAuth", "Expected security schemes to exist")
+	})
+
+	t.Run("SwaggerUI", func(t *testing.T) {
+		page := string(SwaggerUI("openapi.json"))
+		assert.Contains(t, page, `url: "openapi.json"`, "Expected the page to load the document")
+		assert.NotContains(t, page, "{{SPEC_URL}}", "Expected the placeholder to be replaced")
+		assert.Regexp(t, `swagger-ui-dist@\d+\.\d+\.\d+/`, page, "Expected the assets to be pinned to an exact version")
+		assert.NotContains(t, page, "swagger-ui-dist@5/", "Expected no floating major version")
+	})
+}
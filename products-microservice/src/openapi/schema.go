@@ -0,0 +1,351 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const schemaRefPrefix = "#/components/schemas/"
+
+// Schema is the subset of JSON Schema 2020-12, the dialect of OpenAPI 3.1, that the
+// request and response types need
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 Types              `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+}
+
+// Types is the type keyword, written as a string when there is a single type
+type Types []string
+
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// Has reports whether name is one of the types
+func (t Types) Has(name string) bool {
+	for _, candidate := range t {
+		if candidate == name {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	TypeObject  = "object"
+	TypeArray   = "array"
+	TypeString  = "string"
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+	TypeNull    = "null"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// reflector turns Go types into schemas. Named structs become components so clients
+// get one type per DTO. Requests take required fields from their validate tags, the
+// way go-playground checks them, responses from their json tags, the way
+// encoding/json writes them.
+type reflector struct {
+	schemas map[string]*Schema
+	owners  map[string]component
+	errs    []error
+}
+
+type component struct {
+	typ      reflect.Type
+	response bool
+}
+
+func (r *reflector) schema(t reflect.Type, response bool) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() == reflect.Struct && t != timeType && t.Name() != "" {
+		return r.component(t, response)
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if t == timeType {
+			return &Schema{Type: Types{TypeString}, Format: "date-time"}
+		}
+		return r.object(t, response)
+	case reflect.String:
+		return &Schema{Type: Types{TypeString}}
+	case reflect.Bool:
+		return &Schema{Type: Types{TypeBoolean}}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: Types{TypeInteger}, Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: Types{TypeInteger}, Format: "int32"}
+	case reflect.Uint, reflect.Uint64:
+		return &Schema{Type: Types{TypeInteger}, Format: "int64", Minimum: float(0)}
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: Types{TypeInteger}, Format: "int32", Minimum: float(0)}
+	case reflect.Float32:
+		return &Schema{Type: Types{TypeNumber}, Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: Types{TypeNumber}, Format: "double"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: Types{TypeArray}, Items: r.schema(t.Elem(), response)}
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			r.errs = append(r.errs, fmt.Errorf("unsupported map key %s", t.Key()))
+		}
+		return &Schema{Type: Types{TypeObject}, AdditionalProperties: r.schema(t.Elem(), response)}
+	case reflect.Interface:
+		// Anything goes
+		return &Schema{}
+	default:
+		r.errs = append(r.errs, fmt.Errorf("unsupported type %s", t))
+		return &Schema{}
+	}
+}
+
+// component registers t under its name and returns a reference to it
+func (r *reflector) component(t reflect.Type, response bool) *Schema {
+	ref := &Schema{Ref: schemaRefPrefix + t.Name()}
+
+	owner, ok := r.owners[t.Name()]
+	if ok {
+		if owner.typ != t {
+			r.errs = append(r.errs, fmt.Errorf("schema %s is both %s and %s", t.Name(), owner.typ, t))
+		} else if owner.response != response {
+			r.errs = append(r.errs, fmt.Errorf("schema %s is used by both requests and responses", t.Name()))
+		}
+		return ref
+	}
+
+	// Registered before the fields so recursive types end in a reference
+	r.owners[t.Name()] = component{typ: t, response: response}
+	r.schemas[t.Name()] = &Schema{}
+	*r.schemas[t.Name()] = *r.object(t, response)
+	return ref
+}
+
+func (r *reflector) object(t reflect.Type, response bool) *Schema {
+	schema := &Schema{Type: Types{TypeObject}, Properties: map[string]*Schema{}}
+	r.fields(schema, t, response)
+	return schema
+}
+
+func (r *reflector) fields(schema *Schema, t reflect.Type, response bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, omitempty, ok := jsonName(field)
+		if !ok {
+			continue
+		}
+
+		// Embedded structs without a json name are flattened by encoding/json
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				r.fields(schema, embedded, response)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		rules := strings.Split(field.Tag.Get("validate"), ",")
+		required := !omitempty
+		if !response {
+			required = hasRule(rules, "required")
+		}
+
+		property := r.schema(field.Type, response)
+		applyRules(property, field.Type, rules)
+
+		// What encoding/json writes, or reads, as null
+		nilable := field.Type.Kind() == reflect.Pointer || field.Type.Kind() == reflect.Slice || field.Type.Kind() == reflect.Map
+		if nilable && ((response && !omitempty) || (!response && !required)) {
+			property = nullable(property)
+		}
+
+		schema.Properties[name] = property
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// jsonName reads the json tag, ok is false for fields encoding/json skips
+func jsonName(field reflect.StructField) (name string, omitempty bool, ok bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false
+	}
+	parts := strings.Split(tag, ",")
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			omitempty = true
+		}
+	}
+	return parts[0], omitempty, true
+}
+
+func hasRule(rules []string, name string) bool {
+	for _, rule := range rules {
+		if rule == "dive" {
+			return false
+		}
+		if rule == name {
+			return true
+		}
+	}
+	return false
+}
+
+// applyRules translates the go-playground rules JSON Schema can express, the others
+// are only checked by the validator. Rules after dive apply to the items.
+func applyRules(schema *Schema, t reflect.Type, rules []string) {
+	pointer := t.Kind() == reflect.Pointer
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if schema.Ref != "" {
+		return
+	}
+
+	omitempty := false
+	constrained := false
+	for i, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "dive":
+			if schema.Items != nil {
+				applyRules(schema.Items, t.Elem(), rules[i+1:])
+			}
+			return
+		case "omitempty":
+			omitempty = true
+		case "min", "gte":
+			constrained = setBound(schema, t, param, true) || constrained
+		case "max", "lte":
+			constrained = setBound(schema, t, param, false) || constrained
+		case "len":
+			constrained = setBound(schema, t, param, true) || constrained
+			constrained = setBound(schema, t, param, false) || constrained
+		case "oneof":
+			for _, value := range strings.Fields(param) {
+				schema.Enum = append(schema.Enum, enumValue(t, value))
+			}
+			constrained = true
+		}
+	}
+
+	// go-playground skips the other rules for zero values under omitempty, so the
+	// zero value stays valid unless the field is a pointer and may just be null
+	if omitempty && constrained && !pointer {
+		zero := reflect.Zero(t).Interface()
+		if len(schema.Enum) > 0 {
+			schema.Enum = append(schema.Enum, zero)
+			return
+		}
+		constraints := *schema
+		*schema = Schema{AnyOf: []*Schema{{Enum: []interface{}{zero}}, &constraints}}
+	}
+}
+
+func setBound(schema *Schema, t reflect.Type, param string, lower bool) bool {
+	value, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return false
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		if lower {
+			schema.MinLength = integer(int(value))
+		} else {
+			schema.MaxLength = integer(int(value))
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if lower {
+			schema.MinItems = integer(int(value))
+		} else {
+			schema.MaxItems = integer(int(value))
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if lower {
+			schema.Minimum = float(value)
+		} else {
+			schema.Maximum = float(value)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+func enumValue(t reflect.Type, value string) interface{} {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if parsed, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return parsed
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if parsed, err := strconv.ParseUint(value, 10, 64); err == nil {
+			return parsed
+		}
+	}
+	return value
+}
+
+// nullable lets schema also be null
+func nullable(schema *Schema) *Schema {
+	if schema.Ref != "" || len(schema.AnyOf) > 0 {
+		return &Schema{AnyOf: []*Schema{schema, {Type: Types{TypeNull}}}}
+	}
+	if len(schema.Type) == 0 || schema.Type.Has(TypeNull) {
+		return schema
+	}
+	schema.Type = append(schema.Type, TypeNull)
+	if len(schema.Enum) > 0 {
+		schema.Enum = append(schema.Enum, nil)
+	}
+	return schema
+}
+
+func float(value float64) *float64 {
+	return &value
+}
+
+func integer(value int) *int {
+	return &value
+}
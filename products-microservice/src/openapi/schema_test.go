@@ -0,0 +1,102 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testAudit struct {
+	CreatedBy string `json:"created_by"`
+}
+
+type testItemRequest struct {
+	Name     string   `json:"name" validate:"required,min=1,max=100"`
+	Kind     string   `json:"kind" validate:"omitempty,oneof=new used"`
+	Quantity int      `json:"quantity" validate:"required,min=1"`
+	Discount int      `json:"discount" validate:"omitempty,max=50"`
+	Tags     []string `json:"tags" validate:"required,max=3,dive,min=2"`
+	Notes    *string  `json:"notes"`
+	internal string
+}
+
+type testItemResponse struct {
+	testAudit
+	ItemID    uint       `json:"item_id"`
+	Tags      []string   `json:"tags"`
+	Warning   string     `json:"warning,omitempty"`
+	DeletedAt *time.Time `json:"deleted_at"`
+	Secret    string     `json:"-"`
+	Children  []testItemResponse
+}
+
+func TestSchema(t *testing.T) {
+
+	t.Run("Schema_Request_Rules", func(t *testing.T) {
+		b := NewBuilder(Info{})
+		ref := b.RequestSchema(testItemRequest{})
+		assert.Equal(t, "#/components/schemas/testItemRequest", ref.Ref, "Expected named structs to be components")
+
+		schema := b.reflector.schemas["testItemRequest"]
+		assert.Equal(t, []string{"name", "quantity", "tags"}, schema.Required, "Expected the fields validate requires")
+		assert.NotContains(t, schema.Properties, "internal", "Expected unexported fields to be skipped")
+
+		assert.Equal(t, 1, *schema.Properties["name"].MinLength, "Expected min to bound strings")
+		assert.Equal(t, 100, *schema.Properties["name"].MaxLength, "Expected max to bound strings")
+		assert.Equal(t, float64(1), *schema.Properties["quantity"].Minimum, "Expected min to bound numbers")
+		assert.Equal(t, []interface{}{"new", "used", ""}, schema.Properties["kind"].Enum, "Expected omitempty to allow the empty string")
+
+		discount := schema.Properties["discount"]
+		assert.Len(t, discount.AnyOf, 2, "Expected omitempty to allow zero beside the bounds")
+		assert.Equal(t, []interface{}{0}, discount.AnyOf[0].Enum, "Expected zero to be allowed")
+		assert.Equal(t, float64(50), *discount.AnyOf[1].Maximum, "Expected the bound to be kept")
+
+		tags := schema.Properties["tags"]
+		assert.Equal(t, Types{TypeArray}, tags.Type, "Expected required slices to not be nullable")
+		assert.Equal(t, 3, *tags.MaxItems, "Expected max to bound slices")
+		assert.Equal(t, 2, *tags.Items.MinLength, "Expected rules after dive to apply to the items")
+
+		assert.Equal(t, Types{TypeString, TypeNull}, schema.Properties["notes"].Type, "Expected optional pointers to be nullable")
+	})
+
+	t.Run("Schema_Response_Fields", func(t *testing.T) {
+		b := NewBuilder(Info{})
+		b.ResponseSchema(&testItemResponse{})
+
+		schema := b.reflector.schemas["testItemResponse"]
+		assert.Contains(t, schema.Properties, "created_by", "Expected embedded structs to be flattened")
+		assert.Contains(t, schema.Properties, "Children", "Expected fields without a json tag to keep their name")
+		assert.NotContains(t, schema.Properties, "Secret", "Expected skipped fields to be left out")
+		assert.NotContains(t, schema.Required, "warning", "Expected omitempty fields to be optional")
+		assert.Contains(t, schema.Required, "item_id", "Expected the other fields to always be present")
+
+		assert.Equal(t, float64(0), *schema.Properties["item_id"].Minimum, "Expected unsigned integers to be positive")
+		assert.Equal(t, Types{TypeArray, TypeNull}, schema.Properties["tags"].Type, "Expected nil slices to be null")
+		assert.Equal(t, "date-time", schema.Properties["deleted_at"].Format, "Expected times to be date-times")
+		assert.Equal(t, Types{TypeString, TypeNull}, schema.Properties["deleted_at"].Type, "Expected nil pointers to be null")
+
+		children := schema.Properties["Children"]
+		assert.Equal(t, "#/components/schemas/testItemResponse", children.Items.Ref, "Expected recursive types to reference themselves")
+	})
+
+	t.Run("Schema_Conflicting_Directions", func(t *testing.T) {
+		b := NewBuilder(Info{})
+		b.RequestSchema(testItemRequest{})
+		b.ResponseSchema(testItemRequest{})
+
+		_, err := b.Document()
+		assert.ErrorContains(t, err, "used by both requests and responses", "Expected a type to have a single meaning")
+	})
+
+	t.Run("Types_MarshalJSON", func(t *testing.T) {
+		single, err := json.Marshal(Types{TypeString})
+		assert.Nil(t, err, "Expected no error")
+		assert.Equal(t, `"string"`, string(single), "Expected a single type as a string")
+
+		nullable, err := json.Marshal(Types{TypeString, TypeNull})
+		assert.Nil(t, err, "Expected no error")
+		assert.Equal(t, `["string","null"]`, string(nullable), "Expected several types as an array")
+	})
+}
@@ -0,0 +1,17 @@
+package openapi
+
+import (
+	_ "embed"
+	"html/template"
+	"strings"
+)
+
+//go:embed swagger_ui.html
+var swaggerUIPage string
+
+// SwaggerUI is the page rendering the document served at specURL. The page is
+// embedded, the Swagger UI scripts and styles are loaded from the jsDelivr CDN at
+// an exact version. make swagger-ui-sri prints the integrity attributes of those files.
+func SwaggerUI(specURL string) []byte {
+	return []byte(strings.Replace(swaggerUIPage, "{{SPEC_URL}}", template.JSEscapeString(specURL), 1))
+}
@@ -0,0 +1,324 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Where a Violation was found
+const (
+	InBody     = "body"
+	InResponse = "response"
+)
+
+// Violation is a value that breaks the document
+type Violation struct {
+	// In is path, query, header, body or response
+	In string
+	// Pointer is the RFC 6901 JSON pointer to the value, parameters are pointed to
+	// by their name
+	Pointer string
+	// Keyword is the schema keyword that failed, e.g. required or maxLength
+	Keyword string
+	Message string
+}
+
+// Operation returns the operation of a route, path uses the gin syntax the route is
+// registered with, or nil when the route isn't documented
+func (d *Document) Operation(method string, path string) *Operation {
+	path, _ = ginPath(path)
+	item, ok := d.Paths[path]
+	if !ok {
+		return nil
+	}
+	return (*item)[strings.ToLower(method)]
+}
+
+// ValidateRequest checks the parameters and the JSON body of a request to operation,
+// pathParams holds the values of the path parameters by name
+func (d *Document) ValidateRequest(operation *Operation, req *http.Request, pathParams map[string]string, body []byte) []Violation {
+	var violations []Violation
+
+	query := req.URL.Query()
+	for _, param := range operation.Parameters {
+		var raw string
+		var present bool
+		switch param.In {
+		case InPath:
+			raw, present = pathParams[param.Name]
+		case InQuery:
+			raw, present = query.Get(param.Name), query.Has(param.Name)
+		case InHeader:
+			raw = req.Header.Get(param.Name)
+			present = raw != ""
+		}
+
+		pointer := "/" + escapePointer(param.Name)
+		if !present {
+			if param.Required {
+				violations = append(violations, Violation{In: param.In, Pointer: pointer, Keyword: "required", Message: "is required"})
+			}
+			continue
+		}
+		d.validate(param.Schema, parseParameter(d.Resolve(param.Schema), raw), param.In, pointer, &violations)
+	}
+
+	if operation.RequestBody == nil {
+		return violations
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		if operation.RequestBody.Required {
+			violations = append(violations, Violation{In: InBody, Keyword: "required", Message: "request body is required"})
+		}
+		return violations
+	}
+	media, ok := operation.RequestBody.Content[ContentTypeJSON]
+	if !ok {
+		return violations
+	}
+	value, err := decodeJSON(body)
+	if err != nil {
+		return append(violations, Violation{In: InBody, Keyword: "type", Message: "must be valid JSON"})
+	}
+	d.validate(media.Schema, value, InBody, "", &violations)
+	return violations
+}
+
+// ValidateResponse checks that status is documented for operation and that a JSON
+// body matches the schema of its response
+func (d *Document) ValidateResponse(operation *Operation, status int, body []byte) []Violation {
+	res, ok := operation.Responses[strconv.Itoa(status)]
+	if !ok {
+		res, ok = operation.Responses["default"]
+	}
+	if !ok {
+		return []Violation{{In: InResponse, Keyword: "responses", Message: fmt.Sprintf("status %d is not documented", status)}}
+	}
+
+	media, ok := res.Content[ContentTypeJSON]
+	if !ok || len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	value, err := decodeJSON(body)
+	if err != nil {
+		return []Violation{{In: InResponse, Keyword: "type", Message: "must be valid JSON"}}
+	}
+
+	var violations []Violation
+	d.validate(media.Schema, value, InResponse, "", &violations)
+	return violations
+}
+
+func (d *Document) validate(schema *Schema, value interface{}, in string, pointer string, violations *[]Violation) {
+	schema = d.Resolve(schema)
+	if schema == nil {
+		return
+	}
+	fail := func(keyword string, format string, args ...interface{}) {
+		*violations = append(*violations, Violation{In: in, Pointer: pointer, Keyword: keyword, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(schema.AnyOf) > 0 {
+		d.validateAnyOf(schema.AnyOf, value, in, pointer, violations)
+	}
+
+	if len(schema.Type) > 0 && !matchesType(schema.Type, value) {
+		fail("type", "must be %s", strings.Join(schema.Type, " or "))
+		return
+	}
+
+	if len(schema.Enum) > 0 && !inEnum(schema.Enum, value) {
+		allowed := make([]string, 0, len(schema.Enum))
+		for _, candidate := range schema.Enum {
+			encoded, _ := json.Marshal(candidate)
+			allowed = append(allowed, string(encoded))
+		}
+		fail("enum", "must be one of %s", strings.Join(allowed, ", "))
+	}
+
+	switch value := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(value)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			fail("minLength", "must be at least %d characters long", *schema.MinLength)
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			fail("maxLength", "must be at most %d characters long", *schema.MaxLength)
+		}
+		if schema.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, value); err != nil {
+				fail("format", "must be an RFC 3339 date-time")
+			}
+		}
+	case json.Number:
+		number, _ := value.Float64()
+		if schema.Minimum != nil && number < *schema.Minimum {
+			fail("minimum", "must be at least %s", formatFloat(*schema.Minimum))
+		}
+		if schema.Maximum != nil && number > *schema.Maximum {
+			fail("maximum", "must be at most %s", formatFloat(*schema.Maximum))
+		}
+		if schema.Format == "int32" && (number < math.MinInt32 || number > math.MaxInt32) {
+			fail("format", "must fit in 32 bits")
+		}
+	case []interface{}:
+		if schema.MinItems != nil && len(value) < *schema.MinItems {
+			fail("minItems", "must have at least %d items", *schema.MinItems)
+		}
+		if schema.MaxItems != nil && len(value) > *schema.MaxItems {
+			fail("maxItems", "must have at most %d items", *schema.MaxItems)
+		}
+		for i, item := range value {
+			d.validate(schema.Items, item, in, pointer+"/"+strconv.Itoa(i), violations)
+		}
+	case map[string]interface{}:
+		for _, name := range schema.Required {
+			if _, ok := value[name]; !ok {
+				*violations = append(*violations, Violation{In: in, Pointer: pointer + "/" + escapePointer(name), Keyword: "required", Message: "is required"})
+			}
+		}
+		names := make([]string, 0, len(value))
+		for name := range value {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			propertySchema, ok := schema.Properties[name]
+			if !ok {
+				propertySchema = schema.AdditionalProperties
+			}
+			d.validate(propertySchema, value[name], in, pointer+"/"+escapePointer(name), violations)
+		}
+	}
+}
+
+// validateAnyOf reports the violations of the only alternative that isn't a single
+// value, the shape of nullable values and omitempty rules, or that none matched
+func (d *Document) validateAnyOf(alternatives []*Schema, value interface{}, in string, pointer string, violations *[]Violation) {
+	var candidate []Violation
+	candidates := 0
+	for _, alternative := range alternatives {
+		var found []Violation
+		d.validate(alternative, value, in, pointer, &found)
+		if len(found) == 0 {
+			return
+		}
+		if resolved := d.Resolve(alternative); (len(resolved.Type) == 1 && resolved.Type[0] == TypeNull) || (len(resolved.Type) == 0 && len(resolved.Enum) == 1) {
+			continue
+		}
+		candidate = found
+		candidates++
+	}
+
+	if candidates == 1 {
+		*violations = append(*violations, candidate...)
+		return
+	}
+	*violations = append(*violations, Violation{In: in, Pointer: pointer, Keyword: "anyOf", Message: "must match one of the allowed schemas"})
+}
+
+func matchesType(types Types, value interface{}) bool {
+	for _, name := range types {
+		switch value := value.(type) {
+		case nil:
+			if name == TypeNull {
+				return true
+			}
+		case bool:
+			if name == TypeBoolean {
+				return true
+			}
+		case string:
+			if name == TypeString {
+				return true
+			}
+		case json.Number:
+			if name == TypeNumber {
+				return true
+			}
+			if _, err := value.Int64(); name == TypeInteger && err == nil {
+				return true
+			}
+		case []interface{}:
+			if name == TypeArray {
+				return true
+			}
+		case map[string]interface{}:
+			if name == TypeObject {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, candidate := range enum {
+		if candidate == nil || value == nil {
+			if candidate == nil && value == nil {
+				return true
+			}
+			continue
+		}
+		if number, ok := value.(json.Number); ok {
+			if parsed, err := number.Float64(); err == nil && fmt.Sprint(candidate) == formatFloat(parsed) {
+				return true
+			}
+			continue
+		}
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+// parseParameter reads a parameter the way it would be written in JSON, so it can be
+// validated like a body. Values that don't parse stay strings and fail the type.
+func parseParameter(schema *Schema, raw string) interface{} {
+	if schema == nil {
+		return raw
+	}
+	switch {
+	case schema.Type.Has(TypeInteger), schema.Type.Has(TypeNumber):
+		if _, err := strconv.ParseFloat(raw, 64); err == nil {
+			return json.Number(raw)
+		}
+	case schema.Type.Has(TypeBoolean):
+		if parsed, err := strconv.ParseBool(raw); err == nil {
+			return parsed
+		}
+	}
+	return raw
+}
+
+func decodeJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	err := decoder.Decode(&value)
+	if err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after the JSON value")
+	}
+	return value, nil
+}
+
+func escapePointer(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,63 @@
+package openapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+
+	b := NewBuilder(Info{})
+	b.Add(http.MethodPost, "/items", &Operation{
+		OperationID: "createItem",
+		RequestBody: b.JSONBody(testItemRequest{}),
+		Responses:   map[string]*Response{"201": JSONResponse("The item", b.ResponseSchema(testItemResponse{}))},
+	})
+	document, err := b.Document()
+	assert.Nil(t, err, "Expected the document to build")
+	operation := document.Operation(http.MethodPost, "/items")
+
+	validateBody := func(body string) []Violation {
+		req, _ := http.NewRequest(http.MethodPost, "/items", nil)
+		return document.ValidateRequest(operation, req, nil, []byte(body))
+	}
+
+	t.Run("Validate_Valid_Body", func(t *testing.T) {
+		violations := validateBody(`{"name": "Item", "kind": "", "quantity": 2, "discount": 0, "tags": ["ab"], "notes": null}`)
+		assert.Empty(t, violations, "Expected zero values allowed by omitempty and null optional fields to be valid")
+	})
+
+	t.Run("Validate_Nested_Pointers", func(t *testing.T) {
+		violations := validateBody(`{"name": "Item", "kind": "broken", "quantity": 2, "discount": 60, "tags": ["ab", "c"], "notes": 5}`)
+		assert.Equal(t, []Violation{
+			{In: InBody, Pointer: "/discount", Keyword: "maximum", Message: "must be at most 50"},
+			{In: InBody, Pointer: "/kind", Keyword: "enum", Message: `must be one of "new", "used", ""`},
+			{In: InBody, Pointer: "/notes", Keyword: "type", Message: "must be string or null"},
+			{In: InBody, Pointer: "/tags/1", Keyword: "minLength", Message: "must be at least 2 characters long"},
+		}, violations, "Expected each violation to point at its value")
+	})
+
+	t.Run("Validate_Invalid_JSON", func(t *testing.T) {
+		violations := validateBody(`{"name": "Item"} {}`)
+		assert.Equal(t, []Violation{{In: InBody, Keyword: "type", Message: "must be valid JSON"}}, violations, "Expected trailing data to be rejected")
+
+		violations = validateBody(`[]`)
+		assert.Equal(t, []Violation{{In: InBody, Keyword: "type", Message: "must be object"}}, violations, "Expected the body to be an object")
+	})
+
+	t.Run("Validate_Response", func(t *testing.T) {
+		violations := document.ValidateResponse(operation, http.StatusCreated, []byte(`{"created_by": "me", "item_id": 1, "tags": null, "deleted_at": "yesterday", "Children": [{}]}`))
+		assert.Contains(t, violations, Violation{In: InResponse, Pointer: "/deleted_at", Keyword: "format", Message: "must be an RFC 3339 date-time"}, "Expected formats to be checked")
+		assert.Contains(t, violations, Violation{In: InResponse, Pointer: "/Children/0/item_id", Keyword: "required", Message: "is required"}, "Expected referenced schemas to be followed")
+		assert.NotContains(t, violations, Violation{In: InResponse, Pointer: "/tags", Keyword: "type", Message: "must be array or null"}, "Expected nil slices to be valid")
+
+		violations = document.ValidateResponse(operation, http.StatusOK, nil)
+		assert.Equal(t, "status 200 is not documented", violations[0].Message, "Expected undocumented statuses to be reported")
+	})
+
+	t.Run("EscapePointer", func(t *testing.T) {
+		assert.Equal(t, "a~1b~0c", escapePointer("a/b~c"), "Expected RFC 6901 escaping")
+	})
+}
@@ -0,0 +1,124 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidLimit = errors.New("invalid rate limit")
+
+// Limit is a token bucket that holds up to Burst tokens and refills Requests tokens
+// every Period. A zero Burst means Requests.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+// Policy holds the limits of a route group, reads and writes are counted in separate buckets
+type Policy struct {
+	Read  Limit
+	Write Limit
+}
+
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Period > 0
+}
+
+func (l Limit) capacity() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return float64(l.Requests)
+}
+
+// rate is the number of tokens added per second
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// refill returns the tokens of a bucket that held tokens elapsed ago
+func (l Limit) refill(tokens float64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return tokens
+	}
+	return math.Min(l.capacity(), tokens+elapsed.Seconds()*l.rate())
+}
+
+// fullAfter is how long an empty bucket takes to refill, stores forget buckets after it
+func (l Limit) fullAfter() time.Duration {
+	return secondsToDuration(l.capacity() / l.rate())
+}
+
+// String formats the limit the way ParseLimit reads it
+func (l Limit) String() string {
+	value := fmt.Sprintf("%d/%s", l.Requests, l.Period)
+	if l.Burst > 0 {
+		value += fmt.Sprintf(",burst=%d", l.Burst)
+	}
+	return value
+}
+
+// ParseLimit reads limits such as "100/1m" or "100/1m,burst=20"
+func ParseLimit(value string) (Limit, error) {
+	rate, burst, hasBurst := strings.Cut(strings.TrimSpace(value), ",")
+
+	requests, period, found := strings.Cut(rate, "/")
+	if !found {
+		return Limit{}, fmt.Errorf("%w: %q, expected requests/period", ErrInvalidLimit, value)
+	}
+
+	var limit Limit
+	var err error
+	limit.Requests, err = strconv.Atoi(strings.TrimSpace(requests))
+	if err != nil || limit.Requests <= 0 {
+		return Limit{}, fmt.Errorf("%w: %q, requests must be a positive number", ErrInvalidLimit, value)
+	}
+
+	limit.Period, err = time.ParseDuration(strings.TrimSpace(period))
+	if err != nil || limit.Period <= 0 {
+		return Limit{}, fmt.Errorf("%w: %q, period must be a positive duration", ErrInvalidLimit, value)
+	}
+
+	if hasBurst {
+		name, burstValue, _ := strings.Cut(strings.TrimSpace(burst), "=")
+		limit.Burst, err = strconv.Atoi(burstValue)
+		if name != "burst" || err != nil || limit.Burst <= 0 {
+			return Limit{}, fmt.Errorf("%w: %q, burst must be a positive number", ErrInvalidLimit, value)
+		}
+	}
+
+	return limit, nil
+}
+
+// Result is the outcome of taking a token from a bucket
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is how long until the next token, zero when allowed
+	RetryAfter time.Duration
+}
+
+func newResult(limit Limit, allowed bool, tokens float64) *Result {
+	result := &Result{
+		Allowed:   allowed,
+		Limit:     int(limit.capacity()),
+		Remaining: int(math.Floor(tokens)),
+		Reset:     secondsToDuration((limit.capacity() - tokens) / limit.rate()),
+	}
+	if !allowed {
+		result.RetryAfter = secondsToDuration((1 - tokens) / limit.rate())
+	}
+	return result
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
@@ -0,0 +1,43 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimit(t *testing.T) {
+
+	t.Run("ParseLimit_Success", func(t *testing.T) {
+		limit, err := ParseLimit("100/1m")
+		assert.Nil(t, err, "Expected no error parsing limit")
+		assert.Equal(t, Limit{Requests: 100, Period: time.Minute}, limit, "Expected requests and period")
+
+		limit, err = ParseLimit(" 10/1s,burst=50 ")
+		assert.Nil(t, err, "Expected no error parsing limit with burst")
+		assert.Equal(t, Limit{Requests: 10, Period: time.Second, Burst: 50}, limit, "Expected burst")
+		assert.Equal(t, "10/1s,burst=50", limit.String(), "Expected limit to format back")
+	})
+
+	t.Run("ParseLimit_Invalid", func(t *testing.T) {
+		for _, value := range []string{"100", "0/1m", "100/0s", "abc/1m", "100/minute", "100/1m,burst=0", "100/1m,size=5"} {
+			_, err := ParseLimit(value)
+			assert.ErrorIs(t, err, ErrInvalidLimit, "Expected %q to be rejected", value)
+		}
+	})
+
+	t.Run("Result_Timing", func(t *testing.T) {
+		limit := Limit{Requests: 1, Period: time.Second, Burst: 10}
+
+		result := newResult(limit, true, 9)
+		assert.Equal(t, 10, result.Limit, "Expected burst as limit")
+		assert.Equal(t, 9, result.Remaining, "Expected remaining tokens")
+		assert.Equal(t, time.Second, result.Reset, "Expected one token to refill")
+		assert.Zero(t, result.RetryAfter, "Expected no retry when allowed")
+
+		result = newResult(limit, false, 0.25)
+		assert.Equal(t, 0, result.Remaining, "Expected no remaining tokens")
+		assert.Equal(t, 750*time.Millisecond, result.RetryAfter, "Expected time until the next token")
+	})
+}
@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const DefaultSweepInterval = time.Minute
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	fullAt    time.Time
+}
+
+type MemoryStoreImpl struct {
+	mu            sync.Mutex
+	buckets       map[string]*bucket
+	sweepInterval time.Duration
+	sweptAt       time.Time
+	now           func() time.Time
+}
+
+// Take implements Store.
+func (m *MemoryStoreImpl) Take(ctx context.Context, key string, limit Limit) (*Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: limit.capacity(), updatedAt: now}
+		m.buckets[key] = b
+	}
+
+	b.tokens = limit.refill(b.tokens, now.Sub(b.updatedAt))
+	if now.After(b.updatedAt) {
+		b.updatedAt = now
+	}
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	b.fullAt = now.Add(limit.fullAfter())
+
+	return newResult(limit, allowed, b.tokens), nil
+}
+
+// sweep drops buckets that have refilled, they behave exactly like missing ones
+func (m *MemoryStoreImpl) sweep(now time.Time) {
+	if now.Sub(m.sweptAt) < m.sweepInterval {
+		return
+	}
+	m.sweptAt = now
+
+	for key, b := range m.buckets {
+		if !now.Before(b.fullAt) {
+			delete(m.buckets, key)
+		}
+	}
+}
+
+// NewMemoryStoreImpl keeps buckets in process, limits are per replica
+func NewMemoryStoreImpl(sweepInterval time.Duration) Store {
+	return &MemoryStoreImpl{
+		buckets:       make(map[string]*bucket),
+		sweepInterval: sweepInterval,
+		sweptAt:       time.Now(),
+		now:           time.Now,
+	}
+}
@@ -0,0 +1,55 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStoreImpl(t *testing.T) {
+	limit := Limit{Requests: 2, Period: time.Second}
+
+	newStore := func(now *time.Time) *MemoryStoreImpl {
+		store := NewMemoryStoreImpl(time.Minute).(*MemoryStoreImpl)
+		store.now = func() time.Time { return *now }
+		return store
+	}
+
+	t.Run("Take_Until_Empty_Then_Refill", func(t *testing.T) {
+		now := time.Now()
+		store := newStore(&now)
+
+		for i := 0; i < 2; i++ {
+			result, err := store.Take(context.Background(), "client", limit)
+			assert.Nil(t, err, "Expected no error taking token")
+			assert.True(t, result.Allowed, "Expected token %d to be allowed", i)
+		}
+
+		result, _ := store.Take(context.Background(), "client", limit)
+		assert.False(t, result.Allowed, "Expected empty bucket to deny")
+		assert.Equal(t, 500*time.Millisecond, result.RetryAfter, "Expected half a second until the next token")
+
+		other, _ := store.Take(context.Background(), "other", limit)
+		assert.True(t, other.Allowed, "Expected other clients to have their own bucket")
+
+		now = now.Add(500 * time.Millisecond)
+		result, _ = store.Take(context.Background(), "client", limit)
+		assert.True(t, result.Allowed, "Expected refilled token to be allowed")
+		assert.Equal(t, 0, result.Remaining, "Expected no tokens left")
+	})
+
+	t.Run("Sweep_Drops_Full_Buckets", func(t *testing.T) {
+		now := time.Now()
+		store := newStore(&now)
+
+		_, _ = store.Take(context.Background(), "client", limit)
+		assert.Len(t, store.buckets, 1, "Expected one bucket")
+
+		now = now.Add(2 * time.Minute)
+		_, _ = store.Take(context.Background(), "other", limit)
+		assert.Len(t, store.buckets, 1, "Expected the refilled bucket to be dropped")
+		assert.Contains(t, store.buckets, "other", "Expected the new bucket to stay")
+	})
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const DefaultRedisKeyPrefix = "ratelimit:"
+
+// takeScript refills and takes from the bucket atomically. Tokens are returned as a
+// string because Redis truncates Lua numbers to integers.
+var takeScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local ttl = tonumber(ARGV[4])
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "updated_at")
+local tokens = tonumber(state[1])
+local updated_at = tonumber(state[2])
+if tokens == nil or updated_at == nil then
+	tokens = capacity
+	updated_at = now
+end
+
+if now > updated_at then
+	tokens = math.min(capacity, tokens + (now - updated_at) * rate)
+	updated_at = now
+end
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "updated_at", tostring(updated_at))
+redis.call("PEXPIRE", KEYS[1], ttl)
+
+return {allowed, tostring(tokens)}
+`)
+
+type RedisStoreImpl struct {
+	client redis.Scripter
+	prefix string
+	now    func() time.Time
+}
+
+// Take implements Store.
+func (r *RedisStoreImpl) Take(ctx context.Context, key string, limit Limit) (*Result, error) {
+	now := r.now().UnixMilli()
+	ttl := limit.fullAfter().Milliseconds() + 1
+
+	values, err := takeScript.Run(ctx, r.client, []string{r.prefix + key},
+		limit.capacity(), limit.rate()/1000, now, ttl,
+	).Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values) != 2 {
+		return nil, fmt.Errorf("unexpected rate limit script reply %v", values)
+	}
+	allowed, _ := values[0].(int64)
+	tokensValue, _ := values[1].(string)
+	tokens, err := strconv.ParseFloat(tokensValue, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected rate limit script reply %v", values)
+	}
+
+	return newResult(limit, allowed == 1, tokens), nil
+}
+
+// NewRedisStoreImpl shares buckets between replicas through any Redis compatible
+// server. The replicas' clocks are used for refills so they should be kept in sync.
+func NewRedisStoreImpl(client redis.Scripter, prefix string) Store {
+	return &RedisStoreImpl{client: client, prefix: prefix, now: time.Now}
+}
@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisStoreImpl(t *testing.T) {
+	limit := Limit{Requests: 2, Period: time.Second}
+
+	newStore := func(t *testing.T, now *time.Time) (*RedisStoreImpl, *miniredis.Miniredis) {
+		server := miniredis.RunT(t)
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { _ = client.Close() })
+
+		store := NewRedisStoreImpl(client, DefaultRedisKeyPrefix).(*RedisStoreImpl)
+		store.now = func() time.Time { return *now }
+		return store, server
+	}
+
+	t.Run("Take_Until_Empty_Then_Refill", func(t *testing.T) {
+		now := time.Now()
+		store, _ := newStore(t, &now)
+
+		for i := 0; i < 2; i++ {
+			result, err := store.Take(context.Background(), "client", limit)
+			assert.Nil(t, err, "Expected no error taking token")
+			assert.True(t, result.Allowed, "Expected token %d to be allowed", i)
+		}
+
+		result, err := store.Take(context.Background(), "client", limit)
+		assert.Nil(t, err, "Expected no error taking token")
+		assert.False(t, result.Allowed, "Expected empty bucket to deny")
+		assert.Equal(t, 500*time.Millisecond, result.RetryAfter, "Expected half a second until the next token")
+
+		now = now.Add(250 * time.Millisecond)
+		result, _ = store.Take(context.Background(), "client", limit)
+		assert.False(t, result.Allowed, "Expected half a token to deny")
+
+		now = now.Add(250 * time.Millisecond)
+		result, _ = store.Take(context.Background(), "client", limit)
+		assert.True(t, result.Allowed, "Expected refilled token to be allowed")
+	})
+
+	t.Run("Take_Sets_Expiry", func(t *testing.T) {
+		now := time.Now()
+		store, server := newStore(t, &now)
+
+		_, err := store.Take(context.Background(), "client", limit)
+		assert.Nil(t, err, "Expected no error taking token")
+
+		ttl := server.TTL(DefaultRedisKeyPrefix + "client")
+		assert.True(t, ttl > 0 && ttl <= time.Second+time.Millisecond, "Expected bucket to expire once refilled, got %s", ttl)
+
+		server.FastForward(2 * time.Second)
+		assert.False(t, server.Exists(DefaultRedisKeyPrefix+"client"), "Expected bucket to be gone")
+	})
+
+	t.Run("Take_Store_Error", func(t *testing.T) {
+		now := time.Now()
+		store, server := newStore(t, &now)
+		server.Close()
+
+		_, err := store.Take(context.Background(), "client", limit)
+		assert.NotNil(t, err, "Expected error when the server is down")
+	})
+}
@@ -0,0 +1,10 @@
+package ratelimit
+
+import "context"
+
+// Store keeps the token buckets. Every replica must share the store for the limits
+// to hold across the deployment.
+type Store interface {
+	// Take removes a token from the bucket under key, creating a full bucket if needed
+	Take(ctx context.Context, key string, limit Limit) (*Result, error)
+}
@@ -0,0 +1,118 @@
+package replica
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+const (
+	readSetting   = "replica:read"
+	pinnedSetting = "replica:pinned"
+)
+
+// Register sends the queries marked with Read to replicas, picked round robin.
+// Everything else, including reads that follow a write such as fetching a product by
+// ID, keeps going to the primary so requests always see their own writes.
+func Register(db *gorm.DB, replicas []gorm.Dialector) (*dbresolver.DBResolver, error) {
+	resolver := dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   dbresolver.RoundRobinPolicy(),
+	})
+
+	err := db.Use(resolver)
+	if err != nil {
+		return nil, err
+	}
+
+	// dbresolver sends every query to the replicas unless told otherwise, so its
+	// callbacks are wrapped to pin the queries that didn't opt in. The wrappers keep
+	// Before("*"), otherwise gorm would still run the original ones.
+	callbacks := db.Callback()
+	err = callbacks.Query().Before("*").Replace(resolverName, pinPrimary(callbacks.Query().Get(resolverName)))
+	if err != nil {
+		return nil, err
+	}
+	err = callbacks.Row().Before("*").Replace(resolverName, pinPrimary(callbacks.Row().Get(resolverName)))
+	if err != nil {
+		return nil, err
+	}
+
+	return resolver, nil
+}
+
+func pinPrimary(switchReplica func(*gorm.DB)) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		_, read := db.Get(readSetting)
+		// Write.ModifyStatement runs this callback again, the setting stops the recursion
+		_, pinned := db.Get(pinnedSetting)
+		if !read && !pinned {
+			db.Statement.Settings.Store(pinnedSetting, true)
+			dbresolver.Write.ModifyStatement(db.Statement)
+		}
+		switchReplica(db)
+	}
+}
+
+// Read is a scope for lists and searches that tolerate replication lag, they are
+// served by a replica when there is one
+func Read(db *gorm.DB) *gorm.DB {
+	return db.Set(readSetting, true)
+}
+
+// PingPrimary checks the primary of db
+func PingPrimary(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// PingReplicas checks every replica of db, it passes when there are none
+func PingReplicas(ctx context.Context, db *gorm.DB) error {
+	primary, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	return each(db, func(pool gorm.ConnPool) error {
+		if pool == gorm.ConnPool(primary) {
+			return nil
+		}
+		if pinger, ok := pool.(interface{ PingContext(context.Context) error }); ok {
+			return pinger.PingContext(ctx)
+		}
+		return nil
+	})
+}
+
+// Close closes the primary and every replica of db
+func Close(db *gorm.DB) error {
+	var errs []error
+	err := each(db, func(pool gorm.ConnPool) error {
+		if closer, ok := pool.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+		return nil
+	})
+	return errors.Join(append(errs, err)...)
+}
+
+// each calls fc with the pool of the primary and of every replica
+func each(db *gorm.DB, fc func(pool gorm.ConnPool) error) error {
+	if resolver, ok := db.Config.Plugins[resolverName].(*dbresolver.DBResolver); ok {
+		return resolver.Call(fc)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return fc(sqlDB)
+}
+
+var resolverName = (&dbresolver.DBResolver{}).Name()
@@ -0,0 +1,98 @@
+package replica
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type item struct {
+	ID   uint
+	Name string
+}
+
+func TestReplica(t *testing.T) {
+	// The primary and the replica are separate files, so each read shows where it went
+	setup := func(t *testing.T) *gorm.DB {
+		dir := t.TempDir()
+		replicaPath := filepath.Join(dir, "replica.db")
+
+		replicaDB, err := gorm.Open(sqlite.Open(replicaPath), &gorm.Config{})
+		assert.Nil(t, err, "Expected no error opening the replica")
+		assert.Nil(t, replicaDB.AutoMigrate(&item{}), "Expected no error migrating the replica")
+		assert.Nil(t, replicaDB.Create(&item{ID: 1, Name: "replica"}).Error, "Expected no error seeding the replica")
+		assert.Nil(t, Close(replicaDB), "Expected no error closing the replica")
+
+		db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "primary.db")), &gorm.Config{})
+		assert.Nil(t, err, "Expected no error opening the primary")
+		assert.Nil(t, db.AutoMigrate(&item{}), "Expected no error migrating the primary")
+
+		_, err = Register(db, []gorm.Dialector{sqlite.Open(replicaPath)})
+		assert.Nil(t, err, "Expected no error registering the replica")
+		return db
+	}
+
+	t.Run("Register_Writes_Go_To_Primary", func(t *testing.T) {
+		db := setup(t)
+		defer Close(db)
+
+		assert.Nil(t, db.Create(&item{ID: 1, Name: "primary"}).Error, "Expected no error creating")
+
+		var found item
+		assert.Nil(t, db.First(&found, 1).Error, "Expected no error reading")
+		assert.Equal(t, "primary", found.Name, "Expected reads to stay on the primary by default")
+
+		var count int64
+		assert.Nil(t, db.Model(&item{}).Where("name = ?", "primary").Count(&count).Error, "Expected no error counting")
+		assert.Equal(t, int64(1), count, "Expected counts to stay on the primary by default")
+	})
+
+	t.Run("Read_Goes_To_Replica", func(t *testing.T) {
+		db := setup(t)
+		defer Close(db)
+
+		assert.Nil(t, db.Create(&item{ID: 1, Name: "primary"}).Error, "Expected no error creating")
+
+		var items []item
+		assert.Nil(t, db.Scopes(Read).Find(&items).Error, "Expected no error listing")
+		assert.Equal(t, []item{{ID: 1, Name: "replica"}}, items, "Expected the list to be served by the replica")
+	})
+
+	t.Run("Read_In_Transaction_Goes_To_Primary", func(t *testing.T) {
+		db := setup(t)
+		defer Close(db)
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			err := tx.Create(&item{ID: 1, Name: "primary"}).Error
+			if err != nil {
+				return err
+			}
+
+			var items []item
+			err = tx.Scopes(Read).Find(&items).Error
+			assert.Equal(t, "primary", items[0].Name, "Expected transactions to read their own writes")
+			return err
+		})
+		assert.Nil(t, err, "Expected no error")
+	})
+
+	t.Run("Ping_And_Close", func(t *testing.T) {
+		db := setup(t)
+
+		assert.Nil(t, PingPrimary(context.Background(), db), "Expected the primary to answer")
+		assert.Nil(t, PingReplicas(context.Background(), db), "Expected the replica to answer")
+
+		primary, err := db.DB()
+		assert.Nil(t, err, "Expected no error getting the primary")
+		assert.Nil(t, primary.Close(), "Expected no error closing the primary")
+		assert.NotNil(t, PingPrimary(context.Background(), db), "Expected the closed primary to fail")
+		assert.Nil(t, PingReplicas(context.Background(), db), "Expected the replica not to depend on the primary")
+
+		assert.Nil(t, Close(db), "Expected no error closing")
+		assert.NotNil(t, PingReplicas(context.Background(), db), "Expected the closed replica to fail")
+	})
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/models"
+)
+
+// ApiKeyRepository scopes creating, listing and revoking keys to the tenant stored in
+// ctx. Keys are authenticated before the tenant of the request is known, so
+// GetApiKeyByPrefix and TouchApiKey look across tenants.
+type ApiKeyRepository interface {
+	CreateApiKey(ctx context.Context, apiKey *models.ApiKey) (*models.ApiKey, error)
+	GetApiKeyByPrefix(ctx context.Context, prefix string) (*models.ApiKey, error)
+	GetAllApiKeys(ctx context.Context) ([]models.ApiKey, error)
+	// RevokeApiKey keeps the first revocation time when a key is revoked twice
+	RevokeApiKey(ctx context.Context, apiKeyID uint, revokedAt time.Time) error
+	TouchApiKey(ctx context.Context, apiKeyID uint, usedAt time.Time) error
+}
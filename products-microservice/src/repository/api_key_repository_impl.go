@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/models"
+	"github.com/dieg0code/products-microservice/src/tenant"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type ApiKeyRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// CreateApiKey implements ApiKeyRepository.
+func (a *ApiKeyRepositoryImpl) CreateApiKey(ctx context.Context, apiKey *models.ApiKey) (*models.ApiKey, error) {
+	res := a.db.WithContext(ctx).Create(apiKey)
+	if res.Error != nil {
+		logrus.WithError(res.Error).Error("Error creating api key")
+		return nil, res.Error
+	}
+
+	return apiKey, nil
+}
+
+// GetApiKeyByPrefix implements ApiKeyRepository.
+func (a *ApiKeyRepositoryImpl) GetApiKeyByPrefix(ctx context.Context, prefix string) (*models.ApiKey, error) {
+	var apiKey models.ApiKey
+
+	res := a.db.WithContext(tenant.AcrossTenants(ctx)).Where(PrefixPlaceholder, prefix).First(&apiKey)
+	if res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrApiKeyNotFound
+		}
+		logrus.WithError(res.Error).Error("Error getting api key")
+		return nil, res.Error
+	}
+
+	return &apiKey, nil
+}
+
+// GetAllApiKeys implements ApiKeyRepository.
+func (a *ApiKeyRepositoryImpl) GetAllApiKeys(ctx context.Context) ([]models.ApiKey, error) {
+	var apiKeys []models.ApiKey
+
+	res := a.db.WithContext(ctx).Order("id").Find(&apiKeys)
+	if res.Error != nil {
+		logrus.WithError(res.Error).Error("Error getting api keys")
+		return nil, res.Error
+	}
+
+	return apiKeys, nil
+}
+
+// RevokeApiKey implements ApiKeyRepository.
+func (a *ApiKeyRepositoryImpl) RevokeApiKey(ctx context.Context, apiKeyID uint, revokedAt time.Time) error {
+	res := a.db.WithContext(ctx).Model(&models.ApiKey{}).Where(IdPlaceholder, apiKeyID).Where(NotRevokedPlaceholder).Update("revoked_at", revokedAt)
+	if res.Error != nil {
+		logrus.WithError(res.Error).Error("Error revoking api key")
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		var count int64
+		res = a.db.WithContext(ctx).Model(&models.ApiKey{}).Where(IdPlaceholder, apiKeyID).Count(&count)
+		if res.Error != nil {
+			logrus.WithError(res.Error).Error("Error checking api key")
+			return res.Error
+		}
+		if count == 0 {
+			return ErrApiKeyNotFound
+		}
+	}
+
+	return nil
+}
+
+// TouchApiKey implements ApiKeyRepository.
+func (a *ApiKeyRepositoryImpl) TouchApiKey(ctx context.Context, apiKeyID uint, usedAt time.Time) error {
+	// UpdateColumn leaves updated_at alone, last use is not a change to the key
+	res := a.db.WithContext(tenant.AcrossTenants(ctx)).Model(&models.ApiKey{}).Where(IdPlaceholder, apiKeyID).UpdateColumn("last_used_at", usedAt)
+	if res.Error != nil {
+		logrus.WithError(res.Error).Error("Error updating api key last use")
+		return res.Error
+	}
+
+	return nil
+}
+
+func NewApiKeyRepositoryImpl(db *gorm.DB) ApiKeyRepository {
+	return &ApiKeyRepositoryImpl{db: db}
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/models"
+	"github.com/dieg0code/products-microservice/src/tenant"
+	"github.com/dieg0code/products-microservice/src/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApiKeyRepositoryImpl(t *testing.T) {
+	ctx := tenant.WithTenant(context.Background(), tenant.DefaultTenantID)
+
+	t.Run("CreateApiKey_And_GetApiKeyByPrefix", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.ApiKey{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		repo := NewApiKeyRepositoryImpl(db)
+
+		created, err := repo.CreateApiKey(ctx, &models.ApiKey{Name: "sales", Prefix: "abc123", KeyHash: "hash", Scopes: "stock:reserve"})
+		assert.Nil(t, err, "Expected no error creating api key")
+		assert.NotZero(t, created.ID, "Expected api key ID to be set")
+
+		_, err = repo.CreateApiKey(ctx, &models.ApiKey{Name: "other", Prefix: "abc123", KeyHash: "hash", Scopes: "stock:reserve"})
+		assert.NotNil(t, err, "Expected duplicated prefix to fail")
+
+		apiKey, err := repo.GetApiKeyByPrefix(ctx, "abc123")
+		assert.Nil(t, err, "Expected no error getting api key")
+		assert.Equal(t, "sales", apiKey.Name, "Expected api key name to be the same")
+
+		_, err = repo.GetApiKeyByPrefix(ctx, "missing")
+		assert.ErrorIs(t, err, ErrApiKeyNotFound, "Expected api key not found error")
+	})
+
+	t.Run("RevokeApiKey_Keeps_First_Revocation", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.ApiKey{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		repo := NewApiKeyRepositoryImpl(db)
+		created, _ := repo.CreateApiKey(ctx, &models.ApiKey{Name: "sales", Prefix: "abc123", KeyHash: "hash", Scopes: "stock:reserve"})
+
+		first := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+		err := repo.RevokeApiKey(ctx, created.ID, first)
+		assert.Nil(t, err, "Expected no error revoking api key")
+
+		err = repo.RevokeApiKey(ctx, created.ID, time.Now())
+		assert.Nil(t, err, "Expected revoking twice to succeed")
+
+		apiKey, _ := repo.GetApiKeyByPrefix(ctx, "abc123")
+		assert.True(t, first.Equal(*apiKey.RevokedAt), "Expected first revocation time to be kept")
+
+		err = repo.RevokeApiKey(ctx, 99, time.Now())
+		assert.ErrorIs(t, err, ErrApiKeyNotFound, "Expected api key not found error")
+	})
+
+	t.Run("TouchApiKey_And_GetAllApiKeys", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.ApiKey{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		repo := NewApiKeyRepositoryImpl(db)
+		created, _ := repo.CreateApiKey(ctx, &models.ApiKey{Name: "sales", Prefix: "abc123", KeyHash: "hash", Scopes: "stock:reserve"})
+		_, _ = repo.CreateApiKey(ctx, &models.ApiKey{Name: "users", Prefix: "def456", KeyHash: "hash", Scopes: "products:read"})
+
+		usedAt := time.Now().UTC().Truncate(time.Second)
+		err := repo.TouchApiKey(ctx, created.ID, usedAt)
+		assert.Nil(t, err, "Expected no error touching api key")
+
+		apiKeys, err := repo.GetAllApiKeys(ctx)
+		assert.Nil(t, err, "Expected no error getting api keys")
+		assert.Len(t, apiKeys, 2, "Expected two api keys")
+		assert.True(t, usedAt.Equal(*apiKeys[0].LastUsedAt), "Expected last use to be stored")
+		assert.Nil(t, apiKeys[1].LastUsedAt, "Expected unused key to have no last use")
+	})
+
+	t.Run("Tenant_Isolation", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.ApiKey{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		repo := NewApiKeyRepositoryImpl(db)
+		acme := tenant.WithTenant(context.Background(), "acme")
+
+		created, err := repo.CreateApiKey(acme, &models.ApiKey{Name: "sales", Prefix: "abc123", KeyHash: "hash", Scopes: "stock:reserve"})
+		assert.Nil(t, err, "Expected no error creating api key")
+		assert.Equal(t, "acme", created.TenantID, "Expected the key to belong to the tenant of the context")
+
+		apiKeys, err := repo.GetAllApiKeys(ctx)
+		assert.Nil(t, err, "Expected no error getting api keys")
+		assert.Empty(t, apiKeys, "Expected keys of other tenants to be hidden")
+
+		err = repo.RevokeApiKey(ctx, created.ID, time.Now())
+		assert.ErrorIs(t, err, ErrApiKeyNotFound, "Expected keys of other tenants not to be revoked")
+
+		apiKey, err := repo.GetApiKeyByPrefix(context.Background(), "abc123")
+		assert.Nil(t, err, "Expected keys to be found before the tenant is known")
+		assert.Equal(t, "acme", apiKey.TenantID, "Expected the tenant of the key")
+		assert.Nil(t, apiKey.RevokedAt, "Expected the key to stay active")
+
+		err = repo.TouchApiKey(context.Background(), created.ID, time.Now())
+		assert.Nil(t, err, "Expected no error touching api key")
+	})
+}
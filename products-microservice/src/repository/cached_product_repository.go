@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/dieg0code/products-microservice/src/cache"
+)
+
+// CachedProductRepository serves GetProductById from a cache and invalidates it on writes
+type CachedProductRepository interface {
+	ProductRepository
+	// InvalidateProducts drops cached products changed outside this repository
+	InvalidateProducts(ctx context.Context, productIDs ...uint)
+	Stats() cache.Stats
+}
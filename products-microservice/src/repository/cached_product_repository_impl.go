@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/cache"
+	"github.com/dieg0code/products-microservice/src/logging"
+	"github.com/dieg0code/products-microservice/src/models"
+	"github.com/dieg0code/products-microservice/src/tenant"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	DefaultProductCacheTTL = 30 * time.Second
+	// DefaultProductLoadTimeout bounds the query shared by concurrent misses, it no
+	// longer follows the deadline of the caller that started it
+	DefaultProductLoadTimeout = 5 * time.Second
+)
+
+type CachedProductRepositoryImpl struct {
+	ProductRepository
+	cache cache.Cache
+	ttl   time.Duration
+	group singleflight.Group
+	// invalidations counts the invalidations, a load only fills the cache when none
+	// happened since it started. mu makes that check and the fill one step.
+	mu            sync.RWMutex
+	invalidations uint64
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	errors        atomic.Uint64
+}
+
+// GetProductById implements ProductRepository.
+func (c *CachedProductRepositoryImpl) GetProductById(ctx context.Context, ProductID uint) (*models.Product, error) {
+	key, ok := productCacheKey(ctx, ProductID)
+	if !ok {
+		return c.ProductRepository.GetProductById(ctx, ProductID)
+	}
+
+	value, found, err := c.cache.Get(ctx, key)
+	if err != nil {
+		c.errors.Add(1)
+		logging.FromContext(ctx).WithError(err).WithField("cache_key", key).Warn("Error reading product cache")
+	}
+	if found {
+		var product models.Product
+		if err := json.Unmarshal(value, &product); err == nil {
+			c.hits.Add(1)
+			return &product, nil
+		}
+		c.errors.Add(1)
+	}
+	c.misses.Add(1)
+
+	// Concurrent misses share one query, which must not fail because the first caller left
+	result, err, _ := c.group.Do(key, func() (interface{}, error) {
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), DefaultProductLoadTimeout)
+		defer cancel()
+
+		c.mu.RLock()
+		invalidations := c.invalidations
+		c.mu.RUnlock()
+
+		product, err := c.ProductRepository.GetProductById(loadCtx, ProductID)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := json.Marshal(product)
+		if err == nil {
+			err = c.fill(loadCtx, key, value, invalidations)
+		}
+		if err != nil {
+			c.errors.Add(1)
+			logging.FromContext(ctx).WithError(err).WithField("cache_key", key).Warn("Error writing product cache")
+		}
+		return product, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Every caller gets its own copy so one can't modify what the others see
+	product := *result.(*models.Product)
+	return &product, nil
+}
+
+// fill caches a loaded product unless a write invalidated the cache while it was
+// loading, the row may then be older than the write
+func (c *CachedProductRepositoryImpl) fill(ctx context.Context, key string, value []byte, invalidations uint64) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.invalidations != invalidations {
+		return nil
+	}
+	return c.cache.Set(ctx, key, value, c.ttl)
+}
+
+// UpdateProduct implements ProductRepository.
+func (c *CachedProductRepositoryImpl) UpdateProduct(ctx context.Context, productID uint, product *models.Product) (*models.Product, error) {
+	updated, err := c.ProductRepository.UpdateProduct(ctx, productID, product)
+	c.InvalidateProducts(ctx, productID)
+	return updated, err
+}
+
+// DeleteProduct implements ProductRepository.
+func (c *CachedProductRepositoryImpl) DeleteProduct(ctx context.Context, ProductID uint) error {
+	err := c.ProductRepository.DeleteProduct(ctx, ProductID)
+	c.InvalidateProducts(ctx, ProductID)
+	return err
+}
+
+// ApplyOperations implements ProductRepository.
+func (c *CachedProductRepositoryImpl) ApplyOperations(ctx context.Context, operations []models.ProductOperation, atomic bool) ([]models.ProductOperationResult, error) {
+	results, err := c.ProductRepository.ApplyOperations(ctx, operations, atomic)
+
+	var productIDs []uint
+	for _, operation := range operations {
+		if operation.Op != models.ProductOperationCreate {
+			productIDs = append(productIDs, operation.ProductID)
+		}
+	}
+	c.InvalidateProducts(ctx, productIDs...)
+
+	return results, err
+}
+
+// InvalidateProducts implements CachedProductRepository. Writes invalidate even when
+// they fail, a failed write may still have reached the database.
+func (c *CachedProductRepositoryImpl) InvalidateProducts(ctx context.Context, productIDs ...uint) {
+	keys := make([]string, 0, len(productIDs))
+	for _, productID := range productIDs {
+		if key, ok := productCacheKey(ctx, productID); ok {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+
+	// Waits for the fills in progress, the ones that start later see the new count
+	c.mu.Lock()
+	c.invalidations++
+	c.mu.Unlock()
+	// Callers that arrive from now on must not join a load that started before the write
+	for _, key := range keys {
+		c.group.Forget(key)
+	}
+
+	err := c.cache.Delete(context.WithoutCancel(ctx), keys...)
+	if err != nil {
+		c.errors.Add(1)
+		logging.FromContext(ctx).WithError(err).WithField("cache_keys", keys).Error("Error invalidating product cache")
+	}
+}
+
+// Stats implements CachedProductRepository.
+func (c *CachedProductRepositoryImpl) Stats() cache.Stats {
+	return cache.Stats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+		Errors: c.errors.Load(),
+	}
+}
+
+// productCacheKey is scoped to the tenant, without one the cache is skipped
+func productCacheKey(ctx context.Context, productID uint) (string, bool) {
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("product:%s:%d", tenantID, productID), true
+}
+
+func NewCachedProductRepositoryImpl(repo ProductRepository, cache cache.Cache, ttl time.Duration) CachedProductRepository {
+	return &CachedProductRepositoryImpl{ProductRepository: repo, cache: cache, ttl: ttl}
+}
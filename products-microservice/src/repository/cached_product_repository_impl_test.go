@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/cache"
+	"github.com/dieg0code/products-microservice/src/models"
+	"github.com/dieg0code/products-microservice/src/tenant"
+	"github.com/dieg0code/products-microservice/src/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestCachedProductRepositoryImpl(t *testing.T) {
+	ctx := tenant.WithTenant(context.Background(), tenant.DefaultTenantID)
+	product := &models.Product{Model: gorm.Model{ID: 1}, Name: "Test Product", Category: "Test Category", Price: 1000, Stock: 10}
+
+	t.Run("GetProductById_Hit_After_Miss", func(t *testing.T) {
+		mockRepo := new(testutils.MockProductRepository)
+		mockRepo.On("GetProductById", mock.Anything, uint(1)).Return(product, nil).Once()
+		repo := NewCachedProductRepositoryImpl(mockRepo, cache.NewLRUCacheImpl(10), time.Minute)
+
+		first, err := repo.GetProductById(ctx, 1)
+		assert.Nil(t, err, "Expected no error on miss")
+		second, err := repo.GetProductById(ctx, 1)
+		assert.Nil(t, err, "Expected no error on hit")
+
+		assert.Equal(t, product.Name, first.Name, "Expected loaded product")
+		assert.Equal(t, product.Name, second.Name, "Expected cached product")
+		assert.Equal(t, cache.Stats{Hits: 1, Misses: 1}, repo.Stats(), "Expected one hit and one miss")
+		mockRepo.AssertNumberOfCalls(t, "GetProductById", 1)
+	})
+
+	t.Run("GetProductById_Load_Has_Deadline", func(t *testing.T) {
+		mockRepo := new(testutils.MockProductRepository)
+		hasDeadline := mock.MatchedBy(func(ctx context.Context) bool {
+			_, ok := ctx.Deadline()
+			return ok
+		})
+		mockRepo.On("GetProductById", hasDeadline, uint(1)).Return(product, nil)
+		repo := NewCachedProductRepositoryImpl(mockRepo, cache.NewLRUCacheImpl(10), time.Minute)
+
+		_, err := repo.GetProductById(ctx, 1)
+		assert.Nil(t, err, "Expected the load to run with a deadline")
+	})
+
+	t.Run("GetProductById_Scoped_To_Tenant", func(t *testing.T) {
+		mockRepo := new(testutils.MockProductRepository)
+		mockRepo.On("GetProductById", mock.Anything, uint(1)).Return(product, nil)
+		repo := NewCachedProductRepositoryImpl(mockRepo, cache.NewLRUCacheImpl(10), time.Minute)
+
+		_, _ = repo.GetProductById(ctx, 1)
+		_, _ = repo.GetProductById(tenant.WithTenant(context.Background(), "acme"), 1)
+
+		mockRepo.AssertNumberOfCalls(t, "GetProductById", 2)
+	})
+
+	t.Run("GetProductById_NotFound_Not_Cached", func(t *testing.T) {
+		mockRepo := new(testutils.MockProductRepository)
+		mockRepo.On("GetProductById", mock.Anything, uint(1)).Return((*models.Product)(nil), ErrProductNotFound)
+		repo := NewCachedProductRepositoryImpl(mockRepo, cache.NewLRUCacheImpl(10), time.Minute)
+
+		_, err := repo.GetProductById(ctx, 1)
+		assert.ErrorIs(t, err, ErrProductNotFound, "Expected not found error")
+		_, err = repo.GetProductById(ctx, 1)
+		assert.ErrorIs(t, err, ErrProductNotFound, "Expected not found error")
+
+		mockRepo.AssertNumberOfCalls(t, "GetProductById", 2)
+	})
+
+	t.Run("GetProductById_Collapses_Concurrent_Misses", func(t *testing.T) {
+		release := make(chan time.Time)
+		mockRepo := new(testutils.MockProductRepository)
+		mockRepo.On("GetProductById", mock.Anything, uint(1)).WaitUntil(release).Return(product, nil)
+		repo := NewCachedProductRepositoryImpl(mockRepo, cache.NewLRUCacheImpl(10), time.Minute)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				loaded, err := repo.GetProductById(ctx, 1)
+				assert.Nil(t, err, "Expected no error")
+				assert.Equal(t, product.Name, loaded.Name, "Expected loaded product")
+			}()
+		}
+
+		// Give every caller time to join the in-flight load before it finishes
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		mockRepo.AssertNumberOfCalls(t, "GetProductById", 1)
+	})
+
+	t.Run("GetProductById_Does_Not_Cache_Load_Racing_A_Write", func(t *testing.T) {
+		release := make(chan time.Time)
+		updated := &models.Product{Model: gorm.Model{ID: 1}, Name: "Updated Product"}
+		mockRepo := new(testutils.MockProductRepository)
+		mockRepo.On("GetProductById", mock.Anything, uint(1)).WaitUntil(release).Return(product, nil).Once()
+		mockRepo.On("GetProductById", mock.Anything, uint(1)).Return(updated, nil)
+		mockRepo.On("UpdateProduct", mock.Anything, uint(1), mock.Anything).Return(updated, nil)
+		repo := NewCachedProductRepositoryImpl(mockRepo, cache.NewLRUCacheImpl(10), time.Minute)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, _ = repo.GetProductById(ctx, 1)
+		}()
+
+		// The load has read the old row when the write commits and invalidates
+		time.Sleep(50 * time.Millisecond)
+		_, _ = repo.UpdateProduct(ctx, 1, updated)
+		close(release)
+		<-done
+
+		loaded, err := repo.GetProductById(ctx, 1)
+		assert.Nil(t, err, "Expected no error")
+		assert.Equal(t, updated.Name, loaded.Name, "Expected the old row not to be cached")
+		mockRepo.AssertNumberOfCalls(t, "GetProductById", 2)
+	})
+
+	t.Run("Writes_Invalidate", func(t *testing.T) {
+		mockRepo := new(testutils.MockProductRepository)
+		mockRepo.On("GetProductById", mock.Anything, uint(1)).Return(product, nil)
+		mockRepo.On("UpdateProduct", mock.Anything, uint(1), mock.Anything).Return(product, nil)
+		mockRepo.On("DeleteProduct", mock.Anything, uint(1)).Return(nil)
+		mockRepo.On("ApplyOperations", mock.Anything, mock.Anything, true).Return([]models.ProductOperationResult{{}}, nil)
+		repo := NewCachedProductRepositoryImpl(mockRepo, cache.NewLRUCacheImpl(10), time.Minute)
+
+		writes := []func(){
+			func() { _, _ = repo.UpdateProduct(ctx, 1, &models.Product{Name: "Updated Product"}) },
+			func() { _ = repo.DeleteProduct(ctx, 1) },
+			func() {
+				_, _ = repo.ApplyOperations(ctx, []models.ProductOperation{{Op: models.ProductOperationUpdate, ProductID: 1}}, true)
+			},
+			func() { repo.InvalidateProducts(ctx, 1) },
+		}
+
+		_, _ = repo.GetProductById(ctx, 1)
+		for _, write := range writes {
+			write()
+			_, _ = repo.GetProductById(ctx, 1)
+		}
+
+		mockRepo.AssertNumberOfCalls(t, "GetProductById", 1+len(writes))
+	})
+
+	t.Run("Sale_Invalidates_Stock", func(t *testing.T) {
+		mockRepo := new(testutils.MockProductRepository)
+		mockRepo.On("GetProductById", mock.Anything, uint(1)).Return(product, nil)
+		mockStockRepo := new(testutils.MockStockRepository)
+		mockStockRepo.On("DecrementStockForSale", mock.Anything, "sale-1", map[uint]int{1: 2}).Return([]models.StockShortage(nil), nil)
+
+		repo := NewCachedProductRepositoryImpl(mockRepo, cache.NewLRUCacheImpl(10), time.Minute)
+		stockRepo := NewCachedStockRepositoryImpl(mockStockRepo, repo)
+
+		_, _ = repo.GetProductById(ctx, 1)
+		_, err := stockRepo.DecrementStockForSale(ctx, "sale-1", map[uint]int{1: 2})
+		assert.Nil(t, err, "Expected no error decrementing stock")
+		_, _ = repo.GetProductById(ctx, 1)
+
+		mockRepo.AssertNumberOfCalls(t, "GetProductById", 2)
+	})
+}
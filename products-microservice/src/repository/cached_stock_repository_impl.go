@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/dieg0code/products-microservice/src/models"
+)
+
+// CachedStockRepositoryImpl drops the cached products whose stock a sale or an adjustment changed
+type CachedStockRepositoryImpl struct {
+	StockRepository
+	products CachedProductRepository
+}
+
+// DecrementStockForSale implements StockRepository.
+func (c *CachedStockRepositoryImpl) DecrementStockForSale(ctx context.Context, saleID string, items map[uint]int) ([]models.StockShortage, error) {
+	shortages, err := c.StockRepository.DecrementStockForSale(ctx, saleID, items)
+	if err == nil && len(shortages) == 0 {
+		productIDs := make([]uint, 0, len(items))
+		for productID := range items {
+			productIDs = append(productIDs, productID)
+		}
+		c.products.InvalidateProducts(ctx, productIDs...)
+	}
+
+	return shortages, err
+}
+
+// AdjustStock implements StockRepository.
+func (c *CachedStockRepositoryImpl) AdjustStock(ctx context.Context, productID uint, delta int) (*models.Product, error) {
+	product, err := c.StockRepository.AdjustStock(ctx, productID, delta)
+	c.products.InvalidateProducts(ctx, productID)
+	return product, err
+}
+
+func NewCachedStockRepositoryImpl(stockRepo StockRepository, products CachedProductRepository) StockRepository {
+	return &CachedStockRepositoryImpl{StockRepository: stockRepo, products: products}
+}
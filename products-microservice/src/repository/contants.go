@@ -2,3 +2,21 @@ package repository
 
 const IdPlaceholder string = "id = ?"
 const CategoryPlaceholder string = "category = ?"
+const SaleIdPlaceholder string = "sale_id = ?"
+const StockAvailablePlaceholder string = "id = ? AND stock >= ?"
+const StockAdjustablePlaceholder string = "id = ? AND stock + ? >= 0"
+const KeyPlaceholder string = "key = ?"
+const AbandonedKeyPlaceholder string = "key = ? AND request_hash = ? AND status_code = 0 AND (locked_until IS NULL OR locked_until < ?)"
+const ExpiresBeforePlaceholder string = "expires_at < ?"
+const DeletedBeforePlaceholder string = "deleted_at < ?"
+const IdsPlaceholder string = "id IN ?"
+const CategoriesPlaceholder string = "category IN ?"
+const CategoryPositionSelect string = "*, ROW_NUMBER() OVER (PARTITION BY category ORDER BY id) AS category_position"
+const CategoryPositionPlaceholder string = "category_position > ? AND category_position <= ?"
+const NameContainsPlaceholder string = "LOWER(name) LIKE ? ESCAPE '\\'"
+const MinPricePlaceholder string = "price >= ?"
+const MaxPricePlaceholder string = "price <= ?"
+const InStockPlaceholder string = "stock > 0"
+const OutOfStockPlaceholder string = "stock <= 0"
+const PrefixPlaceholder string = "prefix = ?"
+const NotRevokedPlaceholder string = "revoked_at IS NULL"
@@ -0,0 +1,8 @@
+package repository
+
+import "errors"
+
+var ErrProductNotFound = errors.New("product not found")
+var ErrSaleAlreadyProcessed = errors.New("sale already processed")
+var ErrApiKeyNotFound = errors.New("api key not found")
+var ErrInsufficientStock = errors.New("insufficient stock")
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/models"
+)
+
+type IdempotencyRepository interface {
+	// Reserve stores the record unless its key is already taken, reporting whether it was stored.
+	Reserve(ctx context.Context, record *models.IdempotencyRecord) (bool, error)
+	GetByKey(ctx context.Context, key string) (*models.IdempotencyRecord, error)
+	Complete(ctx context.Context, key string, statusCode int, contentType string, body string) error
+	Release(ctx context.Context, key string) error
+	// TakeOver locks a key until lockedUntil when it is still in progress for the same
+	// request but its lock expired before now, reporting whether it was taken over.
+	TakeOver(ctx context.Context, key string, requestHash string, now time.Time, lockedUntil time.Time) (bool, error)
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
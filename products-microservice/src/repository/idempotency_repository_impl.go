@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/logging"
+	"github.com/dieg0code/products-microservice/src/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IdempotencyRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// Reserve implements IdempotencyRepository.
+func (i *IdempotencyRepositoryImpl) Reserve(ctx context.Context, record *models.IdempotencyRecord) (bool, error) {
+	res := i.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if res.Error != nil {
+		logging.FromContext(ctx).WithError(res.Error).Error("Error reserving idempotency key")
+		return false, res.Error
+	}
+
+	return res.RowsAffected > 0, nil
+}
+
+// GetByKey implements IdempotencyRepository.
+func (i *IdempotencyRepositoryImpl) GetByKey(ctx context.Context, key string) (*models.IdempotencyRecord, error) {
+	var record models.IdempotencyRecord
+
+	res := i.db.WithContext(ctx).Where(KeyPlaceholder, key).First(&record)
+	if res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("idempotency key not found")
+		}
+		logging.FromContext(ctx).WithError(res.Error).Error("Error getting idempotency key")
+		return nil, res.Error
+	}
+
+	return &record, nil
+}
+
+// Complete implements IdempotencyRepository.
+func (i *IdempotencyRepositoryImpl) Complete(ctx context.Context, key string, statusCode int, contentType string, body string) error {
+	res := i.db.WithContext(ctx).Model(&models.IdempotencyRecord{}).Where(KeyPlaceholder, key).Updates(map[string]interface{}{
+		"status_code":  statusCode,
+		"content_type": contentType,
+		"body":         body,
+	})
+	if res.Error != nil {
+		logging.FromContext(ctx).WithError(res.Error).Error("Error storing idempotent response")
+		return res.Error
+	}
+
+	return nil
+}
+
+// Release implements IdempotencyRepository.
+func (i *IdempotencyRepositoryImpl) Release(ctx context.Context, key string) error {
+	res := i.db.WithContext(ctx).Where(KeyPlaceholder, key).Delete(&models.IdempotencyRecord{})
+	if res.Error != nil {
+		logging.FromContext(ctx).WithError(res.Error).Error("Error releasing idempotency key")
+		return res.Error
+	}
+
+	return nil
+}
+
+// TakeOver implements IdempotencyRepository.
+func (i *IdempotencyRepositoryImpl) TakeOver(ctx context.Context, key string, requestHash string, now time.Time, lockedUntil time.Time) (bool, error) {
+	res := i.db.WithContext(ctx).Model(&models.IdempotencyRecord{}).
+		Where(AbandonedKeyPlaceholder, key, requestHash, now).
+		Update("locked_until", lockedUntil)
+	if res.Error != nil {
+		logging.FromContext(ctx).WithError(res.Error).Error("Error taking over idempotency key")
+		return false, res.Error
+	}
+
+	return res.RowsAffected > 0, nil
+}
+
+// DeleteExpired implements IdempotencyRepository.
+func (i *IdempotencyRepositoryImpl) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	res := i.db.WithContext(ctx).Where(ExpiresBeforePlaceholder, now).Delete(&models.IdempotencyRecord{})
+	if res.Error != nil {
+		logging.FromContext(ctx).WithError(res.Error).Error("Error deleting expired idempotency keys")
+		return 0, res.Error
+	}
+
+	return res.RowsAffected, nil
+}
+
+func NewIdempotencyRepositoryImpl(db *gorm.DB) IdempotencyRepository {
+	return &IdempotencyRepositoryImpl{db: db}
+}
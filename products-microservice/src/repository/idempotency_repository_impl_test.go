@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/models"
+	"github.com/dieg0code/products-microservice/src/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyRepositoryImpl(t *testing.T) {
+
+	t.Run("Reserve_And_Complete", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.IdempotencyRecord{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		repo := NewIdempotencyRepositoryImpl(db)
+		now := time.Now()
+
+		reserved, err := repo.Reserve(context.Background(), &models.IdempotencyRecord{Key: "key-1", RequestHash: "hash", CreatedAt: now, ExpiresAt: now.Add(time.Hour)})
+		assert.Nil(t, err, "Expected no error reserving key")
+		assert.True(t, reserved, "Expected key to be reserved")
+
+		reserved, err = repo.Reserve(context.Background(), &models.IdempotencyRecord{Key: "key-1", RequestHash: "other", CreatedAt: now, ExpiresAt: now.Add(time.Hour)})
+		assert.Nil(t, err, "Expected no error reserving key twice")
+		assert.False(t, reserved, "Expected key to be taken")
+
+		err = repo.Complete(context.Background(), "key-1", 201, "application/json", `{"code":201}`)
+		assert.Nil(t, err, "Expected no error completing key")
+
+		record, err := repo.GetByKey(context.Background(), "key-1")
+		assert.Nil(t, err, "Expected no error getting key")
+		assert.Equal(t, "hash", record.RequestHash, "Expected original request hash")
+		assert.Equal(t, 201, record.StatusCode, "Expected stored status code")
+		assert.Equal(t, `{"code":201}`, record.Body, "Expected stored body")
+	})
+
+	t.Run("TakeOver", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.IdempotencyRecord{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		repo := NewIdempotencyRepositoryImpl(db)
+		now := time.Now()
+		expired := now.Add(-time.Second)
+
+		_, err := repo.Reserve(context.Background(), &models.IdempotencyRecord{Key: "key-1", RequestHash: "hash", CreatedAt: now, ExpiresAt: now.Add(time.Hour), LockedUntil: &expired})
+		assert.Nil(t, err, "Expected no error reserving key")
+
+		taken, err := repo.TakeOver(context.Background(), "key-1", "other", now, now.Add(time.Minute))
+		assert.Nil(t, err, "Expected no error taking over key")
+		assert.False(t, taken, "Expected a different request to not take over the key")
+
+		taken, err = repo.TakeOver(context.Background(), "key-1", "hash", now, now.Add(time.Minute))
+		assert.Nil(t, err, "Expected no error taking over key")
+		assert.True(t, taken, "Expected the expired lock to be taken over")
+
+		taken, err = repo.TakeOver(context.Background(), "key-1", "hash", now, now.Add(time.Minute))
+		assert.Nil(t, err, "Expected no error taking over key")
+		assert.False(t, taken, "Expected the new lock to hold")
+
+		err = repo.Complete(context.Background(), "key-1", 201, "application/json", `{}`)
+		assert.Nil(t, err, "Expected no error completing key")
+
+		taken, err = repo.TakeOver(context.Background(), "key-1", "hash", now.Add(time.Hour), now.Add(2*time.Hour))
+		assert.Nil(t, err, "Expected no error taking over key")
+		assert.False(t, taken, "Expected a completed key to never be taken over")
+	})
+
+	t.Run("DeleteExpired", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.IdempotencyRecord{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		repo := NewIdempotencyRepositoryImpl(db)
+		now := time.Now()
+
+		_, err := repo.Reserve(context.Background(), &models.IdempotencyRecord{Key: "old", RequestHash: "hash", CreatedAt: now, ExpiresAt: now.Add(-time.Minute)})
+		assert.Nil(t, err, "Expected no error reserving key")
+		_, err = repo.Reserve(context.Background(), &models.IdempotencyRecord{Key: "new", RequestHash: "hash", CreatedAt: now, ExpiresAt: now.Add(time.Hour)})
+		assert.Nil(t, err, "Expected no error reserving key")
+
+		deleted, err := repo.DeleteExpired(context.Background(), now)
+		assert.Nil(t, err, "Expected no error deleting expired keys")
+		assert.Equal(t, int64(1), deleted, "Expected one expired key to be deleted")
+
+		_, err = repo.GetByKey(context.Background(), "old")
+		assert.NotNil(t, err, "Expected expired key to be gone")
+		_, err = repo.GetByKey(context.Background(), "new")
+		assert.Nil(t, err, "Expected live key to remain")
+	})
+}
@@ -1,13 +1,33 @@
 package repository
 
-import "github.com/dieg0code/products-microservice/src/models"
+import (
+	"context"
+	"time"
 
+	"github.com/dieg0code/products-microservice/src/models"
+)
+
+// ProductRepository scopes every query to the tenant stored in ctx
 type ProductRepository interface {
-	CreateProduct(product *models.Product) (*models.Product, error)
-	GetProductById(ProductID uint) (*models.Product, error)
-	GetAllProducts(offset int, pageSize int) ([]models.Product, error)
-	GetByCategory(category string) ([]models.Product, error)
-	UpdateProduct(productID uint, product *models.Product) (*models.Product, error)
-	DeleteProduct(ProductID uint) error
-	CheckProductExist(ProductID uint) (bool, error)
+	CreateProduct(ctx context.Context, product *models.Product) (*models.Product, error)
+	GetProductById(ctx context.Context, ProductID uint) (*models.Product, error)
+	GetAllProducts(ctx context.Context, offset int, pageSize int) ([]models.Product, error)
+	GetByCategory(ctx context.Context, category string) ([]models.Product, error)
+	UpdateProduct(ctx context.Context, productID uint, product *models.Product) (*models.Product, error)
+	DeleteProduct(ctx context.Context, ProductID uint) error
+	CheckProductExist(ctx context.Context, ProductID uint) (bool, error)
+	GetProductsByIds(ctx context.Context, productIDs []uint) ([]models.Product, error)
+	// GetByCategories returns the page starting at offset of every category, each
+	// category ordered by id
+	GetByCategories(ctx context.Context, categories []string, offset int, pageSize int) ([]models.Product, error)
+	CountByCategories(ctx context.Context, categories []string) (map[string]int64, error)
+	// SearchProducts returns a page of the products matching filter and the total number of matches
+	SearchProducts(ctx context.Context, filter models.ProductFilter, offset int, pageSize int) ([]models.Product, int64, error)
+	GetCategories(ctx context.Context) ([]string, error)
+	// ApplyOperations runs the operations in order. When atomic is set the first failure rolls
+	// back the previous operations and the remaining ones are not attempted.
+	ApplyOperations(ctx context.Context, operations []models.ProductOperation, atomic bool) ([]models.ProductOperationResult, error)
+	// PurgeDeleted permanently removes the products soft deleted before deletedBefore and
+	// returns how many were removed
+	PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error)
 }
@@ -1,10 +1,14 @@
 package repository
 
 import (
+	"context"
 	"errors"
+	"strings"
+	"time"
 
+	"github.com/dieg0code/products-microservice/src/logging"
 	"github.com/dieg0code/products-microservice/src/models"
-	"github.com/sirupsen/logrus"
+	"github.com/dieg0code/products-microservice/src/replica"
 	"gorm.io/gorm"
 )
 
@@ -13,13 +17,13 @@ type ProductRepositoryImpl struct {
 }
 
 // CheckProductExist implements ProductRepository.
-func (p *ProductRepositoryImpl) CheckProductExist(ProductID uint) (bool, error) {
+func (p *ProductRepositoryImpl) CheckProductExist(ctx context.Context, ProductID uint) (bool, error) {
 	var exists int64
 
-	res := p.db.Model(&models.Product{}).Where(IdPlaceholder, ProductID).Count(&exists)
+	res := p.db.WithContext(ctx).Model(&models.Product{}).Where(IdPlaceholder, ProductID).Count(&exists)
 
 	if res.Error != nil {
-		logrus.WithError(res.Error).Error("Error checking product existence")
+		logging.FromContext(ctx).WithError(res.Error).Error("Error checking product existence")
 		return false, res.Error
 	}
 
@@ -27,11 +31,11 @@ func (p *ProductRepositoryImpl) CheckProductExist(ProductID uint) (bool, error)
 }
 
 // CreateProduct implements ProductRepository.
-func (p *ProductRepositoryImpl) CreateProduct(product *models.Product) (*models.Product, error) {
+func (p *ProductRepositoryImpl) CreateProduct(ctx context.Context, product *models.Product) (*models.Product, error) {
 
-	result := p.db.Create(product)
+	result := p.db.WithContext(ctx).Create(product)
 	if result.Error != nil {
-		logrus.WithError(result.Error).Error("Error creating product")
+		logging.FromContext(ctx).WithError(result.Error).Error("Error creating product")
 		return nil, result.Error
 	}
 
@@ -39,21 +43,21 @@ func (p *ProductRepositoryImpl) CreateProduct(product *models.Product) (*models.
 }
 
 // DeleteProduct implements ProductRepository.
-func (p *ProductRepositoryImpl) DeleteProduct(ProductID uint) error {
-	exists, err := p.CheckProductExist(ProductID)
+func (p *ProductRepositoryImpl) DeleteProduct(ctx context.Context, ProductID uint) error {
+	exists, err := p.CheckProductExist(ctx, ProductID)
 
 	if err != nil {
-		logrus.WithError(err).Error("Error checking product existence")
+		logging.FromContext(ctx).WithError(err).Error("Error checking product existence")
 	}
 
 	if !exists {
-		logrus.Error("Product not found")
-		return errors.New("product not found")
+		logging.FromContext(ctx).Error("Product not found")
+		return ErrProductNotFound
 	}
 
-	result := p.db.Delete(&models.Product{}, ProductID)
+	result := p.db.WithContext(ctx).Delete(&models.Product{}, ProductID)
 	if result.Error != nil {
-		logrus.WithError(result.Error).Error("Error deleting product")
+		logging.FromContext(ctx).WithError(result.Error).Error("Error deleting product")
 		return result.Error
 	}
 
@@ -61,12 +65,14 @@ func (p *ProductRepositoryImpl) DeleteProduct(ProductID uint) error {
 }
 
 // GetAllProducts implements ProductRepository.
-func (p *ProductRepositoryImpl) GetAllProducts(offset int, pageSize int) ([]models.Product, error) {
+func (p *ProductRepositoryImpl) GetAllProducts(ctx context.Context, offset int, pageSize int) ([]models.Product, error) {
 	var products []models.Product
 
-	res := p.db.Offset(offset).Limit(pageSize).Find(&products)
+	// Without an order the database may return the rows of consecutive pages in different
+	// orders, so walking every page, as productsctl export does, could skip products
+	res := p.db.WithContext(ctx).Scopes(replica.Read).Order("id").Offset(offset).Limit(pageSize).Find(&products)
 	if res.Error != nil {
-		logrus.WithError(res.Error).Error("Error getting all products")
+		logging.FromContext(ctx).WithError(res.Error).Error("Error getting all products")
 		return nil, res.Error
 	}
 
@@ -74,37 +80,147 @@ func (p *ProductRepositoryImpl) GetAllProducts(offset int, pageSize int) ([]mode
 }
 
 // GetByCategory implements ProductRepository.
-func (p *ProductRepositoryImpl) GetByCategory(category string) ([]models.Product, error) {
+func (p *ProductRepositoryImpl) GetByCategory(ctx context.Context, category string) ([]models.Product, error) {
 
 	var products []models.Product
 
-	res := p.db.Where(CategoryPlaceholder, category).Find(&products)
+	res := p.db.WithContext(ctx).Scopes(replica.Read).Where(CategoryPlaceholder, category).Find(&products)
 	if res.Error != nil {
-		logrus.WithError(res.Error).Error("Error getting products by category")
+		logging.FromContext(ctx).WithError(res.Error).Error("Error getting products by category")
 		return nil, res.Error
 	}
 
 	return products, nil
 }
 
+// GetProductsByIds implements ProductRepository.
+func (p *ProductRepositoryImpl) GetProductsByIds(ctx context.Context, productIDs []uint) ([]models.Product, error) {
+	var products []models.Product
+
+	res := p.db.WithContext(ctx).Where(IdsPlaceholder, productIDs).Find(&products)
+	if res.Error != nil {
+		logging.FromContext(ctx).WithError(res.Error).Error("Error getting products by ids")
+		return nil, res.Error
+	}
+
+	return products, nil
+}
+
+// GetByCategories implements ProductRepository.
+func (p *ProductRepositoryImpl) GetByCategories(ctx context.Context, categories []string, offset int, pageSize int) ([]models.Product, error) {
+	var products []models.Product
+
+	// Number the products within their category so one query pages every category
+	ranked := p.db.WithContext(ctx).Model(&models.Product{}).Select(CategoryPositionSelect).Where(CategoriesPlaceholder, categories)
+
+	res := p.db.WithContext(ctx).Scopes(replica.Read).Table("(?) AS products", ranked).
+		Where(CategoryPositionPlaceholder, offset, offset+pageSize).Order("id").Find(&products)
+	if res.Error != nil {
+		logging.FromContext(ctx).WithError(res.Error).Error("Error getting products by categories")
+		return nil, res.Error
+	}
+
+	return products, nil
+}
+
+// CountByCategories implements ProductRepository.
+func (p *ProductRepositoryImpl) CountByCategories(ctx context.Context, categories []string) (map[string]int64, error) {
+	var rows []struct {
+		Category string
+		Total    int64
+	}
+
+	res := p.db.WithContext(ctx).Scopes(replica.Read).Model(&models.Product{}).Select("category, COUNT(*) AS total").
+		Where(CategoriesPlaceholder, categories).Group("category").Scan(&rows)
+	if res.Error != nil {
+		logging.FromContext(ctx).WithError(res.Error).Error("Error counting products by categories")
+		return nil, res.Error
+	}
+
+	totals := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		totals[row.Category] = row.Total
+	}
+
+	return totals, nil
+}
+
+// SearchProducts implements ProductRepository.
+func (p *ProductRepositoryImpl) SearchProducts(ctx context.Context, filter models.ProductFilter, offset int, pageSize int) ([]models.Product, int64, error) {
+	query := p.db.WithContext(ctx).Scopes(replica.Read).Model(&models.Product{})
+
+	if filter.Category != "" {
+		query = query.Where(CategoryPlaceholder, filter.Category)
+	}
+	if filter.NameContains != "" {
+		escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(strings.ToLower(filter.NameContains))
+		query = query.Where(NameContainsPlaceholder, "%"+escaped+"%")
+	}
+	if filter.MinPrice != nil {
+		query = query.Where(MinPricePlaceholder, *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		query = query.Where(MaxPricePlaceholder, *filter.MaxPrice)
+	}
+	if filter.InStock != nil {
+		if *filter.InStock {
+			query = query.Where(InStockPlaceholder)
+		} else {
+			query = query.Where(OutOfStockPlaceholder)
+		}
+	}
+
+	// Make the conditions reusable for both the count and the page query
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	res := query.Count(&total)
+	if res.Error != nil {
+		logging.FromContext(ctx).WithError(res.Error).Error("Error counting products")
+		return nil, 0, res.Error
+	}
+
+	var products []models.Product
+	res = query.Order("id").Offset(offset).Limit(pageSize).Find(&products)
+	if res.Error != nil {
+		logging.FromContext(ctx).WithError(res.Error).Error("Error searching products")
+		return nil, 0, res.Error
+	}
+
+	return products, total, nil
+}
+
+// GetCategories implements ProductRepository.
+func (p *ProductRepositoryImpl) GetCategories(ctx context.Context) ([]string, error) {
+	var categories []string
+
+	res := p.db.WithContext(ctx).Scopes(replica.Read).Model(&models.Product{}).Distinct("category").Order("category").Pluck("category", &categories)
+	if res.Error != nil {
+		logging.FromContext(ctx).WithError(res.Error).Error("Error getting categories")
+		return nil, res.Error
+	}
+
+	return categories, nil
+}
+
 // GetProductById implements ProductRepository.
-func (p *ProductRepositoryImpl) GetProductById(ProductID uint) (*models.Product, error) {
-	exists, err := p.CheckProductExist(ProductID)
+func (p *ProductRepositoryImpl) GetProductById(ctx context.Context, ProductID uint) (*models.Product, error) {
+	exists, err := p.CheckProductExist(ctx, ProductID)
 	if err != nil {
-		logrus.WithError(err).Error("Error checking product existence")
+		logging.FromContext(ctx).WithError(err).Error("Error checking product existence")
 		return nil, err
 	}
 
 	if !exists {
-		logrus.Error("Product not found")
-		return nil, errors.New("product not found")
+		logging.FromContext(ctx).Error("Product not found")
+		return nil, ErrProductNotFound
 	}
 
 	var product models.Product
 
-	res := p.db.First(&product, ProductID)
+	res := p.db.WithContext(ctx).First(&product, ProductID)
 	if res.Error != nil {
-		logrus.WithError(res.Error).Error("Error getting product by id")
+		logging.FromContext(ctx).WithError(res.Error).Error("Error getting product by id")
 		return nil, res.Error
 	}
 
@@ -112,27 +228,88 @@ func (p *ProductRepositoryImpl) GetProductById(ProductID uint) (*models.Product,
 }
 
 // UpdateProduct implements ProductRepository.
-func (p *ProductRepositoryImpl) UpdateProduct(prodctID uint, product *models.Product) (*models.Product, error) {
-	exists, err := p.CheckProductExist(product.ID)
+func (p *ProductRepositoryImpl) UpdateProduct(ctx context.Context, prodctID uint, product *models.Product) (*models.Product, error) {
+	product.ID = prodctID
+
+	exists, err := p.CheckProductExist(ctx, product.ID)
 	if err != nil {
-		logrus.WithError(err).Error("Error checking product existence")
+		logging.FromContext(ctx).WithError(err).Error("Error checking product existence")
 		return nil, err
 	}
 
 	if !exists {
-		logrus.WithField("product_id", product.ID).Errorf("Product with id %d not found", product.ID)
-		return nil, errors.New("product not found")
+		logging.FromContext(ctx).WithField("product_id", product.ID).Errorf("Product with id %d not found", product.ID)
+		return nil, ErrProductNotFound
 	}
 
-	result := p.db.Where(IdPlaceholder, product.ID).Updates(product)
+	result := p.db.WithContext(ctx).Where(IdPlaceholder, product.ID).Updates(product)
 	if result.Error != nil {
-		logrus.WithError(result.Error).Error("Error updating product")
+		logging.FromContext(ctx).WithError(result.Error).Error("Error updating product")
 		return nil, result.Error
 	}
 
 	return product, nil
 }
 
+// ApplyOperations implements ProductRepository.
+func (p *ProductRepositoryImpl) ApplyOperations(ctx context.Context, operations []models.ProductOperation, atomic bool) ([]models.ProductOperationResult, error) {
+	results := make([]models.ProductOperationResult, 0, len(operations))
+
+	if !atomic {
+		for _, operation := range operations {
+			results = append(results, p.applyOperation(ctx, operation))
+		}
+		return results, nil
+	}
+
+	errOperationFailed := errors.New("product operation failed")
+
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		txRepo := &ProductRepositoryImpl{db: tx}
+		for _, operation := range operations {
+			result := txRepo.applyOperation(ctx, operation)
+			results = append(results, result)
+			if result.Err != nil {
+				return errOperationFailed
+			}
+		}
+		return nil
+	})
+
+	if err != nil && !errors.Is(err, errOperationFailed) {
+		logging.FromContext(ctx).WithError(err).Error("Error committing product operations")
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (p *ProductRepositoryImpl) applyOperation(ctx context.Context, operation models.ProductOperation) models.ProductOperationResult {
+	switch operation.Op {
+	case models.ProductOperationCreate:
+		product, err := p.CreateProduct(ctx, operation.Product)
+		return models.ProductOperationResult{Product: product, Err: err}
+	case models.ProductOperationUpdate:
+		product, err := p.UpdateProduct(ctx, operation.ProductID, operation.Product)
+		return models.ProductOperationResult{Product: product, Err: err}
+	case models.ProductOperationDelete:
+		return models.ProductOperationResult{Err: p.DeleteProduct(ctx, operation.ProductID)}
+	}
+
+	return models.ProductOperationResult{Err: errors.New("unknown product operation")}
+}
+
+// PurgeDeleted implements ProductRepository.
+func (p *ProductRepositoryImpl) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	res := p.db.WithContext(ctx).Unscoped().Where(DeletedBeforePlaceholder, deletedBefore).Delete(&models.Product{})
+	if res.Error != nil {
+		logging.FromContext(ctx).WithError(res.Error).Error("Error purging deleted products")
+		return 0, res.Error
+	}
+
+	return res.RowsAffected, nil
+}
+
 func NewPorductRespositoryImpl(db *gorm.DB) ProductRepository {
 	return &ProductRepositoryImpl{db}
 }
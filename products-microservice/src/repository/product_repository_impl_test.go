@@ -1,14 +1,18 @@
 package repository
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/dieg0code/products-microservice/src/models"
+	"github.com/dieg0code/products-microservice/src/tenant"
 	"github.com/dieg0code/products-microservice/src/testutils"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestProductRespositoryImpl(t *testing.T) {
+	ctx := tenant.WithTenant(context.Background(), tenant.DefaultTenantID)
 
 	t.Run("CheckProductExist_Success", func(t *testing.T) {
 		db := testutils.SetupTestDB(&models.Product{})
@@ -29,14 +33,14 @@ func TestProductRespositoryImpl(t *testing.T) {
 			Stock:    10,
 		}
 
-		product, err := repo.CreateProduct(mockProduct)
+		product, err := repo.CreateProduct(ctx, mockProduct)
 
 		assert.Nil(t, err, "Expected no error creating product")
 		assert.NotNil(t, product, "Expected product to be created")
 		assert.NotEqual(t, uint(0), product.ID, "Expected product ID to be set")
 		assert.Equal(t, mockProduct.Name, product.Name, "Expected product name to be the same")
 
-		exists, err := repo.CheckProductExist(product.ID)
+		exists, err := repo.CheckProductExist(ctx, product.ID)
 
 		assert.Nil(t, err, "Expected no error checking product existence")
 		assert.True(t, exists, "Expected product to exist")
@@ -54,7 +58,7 @@ func TestProductRespositoryImpl(t *testing.T) {
 
 		repo := NewPorductRespositoryImpl(db)
 
-		exists, err := repo.CheckProductExist(1)
+		exists, err := repo.CheckProductExist(ctx, 1)
 
 		assert.Nil(t, err, "Expected no error checking product existence")
 		assert.False(t, exists, "Expected product to not exist")
@@ -79,7 +83,7 @@ func TestProductRespositoryImpl(t *testing.T) {
 			Stock:    10,
 		}
 
-		product, err := repo.CreateProduct(mockProduct)
+		product, err := repo.CreateProduct(ctx, mockProduct)
 
 		assert.Nil(t, err, "Expected no error creating product")
 		assert.NotNil(t, product, "Expected product to be created")
@@ -106,14 +110,14 @@ func TestProductRespositoryImpl(t *testing.T) {
 			Stock:    10,
 		}
 
-		product, err := repo.CreateProduct(mockProduct)
+		product, err := repo.CreateProduct(ctx, mockProduct)
 
 		assert.Nil(t, err, "Expected no error creating product")
 		assert.NotNil(t, product, "Expected product to be created")
 		assert.NotEqual(t, uint(0), product.ID, "Expected product ID to be set")
 		assert.Equal(t, mockProduct.Name, product.Name, "Expected product name to be the same")
 
-		product, err = repo.CreateProduct(mockProduct)
+		product, err = repo.CreateProduct(ctx, mockProduct)
 
 		assert.NotNil(t, err, "Expected error creating product")
 		assert.Nil(t, product, "Expected no product to be created")
@@ -138,14 +142,14 @@ func TestProductRespositoryImpl(t *testing.T) {
 			Stock:    10,
 		}
 
-		product, err := repo.CreateProduct(mockProduct)
+		product, err := repo.CreateProduct(ctx, mockProduct)
 
 		assert.Nil(t, err, "Expected no error creating product")
 		assert.NotNil(t, product, "Expected product to be created")
 		assert.NotEqual(t, uint(0), product.ID, "Expected product ID to be set")
 		assert.Equal(t, mockProduct.Name, product.Name, "Expected product name to be the same")
 
-		err = repo.DeleteProduct(product.ID)
+		err = repo.DeleteProduct(ctx, product.ID)
 
 		assert.Nil(t, err, "Expected no error deleting product")
 	})
@@ -162,7 +166,7 @@ func TestProductRespositoryImpl(t *testing.T) {
 
 		repo := NewPorductRespositoryImpl(db)
 
-		err := repo.DeleteProduct(1)
+		err := repo.DeleteProduct(ctx, 1)
 
 		assert.NotNil(t, err, "Expected error deleting product")
 		assert.Equal(t, "product not found", err.Error(), "Expected error message to be 'product not found'")
@@ -187,18 +191,18 @@ func TestProductRespositoryImpl(t *testing.T) {
 			Stock:    10,
 		}
 
-		product, err := repo.CreateProduct(mockProduct)
+		product, err := repo.CreateProduct(ctx, mockProduct)
 
 		assert.Nil(t, err, "Expected no error creating product")
 		assert.NotNil(t, product, "Expected product to be created")
 		assert.NotEqual(t, uint(0), product.ID, "Expected product ID to be set")
 		assert.Equal(t, mockProduct.Name, product.Name, "Expected product name to be the same")
 
-		err = repo.DeleteProduct(product.ID)
+		err = repo.DeleteProduct(ctx, product.ID)
 
 		assert.Nil(t, err, "Expected no error deleting product")
 
-		err = repo.DeleteProduct(product.ID)
+		err = repo.DeleteProduct(ctx, product.ID)
 
 		assert.NotNil(t, err, "Expected error deleting product")
 	})
@@ -222,14 +226,14 @@ func TestProductRespositoryImpl(t *testing.T) {
 			Stock:    10,
 		}
 
-		product, err := repo.CreateProduct(mockProduct)
+		product, err := repo.CreateProduct(ctx, mockProduct)
 
 		assert.Nil(t, err, "Expected no error creating product")
 		assert.NotNil(t, product, "Expected product to be created")
 		assert.NotEqual(t, uint(0), product.ID, "Expected product ID to be set")
 		assert.Equal(t, mockProduct.Name, product.Name, "Expected product name to be the same")
 
-		products, err := repo.GetAllProducts(0, 10)
+		products, err := repo.GetAllProducts(ctx, 0, 10)
 
 		assert.Nil(t, err, "Expected no error getting all products")
 		assert.NotEmpty(t, products, "Expected products to be returned")
@@ -249,7 +253,7 @@ func TestProductRespositoryImpl(t *testing.T) {
 
 		repo := NewPorductRespositoryImpl(db)
 
-		products, err := repo.GetAllProducts(0, 10)
+		products, err := repo.GetAllProducts(ctx, 0, 10)
 
 		assert.Nil(t, err, "Expected no error getting all products")
 		assert.Empty(t, products, "Expected no products to be returned")
@@ -274,14 +278,14 @@ func TestProductRespositoryImpl(t *testing.T) {
 			Stock:    10,
 		}
 
-		product, err := repo.CreateProduct(mockProduct)
+		product, err := repo.CreateProduct(ctx, mockProduct)
 
 		assert.Nil(t, err, "Expected no error creating product")
 		assert.NotNil(t, product, "Expected product to be created")
 		assert.NotEqual(t, uint(0), product.ID, "Expected product ID to be set")
 		assert.Equal(t, mockProduct.Name, product.Name, "Expected product name to be the same")
 
-		products, err := repo.GetByCategory("Test Category")
+		products, err := repo.GetByCategory(ctx, "Test Category")
 
 		assert.Nil(t, err, "Expected no error getting products by category")
 		assert.NotEmpty(t, products, "Expected products to be returned")
@@ -301,7 +305,7 @@ func TestProductRespositoryImpl(t *testing.T) {
 
 		repo := NewPorductRespositoryImpl(db)
 
-		products, err := repo.GetByCategory("Test Category")
+		products, err := repo.GetByCategory(ctx, "Test Category")
 
 		assert.Nil(t, err, "Expected no error getting products by category")
 		assert.Empty(t, products, "Expected no products to be returned")
@@ -326,14 +330,14 @@ func TestProductRespositoryImpl(t *testing.T) {
 			Stock:    10,
 		}
 
-		product, err := repo.CreateProduct(mockProduct)
+		product, err := repo.CreateProduct(ctx, mockProduct)
 
 		assert.Nil(t, err, "Expected no error creating product")
 		assert.NotNil(t, product, "Expected product to be created")
 		assert.NotEqual(t, uint(0), product.ID, "Expected product ID to be set")
 		assert.Equal(t, mockProduct.Name, product.Name, "Expected product name to be the same")
 
-		product, err = repo.GetProductById(product.ID)
+		product, err = repo.GetProductById(ctx, product.ID)
 
 		assert.Nil(t, err, "Expected no error getting product by id")
 		assert.NotNil(t, product, "Expected product to be returned")
@@ -352,7 +356,7 @@ func TestProductRespositoryImpl(t *testing.T) {
 
 		repo := NewPorductRespositoryImpl(db)
 
-		product, err := repo.GetProductById(1)
+		product, err := repo.GetProductById(ctx, 1)
 
 		assert.NotNil(t, err, "Expected error getting product by id")
 		assert.Nil(t, product, "Expected no product to be returned")
@@ -377,7 +381,7 @@ func TestProductRespositoryImpl(t *testing.T) {
 			Stock:    10,
 		}
 
-		product, err := repo.CreateProduct(mockProduct)
+		product, err := repo.CreateProduct(ctx, mockProduct)
 
 		assert.Nil(t, err, "Expected no error creating product")
 		assert.NotNil(t, product, "Expected product to be created")
@@ -386,11 +390,321 @@ func TestProductRespositoryImpl(t *testing.T) {
 
 		product.Name = "Updated Product"
 
-		product, err = repo.UpdateProduct(product.ID, product)
+		product, err = repo.UpdateProduct(ctx, product.ID, product)
 
 		assert.Nil(t, err, "Expected no error updating product")
 		assert.NotNil(t, product, "Expected product to be updated")
 		assert.Equal(t, "Updated Product", product.Name, "Expected product name to be updated")
 	})
 
+	t.Run("UpdateProduct_Uses_ProductID_Argument", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.Product{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		repo := NewPorductRespositoryImpl(db)
+
+		product, err := repo.CreateProduct(ctx, &models.Product{Name: "Test Product", Category: "Test Category", Price: 1000, Stock: 10})
+		assert.Nil(t, err, "Expected no error creating product")
+
+		updated, err := repo.UpdateProduct(ctx, product.ID, &models.Product{Name: "Updated Product", Category: "Test Category", Price: 2000, Stock: 5})
+
+		assert.Nil(t, err, "Expected no error updating product")
+		assert.Equal(t, product.ID, updated.ID, "Expected product ID to be set from the argument")
+		assert.Equal(t, 2000, updated.Price, "Expected product price to be updated")
+	})
+
+	t.Run("GetProductsByIds_Success", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.Product{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		repo := NewPorductRespositoryImpl(db)
+
+		first, err := repo.CreateProduct(ctx, &models.Product{Name: "Product 1", Category: "Test Category", Price: 1000, Stock: 10})
+		assert.Nil(t, err, "Expected no error creating product")
+		_, err = repo.CreateProduct(ctx, &models.Product{Name: "Product 2", Category: "Test Category", Price: 1000, Stock: 10})
+		assert.Nil(t, err, "Expected no error creating product")
+
+		products, err := repo.GetProductsByIds(ctx, []uint{first.ID, 99})
+
+		assert.Nil(t, err, "Expected no error getting products by ids")
+		assert.Equal(t, 1, len(products), "Expected only existing products to be returned")
+		assert.Equal(t, first.ID, products[0].ID, "Expected product ID to be the same")
+	})
+
+	t.Run("ApplyOperations_Atomic_Rollback", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.Product{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		repo := NewPorductRespositoryImpl(db)
+
+		results, err := repo.ApplyOperations(ctx, []models.ProductOperation{
+			{Op: models.ProductOperationCreate, Product: &models.Product{Name: "Product 1", Category: "Test Category", Price: 1000, Stock: 10}},
+			{Op: models.ProductOperationDelete, ProductID: 99},
+			{Op: models.ProductOperationCreate, Product: &models.Product{Name: "Product 2", Category: "Test Category", Price: 1000, Stock: 10}},
+		}, true)
+
+		assert.Nil(t, err, "Expected no error applying operations")
+		assert.Equal(t, 2, len(results), "Expected operations after the failure to be skipped")
+		assert.Nil(t, results[0].Err, "Expected first operation to succeed")
+		assert.NotNil(t, results[1].Err, "Expected second operation to fail")
+
+		products, err := repo.GetAllProducts(ctx, 0, 10)
+		assert.Nil(t, err, "Expected no error getting all products")
+		assert.Empty(t, products, "Expected the created product to be rolled back")
+	})
+
+	t.Run("ApplyOperations_Best_Effort", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.Product{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		repo := NewPorductRespositoryImpl(db)
+
+		results, err := repo.ApplyOperations(ctx, []models.ProductOperation{
+			{Op: models.ProductOperationCreate, Product: &models.Product{Name: "Product 1", Category: "Test Category", Price: 1000, Stock: 10}},
+			{Op: models.ProductOperationDelete, ProductID: 99},
+			{Op: models.ProductOperationCreate, Product: &models.Product{Name: "Product 2", Category: "Test Category", Price: 1000, Stock: 10}},
+		}, false)
+
+		assert.Nil(t, err, "Expected no error applying operations")
+		assert.Equal(t, 3, len(results), "Expected every operation to run")
+		assert.NotNil(t, results[1].Err, "Expected second operation to fail")
+
+		products, err := repo.GetAllProducts(ctx, 0, 10)
+		assert.Nil(t, err, "Expected no error getting all products")
+		assert.Equal(t, 2, len(products), "Expected both products to be created")
+	})
+
+	t.Run("SearchProducts_Filters_And_Paginates", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.Product{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		repo := NewPorductRespositoryImpl(db)
+
+		for _, product := range []*models.Product{
+			{Name: "Blue 100% Shirt", Category: "Clothes", Price: 1000, Stock: 10},
+			{Name: "Blue Pants", Category: "Clothes", Price: 3000, Stock: 0},
+			{Name: "Red Shirt", Category: "Clothes", Price: 1500, Stock: 3},
+			{Name: "Blue Book", Category: "Books", Price: 500, Stock: 1},
+		} {
+			_, err := repo.CreateProduct(ctx, product)
+			assert.Nil(t, err, "Expected no error creating product")
+		}
+
+		inStock := true
+		maxPrice := 2000
+		products, total, err := repo.SearchProducts(ctx, models.ProductFilter{Category: "Clothes", InStock: &inStock, MaxPrice: &maxPrice}, 0, 1)
+
+		assert.Nil(t, err, "Expected no error searching products")
+		assert.Equal(t, int64(2), total, "Expected two matching products")
+		assert.Equal(t, 1, len(products), "Expected page size to be applied")
+		assert.Equal(t, "Blue 100% Shirt", products[0].Name, "Expected products ordered by id")
+
+		products, total, err = repo.SearchProducts(ctx, models.ProductFilter{NameContains: "100%"}, 0, 10)
+
+		assert.Nil(t, err, "Expected no error searching products")
+		assert.Equal(t, int64(1), total, "Expected wildcards in the name to be escaped")
+		assert.Equal(t, "Blue 100% Shirt", products[0].Name, "Expected the literal match")
+	})
+
+	t.Run("GetCategories_And_GetByCategories", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.Product{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		repo := NewPorductRespositoryImpl(db)
+
+		for _, product := range []*models.Product{
+			{Name: "Product 1", Category: "Games", Price: 1000, Stock: 10},
+			{Name: "Product 2", Category: "Books", Price: 1000, Stock: 10},
+			{Name: "Product 3", Category: "Books", Price: 1000, Stock: 10},
+			{Name: "Product 4", Category: "Toys", Price: 1000, Stock: 10},
+		} {
+			_, err := repo.CreateProduct(ctx, product)
+			assert.Nil(t, err, "Expected no error creating product")
+		}
+
+		categories, err := repo.GetCategories(ctx)
+
+		assert.Nil(t, err, "Expected no error getting categories")
+		assert.Equal(t, []string{"Books", "Games", "Toys"}, categories, "Expected distinct sorted categories")
+
+		products, err := repo.GetByCategories(ctx, []string{"Books", "Games"}, 0, 10)
+
+		assert.Nil(t, err, "Expected no error getting products by categories")
+		assert.Equal(t, 3, len(products), "Expected products of both categories")
+
+		products, err = repo.GetByCategories(ctx, []string{"Books", "Games"}, 1, 1)
+
+		assert.Nil(t, err, "Expected no error getting products by categories")
+		assert.Equal(t, 1, len(products), "Expected one page per category")
+		assert.Equal(t, "Product 3", products[0].Name, "Expected the second book")
+
+		totals, err := repo.CountByCategories(ctx, []string{"Books", "Games"})
+
+		assert.Nil(t, err, "Expected no error counting products by categories")
+		assert.Equal(t, map[string]int64{"Books": 2, "Games": 1}, totals, "Expected totals per category")
+	})
+
+	t.Run("Tenant_Isolation", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.Product{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		repo := NewPorductRespositoryImpl(db)
+		acme := tenant.WithTenant(context.Background(), "acme")
+
+		product, err := repo.CreateProduct(ctx, &models.Product{Name: "Test Product", Category: "Test Category", Price: 1000, Stock: 10})
+		assert.Nil(t, err, "Expected no error creating product")
+
+		other, err := repo.CreateProduct(acme, &models.Product{Name: "Test Product", TenantID: "default", Category: "Test Category", Price: 2000, Stock: 5})
+		assert.Nil(t, err, "Expected the same name to be allowed in another tenant")
+		assert.Equal(t, "acme", other.TenantID, "Expected tenant from the context to win")
+
+		_, err = repo.GetProductById(acme, product.ID)
+		assert.ErrorIs(t, err, ErrProductNotFound, "Expected other tenant's product to be hidden")
+
+		_, err = repo.UpdateProduct(acme, product.ID, &models.Product{Name: "Updated Product", Category: "Test Category", Price: 1, Stock: 1})
+		assert.ErrorIs(t, err, ErrProductNotFound, "Expected other tenant's product not to be updated")
+
+		err = repo.DeleteProduct(acme, product.ID)
+		assert.ErrorIs(t, err, ErrProductNotFound, "Expected other tenant's product not to be deleted")
+
+		products, err := repo.GetAllProducts(acme, 0, 10)
+		assert.Nil(t, err, "Expected no error getting products")
+		assert.Equal(t, 1, len(products), "Expected only the tenant's product")
+		assert.Equal(t, other.ID, products[0].ID, "Expected the tenant's product")
+
+		product, err = repo.GetProductById(ctx, product.ID)
+		assert.Nil(t, err, "Expected no error getting product")
+		assert.Equal(t, "Test Product", product.Name, "Expected product to be untouched")
+		assert.Equal(t, 10, product.Stock, "Expected product to be untouched")
+	})
+
+	t.Run("Missing_Tenant", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.Product{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		repo := NewPorductRespositoryImpl(db)
+
+		_, err := repo.CreateProduct(context.Background(), &models.Product{Name: "Test Product", Category: "Test Category", Price: 1000, Stock: 10})
+		assert.ErrorIs(t, err, tenant.ErrMissingTenant, "Expected create without tenant to fail")
+
+		_, err = repo.GetAllProducts(context.Background(), 0, 10)
+		assert.ErrorIs(t, err, tenant.ErrMissingTenant, "Expected query without tenant to fail")
+	})
+
+	t.Run("Cancelled_Context", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.Product{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		repo := NewPorductRespositoryImpl(db)
+		ctx := tenant.WithTenant(context.Background(), tenant.DefaultTenantID)
+
+		created, err := repo.CreateProduct(ctx, &models.Product{Name: "Test Product", Category: "Test Category", Price: 1000, Stock: 10})
+		assert.Nil(t, err, "Expected no error creating product")
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err = repo.GetAllProducts(cancelled, 0, 10)
+		assert.ErrorIs(t, err, context.Canceled, "Expected the query to be cancelled")
+
+		_, err = repo.UpdateProduct(cancelled, created.ID, &models.Product{Name: "Updated Product", Category: "Test Category", Price: 2000, Stock: 5})
+		assert.ErrorIs(t, err, context.Canceled, "Expected the update to be cancelled")
+
+		product, err := repo.GetProductById(ctx, created.ID)
+		assert.Nil(t, err, "Expected no error getting product")
+		assert.Equal(t, "Test Product", product.Name, "Expected the cancelled update not to be applied")
+	})
+
+	t.Run("PurgeDeleted", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.Product{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		repo := NewPorductRespositoryImpl(db)
+		ctx := tenant.WithTenant(context.Background(), tenant.DefaultTenantID)
+		otherCtx := tenant.WithTenant(context.Background(), "acme")
+
+		kept, err := repo.CreateProduct(ctx, &models.Product{Name: "Kept Product", Category: "Test Category", Price: 1000, Stock: 10})
+		assert.Nil(t, err, "Expected no error creating product")
+		deleted, err := repo.CreateProduct(ctx, &models.Product{Name: "Deleted Product", Category: "Test Category", Price: 1000, Stock: 10})
+		assert.Nil(t, err, "Expected no error creating product")
+		other, err := repo.CreateProduct(otherCtx, &models.Product{Name: "Other Product", Category: "Test Category", Price: 1000, Stock: 10})
+		assert.Nil(t, err, "Expected no error creating product")
+
+		assert.Nil(t, repo.DeleteProduct(ctx, deleted.ID), "Expected no error deleting product")
+		assert.Nil(t, repo.DeleteProduct(otherCtx, other.ID), "Expected no error deleting product")
+
+		purged, err := repo.PurgeDeleted(ctx, time.Now().Add(-time.Hour))
+		assert.Nil(t, err, "Expected no error purging")
+		assert.Equal(t, int64(0), purged, "Expected recent deletions to be kept")
+
+		purged, err = repo.PurgeDeleted(ctx, time.Now().Add(time.Minute))
+		assert.Nil(t, err, "Expected no error purging")
+		assert.Equal(t, int64(1), purged, "Expected the deleted product of the tenant to be purged")
+
+		var remaining int64
+		db.Raw("SELECT COUNT(*) FROM products").Scan(&remaining)
+		assert.Equal(t, int64(2), remaining, "Expected the kept product and the other tenant's deletion to remain")
+		_, err = repo.GetProductById(ctx, kept.ID)
+		assert.Nil(t, err, "Expected the kept product to remain")
+	})
 }
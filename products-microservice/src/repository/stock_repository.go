@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/dieg0code/products-microservice/src/models"
+)
+
+type StockRepository interface {
+	// DecrementStockForSale applies every quantity in items (productID -> quantity) or none of them.
+	// When stock is insufficient the sale is recorded as rejected and the shortages are returned.
+	// A sale seen before fails with ErrSaleAlreadyProcessed, along with the shortages recorded
+	// for it when it was rejected.
+	DecrementStockForSale(ctx context.Context, saleID string, items map[uint]int) ([]models.StockShortage, error)
+	// AdjustStock adds delta, which may be negative, to the stock of a product. It fails with
+	// ErrInsufficientStock instead of leaving the stock below zero.
+	AdjustStock(ctx context.Context, productID uint, delta int) (*models.Product, error)
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/logging"
+	"github.com/dieg0code/products-microservice/src/models"
+	"gorm.io/gorm"
+)
+
+type StockRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// DecrementStockForSale implements StockRepository.
+func (s *StockRepositoryImpl) DecrementStockForSale(ctx context.Context, saleID string, items map[uint]int) ([]models.StockShortage, error) {
+	var shortages []models.StockShortage
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var processed []models.ProcessedSale
+		res := tx.Where(SaleIdPlaceholder, saleID).Limit(1).Find(&processed)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if len(processed) > 0 {
+			if processed[0].Status == models.SaleStatusRejected && processed[0].Shortages != "" {
+				// The compensation of a rejected sale may not have gone out, hand the
+				// shortages back so redeliveries can publish it again
+				if err := json.Unmarshal([]byte(processed[0].Shortages), &shortages); err != nil {
+					return err
+				}
+			}
+			return ErrSaleAlreadyProcessed
+		}
+
+		// Lock rows in a stable order so concurrent sales can't deadlock each other
+		productIDs := make([]uint, 0, len(items))
+		for productID := range items {
+			productIDs = append(productIDs, productID)
+		}
+		sort.Slice(productIDs, func(i, j int) bool { return productIDs[i] < productIDs[j] })
+
+		for _, productID := range productIDs {
+			quantity := items[productID]
+
+			res := tx.Model(&models.Product{}).
+				Where(StockAvailablePlaceholder, productID, quantity).
+				Updates(map[string]interface{}{
+					"stock":      gorm.Expr("stock - ?", quantity),
+					"updated_at": time.Now(),
+				})
+			if res.Error != nil {
+				return res.Error
+			}
+
+			if res.RowsAffected > 0 {
+				continue
+			}
+
+			available := 0
+			var product models.Product
+			res = tx.Select("stock").First(&product, productID)
+			if res.Error != nil && !errors.Is(res.Error, gorm.ErrRecordNotFound) {
+				return res.Error
+			}
+			if res.Error == nil {
+				available = product.Stock
+			}
+
+			shortages = append(shortages, models.StockShortage{
+				ProductID: productID,
+				Requested: quantity,
+				Available: available,
+			})
+		}
+
+		if len(shortages) > 0 {
+			return ErrInsufficientStock
+		}
+
+		return tx.Create(&models.ProcessedSale{SaleID: saleID, Status: models.SaleStatusApplied}).Error
+	})
+
+	if errors.Is(err, ErrInsufficientStock) {
+		// The decrements were rolled back, remember the sale and its shortages so
+		// redeliveries don't apply it later but can still be compensated
+		recorded, err := json.Marshal(shortages)
+		if err != nil {
+			return nil, err
+		}
+		res := s.db.WithContext(ctx).Create(&models.ProcessedSale{SaleID: saleID, Status: models.SaleStatusRejected, Shortages: string(recorded)})
+		if res.Error != nil {
+			logging.FromContext(ctx).WithError(res.Error).WithField("sale_id", saleID).Error("Error recording rejected sale")
+			return nil, res.Error
+		}
+
+		return shortages, nil
+	}
+
+	if errors.Is(err, ErrSaleAlreadyProcessed) {
+		return shortages, err
+	}
+
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("sale_id", saleID).Error("Error decrementing stock for sale")
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// AdjustStock implements StockRepository.
+func (s *StockRepositoryImpl) AdjustStock(ctx context.Context, productID uint, delta int) (*models.Product, error) {
+	var product models.Product
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.Product{}).
+			Where(StockAdjustablePlaceholder, productID, delta).
+			Updates(map[string]interface{}{
+				"stock":      gorm.Expr("stock + ?", delta),
+				"updated_at": time.Now(),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		adjusted := res.RowsAffected > 0
+
+		res = tx.First(&product, productID)
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			return ErrProductNotFound
+		}
+		if res.Error != nil {
+			return res.Error
+		}
+
+		// The product exists, so the update only skipped it to keep the stock from going negative
+		if !adjusted {
+			return ErrInsufficientStock
+		}
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, ErrProductNotFound) && !errors.Is(err, ErrInsufficientStock) {
+			logging.FromContext(ctx).WithError(err).WithField("product_id", productID).Error("Error adjusting stock")
+		}
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+func NewStockRepositoryImpl(db *gorm.DB) StockRepository {
+	return &StockRepositoryImpl{db: db}
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dieg0code/products-microservice/src/models"
+	"github.com/dieg0code/products-microservice/src/tenant"
+	"github.com/dieg0code/products-microservice/src/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStockRepositoryImpl(t *testing.T) {
+	ctx := tenant.WithTenant(context.Background(), tenant.DefaultTenantID)
+
+	t.Run("DecrementStockForSale_Success", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.Product{}, &models.ProcessedSale{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		productRepo := NewPorductRespositoryImpl(db)
+		stockRepo := NewStockRepositoryImpl(db)
+
+		product, err := productRepo.CreateProduct(ctx, &models.Product{Name: "Test Product", Category: "Test Category", Price: 1000, Stock: 10})
+		assert.Nil(t, err, "Expected no error creating product")
+
+		shortages, err := stockRepo.DecrementStockForSale(ctx, "sale-1", map[uint]int{product.ID: 3})
+
+		assert.Nil(t, err, "Expected no error decrementing stock")
+		assert.Empty(t, shortages, "Expected no shortages")
+
+		product, err = productRepo.GetProductById(ctx, product.ID)
+		assert.Nil(t, err, "Expected no error getting product")
+		assert.Equal(t, 7, product.Stock, "Expected stock to be decremented")
+	})
+
+	t.Run("DecrementStockForSale_Idempotent", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.Product{}, &models.ProcessedSale{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		productRepo := NewPorductRespositoryImpl(db)
+		stockRepo := NewStockRepositoryImpl(db)
+
+		product, err := productRepo.CreateProduct(ctx, &models.Product{Name: "Test Product", Category: "Test Category", Price: 1000, Stock: 10})
+		assert.Nil(t, err, "Expected no error creating product")
+
+		_, err = stockRepo.DecrementStockForSale(ctx, "sale-1", map[uint]int{product.ID: 3})
+		assert.Nil(t, err, "Expected no error decrementing stock")
+
+		shortages, err := stockRepo.DecrementStockForSale(ctx, "sale-1", map[uint]int{product.ID: 3})
+		assert.ErrorIs(t, err, ErrSaleAlreadyProcessed, "Expected sale to be already processed")
+		assert.Empty(t, shortages, "Expected no shortages for an applied sale")
+
+		product, err = productRepo.GetProductById(ctx, product.ID)
+		assert.Nil(t, err, "Expected no error getting product")
+		assert.Equal(t, 7, product.Stock, "Expected stock to be decremented only once")
+	})
+
+	t.Run("DecrementStockForSale_Insufficient_Stock", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.Product{}, &models.ProcessedSale{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		productRepo := NewPorductRespositoryImpl(db)
+		stockRepo := NewStockRepositoryImpl(db)
+
+		first, err := productRepo.CreateProduct(ctx, &models.Product{Name: "Product 1", Category: "Test Category", Price: 1000, Stock: 10})
+		assert.Nil(t, err, "Expected no error creating product")
+		second, err := productRepo.CreateProduct(ctx, &models.Product{Name: "Product 2", Category: "Test Category", Price: 1000, Stock: 2})
+		assert.Nil(t, err, "Expected no error creating product")
+
+		shortages, err := stockRepo.DecrementStockForSale(ctx, "sale-1", map[uint]int{first.ID: 1, second.ID: 5, 99: 1})
+
+		assert.Nil(t, err, "Expected no error decrementing stock")
+		assert.Equal(t, []models.StockShortage{
+			{ProductID: second.ID, Requested: 5, Available: 2},
+			{ProductID: 99, Requested: 1, Available: 0},
+		}, shortages, "Expected shortages for the second and unknown products")
+
+		first, err = productRepo.GetProductById(ctx, first.ID)
+		assert.Nil(t, err, "Expected no error getting product")
+		assert.Equal(t, 10, first.Stock, "Expected stock to be left untouched")
+
+		var sale models.ProcessedSale
+		err = db.WithContext(ctx).Where(SaleIdPlaceholder, "sale-1").First(&sale).Error
+		assert.Nil(t, err, "Expected sale to be recorded")
+		assert.Equal(t, models.SaleStatusRejected, sale.Status, "Expected sale to be rejected")
+
+		redelivered, err := stockRepo.DecrementStockForSale(ctx, "sale-1", map[uint]int{first.ID: 1})
+		assert.ErrorIs(t, err, ErrSaleAlreadyProcessed, "Expected rejected sale to be already processed")
+		assert.Equal(t, shortages, redelivered, "Expected the recorded shortages of the rejected sale")
+	})
+
+	t.Run("AdjustStock_Success", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.Product{}, &models.ProcessedSale{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		productRepo := NewPorductRespositoryImpl(db)
+		stockRepo := NewStockRepositoryImpl(db)
+
+		product, err := productRepo.CreateProduct(ctx, &models.Product{Name: "Test Product", Category: "Test Category", Price: 1000, Stock: 10})
+		assert.Nil(t, err, "Expected no error creating product")
+
+		adjusted, err := stockRepo.AdjustStock(ctx, product.ID, 5)
+		assert.Nil(t, err, "Expected no error restocking")
+		assert.Equal(t, 15, adjusted.Stock, "Expected stock to be incremented")
+
+		adjusted, err = stockRepo.AdjustStock(ctx, product.ID, -15)
+		assert.Nil(t, err, "Expected no error removing every unit")
+		assert.Equal(t, 0, adjusted.Stock, "Expected stock to be decremented")
+	})
+
+	t.Run("AdjustStock_Insufficient_Stock", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.Product{}, &models.ProcessedSale{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		productRepo := NewPorductRespositoryImpl(db)
+		stockRepo := NewStockRepositoryImpl(db)
+
+		product, err := productRepo.CreateProduct(ctx, &models.Product{Name: "Test Product", Category: "Test Category", Price: 1000, Stock: 2})
+		assert.Nil(t, err, "Expected no error creating product")
+
+		_, err = stockRepo.AdjustStock(ctx, product.ID, -3)
+		assert.ErrorIs(t, err, ErrInsufficientStock, "Expected insufficient stock")
+
+		product, err = productRepo.GetProductById(ctx, product.ID)
+		assert.Nil(t, err, "Expected no error getting product")
+		assert.Equal(t, 2, product.Stock, "Expected stock to be unchanged")
+	})
+
+	t.Run("AdjustStock_Not_Found", func(t *testing.T) {
+		db := testutils.SetupTestDB(&models.Product{}, &models.ProcessedSale{})
+		defer func() {
+			sqlDB, _ := db.DB()
+			err := sqlDB.Close()
+			if err != nil {
+				t.Error("Error closing database connection")
+			}
+		}()
+
+		stockRepo := NewStockRepositoryImpl(db)
+
+		_, err := stockRepo.AdjustStock(ctx, 42, 1)
+		assert.ErrorIs(t, err, ErrProductNotFound, "Expected product not found")
+	})
+}
@@ -0,0 +1,292 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/dieg0code/products-microservice/src/auth"
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/middleware"
+	"github.com/dieg0code/products-microservice/src/openapi"
+	"github.com/dieg0code/products-microservice/src/stream"
+	"github.com/dieg0code/products-microservice/src/tenant"
+)
+
+// The OpenAPI document and the Swagger UI reading it
+const (
+	OpenAPIPath = "/openapi.json"
+	DocsPath    = "/docs"
+)
+
+// APIVersion is the version of the /api/v1 contract, bump it with the routes or DTOs
+const APIVersion = "1.0.0"
+
+const (
+	securityBearer = "bearerAuth"
+	securityApiKey = "apiKeyAuth"
+)
+
+// OpenAPI documents the /api/v1 routes InitRoutes mounts with the router's current
+// configuration. Routes are declared here and in InitRoutes, TestOpenAPI fails when
+// the two disagree.
+func (r *Router) OpenAPI() (*openapi.Document, error) {
+	b := openapi.NewBuilder(openapi.Info{
+		Title:       "Products Microservice",
+		Version:     APIVersion,
+		Description: "Product catalog and stock of the store. Every response is wrapped in an envelope whose data holds the result.",
+	})
+
+	errorEnvelope := b.ResponseObject(response.BaseResponse{})
+	errorEnvelope.Properties["data"] = &openapi.Schema{Type: openapi.Types{openapi.TypeNull}}
+	errorSchema := b.AddSchema("ErrorResponse", errorEnvelope)
+	errorResponse := func(description string) *openapi.Response {
+		return openapi.JSONResponse(description, errorSchema)
+	}
+
+	// envelope is the BaseResponse handlers answer with, data nil stays null
+	envelope := func(description string, data interface{}) *openapi.Response {
+		schema := b.ResponseObject(response.BaseResponse{})
+		schema.Properties["data"] = &openapi.Schema{Type: openapi.Types{openapi.TypeNull}}
+		if data != nil {
+			schema.Properties["data"] = b.ResponseSchema(data)
+		}
+		return openapi.JSONResponse(description, schema)
+	}
+
+	if r.Authenticator != nil {
+		b.AddSecurityScheme(securityBearer, &openapi.SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: "JWT"})
+		b.AddSecurityScheme(securityApiKey, &openapi.SecurityScheme{Type: "apiKey", In: openapi.InHeader, Name: auth.ApiKeyHeader})
+	}
+
+	tenantHeader := &openapi.Parameter{
+		Name:        tenant.HeaderName,
+		In:          openapi.InHeader,
+		Description: "Tenant of the catalog, the subdomain or the service's default tenant otherwise",
+		Schema:      &openapi.Schema{Type: openapi.Types{openapi.TypeString}},
+	}
+	idempotencyHeader := &openapi.Parameter{
+		Name:        middleware.IdempotencyKeyHeader,
+		In:          openapi.InHeader,
+		Description: "Replays the response of an earlier request with the same key instead of executing it again",
+		Schema:      &openapi.Schema{Type: openapi.Types{openapi.TypeString}, MaxLength: intPtr(middleware.MaxIdempotencyKeyLength)},
+	}
+	productID := &openapi.Parameter{
+		Name:     "productID",
+		In:       openapi.InPath,
+		Required: true,
+		Schema:   &openapi.Schema{Type: openapi.Types{openapi.TypeInteger}, Format: "int64", Minimum: floatPtr(1)},
+	}
+	notModified := &openapi.Response{Description: "The cached response is still current"}
+
+	// operation completes what every /api/v1 operation shares, product writes go
+	// through ProductMiddlewares which take an Idempotency-Key
+	operation := func(permission auth.Permission, op *openapi.Operation, idempotent bool) *openapi.Operation {
+		op.Parameters = append(op.Parameters, tenantHeader)
+		if idempotent {
+			op.Parameters = append(op.Parameters, idempotencyHeader)
+		}
+		op.Responses["default"] = errorResponse("Error")
+
+		if r.Authenticator != nil && permission != publicRoute {
+			op.Security = []openapi.SecurityRequirement{{securityBearer: {}}}
+			if permission.Scope != "" {
+				op.Security = append(op.Security, openapi.SecurityRequirement{securityApiKey: {}})
+			}
+			op.Responses["401"] = errorResponse("Missing or invalid credentials")
+			op.Responses["403"] = errorResponse("Insufficient permissions")
+		}
+		return op
+	}
+	cacheable := func(route string, op *openapi.Operation) *openapi.Operation {
+		if _, ok := r.CachePolicies[route]; ok {
+			op.Responses["304"] = notModified
+		}
+		return op
+	}
+
+	b.Add(http.MethodPost, "/api/v1/products", operation(auth.PermissionWriteProducts, &openapi.Operation{
+		OperationID: "createProduct",
+		Summary:     "Create a product",
+		Tags:        []string{"products"},
+		RequestBody: b.JSONBody(request.CreateProductRequest{}),
+		Responses: map[string]*openapi.Response{
+			"201": envelope("ID of the created product", uint(0)),
+			"400": errorResponse("Invalid product"),
+		},
+	}, true))
+
+	b.Add(http.MethodGet, "/api/v1/products/:productID", operation(publicRoute, &openapi.Operation{
+		OperationID: "getProduct",
+		Summary:     "Get a product",
+		Tags:        []string{"products"},
+		Parameters:  []*openapi.Parameter{productID},
+		Responses: map[string]*openapi.Response{
+			"200": withLastModified(envelope("The product", response.ProductResponse{})),
+			"400": errorResponse("Invalid product ID"),
+		},
+	}, false))
+
+	b.Add(http.MethodGet, "/api/v1/products", operation(publicRoute, cacheable(RouteProductsList, &openapi.Operation{
+		OperationID: "listProducts",
+		Summary:     "List products a page at a time",
+		Tags:        []string{"products"},
+		Parameters: []*openapi.Parameter{
+			{Name: "page", In: openapi.InQuery, Schema: &openapi.Schema{Type: openapi.Types{openapi.TypeInteger}, Minimum: floatPtr(1), Default: 1}},
+			{Name: "pageSize", In: openapi.InQuery, Schema: &openapi.Schema{Type: openapi.Types{openapi.TypeInteger}, Minimum: floatPtr(1), Default: 10}},
+		},
+		Responses: map[string]*openapi.Response{
+			"200": envelope("A page of products, null past the last one", []response.ProductResponse{}),
+			"400": errorResponse("Invalid page"),
+		},
+	}), false))
+
+	b.Add(http.MethodGet, "/api/v1/products/category/:category", operation(publicRoute, cacheable(RouteProductsByCategory, &openapi.Operation{
+		OperationID: "listProductsByCategory",
+		Summary:     "List the products of a category",
+		Tags:        []string{"products"},
+		Parameters: []*openapi.Parameter{
+			{Name: "category", In: openapi.InPath, Required: true, Schema: &openapi.Schema{Type: openapi.Types{openapi.TypeString}}},
+		},
+		Responses: map[string]*openapi.Response{
+			"200": envelope("The products of the category", []response.ProductResponse{}),
+		},
+	}), false))
+
+	b.Add(http.MethodPut, "/api/v1/products/:productID", operation(auth.PermissionWriteProducts, &openapi.Operation{
+		OperationID: "updateProduct",
+		Summary:     "Replace a product",
+		Tags:        []string{"products"},
+		Parameters:  []*openapi.Parameter{productID},
+		RequestBody: b.JSONBody(request.UpdateProductRequest{}),
+		Responses: map[string]*openapi.Response{
+			"200": envelope("The updated product", response.ProductResponse{}),
+			"400": errorResponse("Invalid product ID or product"),
+		},
+	}, true))
+
+	b.Add(http.MethodDelete, "/api/v1/products/:productID", operation(auth.PermissionDeleteProducts, &openapi.Operation{
+		OperationID: "deleteProduct",
+		Summary:     "Delete a product",
+		Tags:        []string{"products"},
+		Parameters:  []*openapi.Parameter{productID},
+		Responses: map[string]*openapi.Response{
+			"200": envelope("The product was deleted", nil),
+			"400": errorResponse("Invalid product ID"),
+		},
+	}, true))
+
+	b.Add(http.MethodPost, "/api/v1/products/batch-get", operation(publicRoute, &openapi.Operation{
+		OperationID: "batchGetProducts",
+		Summary:     "Get several products at once",
+		Tags:        []string{"products"},
+		RequestBody: b.JSONBody(request.BatchGetProductsRequest{}),
+		Responses: map[string]*openapi.Response{
+			"200": envelope("The products found and the IDs that were not", response.BatchGetProductsResponse{}),
+			"400": errorResponse("Invalid product IDs"),
+		},
+	}, true))
+
+	b.Add(http.MethodPost, "/api/v1/products/batch", operation(auth.PermissionWriteProducts, &openapi.Operation{
+		OperationID: "batchProducts",
+		Summary:     "Create, update and delete products in one request",
+		Description: "Batches containing deletes also need the permission to delete products.",
+		Tags:        []string{"products"},
+		RequestBody: b.JSONBody(request.BatchProductRequest{}),
+		Responses: map[string]*openapi.Response{
+			"200": envelope("Every operation succeeded", response.BatchProductResponse{}),
+			"207": envelope("Some operations failed, see their status", response.BatchProductResponse{}),
+			"400": errorResponse("Invalid batch"),
+			"403": errorResponse("The batch deletes products without the permission to"),
+		},
+	}, true))
+
+	if r.StockController != nil {
+		b.Add(http.MethodPost, "/api/v1/products/:productID/stock", operation(auth.PermissionWriteProducts, &openapi.Operation{
+			OperationID: "adjustStock",
+			Summary:     "Add to or remove from the stock of a product",
+			Tags:        []string{"products"},
+			Parameters:  []*openapi.Parameter{productID},
+			RequestBody: b.JSONBody(request.AdjustStockRequest{}),
+			Responses: map[string]*openapi.Response{
+				"200": envelope("The product with its new stock", response.ProductResponse{}),
+				"400": errorResponse("Invalid product ID or adjustment"),
+				"404": errorResponse("Product not found"),
+				"409": errorResponse("Not enough stock"),
+			},
+		}, true))
+	}
+
+	if r.ProductStreamController != nil {
+		b.ResponseSchema(stream.ProductEvent{})
+		b.Add(http.MethodGet, "/api/v1/products/stream", operation(publicRoute, &openapi.Operation{
+			OperationID: "streamProducts",
+			Summary:     "Follow product changes as server-sent events",
+			Description: "Each event's data is a ProductEvent and its id can be sent back as Last-Event-ID to resume.",
+			Tags:        []string{"products"},
+			Parameters: []*openapi.Parameter{
+				{Name: "category", In: openapi.InQuery, Description: "Only events of this category", Schema: &openapi.Schema{Type: openapi.Types{openapi.TypeString}}},
+				{Name: "product_ids", In: openapi.InQuery, Description: "Only events of these comma separated product IDs", Schema: &openapi.Schema{Type: openapi.Types{openapi.TypeString}}},
+				{Name: "last_event_id", In: openapi.InQuery, Description: "Replay the events after this one", Schema: &openapi.Schema{Type: openapi.Types{openapi.TypeInteger}, Minimum: floatPtr(0)}},
+				{Name: "Last-Event-ID", In: openapi.InHeader, Description: "Sent by browsers on reconnect, wins over last_event_id", Schema: &openapi.Schema{Type: openapi.Types{openapi.TypeString}}},
+			},
+			Responses: map[string]*openapi.Response{
+				"200": {Description: "The event stream", Content: map[string]*openapi.MediaType{"text/event-stream": {Schema: &openapi.Schema{Type: openapi.Types{openapi.TypeString}}}}},
+				"400": errorResponse("Invalid product_ids"),
+			},
+		}, false))
+	}
+
+	if r.ApiKeyController != nil && r.Authenticator != nil {
+		b.Add(http.MethodPost, "/api/v1/api-keys", operation(auth.PermissionManageApiKeys, &openapi.Operation{
+			OperationID: "createApiKey",
+			Summary:     "Create an API key",
+			Description: "The key is only ever returned by this response.",
+			Tags:        []string{"api-keys"},
+			RequestBody: b.JSONBody(request.CreateApiKeyRequest{}),
+			Responses: map[string]*openapi.Response{
+				"201": envelope("The API key", response.CreatedApiKeyResponse{}),
+				"400": errorResponse("Invalid API key"),
+			},
+		}, false))
+
+		b.Add(http.MethodGet, "/api/v1/api-keys", operation(auth.PermissionManageApiKeys, &openapi.Operation{
+			OperationID: "listApiKeys",
+			Summary:     "List the API keys of the tenant",
+			Tags:        []string{"api-keys"},
+			Responses: map[string]*openapi.Response{
+				"200": envelope("The API keys, without the keys themselves", []response.ApiKeyResponse{}),
+			},
+		}, false))
+
+		b.Add(http.MethodDelete, "/api/v1/api-keys/:apiKeyID", operation(auth.PermissionManageApiKeys, &openapi.Operation{
+			OperationID: "revokeApiKey",
+			Summary:     "Revoke an API key",
+			Tags:        []string{"api-keys"},
+			Parameters: []*openapi.Parameter{
+				{Name: "apiKeyID", In: openapi.InPath, Required: true, Schema: &openapi.Schema{Type: openapi.Types{openapi.TypeInteger}, Format: "int64", Minimum: floatPtr(1)}},
+			},
+			Responses: map[string]*openapi.Response{
+				"200": envelope("The API key was revoked", nil),
+				"400": errorResponse("Invalid API key ID"),
+				"404": errorResponse("API key not found"),
+			},
+		}, false))
+	}
+
+	return b.Document()
+}
+
+func withLastModified(res *openapi.Response) *openapi.Response {
+	res.Headers = map[string]*openapi.Header{
+		"Last-Modified": {Description: "When the product last changed, for If-Modified-Since", Schema: &openapi.Schema{Type: openapi.Types{openapi.TypeString}}},
+	}
+	return res
+}
+
+func floatPtr(value float64) *float64 {
+	return &value
+}
+
+func intPtr(value int) *int {
+	return &value
+}
@@ -0,0 +1,166 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/dieg0code/products-microservice/src/auth"
+	"github.com/dieg0code/products-microservice/src/controllers"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/middleware"
+	"github.com/dieg0code/products-microservice/src/stream"
+	"github.com/dieg0code/products-microservice/src/testutils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestOpenAPI(t *testing.T) {
+
+	newRouter := func() *Router {
+		return NewRouter(controllers.NewProductControllerImpl(new(testutils.MockProductService), validator.New()))
+	}
+
+	fullRouter := func() *Router {
+		verifier, err := auth.NewJWTVerifierImpl(auth.JWTConfig{HMACSecret: routerTestSecret})
+		assert.Nil(t, err, "Expected no error creating verifier")
+
+		r := newRouter()
+		r.StockController = controllers.NewStockControllerImpl(new(testutils.MockStockService), validator.New())
+		r.ProductStreamController = controllers.NewProductStreamControllerImpl(stream.NewHub(10, 10), controllers.DefaultHeartbeatInterval)
+		r.Authenticator = auth.NewAuthenticator(verifier, new(testutils.MockApiKeyService))
+		r.ApiKeyController = controllers.NewApiKeyControllerImpl(new(testutils.MockApiKeyService), validator.New())
+		return r
+	}
+
+	// documented lists the operations of the document as gin registers them
+	documented := func(t *testing.T, r *Router) []string {
+		document, err := r.OpenAPI()
+		assert.Nil(t, err, "Expected the document to build")
+
+		var operations []string
+		for path, item := range document.Paths {
+			segments := strings.Split(path, "/")
+			for i, segment := range segments {
+				if strings.HasPrefix(segment, "{") {
+					segments[i] = ":" + strings.Trim(segment, "{}")
+				}
+			}
+			for method := range *item {
+				operations = append(operations, strings.ToUpper(method)+" "+strings.Join(segments, "/"))
+			}
+		}
+		sort.Strings(operations)
+		return operations
+	}
+
+	mounted := func(engine *gin.Engine) []string {
+		var operations []string
+		for _, route := range engine.Routes() {
+			if strings.HasPrefix(route.Path, "/api/") {
+				operations = append(operations, route.Method+" "+route.Path)
+			}
+		}
+		sort.Strings(operations)
+		return operations
+	}
+
+	t.Run("OpenAPI_Matches_Routes", func(t *testing.T) {
+		r := newRouter()
+		assert.Equal(t, mounted(r.InitRoutes()), documented(t, r), "Expected every API route to be documented and nothing else")
+	})
+
+	t.Run("OpenAPI_Matches_Routes_All_Features", func(t *testing.T) {
+		r := fullRouter()
+		routes := mounted(r.InitRoutes())
+		assert.Contains(t, routes, "POST /api/v1/products/:productID/stock", "Expected the optional routes to be mounted")
+		assert.Contains(t, routes, "DELETE /api/v1/api-keys/:apiKeyID", "Expected the optional routes to be mounted")
+		assert.Equal(t, routes, documented(t, r), "Expected every API route to be documented and nothing else")
+	})
+
+	t.Run("OpenAPI_Security", func(t *testing.T) {
+		document, err := newRouter().OpenAPI()
+		assert.Nil(t, err, "Expected the document to build")
+		assert.Empty(t, document.Components.SecuritySchemes, "Expected no security without an authenticator")
+
+		document, err = fullRouter().OpenAPI()
+		assert.Nil(t, err, "Expected the document to build")
+		assert.Contains(t, document.Components.SecuritySchemes, securityApiKey, "Expected the API key scheme")
+
+		products := *document.Paths["/api/v1/products"]
+		assert.Len(t, products["post"].Security, 2, "Expected writes to accept tokens and API keys")
+		assert.Contains(t, products["post"].Responses, "401", "Expected writes to document missing credentials")
+		assert.Empty(t, products["get"].Security, "Expected reads to stay public")
+
+		apiKeys := *document.Paths["/api/v1/api-keys"]
+		assert.Len(t, apiKeys["get"].Security, 1, "Expected API keys to be managed with tokens only")
+		assert.Contains(t, apiKeys["get"].Security[0], securityBearer, "Expected API keys to be managed with tokens only")
+	})
+
+	t.Run("OpenAPI_Served", func(t *testing.T) {
+		engine := newRouter().InitRoutes()
+
+		req, _ := http.NewRequest(http.MethodGet, OpenAPIPath, nil)
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code, "Expected status code 200")
+		assert.Contains(t, rec.Header().Get("Content-Type"), "application/json", "Expected a JSON document")
+
+		var document struct {
+			OpenAPI    string                     `json:"openapi"`
+			Paths      map[string]json.RawMessage `json:"paths"`
+			Components struct {
+				Schemas map[string]json.RawMessage `json:"schemas"`
+			} `json:"components"`
+		}
+		assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &document), "Expected the document to be valid JSON")
+		assert.Equal(t, "3.1.0", document.OpenAPI, "Expected an OpenAPI 3.1 document")
+		assert.Contains(t, document.Paths, "/api/v1/products/{productID}", "Expected OpenAPI path templates")
+		assert.Contains(t, document.Components.Schemas, "ProductResponse", "Expected the DTOs as schemas")
+
+		req, _ = http.NewRequest(http.MethodGet, DocsPath, nil)
+		rec = httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code, "Expected status code 200")
+		assert.Contains(t, rec.Header().Get("Content-Type"), "text/html", "Expected the Swagger UI page")
+		assert.Contains(t, rec.Body.String(), `url: "openapi.json"`, "Expected the page to load the document")
+	})
+
+	t.Run("OpenAPI_Validates_Requests", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		r := NewRouter(controllers.NewProductControllerImpl(mockService, validator.New()))
+		r.Contract = middleware.ContractConfig{Requests: true}
+		r.AuthDisabled = true
+		engine := r.InitRoutes()
+
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/products", strings.NewReader(`{"name": 5, "category": "Tools", "price": 100, "stock": 10}`))
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, "Expected status code 400")
+		var res response.BaseResponse
+		assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &res), "Expected a JSON error")
+		assert.Equal(t, []response.ContractViolation{{In: "body", Pointer: "/name", Keyword: "type", Message: "must be string"}}, res.Violations, "Expected the violation")
+		mockService.AssertNotCalled(t, "CreateProduct", mock.Anything, mock.Anything)
+	})
+
+	t.Run("OpenAPI_Validates_After_Permission", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		r := NewRouter(controllers.NewProductControllerImpl(mockService, validator.New()))
+		r.Contract = middleware.ContractConfig{Requests: true}
+		engine := r.InitRoutes()
+
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/products", strings.NewReader(`{"name": 5}`))
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, "Expected the permission check to run first")
+	})
+}
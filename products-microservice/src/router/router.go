@@ -1,18 +1,119 @@
 package router
 
 import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/auth"
+	"github.com/dieg0code/products-microservice/src/cache"
 	"github.com/dieg0code/products-microservice/src/controllers"
-	"github.com/dieg0code/products-microservice/src/db"
+	"github.com/dieg0code/products-microservice/src/health"
+	"github.com/dieg0code/products-microservice/src/metrics"
+	"github.com/dieg0code/products-microservice/src/middleware"
+	"github.com/dieg0code/products-microservice/src/openapi"
+	"github.com/dieg0code/products-microservice/src/ratelimit"
+	"github.com/dieg0code/products-microservice/src/tenant"
+	"github.com/dieg0code/products-microservice/src/tracing"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Router struct {
 	ProductController controllers.ProductController
+	// ProductStreamController serves /api/v1/products/stream when set. Its events are
+	// per replica, writes served by other pods are not streamed
+	ProductStreamController controllers.ProductStreamController
+	// StockController serves /api/v1/products/:productID/stock when set
+	StockController controllers.StockController
+	// ProductMiddlewares run before every handler under /api/v1/products
+	ProductMiddlewares []gin.HandlerFunc
+	// GraphQLHandler serves /graphql when set
+	GraphQLHandler gin.HandlerFunc
+	// Authenticator enables bearer token and API key authentication. Without it routes
+	// that need a permission reject every request, unless AuthDisabled is set.
+	Authenticator *auth.Authenticator
+	// AuthDisabled serves every route without checking permissions
+	AuthDisabled bool
+	// ApiKeyController serves /api/v1/api-keys, it is only mounted together with an Authenticator
+	ApiKeyController controllers.ApiKeyController
+	// Tenants configures how /api/v1 and /graphql requests pick their tenant
+	Tenants middleware.TenantConfig
+	// RateLimitStore enables per client rate limiting of the route groups listed in RateLimits
+	RateLimitStore ratelimit.Store
+	RateLimits     map[string]ratelimit.Policy
+	// ProductCacheStats serves /debug/cache/products to administrators when set
+	ProductCacheStats func() cache.Stats
+	// CachePolicies lets clients and CDNs cache the listed routes, the others are never cached
+	CachePolicies map[string]middleware.CachePolicy
+	// MetricsRegistry serves /metrics and records the HTTP metrics of every route when set
+	MetricsRegistry *prometheus.Registry
+	// Contract validates /api/v1 requests, and optionally responses, against the OpenAPI document
+	Contract middleware.ContractConfig
+	// Timeouts bounds how long /api/v1 and /graphql requests may run
+	Timeouts middleware.TimeoutConfig
+	// TracerProvider starts a server span for every request, continuing the caller's trace
+	TracerProvider trace.TracerProvider
+	// Readiness fails /ready while it returns false, so the process can drain before stopping
+	Readiness func() bool
+	// Health checks the dependencies behind /ready and tells /startup and /ready when startup is done
+	Health *health.Health
+	// TrustedProxies may set the client IP through X-Forwarded-For, by default no proxy is
+	// trusted and the rate limiter keys anonymous clients by the peer address
+	TrustedProxies []string
+
+	// contract holds the ValidateContract middleware built by InitRoutes, if enabled
+	contract []gin.HandlerFunc
+}
+
+// Routes that can be given a CachePolicy
+const (
+	RouteProductsList       = "products.list"
+	RouteProductsByCategory = "products.by-category"
+)
+
+// DefaultCachePolicies keep catalog pages short lived. Responses depend on the tenant,
+// which credentials can pick as well, and are private when credentials are sent.
+var DefaultCachePolicies = map[string]middleware.CachePolicy{
+	RouteProductsList:       {CacheControl: "public, max-age=30, stale-while-revalidate=60", Vary: cacheVary},
+	RouteProductsByCategory: {CacheControl: "public, max-age=30, stale-while-revalidate=60", Vary: cacheVary},
+}
+
+var cacheVary = []string{tenant.HeaderName, "Authorization", auth.ApiKeyHeader}
+
+// DefaultRequestTimeout bounds every API request unless DefaultTimeouts says otherwise
+const DefaultRequestTimeout = 10 * time.Second
+
+// DefaultTimeouts leave the event stream open and give batches more time
+var DefaultTimeouts = middleware.TimeoutConfig{
+	Default: DefaultRequestTimeout,
+	Routes: map[string]time.Duration{
+		"GET /api/v1/products/stream": 0,
+		"POST /api/v1/products/batch": 30 * time.Second,
+	},
 }
 
+// Route groups that can be given their own rate limits
+const (
+	RouteGroupProducts = "products"
+	RouteGroupApiKeys  = "api-keys"
+	RouteGroupGraphQL  = "graphql"
+)
+
+// publicRoute leaves a route open to anonymous callers
+var publicRoute = auth.Permission{}
+
 func NewRouter(productController controllers.ProductController) *Router {
 	return &Router{
 		ProductController: productController,
+		Tenants:           middleware.TenantConfig{DefaultTenantID: tenant.DefaultTenantID},
+		CachePolicies:     DefaultCachePolicies,
+		Timeouts:          DefaultTimeouts,
 	}
 }
 
@@ -20,6 +121,26 @@ func (r *Router) InitRoutes() *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.New()
 	router.Use(gin.Recovery())
+	if err := router.SetTrustedProxies(r.TrustedProxies); err != nil {
+		logrus.WithError(err).Warn("Ignoring invalid trusted proxies, no proxy is trusted")
+		_ = router.SetTrustedProxies(nil)
+	}
+
+	if r.TracerProvider != nil {
+		router.Use(otelgin.Middleware(tracing.ServiceName,
+			otelgin.WithTracerProvider(r.TracerProvider),
+			otelgin.WithPropagators(tracing.Propagator),
+			otelgin.WithFilter(isTraced),
+		))
+	}
+
+	// After the tracing middleware so access lines carry the trace IDs
+	router.Use(middleware.RequestLogger())
+
+	if r.MetricsRegistry != nil {
+		router.Use(middleware.Metrics(metrics.NewHTTPMetricsImpl(r.MetricsRegistry)))
+		router.GET("/metrics", gin.WrapH(promhttp.HandlerFor(r.MetricsRegistry, promhttp.HandlerOpts{})))
+	}
 
 	router.GET("", func(ctx *gin.Context) {
 		ctx.JSON(200, gin.H{
@@ -34,33 +155,173 @@ func (r *Router) InitRoutes() *gin.Engine {
 	})
 
 	router.GET("/ready", func(ctx *gin.Context) {
+		if r.Readiness != nil && !r.Readiness() {
+			ctx.JSON(503, gin.H{
+				"status":  health.StatusDown,
+				"message": "Products Microservice is shutting down",
+			})
+			return
+		}
+
+		if r.Health != nil && !r.Health.Started() {
+			ctx.JSON(503, gin.H{
+				"status":  health.StatusDown,
+				"message": "Products Microservice is starting",
+			})
+			return
+		}
+
+		if r.Health == nil {
+			ctx.JSON(200, health.Report{Status: health.StatusUp, Components: map[string]health.ComponentReport{}, CheckedAt: time.Now()})
+			return
+		}
+
+		report := r.Health.Check(ctx.Request.Context())
+		if report.Status == health.StatusDown {
+			ctx.JSON(503, report)
+			return
+		}
+		ctx.JSON(200, report)
+	})
 
-		dbConn := db.DatabaseConnection()
-		err := db.CheckDatabaseConnection(dbConn)
-		if err != nil {
-			ctx.JSON(500, gin.H{
-				"message": "Products Microservice is not ready",
+	router.GET("/startup", func(ctx *gin.Context) {
+		if r.Health != nil && !r.Health.Started() {
+			ctx.JSON(503, gin.H{
+				"message": "Products Microservice is starting",
 			})
 			return
 		}
 
 		ctx.JSON(200, gin.H{
-			"message": "Products Microservice is ready",
+			"message": "Products Microservice is started",
 		})
 	})
 
+	// The document only depends on the configuration, one that doesn't build is a bug
+	document, err := r.OpenAPI()
+	if err != nil {
+		panic("invalid OpenAPI document: " + err.Error())
+	}
+	spec, err := json.Marshal(document)
+	if err != nil {
+		panic("invalid OpenAPI document: " + err.Error())
+	}
+	docs := openapi.SwaggerUI(strings.TrimPrefix(OpenAPIPath, "/"))
+
+	router.GET(OpenAPIPath, func(ctx *gin.Context) {
+		ctx.Data(200, openapi.ContentTypeJSON, spec)
+	})
+
+	router.GET(DocsPath, func(ctx *gin.Context) {
+		ctx.Data(200, "text/html; charset=utf-8", docs)
+	})
+
+	if r.Authenticator != nil {
+		router.Use(middleware.Authenticate(r.Authenticator))
+	} else if r.AuthDisabled {
+		router.Use(middleware.DisableAuthentication())
+	}
+
+	if r.ProductCacheStats != nil {
+		router.GET("/debug/cache/products", middleware.Require(auth.PermissionReadDebug), func(ctx *gin.Context) {
+			ctx.JSON(200, r.ProductCacheStats())
+		})
+	}
+
+	timeout := middleware.Timeout(r.Timeouts)
+	resolveTenant := middleware.ResolveTenant(r.Tenants)
+
+	if r.GraphQLHandler != nil {
+		// Mutations check permissions in their resolvers since reads share the same endpoint
+		graphQLHandlers := append([]gin.HandlerFunc{timeout, resolveTenant}, r.rateLimit(RouteGroupGraphQL)...)
+		graphQLHandlers = append(graphQLHandlers, r.GraphQLHandler)
+		router.POST("/graphql", graphQLHandlers...)
+		router.GET("/graphql", graphQLHandlers...)
+	}
+
 	baseRoute := router.Group("/api/v1")
+	baseRoute.Use(timeout, resolveTenant)
+	// Requests are validated after the rate limits and permission checks, so clients
+	// can't spend the validation on requests that would be turned away anyway
+	r.contract = nil
+	if r.Contract.Enabled() {
+		r.contract = []gin.HandlerFunc{middleware.ValidateContract(document, r.Contract)}
+	}
 	{
 		productRoute := baseRoute.Group("/products")
+		productRoute.Use(r.rateLimit(RouteGroupProducts)...)
 		{
-			productRoute.POST("", r.ProductController.CreateProduct)
-			productRoute.GET("/:productID", r.ProductController.GetProductById)
-			productRoute.GET("", r.ProductController.GetAllProducts)
-			productRoute.GET("/category/:category", r.ProductController.GetByCategory)
-			productRoute.PUT("/:productID", r.ProductController.UpdateProduct)
-			productRoute.DELETE("/:productID", r.ProductController.DeleteProduct)
+			productRoute.POST("", r.productHandlers(auth.PermissionWriteProducts, r.ProductController.CreateProduct)...)
+			productRoute.GET("/:productID", r.productHandlers(publicRoute, r.ProductController.GetProductById)...)
+			productRoute.GET("", r.productHandlers(publicRoute, r.cacheable(RouteProductsList, r.ProductController.GetAllProducts)...)...)
+			productRoute.GET("/category/:category", r.productHandlers(publicRoute, r.cacheable(RouteProductsByCategory, r.ProductController.GetByCategory)...)...)
+			productRoute.PUT("/:productID", r.productHandlers(auth.PermissionWriteProducts, r.ProductController.UpdateProduct)...)
+			productRoute.DELETE("/:productID", r.productHandlers(auth.PermissionDeleteProducts, r.ProductController.DeleteProduct)...)
+			productRoute.POST("/batch-get", r.productHandlers(publicRoute, r.ProductController.BatchGetProducts)...)
+			// Batches containing deletes additionally need the delete permission, checked by the controller
+			productRoute.POST("/batch", r.productHandlers(auth.PermissionWriteProducts, r.ProductController.BatchProducts)...)
+
+			if r.StockController != nil {
+				productRoute.POST("/:productID/stock", r.productHandlers(auth.PermissionWriteProducts, r.StockController.AdjustStock)...)
+			}
+			if r.ProductStreamController != nil {
+				productRoute.GET("/stream", r.productHandlers(publicRoute, r.ProductStreamController.StreamProducts)...)
+			}
+		}
+
+		// Without authentication anyone could mint keys, so the resource needs both
+		if r.ApiKeyController != nil && r.Authenticator != nil {
+			apiKeyRoute := baseRoute.Group("/api-keys")
+			apiKeyRoute.Use(r.rateLimit(RouteGroupApiKeys)...)
+			apiKeyRoute.Use(middleware.Require(auth.PermissionManageApiKeys))
+			apiKeyRoute.Use(r.contract...)
+			{
+				apiKeyRoute.POST("", r.ApiKeyController.CreateApiKey)
+				apiKeyRoute.GET("", r.ApiKeyController.GetAllApiKeys)
+				apiKeyRoute.DELETE("/:apiKeyID", r.ApiKeyController.RevokeApiKey)
+			}
 		}
 	}
 
 	return router
 }
+
+// productHandlers checks the permission, then the contract, before ProductMiddlewares
+// run, so rejected requests never reach middlewares with side effects such as
+// idempotency keys.
+func (r *Router) productHandlers(permission auth.Permission, handler ...gin.HandlerFunc) []gin.HandlerFunc {
+	var handlers []gin.HandlerFunc
+	if permission != publicRoute {
+		handlers = append(handlers, middleware.Require(permission))
+	}
+	handlers = append(handlers, r.contract...)
+	handlers = append(handlers, r.ProductMiddlewares...)
+	return append(handlers, handler...)
+}
+
+// cacheable puts the HTTP cache middleware in front of handler when the route has a policy
+func (r *Router) cacheable(route string, handler gin.HandlerFunc) []gin.HandlerFunc {
+	policy, ok := r.CachePolicies[route]
+	if !ok {
+		return []gin.HandlerFunc{handler}
+	}
+	return []gin.HandlerFunc{middleware.HTTPCache(policy), handler}
+}
+
+// isTraced leaves probes and scrapes out of the traces
+func isTraced(req *http.Request) bool {
+	switch req.URL.Path {
+	case "/health", "/ready", "/startup", "/metrics":
+		return false
+	}
+	return true
+}
+
+// rateLimit returns the rate limiting middleware of a route group, if it has limits
+func (r *Router) rateLimit(group string) []gin.HandlerFunc {
+	policy, ok := r.RateLimits[group]
+	if r.RateLimitStore == nil || !ok {
+		return nil
+	}
+	return []gin.HandlerFunc{middleware.RateLimit(r.RateLimitStore, group, policy)}
+}
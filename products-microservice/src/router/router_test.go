@@ -0,0 +1,416 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/auth"
+	"github.com/dieg0code/products-microservice/src/cache"
+	"github.com/dieg0code/products-microservice/src/controllers"
+	"github.com/dieg0code/products-microservice/src/health"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/middleware"
+	"github.com/dieg0code/products-microservice/src/ratelimit"
+	"github.com/dieg0code/products-microservice/src/tenant"
+	"github.com/dieg0code/products-microservice/src/testutils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var routerTestSecret = []byte("test-secret")
+
+func bearer(t *testing.T, role string) string {
+	return tenantBearer(t, role, "")
+}
+
+// tenantBearer signs a token bound to tenantID, or to no tenant when it is empty
+func tenantBearer(t *testing.T, role string, tenantID string) string {
+	claims := jwt.MapClaims{
+		"sub":   "user-1",
+		"roles": []string{role},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+	if tenantID != "" {
+		claims["tenant_id"] = tenantID
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	signed, err := token.SignedString(routerTestSecret)
+	assert.Nil(t, err, "Expected no error signing token")
+	return "Bearer " + signed
+}
+
+func TestRouter(t *testing.T) {
+
+	setupRouter := func(mockService *testutils.MockProductService) *gin.Engine {
+		verifier, err := auth.NewJWTVerifierImpl(auth.JWTConfig{HMACSecret: routerTestSecret})
+		assert.Nil(t, err, "Expected no error creating verifier")
+
+		apiKeyService := new(testutils.MockApiKeyService)
+		apiKeyService.On("AuthenticateApiKey", mock.Anything, "pmk_abc_write").Return(&auth.Principal{Subject: "api-key:abc", Scopes: []auth.Scope{auth.ScopeProductsWrite}}, nil)
+		apiKeyService.On("AuthenticateApiKey", mock.Anything, "pmk_acme_write").Return(&auth.Principal{Subject: "api-key:acme", Scopes: []auth.Scope{auth.ScopeProductsWrite}, TenantID: "acme"}, nil)
+		apiKeyService.On("GetAllApiKeys", mock.Anything).Return([]response.ApiKeyResponse{}, nil)
+
+		r := NewRouter(controllers.NewProductControllerImpl(mockService, validator.New()))
+		r.Authenticator = auth.NewAuthenticator(verifier, apiKeyService)
+		r.ApiKeyController = controllers.NewApiKeyControllerImpl(apiKeyService, validator.New())
+		return r.InitRoutes()
+	}
+
+	perform := func(router *gin.Engine, method string, path string, body string, authorization string) int {
+		req, err := http.NewRequest(method, path, bytes.NewBufferString(body))
+		assert.Nil(t, err, "Expected no error creating request")
+		if strings.HasPrefix(authorization, auth.ApiKeyPrefix+"_") {
+			req.Header.Set(auth.ApiKeyHeader, authorization)
+		} else if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	t.Run("InitRoutes_Reads_Are_Public", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		mockService.On("GetProductById", mock.Anything, uint(1)).Return(&response.ProductResponse{ProductID: 1}, nil)
+
+		code := perform(setupRouter(mockService), http.MethodGet, "/api/v1/products/1", "", "")
+		assert.Equal(t, http.StatusOK, code, "Expected anonymous read to succeed")
+	})
+
+	t.Run("InitRoutes_Writes_Need_Editor", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		mockService.On("UpdateProduct", mock.Anything, uint(1), mock.Anything).Return(&response.ProductResponse{ProductID: 1}, nil)
+		router := setupRouter(mockService)
+		body := `{"name":"Product","category":"Books","price":10,"stock":1}`
+
+		assert.Equal(t, http.StatusUnauthorized, perform(router, http.MethodPut, "/api/v1/products/1", body, ""), "Expected anonymous write to be rejected")
+		assert.Equal(t, http.StatusForbidden, perform(router, http.MethodPut, "/api/v1/products/1", body, bearer(t, "viewer")), "Expected viewer write to be forbidden")
+		assert.Equal(t, http.StatusOK, perform(router, http.MethodPut, "/api/v1/products/1", body, bearer(t, "editor")), "Expected editor write to succeed")
+		assert.Equal(t, http.StatusOK, perform(router, http.MethodPut, "/api/v1/products/1", body, "pmk_abc_write"), "Expected API key with write scope to succeed")
+	})
+
+	t.Run("InitRoutes_Deletes_Need_Admin", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		mockService.On("DeleteProduct", mock.Anything, uint(1)).Return(nil)
+		router := setupRouter(mockService)
+
+		assert.Equal(t, http.StatusForbidden, perform(router, http.MethodDelete, "/api/v1/products/1", "", bearer(t, "editor")), "Expected editor delete to be forbidden")
+		assert.Equal(t, http.StatusForbidden, perform(router, http.MethodDelete, "/api/v1/products/1", "", "pmk_abc_write"), "Expected API key delete to be forbidden")
+		assert.Equal(t, http.StatusOK, perform(router, http.MethodDelete, "/api/v1/products/1", "", bearer(t, "admin")), "Expected admin delete to succeed")
+		mockService.AssertNumberOfCalls(t, "DeleteProduct", 1)
+	})
+
+	t.Run("InitRoutes_Batch_Delete_Needs_Admin", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		mockService.On("ExecuteBatch", mock.Anything, mock.Anything).Return(&response.BatchProductResponse{}, nil)
+		router := setupRouter(mockService)
+		body := `{"operations":[{"op":"delete","product_id":1}]}`
+
+		assert.Equal(t, http.StatusForbidden, perform(router, http.MethodPost, "/api/v1/products/batch", body, bearer(t, "editor")), "Expected editor batch delete to be forbidden")
+		assert.Equal(t, http.StatusOK, perform(router, http.MethodPost, "/api/v1/products/batch", body, bearer(t, "admin")), "Expected admin batch delete to succeed")
+		mockService.AssertNumberOfCalls(t, "ExecuteBatch", 1)
+	})
+	t.Run("InitRoutes_Credentials_Stay_In_Their_Tenant", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		mockService.On("UpdateProduct", mock.MatchedBy(func(ctx context.Context) bool {
+			tenantID, _ := tenant.FromContext(ctx)
+			return tenantID == "acme"
+		}), uint(1), mock.Anything).Return(&response.ProductResponse{ProductID: 1}, nil)
+		router := setupRouter(mockService)
+		body := `{"name":"Product","category":"Books","price":10,"stock":1}`
+
+		update := func(authorization string, tenantID string) int {
+			req, err := http.NewRequest(http.MethodPut, "/api/v1/products/1", bytes.NewBufferString(body))
+			assert.Nil(t, err, "Expected no error creating request")
+			if strings.HasPrefix(authorization, auth.ApiKeyPrefix+"_") {
+				req.Header.Set(auth.ApiKeyHeader, authorization)
+			} else {
+				req.Header.Set("Authorization", authorization)
+			}
+			if tenantID != "" {
+				req.Header.Set(tenant.HeaderName, tenantID)
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			return rec.Code
+		}
+
+		assert.Equal(t, http.StatusOK, update(tenantBearer(t, "editor", "acme"), ""), "Expected the token's tenant to be used")
+		assert.Equal(t, http.StatusForbidden, update(tenantBearer(t, "editor", "acme"), "globex"), "Expected a token of acme not to reach globex")
+		assert.Equal(t, http.StatusForbidden, update(bearer(t, "editor"), "acme"), "Expected a token without tenant to stay in the default tenant")
+		assert.Equal(t, http.StatusOK, update("pmk_acme_write", "acme"), "Expected the key's tenant to be accepted")
+		assert.Equal(t, http.StatusForbidden, update("pmk_acme_write", "globex"), "Expected a key of acme not to reach globex")
+		assert.Equal(t, http.StatusForbidden, update("pmk_abc_write", "acme"), "Expected a key of the default tenant not to reach acme")
+		mockService.AssertNumberOfCalls(t, "UpdateProduct", 2)
+	})
+
+	t.Run("InitRoutes_ApiKeys_Need_Admin", func(t *testing.T) {
+		router := setupRouter(new(testutils.MockProductService))
+
+		assert.Equal(t, http.StatusUnauthorized, perform(router, http.MethodGet, "/api/v1/api-keys", "", ""), "Expected anonymous listing to be rejected")
+		assert.Equal(t, http.StatusForbidden, perform(router, http.MethodGet, "/api/v1/api-keys", "", "pmk_abc_write"), "Expected API keys not to manage API keys")
+		assert.Equal(t, http.StatusForbidden, perform(router, http.MethodGet, "/api/v1/api-keys", "", bearer(t, "editor")), "Expected editor listing to be forbidden")
+		assert.Equal(t, http.StatusOK, perform(router, http.MethodGet, "/api/v1/api-keys", "", bearer(t, "admin")), "Expected admin listing to succeed")
+	})
+
+	t.Run("InitRoutes_Writes_Fail_Closed_Without_Authenticator", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		mockService.On("DeleteProduct", mock.Anything, uint(1)).Return(nil)
+		r := NewRouter(controllers.NewProductControllerImpl(mockService, validator.New()))
+
+		assert.Equal(t, http.StatusUnauthorized, perform(r.InitRoutes(), http.MethodDelete, "/api/v1/products/1", "", ""), "Expected writes to be rejected without an authenticator")
+		mockService.AssertNotCalled(t, "DeleteProduct", mock.Anything, uint(1))
+
+		r.AuthDisabled = true
+		assert.Equal(t, http.StatusOK, perform(r.InitRoutes(), http.MethodDelete, "/api/v1/products/1", "", ""), "Expected writes to be allowed when auth is disabled")
+	})
+
+	t.Run("InitRoutes_Cache_Stats_Need_Admin", func(t *testing.T) {
+		verifier, err := auth.NewJWTVerifierImpl(auth.JWTConfig{HMACSecret: routerTestSecret})
+		assert.Nil(t, err, "Expected no error creating verifier")
+
+		r := NewRouter(controllers.NewProductControllerImpl(new(testutils.MockProductService), validator.New()))
+		r.Authenticator = auth.NewAuthenticator(verifier, new(testutils.MockApiKeyService))
+		r.ProductCacheStats = func() cache.Stats { return cache.Stats{Hits: 1} }
+		router := r.InitRoutes()
+
+		assert.Equal(t, http.StatusUnauthorized, perform(router, http.MethodGet, "/debug/cache/products", "", ""), "Expected anonymous requests to be rejected")
+		assert.Equal(t, http.StatusForbidden, perform(router, http.MethodGet, "/debug/cache/products", "", bearer(t, "editor")), "Expected editors to be forbidden")
+		assert.Equal(t, http.StatusOK, perform(router, http.MethodGet, "/debug/cache/products", "", bearer(t, "admin")), "Expected admins to read the stats")
+	})
+
+	t.Run("InitRoutes_ApiKeys_Need_Authenticator", func(t *testing.T) {
+		r := NewRouter(controllers.NewProductControllerImpl(new(testutils.MockProductService), validator.New()))
+		r.ApiKeyController = controllers.NewApiKeyControllerImpl(new(testutils.MockApiKeyService), validator.New())
+		router := r.InitRoutes()
+
+		assert.Equal(t, http.StatusNotFound, perform(router, http.MethodGet, "/api/v1/api-keys", "", ""), "Expected api keys not to be mounted")
+	})
+
+	t.Run("InitRoutes_Rate_Limits_Route_Groups", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		mockService.On("GetProductById", mock.Anything, uint(1)).Return(&response.ProductResponse{ProductID: 1}, nil)
+
+		r := NewRouter(controllers.NewProductControllerImpl(mockService, validator.New()))
+		r.RateLimitStore = ratelimit.NewMemoryStoreImpl(time.Minute)
+		r.RateLimits = map[string]ratelimit.Policy{
+			RouteGroupProducts: {Read: ratelimit.Limit{Requests: 1, Period: time.Minute}},
+		}
+		router := r.InitRoutes()
+
+		assert.Equal(t, http.StatusOK, perform(router, http.MethodGet, "/api/v1/products/1", "", ""), "Expected first read to succeed")
+		assert.Equal(t, http.StatusTooManyRequests, perform(router, http.MethodGet, "/api/v1/products/1", "", ""), "Expected second read to be limited")
+		assert.Equal(t, http.StatusOK, perform(router, http.MethodGet, "/health", "", ""), "Expected routes outside the group not to be limited")
+	})
+
+	t.Run("InitRoutes_Rate_Limits_Ignore_Untrusted_Forwarded_For", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		mockService.On("GetProductById", mock.Anything, uint(1)).Return(&response.ProductResponse{ProductID: 1}, nil)
+
+		read := func(router *gin.Engine, forwardedFor string) int {
+			req, err := http.NewRequest(http.MethodGet, "/api/v1/products/1", nil)
+			assert.Nil(t, err, "Expected no error creating request")
+			req.RemoteAddr = "10.0.0.1:1234"
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			return rec.Code
+		}
+
+		newRouter := func(trustedProxies []string) *gin.Engine {
+			r := NewRouter(controllers.NewProductControllerImpl(mockService, validator.New()))
+			r.RateLimitStore = ratelimit.NewMemoryStoreImpl(time.Minute)
+			r.RateLimits = map[string]ratelimit.Policy{
+				RouteGroupProducts: {Read: ratelimit.Limit{Requests: 1, Period: time.Minute}},
+			}
+			r.TrustedProxies = trustedProxies
+			return r.InitRoutes()
+		}
+
+		router := newRouter(nil)
+		assert.Equal(t, http.StatusOK, read(router, "203.0.113.1"), "Expected first read to succeed")
+		assert.Equal(t, http.StatusTooManyRequests, read(router, "203.0.113.2"), "Expected a spoofed X-Forwarded-For not to reset the limit")
+
+		router = newRouter([]string{"10.0.0.0/8"})
+		assert.Equal(t, http.StatusOK, read(router, "203.0.113.1"), "Expected first read to succeed")
+		assert.Equal(t, http.StatusOK, read(router, "203.0.113.2"), "Expected clients behind a trusted proxy to be told apart")
+		assert.Equal(t, http.StatusTooManyRequests, read(router, "203.0.113.1"), "Expected the forwarded client to be limited")
+	})
+
+	t.Run("InitRoutes_Cache_Policies", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		mockService.On("GetAllProducts", mock.Anything, 1, 10).Return([]response.ProductResponse{}, nil)
+		mockService.On("GetProductById", mock.Anything, uint(1)).Return(&response.ProductResponse{ProductID: 1}, nil)
+
+		router := NewRouter(controllers.NewProductControllerImpl(mockService, validator.New())).InitRoutes()
+
+		req, err := http.NewRequest(http.MethodGet, "/api/v1/products?page=1&pageSize=10", nil)
+		assert.Nil(t, err, "Expected no error creating request")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code, "Expected status code 200")
+		assert.NotEmpty(t, rec.Header().Get("Cache-Control"), "Expected the list to be cacheable")
+		assert.NotEmpty(t, rec.Header().Get("ETag"), "Expected an ETag on the list")
+		assert.Equal(t, []string{tenant.HeaderName, "Authorization", auth.ApiKeyHeader}, rec.Header().Values("Vary"), "Expected the list to vary with the tenant and the credentials")
+
+		req, err = http.NewRequest(http.MethodGet, "/api/v1/products/1", nil)
+		assert.Nil(t, err, "Expected no error creating request")
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code, "Expected status code 200")
+		assert.Empty(t, rec.Header().Get("ETag"), "Expected routes without a policy not to be cached")
+	})
+
+	t.Run("InitRoutes_Metrics", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		mockService.On("GetProductById", mock.Anything, uint(1)).Return(&response.ProductResponse{ProductID: 1}, nil)
+
+		r := NewRouter(controllers.NewProductControllerImpl(mockService, validator.New()))
+		r.MetricsRegistry = prometheus.NewRegistry()
+		router := r.InitRoutes()
+
+		assert.Equal(t, http.StatusOK, perform(router, http.MethodGet, "/api/v1/products/1", "", ""), "Expected status code 200")
+
+		req, err := http.NewRequest(http.MethodGet, "/metrics", nil)
+		assert.Nil(t, err, "Expected no error creating request")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code, "Expected status code 200")
+		assert.Contains(t, rec.Body.String(), `products_http_requests_total{method="GET",route="/api/v1/products/:productID",status="200"} 1`, "Expected the request to be counted")
+	})
+
+	t.Run("InitRoutes_Continues_Traces", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		mockService.On("GetProductById", mock.Anything, uint(1)).Return(&response.ProductResponse{ProductID: 1}, nil)
+
+		recorder := tracetest.NewSpanRecorder()
+		r := NewRouter(controllers.NewProductControllerImpl(mockService, validator.New()))
+		r.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		router := r.InitRoutes()
+
+		req, err := http.NewRequest(http.MethodGet, "/api/v1/products/1", nil)
+		assert.Nil(t, err, "Expected no error creating request")
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Equal(t, http.StatusOK, perform(router, http.MethodGet, "/health", "", ""), "Expected status code 200")
+
+		spans := recorder.Ended()
+		assert.Equal(t, 1, len(spans), "Expected one span, probes are not traced")
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String(), "Expected the caller's trace")
+		assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent().SpanID().String(), "Expected the caller's span as parent")
+	})
+
+	t.Run("InitRoutes_Request_Timeout", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		var serviceErr error
+		mockService.On("GetProductById", mock.Anything, uint(1)).Run(func(args mock.Arguments) {
+			ctx := args.Get(0).(context.Context)
+			<-ctx.Done()
+			serviceErr = ctx.Err()
+		}).Return((*response.ProductResponse)(nil), context.DeadlineExceeded)
+
+		r := NewRouter(controllers.NewProductControllerImpl(mockService, validator.New()))
+		r.Timeouts = middleware.TimeoutConfig{Default: 20 * time.Millisecond}
+		router := r.InitRoutes()
+
+		assert.Equal(t, http.StatusGatewayTimeout, perform(router, http.MethodGet, "/api/v1/products/1", "", ""), "Expected status code 504")
+		assert.ErrorIs(t, serviceErr, context.DeadlineExceeded, "Expected the service call to be cancelled")
+	})
+
+	t.Run("InitRoutes_Client_Disconnect", func(t *testing.T) {
+		mockService := new(testutils.MockProductService)
+		serviceErr := make(chan error, 1)
+		mockService.On("GetProductById", mock.Anything, uint(1)).Run(func(args mock.Arguments) {
+			ctx := args.Get(0).(context.Context)
+			<-ctx.Done()
+			serviceErr <- ctx.Err()
+		}).Return((*response.ProductResponse)(nil), context.Canceled)
+
+		router := NewRouter(controllers.NewProductControllerImpl(mockService, validator.New())).InitRoutes()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/api/v1/products/1", nil)
+		assert.Nil(t, err, "Expected no error creating request")
+
+		done := make(chan struct{})
+		go func() {
+			router.ServeHTTP(httptest.NewRecorder(), req)
+			close(done)
+		}()
+		cancel()
+
+		select {
+		case err := <-serviceErr:
+			assert.ErrorIs(t, err, context.Canceled, "Expected the service call to see the disconnect")
+		case <-time.After(time.Second):
+			t.Fatal("Expected the disconnect to cancel the service call")
+		}
+		<-done
+	})
+
+	t.Run("InitRoutes_Not_Ready_While_Draining", func(t *testing.T) {
+		r := NewRouter(controllers.NewProductControllerImpl(new(testutils.MockProductService), validator.New()))
+		r.Readiness = func() bool { return false }
+		router := r.InitRoutes()
+
+		assert.Equal(t, http.StatusServiceUnavailable, perform(router, http.MethodGet, "/ready", "", ""), "Expected status code 503")
+		assert.Equal(t, http.StatusOK, perform(router, http.MethodGet, "/health", "", ""), "Expected the process to stay healthy")
+	})
+
+	t.Run("InitRoutes_Ready_Reports_Components", func(t *testing.T) {
+		checks := health.NewHealth(health.Config{})
+		checks.Register("database", health.CheckerFunc(func(ctx context.Context) error { return errors.New("connection refused") }))
+		checks.RegisterOptional("redis", health.CheckerFunc(func(ctx context.Context) error { return nil }))
+
+		checks.MarkStarted()
+
+		r := NewRouter(controllers.NewProductControllerImpl(new(testutils.MockProductService), validator.New()))
+		r.Health = checks
+		router := r.InitRoutes()
+
+		req, err := http.NewRequest(http.MethodGet, "/ready", nil)
+		assert.Nil(t, err, "Expected no error creating request")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		var report health.Report
+		assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &report), "Expected a JSON report")
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "Expected status code 503")
+		assert.Equal(t, health.StatusDown, report.Status, "Expected status down")
+		assert.Equal(t, health.StatusDown, report.Components["database"].Status, "Expected the database to be down")
+		assert.Equal(t, health.StatusUp, report.Components["redis"].Status, "Expected redis to be up")
+	})
+
+	t.Run("InitRoutes_Startup", func(t *testing.T) {
+		checks := health.NewHealth(health.Config{})
+
+		r := NewRouter(controllers.NewProductControllerImpl(new(testutils.MockProductService), validator.New()))
+		r.Health = checks
+		router := r.InitRoutes()
+
+		assert.Equal(t, http.StatusServiceUnavailable, perform(router, http.MethodGet, "/startup", "", ""), "Expected status code 503 before startup")
+		assert.Equal(t, http.StatusServiceUnavailable, perform(router, http.MethodGet, "/ready", "", ""), "Expected not to be ready before startup")
+		checks.MarkStarted()
+		assert.Equal(t, http.StatusOK, perform(router, http.MethodGet, "/startup", "", ""), "Expected status code 200")
+		assert.Equal(t, http.StatusOK, perform(router, http.MethodGet, "/ready", "", ""), "Expected to be ready after startup")
+	})
+}
@@ -0,0 +1,20 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dieg0code/products-microservice/src/auth"
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/json/response"
+)
+
+var ErrApiKeyExpiryInPast = errors.New("api key expiry must be in the future")
+
+type ApiKeyService interface {
+	CreateApiKey(ctx context.Context, apiKey *request.CreateApiKeyRequest) (*response.CreatedApiKeyResponse, error)
+	GetAllApiKeys(ctx context.Context) ([]response.ApiKeyResponse, error)
+	RevokeApiKey(ctx context.Context, apiKeyID uint) error
+	// AuthenticateApiKey returns auth.ErrInvalidApiKey for unknown, expired and revoked keys alike
+	AuthenticateApiKey(ctx context.Context, key string) (*auth.Principal, error)
+}
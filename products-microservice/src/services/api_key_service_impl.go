@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/auth"
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/models"
+	"github.com/dieg0code/products-microservice/src/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// ApiKeyLastUsedResolution avoids a write on every authenticated request
+const ApiKeyLastUsedResolution = time.Minute
+
+type ApiKeyServiceImpl struct {
+	apiKeyRepo repository.ApiKeyRepository
+}
+
+// CreateApiKey implements ApiKeyService.
+func (a *ApiKeyServiceImpl) CreateApiKey(ctx context.Context, apiKey *request.CreateApiKeyRequest) (*response.CreatedApiKeyResponse, error) {
+	if apiKey.ExpiresAt != nil && !apiKey.ExpiresAt.After(time.Now()) {
+		return nil, ErrApiKeyExpiryInPast
+	}
+
+	var scopes []auth.Scope
+	for _, value := range apiKey.Scopes {
+		if scope, ok := auth.ParseScope(value); ok {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	key, prefix, hash, err := auth.GenerateApiKey()
+	if err != nil {
+		return nil, err
+	}
+
+	apiKeyModel := &models.ApiKey{
+		Name:      apiKey.Name,
+		Prefix:    prefix,
+		KeyHash:   hash,
+		Scopes:    auth.FormatScopes(scopes),
+		ExpiresAt: apiKey.ExpiresAt,
+	}
+
+	created, err := a.apiKeyRepo.CreateApiKey(ctx, apiKeyModel)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.CreatedApiKeyResponse{
+		ApiKeyResponse: toApiKeyResponse(created),
+		Key:            key,
+	}, nil
+}
+
+// GetAllApiKeys implements ApiKeyService.
+func (a *ApiKeyServiceImpl) GetAllApiKeys(ctx context.Context) ([]response.ApiKeyResponse, error) {
+	apiKeys, err := a.apiKeyRepo.GetAllApiKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKeyResponses := make([]response.ApiKeyResponse, len(apiKeys))
+	for i := range apiKeys {
+		apiKeyResponses[i] = toApiKeyResponse(&apiKeys[i])
+	}
+
+	return apiKeyResponses, nil
+}
+
+// RevokeApiKey implements ApiKeyService.
+func (a *ApiKeyServiceImpl) RevokeApiKey(ctx context.Context, apiKeyID uint) error {
+	return a.apiKeyRepo.RevokeApiKey(ctx, apiKeyID, time.Now())
+}
+
+// AuthenticateApiKey implements ApiKeyService.
+func (a *ApiKeyServiceImpl) AuthenticateApiKey(ctx context.Context, key string) (*auth.Principal, error) {
+	prefix, ok := auth.ApiKeyPrefixOf(key)
+	if !ok {
+		return nil, auth.ErrInvalidApiKey
+	}
+
+	apiKey, err := a.apiKeyRepo.GetApiKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, repository.ErrApiKeyNotFound) {
+			return nil, auth.ErrInvalidApiKey
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if !auth.ApiKeyMatches(key, apiKey.KeyHash) || apiKey.RevokedAt != nil || (apiKey.ExpiresAt != nil && !apiKey.ExpiresAt.After(now)) {
+		return nil, auth.ErrInvalidApiKey
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= ApiKeyLastUsedResolution {
+		err = a.apiKeyRepo.TouchApiKey(ctx, apiKey.ID, now)
+		if err != nil {
+			logrus.WithError(err).WithField("api_key_prefix", prefix).Warn("Error tracking api key use")
+		}
+	}
+
+	return &auth.Principal{
+		Subject:  "api-key:" + apiKey.Prefix,
+		Scopes:   auth.ParseScopes(apiKey.Scopes),
+		ApiKeyID: apiKey.ID,
+		TenantID: apiKey.TenantID,
+	}, nil
+}
+
+func toApiKeyResponse(apiKey *models.ApiKey) response.ApiKeyResponse {
+	scopes := make([]string, 0)
+	for _, scope := range auth.ParseScopes(apiKey.Scopes) {
+		scopes = append(scopes, string(scope))
+	}
+
+	return response.ApiKeyResponse{
+		ApiKeyID:   apiKey.ID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     scopes,
+		CreatedAt:  apiKey.CreatedAt,
+		ExpiresAt:  apiKey.ExpiresAt,
+		LastUsedAt: apiKey.LastUsedAt,
+		RevokedAt:  apiKey.RevokedAt,
+	}
+}
+
+func NewApiKeyServiceImpl(apiKeyRepo repository.ApiKeyRepository) ApiKeyService {
+	return &ApiKeyServiceImpl{apiKeyRepo: apiKeyRepo}
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/auth"
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/models"
+	"github.com/dieg0code/products-microservice/src/repository"
+	"github.com/dieg0code/products-microservice/src/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestApiKeyServiceImpl(t *testing.T) {
+
+	t.Run("CreateApiKey_Stores_Hash_Only", func(t *testing.T) {
+		mockRepo := new(testutils.MockApiKeyRepository)
+		apiKeyService := NewApiKeyServiceImpl(mockRepo)
+
+		created := &models.ApiKey{}
+		mockRepo.On("CreateApiKey", mock.Anything, mock.AnythingOfType("*models.ApiKey")).Run(func(args mock.Arguments) {
+			*created = *args.Get(1).(*models.ApiKey)
+			created.ID = 1
+		}).Return(created, nil)
+
+		res, err := apiKeyService.CreateApiKey(context.Background(), &request.CreateApiKeyRequest{
+			Name:   "sales",
+			Scopes: []string{"stock:reserve", "products:read"},
+		})
+
+		assert.Nil(t, err, "Expected no error creating api key")
+		assert.True(t, strings.HasPrefix(res.Key, "pmk_"+created.Prefix+"_"), "Expected key to carry the stored prefix")
+		assert.NotContains(t, created.KeyHash, res.Key, "Expected the key not to be stored")
+		assert.True(t, auth.ApiKeyMatches(res.Key, created.KeyHash), "Expected the stored hash to match the key")
+		assert.Equal(t, "stock:reserve products:read", created.Scopes, "Expected scopes to be stored")
+		assert.Equal(t, []string{"stock:reserve", "products:read"}, res.Scopes, "Expected scopes in the response")
+		assert.Equal(t, uint(1), res.ApiKeyID, "Expected api key ID to be the same")
+	})
+
+	t.Run("CreateApiKey_Expiry_In_Past", func(t *testing.T) {
+		mockRepo := new(testutils.MockApiKeyRepository)
+		apiKeyService := NewApiKeyServiceImpl(mockRepo)
+
+		expiresAt := time.Now().Add(-time.Minute)
+		_, err := apiKeyService.CreateApiKey(context.Background(), &request.CreateApiKeyRequest{Name: "sales", Scopes: []string{"stock:reserve"}, ExpiresAt: &expiresAt})
+
+		assert.ErrorIs(t, err, ErrApiKeyExpiryInPast, "Expected expiry error")
+		mockRepo.AssertNotCalled(t, "CreateApiKey", mock.Anything, mock.Anything)
+	})
+
+	t.Run("AuthenticateApiKey_Success_Tracks_Use", func(t *testing.T) {
+		mockRepo := new(testutils.MockApiKeyRepository)
+		apiKeyService := NewApiKeyServiceImpl(mockRepo)
+
+		key, prefix, hash, _ := auth.GenerateApiKey()
+		mockRepo.On("GetApiKeyByPrefix", mock.Anything, prefix).Return(&models.ApiKey{TenantID: "acme", Prefix: prefix, KeyHash: hash, Scopes: "products:write"}, nil)
+		mockRepo.On("TouchApiKey", mock.Anything, mock.Anything, mock.AnythingOfType("time.Time")).Return(nil)
+
+		principal, err := apiKeyService.AuthenticateApiKey(context.Background(), key)
+
+		assert.Nil(t, err, "Expected no error authenticating api key")
+		assert.Equal(t, "api-key:"+prefix, principal.Subject, "Expected subject from the prefix")
+		assert.Equal(t, []auth.Scope{auth.ScopeProductsWrite}, principal.Scopes, "Expected stored scopes")
+		assert.Equal(t, "acme", principal.TenantID, "Expected the key to be bound to its tenant")
+		mockRepo.AssertNumberOfCalls(t, "TouchApiKey", 1)
+	})
+
+	t.Run("AuthenticateApiKey_Skips_Recent_Use", func(t *testing.T) {
+		mockRepo := new(testutils.MockApiKeyRepository)
+		apiKeyService := NewApiKeyServiceImpl(mockRepo)
+
+		key, prefix, hash, _ := auth.GenerateApiKey()
+		lastUsedAt := time.Now().Add(-time.Second)
+		mockRepo.On("GetApiKeyByPrefix", mock.Anything, prefix).Return(&models.ApiKey{Prefix: prefix, KeyHash: hash, LastUsedAt: &lastUsedAt}, nil)
+
+		_, err := apiKeyService.AuthenticateApiKey(context.Background(), key)
+
+		assert.Nil(t, err, "Expected no error authenticating api key")
+		mockRepo.AssertNotCalled(t, "TouchApiKey", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("AuthenticateApiKey_Rejected", func(t *testing.T) {
+		mockRepo := new(testutils.MockApiKeyRepository)
+		apiKeyService := NewApiKeyServiceImpl(mockRepo)
+
+		key, prefix, hash, _ := auth.GenerateApiKey()
+		past := time.Now().Add(-time.Minute)
+
+		cases := map[string]*models.ApiKey{
+			"revoked": {Prefix: prefix, KeyHash: hash, RevokedAt: &past},
+			"expired": {Prefix: prefix, KeyHash: hash, ExpiresAt: &past},
+			"hash":    {Prefix: prefix, KeyHash: auth.HashApiKey("other")},
+		}
+		for name, apiKey := range cases {
+			mockRepo.On("GetApiKeyByPrefix", mock.Anything, prefix).Return(apiKey, nil).Once()
+
+			_, err := apiKeyService.AuthenticateApiKey(context.Background(), key)
+			assert.ErrorIs(t, err, auth.ErrInvalidApiKey, "Expected %s key to be rejected", name)
+		}
+
+		mockRepo.On("GetApiKeyByPrefix", mock.Anything, "missing").Return((*models.ApiKey)(nil), repository.ErrApiKeyNotFound)
+		_, err := apiKeyService.AuthenticateApiKey(context.Background(), "pmk_missing_secret")
+		assert.ErrorIs(t, err, auth.ErrInvalidApiKey, "Expected unknown key to be rejected")
+
+		_, err = apiKeyService.AuthenticateApiKey(context.Background(), "not-a-key")
+		assert.ErrorIs(t, err, auth.ErrInvalidApiKey, "Expected malformed key to be rejected")
+		mockRepo.AssertNotCalled(t, "TouchApiKey", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("RevokeApiKey_NotFound", func(t *testing.T) {
+		mockRepo := new(testutils.MockApiKeyRepository)
+		apiKeyService := NewApiKeyServiceImpl(mockRepo)
+
+		mockRepo.On("RevokeApiKey", mock.Anything, uint(1), mock.AnythingOfType("time.Time")).Return(repository.ErrApiKeyNotFound)
+
+		err := apiKeyService.RevokeApiKey(context.Background(), 1)
+		assert.ErrorIs(t, err, repository.ErrApiKeyNotFound, "Expected api key not found error")
+	})
+}
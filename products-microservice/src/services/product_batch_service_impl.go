@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/logging"
+	"github.com/dieg0code/products-microservice/src/models"
+	"github.com/dieg0code/products-microservice/src/repository"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// GetProductsByIds implements ProductService.
+func (p *ProductServiceImpl) GetProductsByIds(ctx context.Context, productIDs []uint) (*response.BatchGetProductsResponse, error) {
+
+	uniqueIDs := make([]uint, 0, len(productIDs))
+	seen := make(map[uint]bool, len(productIDs))
+	for _, productID := range productIDs {
+		if !seen[productID] {
+			seen[productID] = true
+			uniqueIDs = append(uniqueIDs, productID)
+		}
+	}
+
+	products, err := p.productRepo.GetProductsByIds(ctx, uniqueIDs)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Error getting products by ids")
+		return nil, err
+	}
+
+	byID := make(map[uint]models.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+
+	// Keep the order the caller asked for
+	batchResponse := &response.BatchGetProductsResponse{
+		Products:   []response.ProductResponse{},
+		MissingIDs: []uint{},
+	}
+	for _, productID := range uniqueIDs {
+		product, ok := byID[productID]
+		if !ok {
+			batchResponse.MissingIDs = append(batchResponse.MissingIDs, productID)
+			continue
+		}
+		batchResponse.Products = append(batchResponse.Products, toProductResponse(&product))
+	}
+
+	logging.FromContext(ctx).WithField("total_products", len(batchResponse.Products)).Info("Products retrieved successfully")
+
+	return batchResponse, nil
+}
+
+// ExecuteBatch implements ProductService.
+func (p *ProductServiceImpl) ExecuteBatch(ctx context.Context, batch *request.BatchProductRequest) (*response.BatchProductResponse, error) {
+
+	mode := batch.Mode
+	if mode == "" {
+		mode = request.BatchModeAllOrNothing
+	}
+
+	operations := make([]models.ProductOperation, len(batch.Operations))
+	for i, operation := range batch.Operations {
+		if operation.Op != request.BatchOpDelete && operation.Product == nil {
+			return nil, errors.New("product is required for create and update operations")
+		}
+
+		operations[i] = models.ProductOperation{
+			Op:        operation.Op,
+			ProductID: operation.ProductID,
+		}
+		if operation.Product != nil {
+			operations[i].Product = &models.Product{
+				Name:     operation.Product.Name,
+				Category: operation.Product.Category,
+				Price:    operation.Product.Price,
+				Stock:    operation.Product.Stock,
+			}
+		}
+	}
+
+	results, err := p.productRepo.ApplyOperations(ctx, operations, mode == request.BatchModeAllOrNothing)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Error executing product batch")
+		return nil, err
+	}
+
+	// In all-or-nothing mode a failure undoes the earlier operations and skips the rest
+	rolledBack := mode == request.BatchModeAllOrNothing && len(results) > 0 && results[len(results)-1].Err != nil
+
+	batchResponse := &response.BatchProductResponse{
+		Mode:    mode,
+		Results: make([]response.BatchOperationResult, len(batch.Operations)),
+	}
+	for i, operation := range batch.Operations {
+		result := response.BatchOperationResult{
+			Index:     i,
+			Op:        operation.Op,
+			ProductID: operation.ProductID,
+			Status:    response.BatchStatusSkipped,
+		}
+
+		if i < len(results) {
+			switch {
+			case results[i].Err != nil:
+				result.Status = response.BatchStatusFailed
+				result.Error = batchErrorMessage(operation.Op, results[i].Err)
+				logging.FromContext(ctx).WithError(results[i].Err).WithField("index", i).Warn("Product batch operation failed")
+			case rolledBack:
+				result.Status = response.BatchStatusRolledBack
+			default:
+				result.Status = response.BatchStatusSucceeded
+				if results[i].Product != nil {
+					productResponse := toProductResponse(results[i].Product)
+					result.ProductID = results[i].Product.ID
+					result.Product = &productResponse
+				}
+			}
+		}
+
+		if result.Status == response.BatchStatusSucceeded {
+			batchResponse.Succeeded++
+		} else {
+			batchResponse.Failed++
+		}
+
+		batchResponse.Results[i] = result
+	}
+
+	logging.FromContext(ctx).WithFields(logrus.Fields{
+		"mode":      mode,
+		"succeeded": batchResponse.Succeeded,
+		"failed":    batchResponse.Failed,
+	}).Info("Product batch executed")
+
+	return batchResponse, nil
+}
+
+// batchErrorMessage tells clients why an operation failed without exposing
+// database errors, the way the single product endpoints answer
+func batchErrorMessage(op string, err error) string {
+	switch {
+	case errors.Is(err, repository.ErrProductNotFound):
+		return "Product not found"
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return "A product with this name already exists"
+	}
+
+	switch op {
+	case request.BatchOpCreate:
+		return "Error creating product"
+	case request.BatchOpUpdate:
+		return "Error updating product"
+	}
+	return "Error deleting product"
+}
+
+func toProductResponse(product *models.Product) response.ProductResponse {
+	return response.ProductResponse{
+		ProductID:  product.ID,
+		Name:       product.Name,
+		Category:   product.Category,
+		Price:      product.Price,
+		Stock:      product.Stock,
+		LastUpdate: product.UpdatedAt.Format("02-01-2006"),
+		UpdatedAt:  product.UpdatedAt,
+	}
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/models"
+	"github.com/dieg0code/products-microservice/src/repository"
+	"github.com/dieg0code/products-microservice/src/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestProductBatchServiceImpl(t *testing.T) {
+
+	t.Run("GetProductsByIds_Success", func(t *testing.T) {
+		mockRepo := new(testutils.MockProductRepository)
+
+		productService := NewProductServiceImpl(mockRepo)
+
+		mockRepo.On("GetProductsByIds", mock.Anything, []uint{2, 1, 3}).Return([]models.Product{
+			{Model: gorm.Model{ID: 1}, Name: "Product 1"},
+			{Model: gorm.Model{ID: 2}, Name: "Product 2"},
+		}, nil)
+
+		products, err := productService.GetProductsByIds(context.Background(), []uint{2, 1, 2, 3})
+
+		assert.Nil(t, err, "Expected error to be nil")
+		assert.Equal(t, 2, len(products.Products), "Expected 2 products")
+		assert.Equal(t, uint(2), products.Products[0].ProductID, "Expected requested order to be kept")
+		assert.Equal(t, []uint{3}, products.MissingIDs, "Expected missing product to be reported")
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("GetProductsByIds_Error", func(t *testing.T) {
+		mockRepo := new(testutils.MockProductRepository)
+
+		productService := NewProductServiceImpl(mockRepo)
+
+		mockRepo.On("GetProductsByIds", mock.Anything, []uint{1}).Return([]models.Product{}, assert.AnError)
+
+		products, err := productService.GetProductsByIds(context.Background(), []uint{1})
+
+		assert.NotNil(t, err, "Expected error to be not nil")
+		assert.Nil(t, products, "Expected products to be nil")
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("ExecuteBatch_All_Or_Nothing_Failure", func(t *testing.T) {
+		mockRepo := new(testutils.MockProductRepository)
+
+		productService := NewProductServiceImpl(mockRepo)
+
+		batch := &request.BatchProductRequest{
+			Operations: []request.BatchProductOperation{
+				{Op: request.BatchOpCreate, Product: &request.CreateProductRequest{Name: "Product 1", Category: "Category 1", Price: 1000, Stock: 10}},
+				{Op: request.BatchOpDelete, ProductID: 99},
+				{Op: request.BatchOpDelete, ProductID: 1},
+			},
+		}
+
+		mockRepo.On("ApplyOperations", mock.Anything, mock.Anything, true).Return([]models.ProductOperationResult{
+			{Product: &models.Product{Model: gorm.Model{ID: 1}, Name: "Product 1"}},
+			{Err: assert.AnError},
+		}, nil)
+
+		result, err := productService.ExecuteBatch(context.Background(), batch)
+
+		assert.Nil(t, err, "Expected error to be nil")
+		assert.Equal(t, request.BatchModeAllOrNothing, result.Mode, "Expected all or nothing to be the default mode")
+		assert.Equal(t, 0, result.Succeeded, "Expected no operation to succeed")
+		assert.Equal(t, response.BatchStatusRolledBack, result.Results[0].Status, "Expected first operation to be rolled back")
+		assert.Equal(t, response.BatchStatusFailed, result.Results[1].Status, "Expected second operation to fail")
+		assert.Equal(t, "Error deleting product", result.Results[1].Error, "Expected a generic message instead of the cause")
+		assert.Equal(t, response.BatchStatusSkipped, result.Results[2].Status, "Expected third operation to be skipped")
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("ExecuteBatch_Best_Effort", func(t *testing.T) {
+		mockRepo := new(testutils.MockProductRepository)
+
+		productService := NewProductServiceImpl(mockRepo)
+
+		batch := &request.BatchProductRequest{
+			Mode: request.BatchModeBestEffort,
+			Operations: []request.BatchProductOperation{
+				{Op: request.BatchOpUpdate, ProductID: 1, Product: &request.CreateProductRequest{Name: "Product 1", Category: "Category 1", Price: 1000, Stock: 10}},
+				{Op: request.BatchOpDelete, ProductID: 99},
+			},
+		}
+
+		mockRepo.On("ApplyOperations", mock.Anything, []models.ProductOperation{
+			{Op: models.ProductOperationUpdate, ProductID: 1, Product: &models.Product{Name: "Product 1", Category: "Category 1", Price: 1000, Stock: 10}},
+			{Op: models.ProductOperationDelete, ProductID: 99},
+		}, false).Return([]models.ProductOperationResult{
+			{Product: &models.Product{Model: gorm.Model{ID: 1}, Name: "Product 1"}},
+			{Err: repository.ErrProductNotFound},
+		}, nil)
+
+		result, err := productService.ExecuteBatch(context.Background(), batch)
+
+		assert.Nil(t, err, "Expected error to be nil")
+		assert.Equal(t, 1, result.Succeeded, "Expected one operation to succeed")
+		assert.Equal(t, 1, result.Failed, "Expected one operation to fail")
+		assert.Equal(t, "Product not found", result.Results[1].Error, "Expected a stable message for a missing product")
+		assert.Equal(t, "Product 1", result.Results[0].Product.Name, "Expected updated product to be returned")
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("ExecuteBatch_Duplicate_Name", func(t *testing.T) {
+		mockRepo := new(testutils.MockProductRepository)
+
+		productService := NewProductServiceImpl(mockRepo)
+
+		batch := &request.BatchProductRequest{
+			Mode: request.BatchModeBestEffort,
+			Operations: []request.BatchProductOperation{
+				{Op: request.BatchOpCreate, Product: &request.CreateProductRequest{Name: "Product 1", Category: "Category 1", Price: 1000, Stock: 10}},
+			},
+		}
+
+		mockRepo.On("ApplyOperations", mock.Anything, mock.Anything, false).Return([]models.ProductOperationResult{
+			{Err: fmt.Errorf("ERROR: duplicate key value violates unique constraint \"idx_products_tenant_name\": %w", gorm.ErrDuplicatedKey)},
+		}, nil)
+
+		result, err := productService.ExecuteBatch(context.Background(), batch)
+
+		assert.Nil(t, err, "Expected error to be nil")
+		assert.Equal(t, "A product with this name already exists", result.Results[0].Error, "Expected the database error to stay hidden")
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("ExecuteBatch_Missing_Product", func(t *testing.T) {
+		mockRepo := new(testutils.MockProductRepository)
+
+		productService := NewProductServiceImpl(mockRepo)
+
+		batch := &request.BatchProductRequest{
+			Operations: []request.BatchProductOperation{{Op: request.BatchOpCreate}},
+		}
+
+		result, err := productService.ExecuteBatch(context.Background(), batch)
+
+		assert.NotNil(t, err, "Expected error to be not nil")
+		assert.Nil(t, result, "Expected result to be nil")
+
+		mockRepo.AssertNotCalled(t, "ApplyOperations")
+	})
+}
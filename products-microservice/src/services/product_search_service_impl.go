@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/logging"
+	"github.com/dieg0code/products-microservice/src/models"
+)
+
+// GetByCategories implements ProductService.
+func (p *ProductServiceImpl) GetByCategories(ctx context.Context, categories []string, offset int, pageSize int) (map[string][]response.ProductResponse, error) {
+
+	products, err := p.productRepo.GetByCategories(ctx, categories, offset, pageSize)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Error getting products by categories")
+		return nil, err
+	}
+
+	byCategory := make(map[string][]response.ProductResponse, len(categories))
+	for _, category := range categories {
+		byCategory[category] = []response.ProductResponse{}
+	}
+	for i := range products {
+		byCategory[products[i].Category] = append(byCategory[products[i].Category], toProductResponse(&products[i]))
+	}
+
+	logging.FromContext(ctx).WithField("total_products", len(products)).Info("Products retrieved successfully")
+
+	return byCategory, nil
+}
+
+// CountByCategories implements ProductService.
+func (p *ProductServiceImpl) CountByCategories(ctx context.Context, categories []string) (map[string]int64, error) {
+
+	totals, err := p.productRepo.CountByCategories(ctx, categories)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Error counting products by categories")
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+// SearchProducts implements ProductService.
+func (p *ProductServiceImpl) SearchProducts(ctx context.Context, filter *request.ProductFilter, offset int, pageSize int) ([]response.ProductResponse, int64, error) {
+
+	modelFilter := models.ProductFilter{}
+	if filter != nil {
+		modelFilter = models.ProductFilter{
+			Category:     filter.Category,
+			NameContains: filter.NameContains,
+			MinPrice:     filter.MinPrice,
+			MaxPrice:     filter.MaxPrice,
+			InStock:      filter.InStock,
+		}
+	}
+
+	products, total, err := p.productRepo.SearchProducts(ctx, modelFilter, offset, pageSize)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Error searching products")
+		return nil, 0, err
+	}
+
+	productResponses := make([]response.ProductResponse, 0, len(products))
+	for i := range products {
+		productResponses = append(productResponses, toProductResponse(&products[i]))
+	}
+
+	logging.FromContext(ctx).WithField("total_products", len(productResponses)).Info("Products retrieved successfully")
+
+	return productResponses, total, nil
+}
+
+// GetCategories implements ProductService.
+func (p *ProductServiceImpl) GetCategories(ctx context.Context) ([]string, error) {
+
+	categories, err := p.productRepo.GetCategories(ctx)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Error getting categories")
+		return nil, err
+	}
+
+	return categories, nil
+}
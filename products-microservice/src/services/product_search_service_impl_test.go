@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/models"
+	"github.com/dieg0code/products-microservice/src/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestProductSearchServiceImpl(t *testing.T) {
+
+	t.Run("GetByCategories_Success", func(t *testing.T) {
+		mockRepo := new(testutils.MockProductRepository)
+
+		productService := NewProductServiceImpl(mockRepo)
+
+		mockRepo.On("GetByCategories", mock.Anything, []string{"Books", "Games"}, 0, 10).Return([]models.Product{
+			{Model: gorm.Model{ID: 1}, Name: "Book 1", Category: "Books"},
+			{Model: gorm.Model{ID: 2}, Name: "Book 2", Category: "Books"},
+		}, nil)
+
+		products, err := productService.GetByCategories(context.Background(), []string{"Books", "Games"}, 0, 10)
+
+		assert.Nil(t, err, "Expected error to be nil")
+		assert.Equal(t, 2, len(products["Books"]), "Expected 2 books")
+		assert.NotNil(t, products["Games"], "Expected empty categories to be present")
+		assert.Empty(t, products["Games"], "Expected no games")
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("CountByCategories_Success", func(t *testing.T) {
+		mockRepo := new(testutils.MockProductRepository)
+
+		productService := NewProductServiceImpl(mockRepo)
+
+		mockRepo.On("CountByCategories", mock.Anything, []string{"Books", "Games"}).Return(map[string]int64{"Books": 2}, nil)
+
+		totals, err := productService.CountByCategories(context.Background(), []string{"Books", "Games"})
+
+		assert.Nil(t, err, "Expected error to be nil")
+		assert.Equal(t, int64(2), totals["Books"], "Expected 2 books")
+		assert.Zero(t, totals["Games"], "Expected no games")
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("SearchProducts_Success", func(t *testing.T) {
+		mockRepo := new(testutils.MockProductRepository)
+
+		productService := NewProductServiceImpl(mockRepo)
+
+		minPrice := 100
+		mockRepo.On("SearchProducts", mock.Anything, models.ProductFilter{Category: "Books", MinPrice: &minPrice}, 10, 5).Return([]models.Product{
+			{Model: gorm.Model{ID: 11}, Name: "Book 1", Category: "Books"},
+		}, int64(11), nil)
+
+		products, total, err := productService.SearchProducts(context.Background(), &request.ProductFilter{Category: "Books", MinPrice: &minPrice}, 10, 5)
+
+		assert.Nil(t, err, "Expected error to be nil")
+		assert.Equal(t, int64(11), total, "Expected total to be returned")
+		assert.Equal(t, uint(11), products[0].ProductID, "Expected product to be mapped")
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("SearchProducts_Error", func(t *testing.T) {
+		mockRepo := new(testutils.MockProductRepository)
+
+		productService := NewProductServiceImpl(mockRepo)
+
+		mockRepo.On("SearchProducts", mock.Anything, models.ProductFilter{}, 0, 10).Return([]models.Product{}, int64(0), assert.AnError)
+
+		products, _, err := productService.SearchProducts(context.Background(), nil, 0, 10)
+
+		assert.NotNil(t, err, "Expected error to be not nil")
+		assert.Nil(t, products, "Expected products to be nil")
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("GetCategories_Success", func(t *testing.T) {
+		mockRepo := new(testutils.MockProductRepository)
+
+		productService := NewProductServiceImpl(mockRepo)
+
+		mockRepo.On("GetCategories", mock.Anything).Return([]string{"Books"}, nil)
+
+		categories, err := productService.GetCategories(context.Background())
+
+		assert.Nil(t, err, "Expected error to be nil")
+		assert.Equal(t, []string{"Books"}, categories, "Expected categories to be returned")
+
+		mockRepo.AssertExpectations(t)
+	})
+}
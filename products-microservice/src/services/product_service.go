@@ -1,15 +1,23 @@
 package services
 
 import (
+	"context"
+
 	"github.com/dieg0code/products-microservice/src/json/request"
 	"github.com/dieg0code/products-microservice/src/json/response"
 )
 
 type ProductService interface {
-	CreateProduct(product *request.CreateProductRequest) (*uint, error)
-	GetProductById(productID uint) (*response.ProductResponse, error)
-	GetAllProducts(page int, pageSize int) ([]response.ProductResponse, error)
-	GetByCategory(category string) ([]response.ProductResponse, error)
-	UpdateProduct(productID uint, product *request.UpdateProductRequest) (*response.ProductResponse, error)
-	DeleteProduct(ProductID uint) error
+	CreateProduct(ctx context.Context, product *request.CreateProductRequest) (*uint, error)
+	GetProductById(ctx context.Context, productID uint) (*response.ProductResponse, error)
+	GetAllProducts(ctx context.Context, page int, pageSize int) ([]response.ProductResponse, error)
+	GetByCategory(ctx context.Context, category string) ([]response.ProductResponse, error)
+	UpdateProduct(ctx context.Context, productID uint, product *request.UpdateProductRequest) (*response.ProductResponse, error)
+	DeleteProduct(ctx context.Context, ProductID uint) error
+	GetProductsByIds(ctx context.Context, productIDs []uint) (*response.BatchGetProductsResponse, error)
+	GetByCategories(ctx context.Context, categories []string, offset int, pageSize int) (map[string][]response.ProductResponse, error)
+	CountByCategories(ctx context.Context, categories []string) (map[string]int64, error)
+	SearchProducts(ctx context.Context, filter *request.ProductFilter, offset int, pageSize int) ([]response.ProductResponse, int64, error)
+	GetCategories(ctx context.Context) ([]string, error)
+	ExecuteBatch(ctx context.Context, batch *request.BatchProductRequest) (*response.BatchProductResponse, error)
 }
@@ -1,13 +1,14 @@
 package services
 
 import (
+	"context"
 	"errors"
 
 	"github.com/dieg0code/products-microservice/src/json/request"
 	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/logging"
 	"github.com/dieg0code/products-microservice/src/models"
 	"github.com/dieg0code/products-microservice/src/repository"
-	"github.com/sirupsen/logrus"
 )
 
 type ProductServiceImpl struct {
@@ -15,7 +16,7 @@ type ProductServiceImpl struct {
 }
 
 // CreateProduct implements ProductService.
-func (p *ProductServiceImpl) CreateProduct(product *request.CreateProductRequest) (*uint, error) {
+func (p *ProductServiceImpl) CreateProduct(ctx context.Context, product *request.CreateProductRequest) (*uint, error) {
 
 	productModel := &models.Product{
 		Name:     product.Name,
@@ -24,42 +25,42 @@ func (p *ProductServiceImpl) CreateProduct(product *request.CreateProductRequest
 		Stock:    product.Stock,
 	}
 
-	createdProduct, err := p.productRepo.CreateProduct(productModel)
+	createdProduct, err := p.productRepo.CreateProduct(ctx, productModel)
 	if err != nil {
-		logrus.WithError(err).Error("Error creating product")
+		logging.FromContext(ctx).WithError(err).Error("Error creating product")
 		return nil, err
 	}
 
-	logrus.WithField("product_id", createdProduct.ID).Info("Product created successfully")
+	logging.FromContext(ctx).WithField("product_id", createdProduct.ID).Info("Product created successfully")
 	return &createdProduct.ID, nil
 }
 
 // DeleteProduct implements ProductService.
-func (p *ProductServiceImpl) DeleteProduct(productID uint) error {
+func (p *ProductServiceImpl) DeleteProduct(ctx context.Context, productID uint) error {
 
 	if productID == 0 {
 		return errors.New("product id is required")
 	}
 
-	err := p.productRepo.DeleteProduct(productID)
+	err := p.productRepo.DeleteProduct(ctx, productID)
 	if err != nil {
-		logrus.WithError(err).Error("Error deleting product")
+		logging.FromContext(ctx).WithError(err).Error("Error deleting product")
 		return err
 	}
 
-	logrus.WithField("product_id", productID).Info("Product deleted successfully")
+	logging.FromContext(ctx).WithField("product_id", productID).Info("Product deleted successfully")
 
 	return nil
 }
 
 // GetAllProducts implements ProductService.
-func (p *ProductServiceImpl) GetAllProducts(page int, pageSize int) ([]response.ProductResponse, error) {
+func (p *ProductServiceImpl) GetAllProducts(ctx context.Context, page int, pageSize int) ([]response.ProductResponse, error) {
 
 	offset := (page - 1) * pageSize
 
-	products, err := p.productRepo.GetAllProducts(offset, pageSize)
+	products, err := p.productRepo.GetAllProducts(ctx, offset, pageSize)
 	if err != nil {
-		logrus.WithError(err).Error("Error getting all products")
+		logging.FromContext(ctx).WithError(err).Error("Error getting all products")
 		return nil, err
 	}
 
@@ -72,20 +73,21 @@ func (p *ProductServiceImpl) GetAllProducts(page int, pageSize int) ([]response.
 			Price:      product.Price,
 			Stock:      product.Stock,
 			LastUpdate: product.UpdatedAt.Format("02-01-2006"),
+			UpdatedAt:  product.UpdatedAt,
 		})
 	}
 
-	logrus.WithField("total_products", len(productResponses)).Info("Products retrieved successfully")
+	logging.FromContext(ctx).WithField("total_products", len(productResponses)).Info("Products retrieved successfully")
 
 	return productResponses, nil
 }
 
 // GetByCategory implements ProductService.
-func (p *ProductServiceImpl) GetByCategory(category string) ([]response.ProductResponse, error) {
+func (p *ProductServiceImpl) GetByCategory(ctx context.Context, category string) ([]response.ProductResponse, error) {
 
-	products, err := p.productRepo.GetByCategory(category)
+	products, err := p.productRepo.GetByCategory(ctx, category)
 	if err != nil {
-		logrus.WithError(err).Error("Error getting products by category")
+		logging.FromContext(ctx).WithError(err).Error("Error getting products by category")
 		return nil, err
 	}
 
@@ -98,20 +100,21 @@ func (p *ProductServiceImpl) GetByCategory(category string) ([]response.ProductR
 			Price:      product.Price,
 			Stock:      product.Stock,
 			LastUpdate: product.UpdatedAt.Format("02-01-2006"),
+			UpdatedAt:  product.UpdatedAt,
 		})
 	}
 
-	logrus.WithField("total_products", len(productResponses)).Info("Products retrieved successfully")
+	logging.FromContext(ctx).WithField("total_products", len(productResponses)).Info("Products retrieved successfully")
 
 	return productResponses, nil
 }
 
 // GetProductById implements ProductService.
-func (p *ProductServiceImpl) GetProductById(ProductID uint) (*response.ProductResponse, error) {
+func (p *ProductServiceImpl) GetProductById(ctx context.Context, ProductID uint) (*response.ProductResponse, error) {
 
-	product, err := p.productRepo.GetProductById(ProductID)
+	product, err := p.productRepo.GetProductById(ctx, ProductID)
 	if err != nil {
-		logrus.WithError(err).Error("Error getting product by ID")
+		logging.FromContext(ctx).WithError(err).Error("Error getting product by ID")
 		return nil, err
 	}
 
@@ -122,15 +125,16 @@ func (p *ProductServiceImpl) GetProductById(ProductID uint) (*response.ProductRe
 		Price:      product.Price,
 		Stock:      product.Stock,
 		LastUpdate: product.UpdatedAt.Format("02-01-2006"),
+		UpdatedAt:  product.UpdatedAt,
 	}
 
-	logrus.WithField("product_id", product.ID).Info("Product retrieved successfully")
+	logging.FromContext(ctx).WithField("product_id", product.ID).Info("Product retrieved successfully")
 
 	return productResponse, nil
 }
 
 // UpdateProduct implements ProductService.
-func (p *ProductServiceImpl) UpdateProduct(productID uint, product *request.UpdateProductRequest) (*response.ProductResponse, error) {
+func (p *ProductServiceImpl) UpdateProduct(ctx context.Context, productID uint, product *request.UpdateProductRequest) (*response.ProductResponse, error) {
 
 	productModel := &models.Product{
 		Name:     product.Name,
@@ -139,9 +143,9 @@ func (p *ProductServiceImpl) UpdateProduct(productID uint, product *request.Upda
 		Stock:    product.Stock,
 	}
 
-	updatedProduct, err := p.productRepo.UpdateProduct(productID, productModel)
+	updatedProduct, err := p.productRepo.UpdateProduct(ctx, productID, productModel)
 	if err != nil {
-		logrus.WithError(err).Error("Error updating product")
+		logging.FromContext(ctx).WithError(err).Error("Error updating product")
 		return nil, err
 	}
 
@@ -153,7 +157,7 @@ func (p *ProductServiceImpl) UpdateProduct(productID uint, product *request.Upda
 		Stock:     updatedProduct.Stock,
 	}
 
-	logrus.WithField("product_id", updatedProduct.ID).Info("Product updated successfully")
+	logging.FromContext(ctx).WithField("product_id", updatedProduct.ID).Info("Product updated successfully")
 
 	return productResponse, nil
 }
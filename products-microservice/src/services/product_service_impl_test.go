@@ -1,11 +1,14 @@
 package services
 
 import (
+	"context"
 	"testing"
 
 	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/logging"
 	"github.com/dieg0code/products-microservice/src/models"
 	"github.com/dieg0code/products-microservice/src/testutils"
+	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"gorm.io/gorm"
@@ -32,7 +35,7 @@ func TestProductServiceImpl(t *testing.T) {
 			Stock:    mockReq.Stock,
 		}
 
-		mockRepo.On("CreateProduct", mockModel).Return(&models.Product{
+		mockRepo.On("CreateProduct", mock.Anything, mockModel).Return(&models.Product{
 			Model:    gorm.Model{ID: 1},
 			Name:     mockReq.Name,
 			Category: mockReq.Category,
@@ -40,7 +43,7 @@ func TestProductServiceImpl(t *testing.T) {
 			Stock:    mockReq.Stock,
 		}, nil)
 
-		productID, err := productService.CreateProduct(&mockReq)
+		productID, err := productService.CreateProduct(context.Background(), &mockReq)
 
 		assert.Nil(t, err, "Expected error to be nil")
 		assert.Equal(t, uint(1), *productID, "Expected product ID to be 1")
@@ -67,9 +70,9 @@ func TestProductServiceImpl(t *testing.T) {
 			Stock:    mockReq.Stock,
 		}
 
-		mockRepo.On("CreateProduct", mockModel).Return(&models.Product{}, assert.AnError)
+		mockRepo.On("CreateProduct", mock.Anything, mockModel).Return(&models.Product{}, assert.AnError)
 
-		productID, err := productService.CreateProduct(&mockReq)
+		productID, err := productService.CreateProduct(context.Background(), &mockReq)
 
 		assert.NotNil(t, err, "Expected error to be not nil")
 		assert.Nil(t, productID, "Expected product ID to be nil")
@@ -82,9 +85,9 @@ func TestProductServiceImpl(t *testing.T) {
 
 		productService := NewProductServiceImpl(mockRepo)
 
-		mockRepo.On("DeleteProduct", uint(1)).Return(nil)
+		mockRepo.On("DeleteProduct", mock.Anything, uint(1)).Return(nil)
 
-		err := productService.DeleteProduct(1)
+		err := productService.DeleteProduct(context.Background(), 1)
 
 		assert.Nil(t, err, "Expected error to be nil")
 
@@ -96,21 +99,39 @@ func TestProductServiceImpl(t *testing.T) {
 
 		productService := NewProductServiceImpl(mockRepo)
 
-		mockRepo.On("DeleteProduct", uint(1)).Return(assert.AnError)
+		mockRepo.On("DeleteProduct", mock.Anything, uint(1)).Return(assert.AnError)
 
-		err := productService.DeleteProduct(1)
+		err := productService.DeleteProduct(context.Background(), 1)
 
 		assert.NotNil(t, err, "Expected error to be not nil")
 
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("DeleteProduct_Logs_With_Context_Logger", func(t *testing.T) {
+		mockRepo := new(testutils.MockProductRepository)
+
+		productService := NewProductServiceImpl(mockRepo)
+
+		logger, hook := test.NewNullLogger()
+		ctx := logging.WithLogger(context.Background(), logger.WithField("request_id", "abc"))
+
+		mockRepo.On("DeleteProduct", mock.Anything, uint(1)).Return(assert.AnError)
+
+		err := productService.DeleteProduct(ctx, 1)
+
+		assert.NotNil(t, err, "Expected error to be not nil")
+		assert.Equal(t, "abc", hook.LastEntry().Data["request_id"], "Expected the error to be logged with the request id")
+
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("GetAllProducts_Success", func(t *testing.T) {
 		mockRepo := new(testutils.MockProductRepository)
 
 		productService := NewProductServiceImpl(mockRepo)
 
-		mockRepo.On("GetAllProducts", 0, 10).Return([]models.Product{
+		mockRepo.On("GetAllProducts", mock.Anything, 0, 10).Return([]models.Product{
 			{
 				Model:    gorm.Model{ID: 1},
 				Name:     "Product 1",
@@ -127,7 +148,7 @@ func TestProductServiceImpl(t *testing.T) {
 			},
 		}, nil)
 
-		products, err := productService.GetAllProducts(1, 10)
+		products, err := productService.GetAllProducts(context.Background(), 1, 10)
 
 		assert.Nil(t, err, "Expected error to be nil")
 		assert.Equal(t, 2, len(products), "Expected 2 products")
@@ -140,9 +161,9 @@ func TestProductServiceImpl(t *testing.T) {
 
 		productService := NewProductServiceImpl(mockRepo)
 
-		mockRepo.On("GetAllProducts", 0, 10).Return([]models.Product{}, assert.AnError)
+		mockRepo.On("GetAllProducts", mock.Anything, 0, 10).Return([]models.Product{}, assert.AnError)
 
-		products, err := productService.GetAllProducts(1, 10)
+		products, err := productService.GetAllProducts(context.Background(), 1, 10)
 
 		assert.NotNil(t, err, "Expected error to be not nil")
 		assert.Nil(t, products, "Expected products to be nil")
@@ -155,7 +176,7 @@ func TestProductServiceImpl(t *testing.T) {
 
 		productService := NewProductServiceImpl(mockRepo)
 
-		mockRepo.On("GetByCategory", "Category 1").Return([]models.Product{
+		mockRepo.On("GetByCategory", mock.Anything, "Category 1").Return([]models.Product{
 			{
 				Model:    gorm.Model{ID: 1},
 				Name:     "Product 1",
@@ -172,7 +193,7 @@ func TestProductServiceImpl(t *testing.T) {
 			},
 		}, nil)
 
-		products, err := productService.GetByCategory("Category 1")
+		products, err := productService.GetByCategory(context.Background(), "Category 1")
 
 		assert.Nil(t, err, "Expected error to be nil")
 		assert.Equal(t, 2, len(products), "Expected 2 products")
@@ -185,9 +206,9 @@ func TestProductServiceImpl(t *testing.T) {
 
 		productService := NewProductServiceImpl(mockRepo)
 
-		mockRepo.On("GetByCategory", "Category 1").Return([]models.Product{}, assert.AnError)
+		mockRepo.On("GetByCategory", mock.Anything, "Category 1").Return([]models.Product{}, assert.AnError)
 
-		products, err := productService.GetByCategory("Category 1")
+		products, err := productService.GetByCategory(context.Background(), "Category 1")
 
 		assert.NotNil(t, err, "Expected error to be not nil")
 		assert.Nil(t, products, "Expected products to be nil")
@@ -200,7 +221,7 @@ func TestProductServiceImpl(t *testing.T) {
 
 		productService := NewProductServiceImpl(mockRepo)
 
-		mockRepo.On("GetProductById", uint(1)).Return(&models.Product{
+		mockRepo.On("GetProductById", mock.Anything, uint(1)).Return(&models.Product{
 			Model:    gorm.Model{ID: 1},
 			Name:     "Product 1",
 			Category: "Category 1",
@@ -208,7 +229,7 @@ func TestProductServiceImpl(t *testing.T) {
 			Stock:    10,
 		}, nil)
 
-		product, err := productService.GetProductById(1)
+		product, err := productService.GetProductById(context.Background(), 1)
 
 		assert.Nil(t, err, "Expected error to be nil")
 		assert.NotNil(t, product, "Expected product to be not nil")
@@ -221,9 +242,9 @@ func TestProductServiceImpl(t *testing.T) {
 
 		productService := NewProductServiceImpl(mockRepo)
 
-		mockRepo.On("GetProductById", uint(1)).Return(&models.Product{}, assert.AnError)
+		mockRepo.On("GetProductById", mock.Anything, uint(1)).Return(&models.Product{}, assert.AnError)
 
-		product, err := productService.GetProductById(1)
+		product, err := productService.GetProductById(context.Background(), 1)
 
 		assert.NotNil(t, err, "Expected error to be not nil")
 		assert.Nil(t, product, "Expected product to be nil")
@@ -244,7 +265,7 @@ func TestProductServiceImpl(t *testing.T) {
 			Stock:    10,
 		}
 
-		mockRepo.On("UpdateProduct", uint(1), mock.Anything).Return(&models.Product{
+		mockRepo.On("UpdateProduct", mock.Anything, uint(1), mock.Anything).Return(&models.Product{
 			Model:    gorm.Model{ID: 1},
 			Name:     mockReq.Name,
 			Category: mockReq.Category,
@@ -252,7 +273,7 @@ func TestProductServiceImpl(t *testing.T) {
 			Stock:    mockReq.Stock,
 		}, nil)
 
-		product, err := productService.UpdateProduct(1, mockReq)
+		product, err := productService.UpdateProduct(context.Background(), 1, mockReq)
 
 		assert.Nil(t, err, "Expected error to be nil")
 		assert.NotNil(t, product, "Expected product to be not nil")
@@ -273,9 +294,9 @@ func TestProductServiceImpl(t *testing.T) {
 			Stock:    10,
 		}
 
-		mockRepo.On("UpdateProduct", uint(1), mock.Anything).Return(&models.Product{}, assert.AnError)
+		mockRepo.On("UpdateProduct", mock.Anything, uint(1), mock.Anything).Return(&models.Product{}, assert.AnError)
 
-		product, err := productService.UpdateProduct(1, mockReq)
+		product, err := productService.UpdateProduct(context.Background(), 1, mockReq)
 
 		assert.NotNil(t, err, "Expected error to be not nil")
 		assert.Nil(t, product, "Expected product to be nil")
@@ -0,0 +1,17 @@
+package services
+
+import (
+	"context"
+
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/json/response"
+)
+
+type StockService interface {
+	// ApplySale decrements stock for every product in the sale. It returns a compensation
+	// event when the sale could not be applied and nil when the stock was updated. A sale
+	// rejected before gets its compensation event again, so redeliveries can publish it.
+	ApplySale(ctx context.Context, sale *request.SaleCreatedEvent) (*response.StockCompensationEvent, error)
+	// AdjustStock applies a restock or a correction, the stock never goes below zero
+	AdjustStock(ctx context.Context, productID uint, adjustment *request.AdjustStockRequest) (*response.ProductResponse, error)
+}
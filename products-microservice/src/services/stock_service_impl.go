@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/logging"
+	"github.com/dieg0code/products-microservice/src/models"
+	"github.com/dieg0code/products-microservice/src/repository"
+)
+
+const InsufficientStockReason = "insufficient_stock"
+
+type StockServiceImpl struct {
+	stockRepo repository.StockRepository
+}
+
+// ApplySale implements StockService.
+func (s *StockServiceImpl) ApplySale(ctx context.Context, sale *request.SaleCreatedEvent) (*response.StockCompensationEvent, error) {
+
+	if sale.SaleID == "" {
+		return nil, errors.New("sale id is required")
+	}
+
+	items := make(map[uint]int)
+	for _, product := range sale.Products {
+		if product.Quantity <= 0 {
+			return nil, errors.New("product quantity must be positive")
+		}
+		items[product.ProductID] += product.Quantity
+	}
+
+	shortages, err := s.stockRepo.DecrementStockForSale(ctx, sale.SaleID, items)
+	if errors.Is(err, repository.ErrSaleAlreadyProcessed) && len(shortages) > 0 {
+		// Publishing the compensation may have failed the first time, so it is emitted again
+		logging.FromContext(ctx).WithField("sale_id", sale.SaleID).Info("Sale already rejected, emitting its compensation again")
+		return newStockCompensation(sale.SaleID, shortages), nil
+	}
+
+	if err != nil {
+		if errors.Is(err, repository.ErrSaleAlreadyProcessed) {
+			logging.FromContext(ctx).WithField("sale_id", sale.SaleID).Info("Sale already processed, skipping")
+		} else {
+			logging.FromContext(ctx).WithError(err).Error("Error applying sale to stock")
+		}
+		return nil, err
+	}
+
+	if len(shortages) == 0 {
+		logging.FromContext(ctx).WithField("sale_id", sale.SaleID).Info("Stock decremented successfully")
+		return nil, nil
+	}
+
+	logging.FromContext(ctx).WithField("sale_id", sale.SaleID).Warn("Insufficient stock for sale")
+
+	return newStockCompensation(sale.SaleID, shortages), nil
+}
+
+func newStockCompensation(saleID string, shortages []models.StockShortage) *response.StockCompensationEvent {
+	compensation := &response.StockCompensationEvent{
+		SaleID: saleID,
+		Reason: InsufficientStockReason,
+	}
+	for _, shortage := range shortages {
+		compensation.Shortage = append(compensation.Shortage, response.StockShortageResponse{
+			ProductID: shortage.ProductID,
+			Requested: shortage.Requested,
+			Available: shortage.Available,
+		})
+	}
+	return compensation
+}
+
+// AdjustStock implements StockService.
+func (s *StockServiceImpl) AdjustStock(ctx context.Context, productID uint, adjustment *request.AdjustStockRequest) (*response.ProductResponse, error) {
+	product, err := s.stockRepo.AdjustStock(ctx, productID, adjustment.Delta)
+	if err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx).WithField("product_id", productID).WithField("delta", adjustment.Delta).Info("Stock adjusted successfully")
+
+	productResponse := toProductResponse(product)
+	return &productResponse, nil
+}
+
+func NewStockServiceImpl(stockRepo repository.StockRepository) StockService {
+	return &StockServiceImpl{stockRepo: stockRepo}
+}
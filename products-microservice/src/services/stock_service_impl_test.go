@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/models"
+	"github.com/dieg0code/products-microservice/src/repository"
+	"github.com/dieg0code/products-microservice/src/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestStockServiceImpl(t *testing.T) {
+
+	t.Run("ApplySale_Success", func(t *testing.T) {
+		mockRepo := new(testutils.MockStockRepository)
+
+		stockService := NewStockServiceImpl(mockRepo)
+
+		sale := &request.SaleCreatedEvent{
+			SaleID: "sale-1",
+			Products: []request.SaleProductEvent{
+				{ProductID: 1, Quantity: 2},
+				{ProductID: 2, Quantity: 1},
+				{ProductID: 1, Quantity: 3},
+			},
+		}
+
+		mockRepo.On("DecrementStockForSale", mock.Anything, "sale-1", map[uint]int{1: 5, 2: 1}).Return([]models.StockShortage(nil), nil)
+
+		compensation, err := stockService.ApplySale(context.Background(), sale)
+
+		assert.Nil(t, err, "Expected error to be nil")
+		assert.Nil(t, compensation, "Expected no compensation event")
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("ApplySale_Insufficient_Stock", func(t *testing.T) {
+		mockRepo := new(testutils.MockStockRepository)
+
+		stockService := NewStockServiceImpl(mockRepo)
+
+		sale := &request.SaleCreatedEvent{
+			SaleID:   "sale-1",
+			Products: []request.SaleProductEvent{{ProductID: 1, Quantity: 5}},
+		}
+
+		mockRepo.On("DecrementStockForSale", mock.Anything, "sale-1", map[uint]int{1: 5}).Return([]models.StockShortage{
+			{ProductID: 1, Requested: 5, Available: 2},
+		}, nil)
+
+		compensation, err := stockService.ApplySale(context.Background(), sale)
+
+		assert.Nil(t, err, "Expected error to be nil")
+		assert.NotNil(t, compensation, "Expected compensation event")
+		assert.Equal(t, "sale-1", compensation.SaleID, "Expected compensation for the sale")
+		assert.Equal(t, InsufficientStockReason, compensation.Reason, "Expected insufficient stock reason")
+		assert.Equal(t, 2, compensation.Shortage[0].Available, "Expected available stock to be reported")
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("ApplySale_Already_Processed", func(t *testing.T) {
+		mockRepo := new(testutils.MockStockRepository)
+
+		stockService := NewStockServiceImpl(mockRepo)
+
+		sale := &request.SaleCreatedEvent{
+			SaleID:   "sale-1",
+			Products: []request.SaleProductEvent{{ProductID: 1, Quantity: 1}},
+		}
+
+		mockRepo.On("DecrementStockForSale", mock.Anything, "sale-1", map[uint]int{1: 1}).Return([]models.StockShortage(nil), repository.ErrSaleAlreadyProcessed)
+
+		compensation, err := stockService.ApplySale(context.Background(), sale)
+
+		assert.ErrorIs(t, err, repository.ErrSaleAlreadyProcessed, "Expected already processed error")
+		assert.Nil(t, compensation, "Expected no compensation event")
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("ApplySale_Already_Rejected", func(t *testing.T) {
+		mockRepo := new(testutils.MockStockRepository)
+
+		stockService := NewStockServiceImpl(mockRepo)
+
+		sale := &request.SaleCreatedEvent{
+			SaleID:   "sale-1",
+			Products: []request.SaleProductEvent{{ProductID: 1, Quantity: 5}},
+		}
+
+		shortages := []models.StockShortage{{ProductID: 1, Requested: 5, Available: 2}}
+		mockRepo.On("DecrementStockForSale", mock.Anything, "sale-1", map[uint]int{1: 5}).Return(shortages, repository.ErrSaleAlreadyProcessed)
+
+		compensation, err := stockService.ApplySale(context.Background(), sale)
+
+		assert.Nil(t, err, "Expected no error for a rejected sale")
+		assert.NotNil(t, compensation, "Expected the compensation event to be emitted again")
+		assert.Equal(t, 2, compensation.Shortage[0].Available, "Expected the recorded shortage")
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("ApplySale_Invalid_Quantity", func(t *testing.T) {
+		mockRepo := new(testutils.MockStockRepository)
+
+		stockService := NewStockServiceImpl(mockRepo)
+
+		sale := &request.SaleCreatedEvent{
+			SaleID:   "sale-1",
+			Products: []request.SaleProductEvent{{ProductID: 1, Quantity: 0}},
+		}
+
+		compensation, err := stockService.ApplySale(context.Background(), sale)
+
+		assert.NotNil(t, err, "Expected error to be not nil")
+		assert.Nil(t, compensation, "Expected no compensation event")
+
+		mockRepo.AssertNotCalled(t, "DecrementStockForSale")
+	})
+
+	t.Run("AdjustStock_Success", func(t *testing.T) {
+		mockRepo := new(testutils.MockStockRepository)
+
+		stockService := NewStockServiceImpl(mockRepo)
+
+		mockRepo.On("AdjustStock", mock.Anything, uint(1), -2).Return(&models.Product{Model: gorm.Model{ID: 1}, Name: "Test Product", Stock: 8}, nil)
+
+		product, err := stockService.AdjustStock(context.Background(), 1, &request.AdjustStockRequest{Delta: -2})
+
+		assert.Nil(t, err, "Expected error to be nil")
+		assert.Equal(t, uint(1), product.ProductID, "Expected product ID to be 1")
+		assert.Equal(t, 8, product.Stock, "Expected the adjusted stock")
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("AdjustStock_Insufficient_Stock", func(t *testing.T) {
+		mockRepo := new(testutils.MockStockRepository)
+
+		stockService := NewStockServiceImpl(mockRepo)
+
+		mockRepo.On("AdjustStock", mock.Anything, uint(1), -20).Return((*models.Product)(nil), repository.ErrInsufficientStock)
+
+		product, err := stockService.AdjustStock(context.Background(), 1, &request.AdjustStockRequest{Delta: -20})
+
+		assert.ErrorIs(t, err, repository.ErrInsufficientStock, "Expected insufficient stock")
+		assert.Nil(t, product, "Expected no product")
+	})
+}
@@ -0,0 +1,45 @@
+package stream
+
+import (
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/json/response"
+)
+
+const (
+	EventProductCreated = "product_created"
+	EventProductUpdated = "product_updated"
+	EventProductDeleted = "product_deleted"
+	EventStockChanged   = "stock_changed"
+)
+
+type ProductEvent struct {
+	ID         uint64                    `json:"id"`
+	TenantID   string                    `json:"-"`
+	Type       string                    `json:"type"`
+	ProductID  uint                      `json:"product_id"`
+	Category   string                    `json:"category,omitempty"`
+	Product    *response.ProductResponse `json:"product,omitempty"`
+	OccurredAt time.Time                 `json:"occurred_at"`
+}
+
+// Filter selects the events a subscriber receives, empty fields match everything
+// except TenantID which must always match
+type Filter struct {
+	TenantID   string
+	ProductIDs map[uint]bool
+	Category   string
+}
+
+func (f Filter) Matches(event *ProductEvent) bool {
+	if f.TenantID != event.TenantID {
+		return false
+	}
+	if len(f.ProductIDs) > 0 && !f.ProductIDs[event.ProductID] {
+		return false
+	}
+	if f.Category != "" && f.Category != event.Category {
+		return false
+	}
+	return true
+}
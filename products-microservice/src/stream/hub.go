@@ -0,0 +1,153 @@
+package stream
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	DefaultHistorySize      = 1024
+	DefaultSubscriberBuffer = 64
+)
+
+// Hub fans product events out to every subscriber and keeps the latest ones in a
+// ring buffer so reconnecting clients can resume from their Last-Event-ID. The hub
+// is per process: subscribers only hear about writes served by the same replica.
+type Hub struct {
+	mu          sync.Mutex
+	history     []ProductEvent
+	next        int
+	size        int
+	lastID      uint64
+	subscribers map[*Subscription]struct{}
+	bufferSize  int
+	closed      bool
+}
+
+type Subscription struct {
+	filter Filter
+	events chan ProductEvent
+	hub    *Hub
+}
+
+// Events is closed when the subscriber falls too far behind or the hub is closed
+func (s *Subscription) Events() <-chan ProductEvent {
+	return s.events
+}
+
+func (s *Subscription) Close() {
+	s.hub.unsubscribe(s)
+}
+
+func (h *Hub) Publish(event ProductEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return
+	}
+
+	h.lastID++
+	event.ID = h.lastID
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	h.history[h.next] = event
+	h.next = (h.next + 1) % len(h.history)
+	if h.size < len(h.history) {
+		h.size++
+	}
+
+	for subscriber := range h.subscribers {
+		if !subscriber.filter.Matches(&event) {
+			continue
+		}
+
+		select {
+		case subscriber.events <- event:
+		default:
+			// A slow client must not block everyone else, it can resume with Last-Event-ID
+			delete(h.subscribers, subscriber)
+			close(subscriber.events)
+		}
+	}
+}
+
+// Subscribe registers a subscriber and returns the buffered events newer than
+// lastEventID that match filter. Both happen under the same lock so no event is lost
+// between the replay and the live stream.
+func (h *Hub) Subscribe(filter Filter, lastEventID uint64) (*Subscription, []ProductEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subscription := &Subscription{
+		filter: filter,
+		events: make(chan ProductEvent, h.bufferSize),
+		hub:    h,
+	}
+
+	if h.closed {
+		close(subscription.events)
+		return subscription, nil
+	}
+
+	var replay []ProductEvent
+	if lastEventID > 0 {
+		start := (h.next - h.size + len(h.history)) % len(h.history)
+		for i := 0; i < h.size; i++ {
+			event := h.history[(start+i)%len(h.history)]
+			if event.ID > lastEventID && filter.Matches(&event) {
+				replay = append(replay, event)
+			}
+		}
+	}
+
+	h.subscribers[subscription] = struct{}{}
+
+	return subscription, replay
+}
+
+func (h *Hub) unsubscribe(subscription *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subscribers[subscription]; ok {
+		delete(h.subscribers, subscription)
+		close(subscription.events)
+	}
+}
+
+// Close disconnects every subscriber, later publishes are ignored
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for subscriber := range h.subscribers {
+		delete(h.subscribers, subscriber)
+		close(subscriber.events)
+	}
+}
+
+func (h *Hub) SubscriberCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.subscribers)
+}
+
+func NewHub(historySize int, subscriberBuffer int) *Hub {
+	if historySize <= 0 {
+		historySize = DefaultHistorySize
+	}
+	if subscriberBuffer <= 0 {
+		subscriberBuffer = DefaultSubscriberBuffer
+	}
+
+	return &Hub{
+		history:     make([]ProductEvent, historySize),
+		subscribers: make(map[*Subscription]struct{}),
+		bufferSize:  subscriberBuffer,
+	}
+}
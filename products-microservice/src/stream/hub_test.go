@@ -0,0 +1,115 @@
+package stream
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHub(t *testing.T) {
+
+	t.Run("Publish_Delivers_Matching_Events", func(t *testing.T) {
+		hub := NewHub(8, 8)
+		subscription, replay := hub.Subscribe(Filter{Category: "Books"}, 0)
+		defer subscription.Close()
+
+		assert.Empty(t, replay, "Expected no replay without Last-Event-ID")
+
+		hub.Publish(ProductEvent{Type: EventProductCreated, ProductID: 1, Category: "Games"})
+		hub.Publish(ProductEvent{Type: EventProductCreated, ProductID: 2, Category: "Books"})
+
+		event := <-subscription.Events()
+		assert.Equal(t, uint(2), event.ProductID, "Expected only the Books event")
+		assert.Equal(t, uint64(2), event.ID, "Expected ids to be assigned in publish order")
+		assert.False(t, event.OccurredAt.IsZero(), "Expected OccurredAt to be set")
+	})
+
+	t.Run("Subscribe_Replays_Events_After_LastEventID", func(t *testing.T) {
+		hub := NewHub(8, 8)
+		for i := uint(1); i <= 4; i++ {
+			hub.Publish(ProductEvent{Type: EventProductUpdated, ProductID: i})
+		}
+
+		subscription, replay := hub.Subscribe(Filter{ProductIDs: map[uint]bool{2: true, 4: true}}, 1)
+		defer subscription.Close()
+
+		assert.Len(t, replay, 2, "Expected two replayed events")
+		assert.Equal(t, uint64(2), replay[0].ID, "Expected replay in order")
+		assert.Equal(t, uint64(4), replay[1].ID, "Expected replay in order")
+	})
+
+	t.Run("Subscribe_Replays_Only_Buffered_Events", func(t *testing.T) {
+		hub := NewHub(2, 8)
+		for i := uint(1); i <= 5; i++ {
+			hub.Publish(ProductEvent{Type: EventProductUpdated, ProductID: i})
+		}
+
+		subscription, replay := hub.Subscribe(Filter{}, 1)
+		defer subscription.Close()
+
+		assert.Len(t, replay, 2, "Expected only the ring buffer contents")
+		assert.Equal(t, uint64(4), replay[0].ID, "Expected oldest buffered event first")
+		assert.Equal(t, uint64(5), replay[1].ID, "Expected newest buffered event last")
+	})
+
+	t.Run("Publish_Drops_Slow_Subscriber", func(t *testing.T) {
+		hub := NewHub(8, 1)
+		slow, _ := hub.Subscribe(Filter{}, 0)
+		defer slow.Close()
+
+		hub.Publish(ProductEvent{Type: EventStockChanged, ProductID: 1})
+		hub.Publish(ProductEvent{Type: EventStockChanged, ProductID: 1})
+
+		assert.Equal(t, 0, hub.SubscriberCount(), "Expected slow subscriber to be dropped")
+
+		_, ok := <-slow.Events()
+		assert.True(t, ok, "Expected buffered event to still be readable")
+		_, ok = <-slow.Events()
+		assert.False(t, ok, "Expected channel to be closed")
+	})
+
+	t.Run("Publish_Concurrent_Subscribers", func(t *testing.T) {
+		hub := NewHub(8, 100)
+
+		var wg sync.WaitGroup
+		counts := make([]int, 10)
+		for i := range counts {
+			subscription, _ := hub.Subscribe(Filter{}, 0)
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for range subscription.Events() {
+					counts[i]++
+				}
+			}(i)
+		}
+
+		for i := 0; i < 50; i++ {
+			hub.Publish(ProductEvent{Type: EventProductUpdated, ProductID: 1})
+		}
+		hub.Close()
+		wg.Wait()
+
+		for i, count := range counts {
+			assert.Equal(t, 50, count, "Expected subscriber %d to receive every event", i)
+		}
+	})
+
+	t.Run("Close_Disconnects_Subscribers", func(t *testing.T) {
+		hub := NewHub(8, 8)
+		subscription, _ := hub.Subscribe(Filter{}, 0)
+
+		hub.Close()
+		hub.Publish(ProductEvent{Type: EventProductDeleted, ProductID: 1})
+		subscription.Close()
+
+		_, ok := <-subscription.Events()
+		assert.False(t, ok, "Expected channel to be closed")
+		assert.Equal(t, 0, hub.SubscriberCount(), "Expected no subscribers")
+
+		late, _ := hub.Subscribe(Filter{}, 0)
+		_, ok = <-late.Events()
+		assert.False(t, ok, "Expected subscriptions after Close to be closed")
+	})
+}
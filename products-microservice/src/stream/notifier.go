@@ -0,0 +1,206 @@
+package stream
+
+import (
+	"context"
+
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/logging"
+	"github.com/dieg0code/products-microservice/src/services"
+	"github.com/dieg0code/products-microservice/src/tenant"
+)
+
+// ProductServiceNotifier publishes a ProductEvent after every successful write
+// made through the wrapped ProductService.
+type ProductServiceNotifier struct {
+	services.ProductService
+	hub *Hub
+}
+
+// CreateProduct implements services.ProductService.
+func (n *ProductServiceNotifier) CreateProduct(ctx context.Context, product *request.CreateProductRequest) (*uint, error) {
+	productID, err := n.ProductService.CreateProduct(ctx, product)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := n.ProductService.GetProductById(ctx, *productID)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("product_id", *productID).Warn("Error loading created product for stream")
+		tenantID, _ := tenant.FromContext(ctx)
+		n.hub.Publish(ProductEvent{TenantID: tenantID, Type: EventProductCreated, ProductID: *productID, Category: product.Category})
+		return productID, nil
+	}
+
+	n.publishProduct(ctx, EventProductCreated, created)
+
+	return productID, nil
+}
+
+// UpdateProduct implements services.ProductService.
+func (n *ProductServiceNotifier) UpdateProduct(ctx context.Context, productID uint, product *request.UpdateProductRequest) (*response.ProductResponse, error) {
+	updated, err := n.ProductService.UpdateProduct(ctx, productID, product)
+	if err != nil {
+		return nil, err
+	}
+
+	n.publishProduct(ctx, EventProductUpdated, updated)
+
+	return updated, nil
+}
+
+// DeleteProduct implements services.ProductService.
+func (n *ProductServiceNotifier) DeleteProduct(ctx context.Context, productID uint) error {
+	// Load the product first so category subscribers also hear about the deletion
+	category := ""
+	if product, err := n.ProductService.GetProductById(ctx, productID); err == nil {
+		category = product.Category
+	}
+
+	err := n.ProductService.DeleteProduct(ctx, productID)
+	if err != nil {
+		return err
+	}
+
+	tenantID, _ := tenant.FromContext(ctx)
+	n.hub.Publish(ProductEvent{TenantID: tenantID, Type: EventProductDeleted, ProductID: productID, Category: category})
+
+	return nil
+}
+
+// ExecuteBatch implements services.ProductService.
+func (n *ProductServiceNotifier) ExecuteBatch(ctx context.Context, batch *request.BatchProductRequest) (*response.BatchProductResponse, error) {
+	// Like DeleteProduct, load the deleted products first so category subscribers hear about them
+	categories := n.deletedCategories(ctx, batch)
+
+	result, err := n.ProductService.ExecuteBatch(ctx, batch)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, operation := range result.Results {
+		if operation.Status != response.BatchStatusSucceeded {
+			continue
+		}
+
+		switch operation.Op {
+		case request.BatchOpCreate:
+			n.publishProduct(ctx, EventProductCreated, operation.Product)
+		case request.BatchOpUpdate:
+			n.publishProduct(ctx, EventProductUpdated, operation.Product)
+		case request.BatchOpDelete:
+			tenantID, _ := tenant.FromContext(ctx)
+			n.hub.Publish(ProductEvent{TenantID: tenantID, Type: EventProductDeleted, ProductID: operation.ProductID, Category: categories[operation.ProductID]})
+		}
+	}
+
+	return result, nil
+}
+
+// deletedCategories returns the category of every product the batch deletes
+func (n *ProductServiceNotifier) deletedCategories(ctx context.Context, batch *request.BatchProductRequest) map[uint]string {
+	var productIDs []uint
+	for _, operation := range batch.Operations {
+		if operation.Op == request.BatchOpDelete {
+			productIDs = append(productIDs, operation.ProductID)
+		}
+	}
+	if len(productIDs) == 0 {
+		return nil
+	}
+
+	products, err := n.ProductService.GetProductsByIds(ctx, productIDs)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Warn("Error loading deleted products for stream")
+		return nil
+	}
+
+	categories := make(map[uint]string, len(products.Products))
+	for _, product := range products.Products {
+		categories[product.ProductID] = product.Category
+	}
+	return categories
+}
+
+func (n *ProductServiceNotifier) publishProduct(ctx context.Context, eventType string, product *response.ProductResponse) {
+	if product == nil {
+		return
+	}
+
+	tenantID, _ := tenant.FromContext(ctx)
+	n.hub.Publish(ProductEvent{
+		TenantID:  tenantID,
+		Type:      eventType,
+		ProductID: product.ProductID,
+		Category:  product.Category,
+		Product:   product,
+	})
+}
+
+func NewProductServiceNotifier(productService services.ProductService, hub *Hub) services.ProductService {
+	return &ProductServiceNotifier{ProductService: productService, hub: hub}
+}
+
+// StockServiceNotifier publishes the new stock of every product touched by an applied sale
+// or an adjustment
+type StockServiceNotifier struct {
+	services.StockService
+	productService services.ProductService
+	hub            *Hub
+}
+
+// ApplySale implements services.StockService.
+func (n *StockServiceNotifier) ApplySale(ctx context.Context, sale *request.SaleCreatedEvent) (*response.StockCompensationEvent, error) {
+	compensation, err := n.StockService.ApplySale(ctx, sale)
+	if err != nil || compensation != nil {
+		return compensation, err
+	}
+
+	productIDs := make([]uint, 0, len(sale.Products))
+	for _, product := range sale.Products {
+		productIDs = append(productIDs, product.ProductID)
+	}
+
+	products, err := n.productService.GetProductsByIds(ctx, productIDs)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("sale_id", sale.SaleID).Warn("Error loading products for stock stream")
+		return nil, nil
+	}
+
+	tenantID, _ := tenant.FromContext(ctx)
+	for i := range products.Products {
+		product := products.Products[i]
+		n.hub.Publish(ProductEvent{
+			TenantID:  tenantID,
+			Type:      EventStockChanged,
+			ProductID: product.ProductID,
+			Category:  product.Category,
+			Product:   &product,
+		})
+	}
+
+	return nil, nil
+}
+
+// AdjustStock implements services.StockService.
+func (n *StockServiceNotifier) AdjustStock(ctx context.Context, productID uint, adjustment *request.AdjustStockRequest) (*response.ProductResponse, error) {
+	product, err := n.StockService.AdjustStock(ctx, productID, adjustment)
+	if err != nil {
+		return nil, err
+	}
+
+	tenantID, _ := tenant.FromContext(ctx)
+	n.hub.Publish(ProductEvent{
+		TenantID:  tenantID,
+		Type:      EventStockChanged,
+		ProductID: product.ProductID,
+		Category:  product.Category,
+		Product:   product,
+	})
+
+	return product, nil
+}
+
+func NewStockServiceNotifier(stockService services.StockService, productService services.ProductService, hub *Hub) services.StockService {
+	return &StockServiceNotifier{StockService: stockService, productService: productService, hub: hub}
+}
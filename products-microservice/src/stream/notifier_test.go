@@ -0,0 +1,195 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/tenant"
+	"github.com/dieg0code/products-microservice/src/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNotifier(t *testing.T) {
+	ctx := tenant.WithTenant(context.Background(), "acme")
+	book := response.ProductResponse{ProductID: 1, Name: "Book", Category: "Books", Price: 1000, Stock: 5}
+	game := response.ProductResponse{ProductID: 2, Name: "Game", Category: "Games", Price: 2000, Stock: 3}
+
+	// published drains the events the hub delivered so far to a subscriber of the tenant
+	subscribe := func(hub *Hub) func() []ProductEvent {
+		subscription, _ := hub.Subscribe(Filter{TenantID: "acme"}, 0)
+		t.Cleanup(subscription.Close)
+
+		return func() []ProductEvent {
+			var events []ProductEvent
+			for {
+				select {
+				case event := <-subscription.Events():
+					events = append(events, event)
+				default:
+					return events
+				}
+			}
+		}
+	}
+
+	t.Run("CreateProduct_Publishes_Created", func(t *testing.T) {
+		hub := NewHub(8, 8)
+		published := subscribe(hub)
+		mockService := new(testutils.MockProductService)
+		productID := uint(1)
+		mockService.On("CreateProduct", mock.Anything, mock.Anything).Return(&productID, nil)
+		mockService.On("GetProductById", mock.Anything, uint(1)).Return(&book, nil)
+
+		_, err := NewProductServiceNotifier(mockService, hub).CreateProduct(ctx, &request.CreateProductRequest{Name: "Book", Category: "Books"})
+		assert.Nil(t, err, "Expected no error")
+
+		events := published()
+		if assert.Len(t, events, 1, "Expected one event") {
+			assert.Equal(t, EventProductCreated, events[0].Type, "Expected a created event")
+			assert.Equal(t, "acme", events[0].TenantID, "Expected the tenant of the request")
+			assert.Equal(t, "Books", events[0].Category, "Expected the category of the product")
+			assert.Equal(t, &book, events[0].Product, "Expected the created product")
+		}
+	})
+
+	t.Run("UpdateProduct_Publishes_Updated", func(t *testing.T) {
+		hub := NewHub(8, 8)
+		published := subscribe(hub)
+		mockService := new(testutils.MockProductService)
+		mockService.On("UpdateProduct", mock.Anything, uint(1), mock.Anything).Return(&book, nil)
+
+		_, err := NewProductServiceNotifier(mockService, hub).UpdateProduct(ctx, 1, &request.UpdateProductRequest{})
+		assert.Nil(t, err, "Expected no error")
+
+		events := published()
+		if assert.Len(t, events, 1, "Expected one event") {
+			assert.Equal(t, EventProductUpdated, events[0].Type, "Expected an updated event")
+			assert.Equal(t, "acme", events[0].TenantID, "Expected the tenant of the request")
+			assert.Equal(t, "Books", events[0].Category, "Expected the category of the product")
+		}
+	})
+
+	t.Run("DeleteProduct_Publishes_Category", func(t *testing.T) {
+		hub := NewHub(8, 8)
+		published := subscribe(hub)
+		mockService := new(testutils.MockProductService)
+		mockService.On("GetProductById", mock.Anything, uint(1)).Return(&book, nil)
+		mockService.On("DeleteProduct", mock.Anything, uint(1)).Return(nil)
+
+		err := NewProductServiceNotifier(mockService, hub).DeleteProduct(ctx, 1)
+		assert.Nil(t, err, "Expected no error")
+
+		events := published()
+		if assert.Len(t, events, 1, "Expected one event") {
+			assert.Equal(t, EventProductDeleted, events[0].Type, "Expected a deleted event")
+			assert.Equal(t, "acme", events[0].TenantID, "Expected the tenant of the request")
+			assert.Equal(t, "Books", events[0].Category, "Expected the category of the deleted product")
+		}
+	})
+
+	t.Run("Failed_Writes_Publish_Nothing", func(t *testing.T) {
+		hub := NewHub(8, 8)
+		published := subscribe(hub)
+		mockService := new(testutils.MockProductService)
+		mockService.On("UpdateProduct", mock.Anything, uint(1), mock.Anything).Return((*response.ProductResponse)(nil), errors.New("update failed"))
+		mockService.On("GetProductById", mock.Anything, uint(1)).Return(&book, nil)
+		mockService.On("DeleteProduct", mock.Anything, uint(1)).Return(errors.New("delete failed"))
+		notifier := NewProductServiceNotifier(mockService, hub)
+
+		_, err := notifier.UpdateProduct(ctx, 1, &request.UpdateProductRequest{})
+		assert.NotNil(t, err, "Expected the update error")
+		err = notifier.DeleteProduct(ctx, 1)
+		assert.NotNil(t, err, "Expected the delete error")
+
+		assert.Empty(t, published(), "Expected no events")
+	})
+
+	t.Run("ExecuteBatch_Publishes_Succeeded_Operations", func(t *testing.T) {
+		hub := NewHub(8, 8)
+		published := subscribe(hub)
+		mockService := new(testutils.MockProductService)
+		batch := &request.BatchProductRequest{Operations: []request.BatchProductOperation{
+			{Op: request.BatchOpCreate, Product: &request.CreateProductRequest{Name: "Book"}},
+			{Op: request.BatchOpUpdate, ProductID: 2, Product: &request.CreateProductRequest{Name: "Game"}},
+			{Op: request.BatchOpDelete, ProductID: 2},
+			{Op: request.BatchOpDelete, ProductID: 3},
+		}}
+		mockService.On("GetProductsByIds", mock.Anything, []uint{2, 3}).Return(&response.BatchGetProductsResponse{Products: []response.ProductResponse{game}, MissingIDs: []uint{3}}, nil)
+		mockService.On("ExecuteBatch", mock.Anything, batch).Return(&response.BatchProductResponse{Results: []response.BatchOperationResult{
+			{Index: 0, Op: request.BatchOpCreate, ProductID: 1, Status: response.BatchStatusSucceeded, Product: &book},
+			{Index: 1, Op: request.BatchOpUpdate, ProductID: 2, Status: response.BatchStatusSucceeded, Product: &game},
+			{Index: 2, Op: request.BatchOpDelete, ProductID: 2, Status: response.BatchStatusSucceeded},
+			{Index: 3, Op: request.BatchOpDelete, ProductID: 3, Status: response.BatchStatusFailed, Error: "Product not found"},
+		}}, nil)
+
+		_, err := NewProductServiceNotifier(mockService, hub).ExecuteBatch(ctx, batch)
+		assert.Nil(t, err, "Expected no error")
+
+		events := published()
+		if assert.Len(t, events, 3, "Expected one event per succeeded operation") {
+			assert.Equal(t, EventProductCreated, events[0].Type, "Expected the create first")
+			assert.Equal(t, EventProductUpdated, events[1].Type, "Expected the update second")
+			assert.Equal(t, EventProductDeleted, events[2].Type, "Expected the delete last")
+			assert.Equal(t, "Games", events[2].Category, "Expected the category of the deleted product")
+			for _, event := range events {
+				assert.Equal(t, "acme", event.TenantID, "Expected the tenant of the request")
+			}
+		}
+	})
+
+	t.Run("ApplySale_Publishes_Stock_Changes", func(t *testing.T) {
+		hub := NewHub(8, 8)
+		published := subscribe(hub)
+		mockProducts := new(testutils.MockProductService)
+		mockStock := new(testutils.MockStockService)
+		sale := &request.SaleCreatedEvent{SaleID: "sale-1", Products: []request.SaleProductEvent{{ProductID: 1, Quantity: 1}, {ProductID: 2, Quantity: 1}}}
+		mockStock.On("ApplySale", mock.Anything, sale).Return((*response.StockCompensationEvent)(nil), nil)
+		mockProducts.On("GetProductsByIds", mock.Anything, []uint{1, 2}).Return(&response.BatchGetProductsResponse{Products: []response.ProductResponse{book, game}}, nil)
+
+		_, err := NewStockServiceNotifier(mockStock, mockProducts, hub).ApplySale(ctx, sale)
+		assert.Nil(t, err, "Expected no error")
+
+		events := published()
+		if assert.Len(t, events, 2, "Expected one event per product") {
+			assert.Equal(t, EventStockChanged, events[0].Type, "Expected a stock event")
+			assert.Equal(t, "acme", events[0].TenantID, "Expected the tenant of the sale")
+			assert.Equal(t, "Books", events[0].Category, "Expected the category of the product")
+			assert.Equal(t, "Games", events[1].Category, "Expected the category of the product")
+		}
+	})
+
+	t.Run("ApplySale_Rejected_Publishes_Nothing", func(t *testing.T) {
+		hub := NewHub(8, 8)
+		published := subscribe(hub)
+		mockStock := new(testutils.MockStockService)
+		sale := &request.SaleCreatedEvent{SaleID: "sale-1", Products: []request.SaleProductEvent{{ProductID: 1, Quantity: 9}}}
+		mockStock.On("ApplySale", mock.Anything, sale).Return(&response.StockCompensationEvent{SaleID: "sale-1"}, nil)
+
+		compensation, err := NewStockServiceNotifier(mockStock, new(testutils.MockProductService), hub).ApplySale(ctx, sale)
+		assert.Nil(t, err, "Expected no error")
+		assert.NotNil(t, compensation, "Expected the compensation")
+
+		assert.Empty(t, published(), "Expected no events")
+	})
+
+	t.Run("AdjustStock_Publishes_Stock_Change", func(t *testing.T) {
+		hub := NewHub(8, 8)
+		published := subscribe(hub)
+		mockStock := new(testutils.MockStockService)
+		mockStock.On("AdjustStock", mock.Anything, uint(1), &request.AdjustStockRequest{Delta: 2}).Return(&book, nil)
+
+		_, err := NewStockServiceNotifier(mockStock, new(testutils.MockProductService), hub).AdjustStock(ctx, 1, &request.AdjustStockRequest{Delta: 2})
+		assert.Nil(t, err, "Expected no error")
+
+		events := published()
+		if assert.Len(t, events, 1, "Expected one event") {
+			assert.Equal(t, EventStockChanged, events[0].Type, "Expected a stock event")
+			assert.Equal(t, "acme", events[0].TenantID, "Expected the tenant of the request")
+			assert.Equal(t, "Books", events[0].Category, "Expected the category of the product")
+		}
+	})
+}
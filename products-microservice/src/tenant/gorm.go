@@ -0,0 +1,99 @@
+package tenant
+
+import (
+	"context"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const fieldName = "TenantID"
+
+// RegisterCallbacks scopes every statement on a model with a TenantID field to the
+// tenant of the statement context. Creates get the tenant assigned, reads, updates
+// and deletes get a tenant condition. Statements without a tenant in their context
+// fail with ErrMissingTenant instead of touching every tenant, unless the context
+// comes from AcrossTenants.
+func RegisterCallbacks(db *gorm.DB) error {
+	callbacks := db.Callback()
+
+	err := callbacks.Create().Before("gorm:create").Register("tenant:create", assignTenant)
+	if err != nil {
+		return err
+	}
+	err = callbacks.Query().Before("gorm:query").Register("tenant:query", scopeTenant)
+	if err != nil {
+		return err
+	}
+	err = callbacks.Update().Before("gorm:update").Register("tenant:update", scopeTenant)
+	if err != nil {
+		return err
+	}
+	err = callbacks.Delete().Before("gorm:delete").Register("tenant:delete", scopeTenant)
+	if err != nil {
+		return err
+	}
+	return callbacks.Row().Before("gorm:row").Register("tenant:row", scopeTenant)
+}
+
+func tenantField(db *gorm.DB) *schema.Field {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return nil
+	}
+	return db.Statement.Schema.LookUpField(fieldName)
+}
+
+func statementTenant(db *gorm.DB) (string, bool) {
+	tenantID, ok := FromContext(db.Statement.Context)
+	if !ok {
+		_ = db.AddError(ErrMissingTenant)
+	}
+	return tenantID, ok
+}
+
+func scopeTenant(db *gorm.DB) {
+	field := tenantField(db)
+	if field == nil || isAcrossTenants(db.Statement.Context) {
+		return
+	}
+
+	tenantID, ok := statementTenant(db)
+	if !ok {
+		return
+	}
+
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: tenantID},
+	}})
+}
+
+func assignTenant(db *gorm.DB) {
+	field := tenantField(db)
+	if field == nil {
+		return
+	}
+
+	tenantID, ok := statementTenant(db)
+	if !ok {
+		return
+	}
+
+	// Whatever the caller put in TenantID is overwritten, the context is the only source
+	setTenant(db.Statement.Context, field, db.Statement.ReflectValue, tenantID, db)
+}
+
+func setTenant(ctx context.Context, field *schema.Field, value reflect.Value, tenantID string, db *gorm.DB) {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			setTenant(ctx, field, reflect.Indirect(value.Index(i)), tenantID, db)
+		}
+	case reflect.Struct:
+		err := field.Set(ctx, value, tenantID)
+		if err != nil {
+			_ = db.AddError(err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"net"
+	"regexp"
+	"strings"
+)
+
+const (
+	DefaultTenantID = "default"
+	HeaderName      = "X-Tenant-ID"
+)
+
+var (
+	ErrMissingTenant  = errors.New("tenant not set")
+	ErrInvalidTenant  = errors.New("invalid tenant")
+	ErrTenantMismatch = errors.New("requested tenant does not match the credentials")
+)
+
+// Tenant IDs end up in subdomains and log lines, keep them to DNS label characters
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+func Valid(tenantID string) bool {
+	return tenantIDPattern.MatchString(tenantID)
+}
+
+type tenantKey struct{}
+
+type acrossTenantsKey struct{}
+
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// AcrossTenants lets reads, updates and deletes run on every tenant, for lookups that
+// happen before the tenant of a request is known such as authenticating an API key.
+// Creates still need a tenant.
+func AcrossTenants(ctx context.Context) context.Context {
+	return context.WithValue(ctx, acrossTenantsKey{}, true)
+}
+
+func isAcrossTenants(ctx context.Context) bool {
+	across, _ := ctx.Value(acrossTenantsKey{}).(bool)
+	return across
+}
+
+func FromContext(ctx context.Context) (string, bool) {
+	tenantID, ok := ctx.Value(tenantKey{}).(string)
+	return tenantID, ok && tenantID != ""
+}
+
+// Resolve picks the tenant of a request. A tenant bound to the caller's credentials
+// wins and any other requested tenant is rejected, otherwise the requested tenant is
+// used and callers that ask for nothing get defaultTenantID.
+func Resolve(boundTenantID string, requestedTenantID string, defaultTenantID string) (string, error) {
+	tenantID := requestedTenantID
+	if boundTenantID != "" {
+		if requestedTenantID != "" && requestedTenantID != boundTenantID {
+			return "", ErrTenantMismatch
+		}
+		tenantID = boundTenantID
+	}
+	if tenantID == "" {
+		tenantID = defaultTenantID
+	}
+
+	if tenantID == "" {
+		return "", ErrMissingTenant
+	}
+	if !Valid(tenantID) {
+		return "", ErrInvalidTenant
+	}
+	return tenantID, nil
+}
+
+// ResolveAuthenticated is Resolve for authenticated callers. Credentials without a
+// tenant are bound to defaultTenantID, so they can't reach other tenants by naming
+// them, and are rejected when there is no default tenant.
+func ResolveAuthenticated(boundTenantID string, requestedTenantID string, defaultTenantID string) (string, error) {
+	if boundTenantID == "" {
+		if defaultTenantID == "" {
+			return "", ErrTenantMismatch
+		}
+		boundTenantID = defaultTenantID
+	}
+	return Resolve(boundTenantID, requestedTenantID, defaultTenantID)
+}
+
+// FromHost returns the subdomain of host directly under baseDomain, or an empty
+// string when host is not a subdomain of it
+func FromHost(host string, baseDomain string) string {
+	if baseDomain == "" {
+		return ""
+	}
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+
+	host = strings.ToLower(host)
+	suffix := "." + strings.ToLower(strings.TrimPrefix(baseDomain, "."))
+	if !strings.HasSuffix(host, suffix) {
+		return ""
+	}
+	return strings.TrimSuffix(host, suffix)
+}
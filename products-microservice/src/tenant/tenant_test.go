@@ -0,0 +1,62 @@
+package tenant
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenant(t *testing.T) {
+
+	t.Run("Valid", func(t *testing.T) {
+		assert.True(t, Valid("acme"), "Expected lowercase ID to be valid")
+		assert.True(t, Valid("acme-2"), "Expected dashes to be valid")
+		assert.False(t, Valid("-acme"), "Expected leading dash to be invalid")
+		assert.False(t, Valid("Acme"), "Expected uppercase to be invalid")
+		assert.False(t, Valid("acme.example"), "Expected dots to be invalid")
+		assert.False(t, Valid(""), "Expected empty ID to be invalid")
+	})
+
+	t.Run("Resolve", func(t *testing.T) {
+		tenantID, err := Resolve("", "", DefaultTenantID)
+		assert.Nil(t, err, "Expected no error resolving default tenant")
+		assert.Equal(t, DefaultTenantID, tenantID, "Expected default tenant")
+
+		tenantID, err = Resolve("acme", "", DefaultTenantID)
+		assert.Nil(t, err, "Expected no error resolving bound tenant")
+		assert.Equal(t, "acme", tenantID, "Expected bound tenant")
+
+		_, err = Resolve("acme", "globex", DefaultTenantID)
+		assert.ErrorIs(t, err, ErrTenantMismatch, "Expected mismatch error")
+
+		_, err = Resolve("", "", "")
+		assert.ErrorIs(t, err, ErrMissingTenant, "Expected missing tenant error")
+
+		_, err = Resolve("", "ACME", DefaultTenantID)
+		assert.ErrorIs(t, err, ErrInvalidTenant, "Expected invalid tenant error")
+	})
+
+	t.Run("ResolveAuthenticated", func(t *testing.T) {
+		tenantID, err := ResolveAuthenticated("", "", DefaultTenantID)
+		assert.Nil(t, err, "Expected no error resolving default tenant")
+		assert.Equal(t, DefaultTenantID, tenantID, "Expected default tenant")
+
+		tenantID, err = ResolveAuthenticated("", DefaultTenantID, DefaultTenantID)
+		assert.Nil(t, err, "Expected the default tenant to be requested")
+		assert.Equal(t, DefaultTenantID, tenantID, "Expected default tenant")
+
+		_, err = ResolveAuthenticated("", "globex", DefaultTenantID)
+		assert.ErrorIs(t, err, ErrTenantMismatch, "Expected unbound credentials to stay in the default tenant")
+
+		_, err = ResolveAuthenticated("", "globex", "")
+		assert.ErrorIs(t, err, ErrTenantMismatch, "Expected unbound credentials to be rejected without a default tenant")
+	})
+
+	t.Run("FromHost", func(t *testing.T) {
+		assert.Equal(t, "acme", FromHost("acme.example.com", "example.com"), "Expected subdomain")
+		assert.Equal(t, "acme", FromHost("ACME.example.com:8080", "example.com"), "Expected subdomain without port")
+		assert.Equal(t, "", FromHost("example.com", "example.com"), "Expected no subdomain for the base domain")
+		assert.Equal(t, "", FromHost("acme.other.com", "example.com"), "Expected no subdomain for other domains")
+		assert.Equal(t, "", FromHost("acme.example.com", ""), "Expected no subdomain without a base domain")
+	})
+}
@@ -0,0 +1,38 @@
+package testutils
+
+import (
+	"context"
+	"time"
+
+	"github.com/dieg0code/products-microservice/src/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockApiKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockApiKeyRepository) CreateApiKey(ctx context.Context, apiKey *models.ApiKey) (*models.ApiKey, error) {
+	args := m.Called(ctx, apiKey)
+	return args.Get(0).(*models.ApiKey), args.Error(1)
+}
+
+func (m *MockApiKeyRepository) GetApiKeyByPrefix(ctx context.Context, prefix string) (*models.ApiKey, error) {
+	args := m.Called(ctx, prefix)
+	return args.Get(0).(*models.ApiKey), args.Error(1)
+}
+
+func (m *MockApiKeyRepository) GetAllApiKeys(ctx context.Context) ([]models.ApiKey, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.ApiKey), args.Error(1)
+}
+
+func (m *MockApiKeyRepository) RevokeApiKey(ctx context.Context, apiKeyID uint, revokedAt time.Time) error {
+	args := m.Called(ctx, apiKeyID, revokedAt)
+	return args.Error(0)
+}
+
+func (m *MockApiKeyRepository) TouchApiKey(ctx context.Context, apiKeyID uint, usedAt time.Time) error {
+	args := m.Called(ctx, apiKeyID, usedAt)
+	return args.Error(0)
+}
@@ -0,0 +1,34 @@
+package testutils
+
+import (
+	"context"
+
+	"github.com/dieg0code/products-microservice/src/auth"
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockApiKeyService struct {
+	mock.Mock
+}
+
+func (m *MockApiKeyService) CreateApiKey(ctx context.Context, apiKey *request.CreateApiKeyRequest) (*response.CreatedApiKeyResponse, error) {
+	args := m.Called(ctx, apiKey)
+	return args.Get(0).(*response.CreatedApiKeyResponse), args.Error(1)
+}
+
+func (m *MockApiKeyService) GetAllApiKeys(ctx context.Context) ([]response.ApiKeyResponse, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]response.ApiKeyResponse), args.Error(1)
+}
+
+func (m *MockApiKeyService) RevokeApiKey(ctx context.Context, apiKeyID uint) error {
+	args := m.Called(ctx, apiKeyID)
+	return args.Error(0)
+}
+
+func (m *MockApiKeyService) AuthenticateApiKey(ctx context.Context, key string) (*auth.Principal, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(*auth.Principal), args.Error(1)
+}
@@ -1,6 +1,9 @@
 package testutils
 
 import (
+	"context"
+	"time"
+
 	"github.com/dieg0code/products-microservice/src/models"
 	"github.com/stretchr/testify/mock"
 )
@@ -9,31 +12,60 @@ type MockProductRepository struct {
 	mock.Mock
 }
 
-func (m *MockProductRepository) CreateProduct(product *models.Product) (*models.Product, error) {
-	args := m.Called(product)
+func (m *MockProductRepository) CreateProduct(ctx context.Context, product *models.Product) (*models.Product, error) {
+	args := m.Called(ctx, product)
 	return args.Get(0).(*models.Product), args.Error(1)
 }
-func (m *MockProductRepository) GetProductById(ProductID uint) (*models.Product, error) {
-	args := m.Called(ProductID)
+func (m *MockProductRepository) GetProductById(ctx context.Context, ProductID uint) (*models.Product, error) {
+	args := m.Called(ctx, ProductID)
 	return args.Get(0).(*models.Product), args.Error(1)
 }
-func (m *MockProductRepository) GetAllProducts(offset int, pageSize int) ([]models.Product, error) {
-	args := m.Called(offset, pageSize)
+func (m *MockProductRepository) GetAllProducts(ctx context.Context, offset int, pageSize int) ([]models.Product, error) {
+	args := m.Called(ctx, offset, pageSize)
 	return args.Get(0).([]models.Product), args.Error(1)
 }
-func (m *MockProductRepository) GetByCategory(category string) ([]models.Product, error) {
-	args := m.Called(category)
+func (m *MockProductRepository) GetByCategory(ctx context.Context, category string) ([]models.Product, error) {
+	args := m.Called(ctx, category)
 	return args.Get(0).([]models.Product), args.Error(1)
 }
-func (m *MockProductRepository) UpdateProduct(productID uint, product *models.Product) (*models.Product, error) {
-	args := m.Called(productID, product)
+func (m *MockProductRepository) UpdateProduct(ctx context.Context, productID uint, product *models.Product) (*models.Product, error) {
+	args := m.Called(ctx, productID, product)
 	return args.Get(0).(*models.Product), args.Error(1)
 }
-func (m *MockProductRepository) DeleteProduct(ProductID uint) error {
-	args := m.Called(ProductID)
+func (m *MockProductRepository) DeleteProduct(ctx context.Context, ProductID uint) error {
+	args := m.Called(ctx, ProductID)
 	return args.Error(0)
 }
-func (m *MockProductRepository) CheckProductExist(ProductID uint) (bool, error) {
-	args := m.Called(ProductID)
+func (m *MockProductRepository) CheckProductExist(ctx context.Context, ProductID uint) (bool, error) {
+	args := m.Called(ctx, ProductID)
 	return args.Bool(0), args.Error(1)
 }
+func (m *MockProductRepository) GetProductsByIds(ctx context.Context, productIDs []uint) ([]models.Product, error) {
+	args := m.Called(ctx, productIDs)
+	return args.Get(0).([]models.Product), args.Error(1)
+}
+func (m *MockProductRepository) ApplyOperations(ctx context.Context, operations []models.ProductOperation, atomic bool) ([]models.ProductOperationResult, error) {
+	args := m.Called(ctx, operations, atomic)
+	return args.Get(0).([]models.ProductOperationResult), args.Error(1)
+}
+func (m *MockProductRepository) GetByCategories(ctx context.Context, categories []string, offset int, pageSize int) ([]models.Product, error) {
+	args := m.Called(ctx, categories, offset, pageSize)
+	return args.Get(0).([]models.Product), args.Error(1)
+}
+func (m *MockProductRepository) CountByCategories(ctx context.Context, categories []string) (map[string]int64, error) {
+	args := m.Called(ctx, categories)
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+func (m *MockProductRepository) SearchProducts(ctx context.Context, filter models.ProductFilter, offset int, pageSize int) ([]models.Product, int64, error) {
+	args := m.Called(ctx, filter, offset, pageSize)
+	return args.Get(0).([]models.Product), args.Get(1).(int64), args.Error(2)
+}
+func (m *MockProductRepository) GetCategories(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockProductRepository) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	args := m.Called(ctx, deletedBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
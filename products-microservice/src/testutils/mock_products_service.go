@@ -1,6 +1,8 @@
 package testutils
 
 import (
+	"context"
+
 	"github.com/dieg0code/products-microservice/src/json/request"
 	"github.com/dieg0code/products-microservice/src/json/response"
 	"github.com/stretchr/testify/mock"
@@ -10,27 +12,51 @@ type MockProductService struct {
 	mock.Mock
 }
 
-func (m *MockProductService) CreateProduct(product *request.CreateProductRequest) (*uint, error) {
-	args := m.Called(product)
+func (m *MockProductService) CreateProduct(ctx context.Context, product *request.CreateProductRequest) (*uint, error) {
+	args := m.Called(ctx, product)
 	return args.Get(0).(*uint), args.Error(1)
 }
-func (m *MockProductService) GetProductById(productID uint) (*response.ProductResponse, error) {
-	args := m.Called(productID)
+func (m *MockProductService) GetProductById(ctx context.Context, productID uint) (*response.ProductResponse, error) {
+	args := m.Called(ctx, productID)
 	return args.Get(0).(*response.ProductResponse), args.Error(1)
 }
-func (m *MockProductService) GetAllProducts(page int, pageSize int) ([]response.ProductResponse, error) {
-	args := m.Called(page, pageSize)
+func (m *MockProductService) GetAllProducts(ctx context.Context, page int, pageSize int) ([]response.ProductResponse, error) {
+	args := m.Called(ctx, page, pageSize)
 	return args.Get(0).([]response.ProductResponse), args.Error(1)
 }
-func (m *MockProductService) GetByCategory(category string) ([]response.ProductResponse, error) {
-	args := m.Called(category)
+func (m *MockProductService) GetByCategory(ctx context.Context, category string) ([]response.ProductResponse, error) {
+	args := m.Called(ctx, category)
 	return args.Get(0).([]response.ProductResponse), args.Error(1)
 }
-func (m *MockProductService) UpdateProduct(productID uint, product *request.UpdateProductRequest) (*response.ProductResponse, error) {
-	args := m.Called(productID, product)
+func (m *MockProductService) UpdateProduct(ctx context.Context, productID uint, product *request.UpdateProductRequest) (*response.ProductResponse, error) {
+	args := m.Called(ctx, productID, product)
 	return args.Get(0).(*response.ProductResponse), args.Error(1)
 }
-func (m *MockProductService) DeleteProduct(ProductID uint) error {
-	args := m.Called(ProductID)
+func (m *MockProductService) DeleteProduct(ctx context.Context, ProductID uint) error {
+	args := m.Called(ctx, ProductID)
 	return args.Error(0)
 }
+func (m *MockProductService) GetProductsByIds(ctx context.Context, productIDs []uint) (*response.BatchGetProductsResponse, error) {
+	args := m.Called(ctx, productIDs)
+	return args.Get(0).(*response.BatchGetProductsResponse), args.Error(1)
+}
+func (m *MockProductService) ExecuteBatch(ctx context.Context, batch *request.BatchProductRequest) (*response.BatchProductResponse, error) {
+	args := m.Called(ctx, batch)
+	return args.Get(0).(*response.BatchProductResponse), args.Error(1)
+}
+func (m *MockProductService) GetByCategories(ctx context.Context, categories []string, offset int, pageSize int) (map[string][]response.ProductResponse, error) {
+	args := m.Called(ctx, categories, offset, pageSize)
+	return args.Get(0).(map[string][]response.ProductResponse), args.Error(1)
+}
+func (m *MockProductService) CountByCategories(ctx context.Context, categories []string) (map[string]int64, error) {
+	args := m.Called(ctx, categories)
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+func (m *MockProductService) SearchProducts(ctx context.Context, filter *request.ProductFilter, offset int, pageSize int) ([]response.ProductResponse, int64, error) {
+	args := m.Called(ctx, filter, offset, pageSize)
+	return args.Get(0).([]response.ProductResponse), args.Get(1).(int64), args.Error(2)
+}
+func (m *MockProductService) GetCategories(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]string), args.Error(1)
+}
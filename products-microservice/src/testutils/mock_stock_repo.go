@@ -0,0 +1,22 @@
+package testutils
+
+import (
+	"context"
+
+	"github.com/dieg0code/products-microservice/src/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockStockRepository struct {
+	mock.Mock
+}
+
+func (m *MockStockRepository) DecrementStockForSale(ctx context.Context, saleID string, items map[uint]int) ([]models.StockShortage, error) {
+	args := m.Called(ctx, saleID, items)
+	return args.Get(0).([]models.StockShortage), args.Error(1)
+}
+
+func (m *MockStockRepository) AdjustStock(ctx context.Context, productID uint, delta int) (*models.Product, error) {
+	args := m.Called(ctx, productID, delta)
+	return args.Get(0).(*models.Product), args.Error(1)
+}
@@ -0,0 +1,23 @@
+package testutils
+
+import (
+	"context"
+
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockStockService struct {
+	mock.Mock
+}
+
+func (m *MockStockService) ApplySale(ctx context.Context, sale *request.SaleCreatedEvent) (*response.StockCompensationEvent, error) {
+	args := m.Called(ctx, sale)
+	return args.Get(0).(*response.StockCompensationEvent), args.Error(1)
+}
+
+func (m *MockStockService) AdjustStock(ctx context.Context, productID uint, adjustment *request.AdjustStockRequest) (*response.ProductResponse, error) {
+	args := m.Called(ctx, productID, adjustment)
+	return args.Get(0).(*response.ProductResponse), args.Error(1)
+}
@@ -1,6 +1,7 @@
 package testutils
 
 import (
+	"github.com/dieg0code/products-microservice/src/tenant"
 	"github.com/glebarez/sqlite"
 	"gorm.io/gorm"
 )
@@ -11,6 +12,11 @@ func SetupTestDB(migrations ...interface{}) *gorm.DB {
 		panic("failed to connect database")
 	}
 
+	err = tenant.RegisterCallbacks(db)
+	if err != nil {
+		panic("failed to register tenant callbacks")
+	}
+
 	err = db.AutoMigrate(migrations...)
 	if err != nil {
 		panic("failed to migrate models")
@@ -0,0 +1,97 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanKey = "tracing:span"
+
+// RegisterCallbacks starts a client span around every GORM statement, as a child of
+// the span in the statement context. Missing records are not reported as errors.
+func RegisterCallbacks(db *gorm.DB, provider trace.TracerProvider) error {
+	tracer := provider.Tracer(InstrumentationName)
+	callbacks := db.Callback()
+
+	start := func(operation string) func(*gorm.DB) {
+		return func(db *gorm.DB) {
+			_, span := tracer.Start(db.Statement.Context, "gorm."+operation,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(semconv.DBSystemKey.String(db.Dialector.Name())),
+			)
+			db.InstanceSet(spanKey, span)
+		}
+	}
+
+	err := callbacks.Create().Before("gorm:create").Register("tracing:create_start", start("create"))
+	if err != nil {
+		return err
+	}
+	err = callbacks.Create().After("gorm:create").Register("tracing:create_end", endSpan)
+	if err != nil {
+		return err
+	}
+	err = callbacks.Query().Before("gorm:query").Register("tracing:query_start", start("query"))
+	if err != nil {
+		return err
+	}
+	err = callbacks.Query().After("gorm:query").Register("tracing:query_end", endSpan)
+	if err != nil {
+		return err
+	}
+	err = callbacks.Update().Before("gorm:update").Register("tracing:update_start", start("update"))
+	if err != nil {
+		return err
+	}
+	err = callbacks.Update().After("gorm:update").Register("tracing:update_end", endSpan)
+	if err != nil {
+		return err
+	}
+	err = callbacks.Delete().Before("gorm:delete").Register("tracing:delete_start", start("delete"))
+	if err != nil {
+		return err
+	}
+	err = callbacks.Delete().After("gorm:delete").Register("tracing:delete_end", endSpan)
+	if err != nil {
+		return err
+	}
+	err = callbacks.Row().Before("gorm:row").Register("tracing:row_start", start("row"))
+	if err != nil {
+		return err
+	}
+	err = callbacks.Row().After("gorm:row").Register("tracing:row_end", endSpan)
+	if err != nil {
+		return err
+	}
+	err = callbacks.Raw().Before("gorm:raw").Register("tracing:raw_start", start("raw"))
+	if err != nil {
+		return err
+	}
+	return callbacks.Raw().After("gorm:raw").Register("tracing:raw_end", endSpan)
+}
+
+func endSpan(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	// Values are left out of db.statement, the SQL only has placeholders
+	span.SetAttributes(
+		semconv.DBSQLTableKey.String(db.Statement.Table),
+		semconv.DBStatementKey.String(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.RowsAffected),
+	)
+	if !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		recordError(span, db.Error)
+	}
+}
@@ -0,0 +1,82 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dieg0code/products-microservice/src/models"
+	"github.com/dieg0code/products-microservice/src/tenant"
+	"github.com/dieg0code/products-microservice/src/testutils"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+func TestRegisterCallbacks(t *testing.T) {
+	setup := func() (*gorm.DB, *tracetest.SpanRecorder, *sdktrace.TracerProvider) {
+		db := testutils.SetupTestDB(&models.Product{})
+		recorder := tracetest.NewSpanRecorder()
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+		err := RegisterCallbacks(db, provider)
+		assert.Nil(t, err, "Expected no error registering callbacks")
+
+		return db, recorder, provider
+	}
+
+	t.Run("RegisterCallbacks_Spans_Are_Children", func(t *testing.T) {
+		db, recorder, provider := setup()
+		sqlDB, _ := db.DB()
+		defer sqlDB.Close()
+
+		ctx, parent := provider.Tracer(InstrumentationName).Start(tenant.WithTenant(context.Background(), tenant.DefaultTenantID), "parent")
+		product := &models.Product{Name: "Apple", Category: "Fruit", Price: 100, Stock: 10}
+		assert.Nil(t, db.WithContext(ctx).Create(product).Error, "Expected no error creating product")
+		parent.End()
+
+		spans := recorder.Ended()
+		assert.Equal(t, 2, len(spans), "Expected the create and parent spans")
+		assert.Equal(t, "gorm.create", spans[0].Name(), "Expected the create span")
+		assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind(), "Expected a client span")
+		assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID(), "Expected the create span to be a child")
+
+		attributes := map[string]interface{}{}
+		for _, attribute := range spans[0].Attributes() {
+			attributes[string(attribute.Key)] = attribute.Value.AsInterface()
+		}
+		assert.Equal(t, "products", attributes["db.sql.table"], "Expected the table")
+		assert.Equal(t, "sqlite", attributes["db.system"], "Expected the database system")
+		assert.Contains(t, attributes["db.statement"], "INSERT INTO", "Expected the statement")
+	})
+
+	t.Run("RegisterCallbacks_Not_Found_Is_Not_Error", func(t *testing.T) {
+		db, recorder, _ := setup()
+		sqlDB, _ := db.DB()
+		defer sqlDB.Close()
+
+		ctx := tenant.WithTenant(context.Background(), tenant.DefaultTenantID)
+		err := db.WithContext(ctx).First(&models.Product{}, 999).Error
+		assert.NotNil(t, err, "Expected record not found")
+
+		spans := recorder.Ended()
+		assert.Equal(t, 1, len(spans), "Expected the query span")
+		assert.Equal(t, "gorm.query", spans[0].Name(), "Expected the query span")
+		assert.NotEqual(t, codes.Error, spans[0].Status().Code, "Expected a missing record not to fail the span")
+	})
+
+	t.Run("RegisterCallbacks_Records_Errors", func(t *testing.T) {
+		db, recorder, _ := setup()
+		sqlDB, _ := db.DB()
+		defer sqlDB.Close()
+
+		err := db.WithContext(context.Background()).Find(&[]models.Product{}).Error
+		assert.ErrorIs(t, err, tenant.ErrMissingTenant, "Expected the tenant callbacks to reject the query")
+
+		spans := recorder.Ended()
+		assert.Equal(t, 1, len(spans), "Expected the query span")
+		assert.Equal(t, codes.Error, spans[0].Status().Code, "Expected the span to fail")
+	})
+}
@@ -0,0 +1,158 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/services"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ProductServiceTracer wraps every call to the ProductService in a span, so the time
+// spent in the service can be told apart from the HTTP layer and the queries
+type ProductServiceTracer struct {
+	service services.ProductService
+	tracer  trace.Tracer
+}
+
+// CreateProduct implements services.ProductService.
+func (t *ProductServiceTracer) CreateProduct(ctx context.Context, product *request.CreateProductRequest) (*uint, error) {
+	ctx, span := t.tracer.Start(ctx, "ProductService.CreateProduct")
+	defer span.End()
+
+	productID, err := t.service.CreateProduct(ctx, product)
+	recordError(span, err)
+	if productID != nil {
+		span.SetAttributes(attribute.Int64("product.id", int64(*productID)))
+	}
+	return productID, err
+}
+
+// GetProductById implements services.ProductService.
+func (t *ProductServiceTracer) GetProductById(ctx context.Context, productID uint) (*response.ProductResponse, error) {
+	ctx, span := t.tracer.Start(ctx, "ProductService.GetProductById", trace.WithAttributes(attribute.Int64("product.id", int64(productID))))
+	defer span.End()
+
+	product, err := t.service.GetProductById(ctx, productID)
+	recordError(span, err)
+	return product, err
+}
+
+// GetAllProducts implements services.ProductService.
+func (t *ProductServiceTracer) GetAllProducts(ctx context.Context, page int, pageSize int) ([]response.ProductResponse, error) {
+	ctx, span := t.tracer.Start(ctx, "ProductService.GetAllProducts", trace.WithAttributes(
+		attribute.Int("page", page),
+		attribute.Int("page_size", pageSize),
+	))
+	defer span.End()
+
+	products, err := t.service.GetAllProducts(ctx, page, pageSize)
+	recordError(span, err)
+	span.SetAttributes(attribute.Int("products.count", len(products)))
+	return products, err
+}
+
+// GetByCategory implements services.ProductService.
+func (t *ProductServiceTracer) GetByCategory(ctx context.Context, category string) ([]response.ProductResponse, error) {
+	ctx, span := t.tracer.Start(ctx, "ProductService.GetByCategory", trace.WithAttributes(attribute.String("product.category", category)))
+	defer span.End()
+
+	products, err := t.service.GetByCategory(ctx, category)
+	recordError(span, err)
+	span.SetAttributes(attribute.Int("products.count", len(products)))
+	return products, err
+}
+
+// UpdateProduct implements services.ProductService.
+func (t *ProductServiceTracer) UpdateProduct(ctx context.Context, productID uint, product *request.UpdateProductRequest) (*response.ProductResponse, error) {
+	ctx, span := t.tracer.Start(ctx, "ProductService.UpdateProduct", trace.WithAttributes(attribute.Int64("product.id", int64(productID))))
+	defer span.End()
+
+	updated, err := t.service.UpdateProduct(ctx, productID, product)
+	recordError(span, err)
+	return updated, err
+}
+
+// DeleteProduct implements services.ProductService.
+func (t *ProductServiceTracer) DeleteProduct(ctx context.Context, productID uint) error {
+	ctx, span := t.tracer.Start(ctx, "ProductService.DeleteProduct", trace.WithAttributes(attribute.Int64("product.id", int64(productID))))
+	defer span.End()
+
+	err := t.service.DeleteProduct(ctx, productID)
+	recordError(span, err)
+	return err
+}
+
+// GetProductsByIds implements services.ProductService.
+func (t *ProductServiceTracer) GetProductsByIds(ctx context.Context, productIDs []uint) (*response.BatchGetProductsResponse, error) {
+	ctx, span := t.tracer.Start(ctx, "ProductService.GetProductsByIds", trace.WithAttributes(attribute.Int("products.requested", len(productIDs))))
+	defer span.End()
+
+	products, err := t.service.GetProductsByIds(ctx, productIDs)
+	recordError(span, err)
+	return products, err
+}
+
+// GetByCategories implements services.ProductService.
+func (t *ProductServiceTracer) GetByCategories(ctx context.Context, categories []string, offset int, pageSize int) (map[string][]response.ProductResponse, error) {
+	ctx, span := t.tracer.Start(ctx, "ProductService.GetByCategories", trace.WithAttributes(
+		attribute.StringSlice("product.categories", categories),
+		attribute.Int("offset", offset),
+		attribute.Int("page_size", pageSize),
+	))
+	defer span.End()
+
+	products, err := t.service.GetByCategories(ctx, categories, offset, pageSize)
+	recordError(span, err)
+	return products, err
+}
+
+// CountByCategories implements services.ProductService.
+func (t *ProductServiceTracer) CountByCategories(ctx context.Context, categories []string) (map[string]int64, error) {
+	ctx, span := t.tracer.Start(ctx, "ProductService.CountByCategories", trace.WithAttributes(attribute.StringSlice("product.categories", categories)))
+	defer span.End()
+
+	totals, err := t.service.CountByCategories(ctx, categories)
+	recordError(span, err)
+	return totals, err
+}
+
+// SearchProducts implements services.ProductService.
+func (t *ProductServiceTracer) SearchProducts(ctx context.Context, filter *request.ProductFilter, offset int, pageSize int) ([]response.ProductResponse, int64, error) {
+	ctx, span := t.tracer.Start(ctx, "ProductService.SearchProducts", trace.WithAttributes(
+		attribute.Int("offset", offset),
+		attribute.Int("page_size", pageSize),
+	))
+	defer span.End()
+
+	products, total, err := t.service.SearchProducts(ctx, filter, offset, pageSize)
+	recordError(span, err)
+	span.SetAttributes(attribute.Int64("products.total", total))
+	return products, total, err
+}
+
+// GetCategories implements services.ProductService.
+func (t *ProductServiceTracer) GetCategories(ctx context.Context) ([]string, error) {
+	ctx, span := t.tracer.Start(ctx, "ProductService.GetCategories")
+	defer span.End()
+
+	categories, err := t.service.GetCategories(ctx)
+	recordError(span, err)
+	return categories, err
+}
+
+// ExecuteBatch implements services.ProductService.
+func (t *ProductServiceTracer) ExecuteBatch(ctx context.Context, batch *request.BatchProductRequest) (*response.BatchProductResponse, error) {
+	ctx, span := t.tracer.Start(ctx, "ProductService.ExecuteBatch", trace.WithAttributes(attribute.Int("batch.operations", len(batch.Operations))))
+	defer span.End()
+
+	result, err := t.service.ExecuteBatch(ctx, batch)
+	recordError(span, err)
+	return result, err
+}
+
+func NewProductServiceTracer(service services.ProductService, provider trace.TracerProvider) services.ProductService {
+	return &ProductServiceTracer{service: service, tracer: provider.Tracer(InstrumentationName)}
+}
@@ -0,0 +1,63 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/dieg0code/products-microservice/src/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestProductServiceTracer(t *testing.T) {
+	setup := func() (*testutils.MockProductService, *tracetest.SpanRecorder, *sdktrace.TracerProvider) {
+		recorder := tracetest.NewSpanRecorder()
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		return new(testutils.MockProductService), recorder, provider
+	}
+
+	t.Run("GetProductById_Starts_Span", func(t *testing.T) {
+		mockService, recorder, provider := setup()
+		tracer := NewProductServiceTracer(mockService, provider)
+
+		var serviceCtx context.Context
+		mockService.On("GetProductById", mock.Anything, uint(1)).Run(func(args mock.Arguments) {
+			serviceCtx = args.Get(0).(context.Context)
+		}).Return(&response.ProductResponse{ProductID: 1}, nil)
+
+		product, err := tracer.GetProductById(context.Background(), 1)
+		assert.Nil(t, err, "Expected no error")
+		assert.Equal(t, uint(1), product.ProductID, "Expected the product")
+
+		spans := recorder.Ended()
+		assert.Equal(t, 1, len(spans), "Expected one span")
+		assert.Equal(t, "ProductService.GetProductById", spans[0].Name(), "Expected the span to be named after the method")
+		assert.Equal(t, spans[0].SpanContext().SpanID(), trace.SpanContextFromContext(serviceCtx).SpanID(), "Expected the service to get the span context")
+		assert.Equal(t, codes.Unset, spans[0].Status().Code, "Expected the span not to fail")
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("DeleteProduct_Records_Error", func(t *testing.T) {
+		mockService, recorder, provider := setup()
+		tracer := NewProductServiceTracer(mockService, provider)
+
+		mockService.On("DeleteProduct", mock.Anything, uint(1)).Return(errors.New("database down"))
+
+		err := tracer.DeleteProduct(context.Background(), 1)
+		assert.NotNil(t, err, "Expected the error to be returned")
+
+		spans := recorder.Ended()
+		assert.Equal(t, 1, len(spans), "Expected one span")
+		assert.Equal(t, codes.Error, spans[0].Status().Code, "Expected the span to fail")
+		assert.Equal(t, "database down", spans[0].Status().Description, "Expected the error as description")
+
+		mockService.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,57 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestTracing(t *testing.T) {
+	t.Run("NewProvider_Invalid_Exporter", func(t *testing.T) {
+		_, err := NewProvider(context.Background(), Config{Exporter: "zipkin", SampleRatio: 1})
+		assert.True(t, errors.Is(err, ErrInvalidExporter), "Expected ErrInvalidExporter")
+	})
+
+	t.Run("NewProvider_Invalid_Sample_Ratio", func(t *testing.T) {
+		_, err := NewProvider(context.Background(), Config{Exporter: ExporterStdout, SampleRatio: 1.5})
+		assert.NotNil(t, err, "Expected an error for a ratio above 1")
+	})
+
+	t.Run("NewProvider_None", func(t *testing.T) {
+		provider, err := NewProvider(context.Background(), Config{Exporter: ExporterNone})
+		assert.Nil(t, err, "Expected no error")
+
+		_, span := provider.Tracer(InstrumentationName).Start(context.Background(), "test")
+		assert.False(t, span.IsRecording(), "Expected spans not to be recorded")
+		assert.Nil(t, provider.Shutdown(context.Background()), "Expected no error shutting down")
+	})
+
+	t.Run("LogrusHook_Adds_Trace_IDs", func(t *testing.T) {
+		provider := sdktrace.NewTracerProvider()
+		ctx, span := provider.Tracer(InstrumentationName).Start(context.Background(), "test")
+		defer span.End()
+
+		logger, hook := test.NewNullLogger()
+		logger.AddHook(LogrusHook{})
+
+		logger.WithContext(ctx).Info("with span")
+		entry := hook.LastEntry()
+		assert.Equal(t, span.SpanContext().TraceID().String(), entry.Data["trace_id"], "Expected the trace id")
+		assert.Equal(t, span.SpanContext().SpanID().String(), entry.Data["span_id"], "Expected the span id")
+
+		logger.WithField("product_id", 1).Info("without context")
+		entry = hook.LastEntry()
+		_, ok := entry.Data["trace_id"]
+		assert.False(t, ok, "Expected no trace id without a context")
+
+		logger.WithContext(context.Background()).Log(logrus.InfoLevel, "without span")
+		entry = hook.LastEntry()
+		_, ok = entry.Data["trace_id"]
+		assert.False(t, ok, "Expected no trace id without a span")
+	})
+}
@@ -0,0 +1,164 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	es_translations "github.com/go-playground/validator/v10/translations/es"
+)
+
+// universal holds the languages validation messages are written in, English is the
+// fallback
+var universal = ut.New(en.New(), en.New(), es.New())
+
+// translations registers the messages of each language on a validator
+var translations = map[string]func(*validator.Validate, ut.Translator) error{
+	"en": en_translations.RegisterDefaultTranslations,
+	"es": es_translations.RegisterDefaultTranslations,
+}
+
+// fallbackMessages explain rules the bundled translations don't cover
+var fallbackMessages = map[string]string{
+	"en": "%s failed on the %s rule",
+	"es": "%s no cumple la regla %s",
+}
+
+// Validator returns the validator shared by the service. It names fields by their
+// JSON names and can translate its errors to every supported language. Translations
+// live in the shared translators, so there can only be one such validator; it is
+// safe for concurrent use.
+var Validator = sync.OnceValue(func() *validator.Validate {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(jsonName)
+
+	for locale, register := range translations {
+		trans, _ := universal.GetTranslator(locale)
+		if err := register(validate, trans); err != nil {
+			panic(fmt.Sprintf("validation: registering %s translations: %v", locale, err))
+		}
+	}
+	return validate
+})
+
+// FieldErrors lists the fields err reports with messages in the language preferred
+// by acceptLanguage. It returns nil when err isn't a validation error. Errors of
+// other validators keep their Go field names and untranslated rules.
+func FieldErrors(err error, acceptLanguage string) []response.FieldError {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+
+	trans := Translator(acceptLanguage)
+	fieldErrors := make([]response.FieldError, 0, len(validationErrors))
+	for _, fieldError := range validationErrors {
+		field := fieldPath(fieldError.Namespace())
+		message := fieldError.Translate(trans)
+		if message == fieldError.Error() {
+			message = fmt.Sprintf(fallbackMessages[trans.Locale()], fieldError.Field(), fieldError.Tag())
+		}
+		fieldErrors = append(fieldErrors, response.FieldError{
+			Field:   field,
+			Rule:    fieldError.Tag(),
+			Param:   fieldError.Param(),
+			Message: message,
+		})
+	}
+	return fieldErrors
+}
+
+// Translator picks the first language of an Accept-Language header that has
+// messages, trying the base language of regional tags like es-CL, and falls back
+// to English
+func Translator(acceptLanguage string) ut.Translator {
+	for _, tag := range preferredLanguages(acceptLanguage) {
+		locale := strings.ReplaceAll(tag, "-", "_")
+		if trans, found := universal.GetTranslator(locale); found {
+			return trans
+		}
+		if base, _, regional := strings.Cut(locale, "_"); regional {
+			if trans, found := universal.GetTranslator(base); found {
+				return trans
+			}
+		}
+	}
+	return universal.GetFallback()
+}
+
+// preferredLanguages returns the language tags of an Accept-Language header from
+// the most to the least preferred, leaving out wildcards and tags with q=0
+func preferredLanguages(acceptLanguage string) []string {
+	type weighted struct {
+		tag    string
+		weight float64
+	}
+
+	var languages []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		weight := 1.0
+		for _, param := range strings.Split(params, ";") {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if name != "q" {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				parsed = 0
+			}
+			weight = parsed
+		}
+		if weight <= 0 {
+			continue
+		}
+		languages = append(languages, weighted{tag: tag, weight: weight})
+	}
+
+	sort.SliceStable(languages, func(i, j int) bool {
+		return languages[i].weight > languages[j].weight
+	})
+
+	tags := make([]string, 0, len(languages))
+	for _, language := range languages {
+		tags = append(tags, language.tag)
+	}
+	return tags
+}
+
+// jsonName names a field by its json tag, or by its Go name when it has none
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
+
+// fieldPath drops the request type the validator starts namespaces with, e.g.
+// BatchRequest.operations[0].op becomes operations[0].op
+func fieldPath(namespace string) string {
+	_, path, found := strings.Cut(namespace, ".")
+	if !found {
+		return namespace
+	}
+	return path
+}
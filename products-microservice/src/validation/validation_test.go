@@ -0,0 +1,76 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dieg0code/products-microservice/src/json/request"
+	"github.com/dieg0code/products-microservice/src/json/response"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidation(t *testing.T) {
+
+	t.Run("FieldErrors_English", func(t *testing.T) {
+		err := Validator().Struct(&request.CreateProductRequest{Category: "Tools", Price: 100, Stock: 10})
+
+		fieldErrors := FieldErrors(err, "")
+		assert.Equal(t, []response.FieldError{
+			{Field: "name", Rule: "required", Param: "", Message: "name is a required field"},
+		}, fieldErrors, "Expected the JSON name and an English message")
+	})
+
+	t.Run("FieldErrors_Spanish", func(t *testing.T) {
+		err := Validator().Struct(&request.CreateApiKeyRequest{Name: "ci", Scopes: []string{"products:delete"}})
+
+		fieldErrors := FieldErrors(err, "es-CL")
+		assert.Len(t, fieldErrors, 1, "Expected one error")
+		assert.Equal(t, "scopes[0]", fieldErrors[0].Field, "Expected the index of the invalid scope")
+		assert.Equal(t, "oneof", fieldErrors[0].Rule, "Expected the failed rule")
+		assert.Equal(t, "products:read products:write stock:reserve", fieldErrors[0].Param, "Expected the allowed values")
+		assert.Equal(t, "scopes[0] debe ser uno de [products:read products:write stock:reserve]", fieldErrors[0].Message, "Expected a Spanish message")
+	})
+
+	t.Run("FieldErrors_Nested", func(t *testing.T) {
+		err := Validator().Struct(&request.BatchProductRequest{
+			Operations: []request.BatchProductOperation{
+				{Op: request.BatchOpDelete, ProductID: 1},
+				{Op: request.BatchOpUpdate, ProductID: 2, Product: &request.CreateProductRequest{Name: "Hammer", Category: "Tools", Price: 0, Stock: 1}},
+				{Op: request.BatchOpDelete},
+			},
+		})
+
+		fieldErrors := FieldErrors(err, "es")
+		assert.Len(t, fieldErrors, 2, "Expected one error per invalid field")
+		assert.Equal(t, "operations[1].product.price", fieldErrors[0].Field, "Expected the path by JSON names")
+		assert.Equal(t, "required", fieldErrors[0].Rule, "Expected the failed rule")
+		assert.Equal(t, "operations[2].product_id", fieldErrors[1].Field, "Expected the path by JSON names")
+		assert.Equal(t, "required_unless", fieldErrors[1].Rule, "Expected the failed rule")
+		assert.Equal(t, "Op create", fieldErrors[1].Param, "Expected the parameter of the rule")
+		assert.Equal(t, "product_id no cumple la regla required_unless", fieldErrors[1].Message, "Expected the fallback message for rules without a translation")
+	})
+
+	t.Run("FieldErrors_Other_Errors", func(t *testing.T) {
+		assert.Nil(t, FieldErrors(errors.New("boom"), "es"), "Expected no field errors")
+		assert.Nil(t, FieldErrors(nil, "es"), "Expected no field errors")
+	})
+
+	t.Run("Translator_Accept_Language", func(t *testing.T) {
+		cases := map[string]string{
+			"":                               "en",
+			"es":                             "es",
+			"es-CL":                          "es",
+			"es_cl":                          "es",
+			"fr-CA, es;q=0.5, en;q=0.4":      "es",
+			"en;q=0.5, es-CL;q=0.9":          "es",
+			"es;q=0, en":                     "en",
+			"*, es;q=0.1":                    "es",
+			"de, fr":                         "en",
+			"es;q=abc, en-US;q=0.8":          "en",
+			"es-419;level=1;q=0.7, en;q=0.6": "es",
+		}
+		for acceptLanguage, locale := range cases {
+			assert.Equal(t, locale, Translator(acceptLanguage).Locale(), "Expected %q to pick %s", acceptLanguage, locale)
+		}
+	})
+}